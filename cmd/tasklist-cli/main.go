@@ -0,0 +1,15 @@
+package main
+
+import (
+	"TaskList/internal/cli"
+	"context"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := cli.New().Run(ctx, os.Args[1:])
+	stop()
+	os.Exit(code)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"TaskList/internal/tui"
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+func main() {
+	configPath := flag.String("config", "", "config file shared with tasklist-cli")
+	server := flag.String("server", "", "server url")
+	refresh := flag.Duration("refresh", 10*time.Second, "interval of reloading the list, 0 disables it")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := tui.Run(ctx, tui.Options{
+		ConfigPath: *configPath,
+		Server:     *server,
+		Refresh:    *refresh,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	})
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "tasklist-tui:", err)
+		stop()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"TaskList/internal/config"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var errCheckFailed = errors.New("database check failed")
+
+// db check pings the database, compares the schema version with the binary and runs the sqlite checks
+func db(ctx context.Context, cfg *config.Config, args []string, out io.Writer) error {
+	if len(args) != 1 || args[0] != "check" {
+		return errUsage
+	}
+
+	s, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStorage(s, out)
+
+	failed := false
+	report := func(name string, err error) {
+		if err != nil {
+			failed = true
+			fmt.Fprintf(out, "FAIL %s: %s\n", name, err)
+			return
+		}
+		fmt.Fprintf(out, "ok   %s\n", name)
+	}
+
+	if err = s.Ping(); err != nil {
+		report("connection", err)
+		return errCheckFailed
+	}
+	report("connection", nil)
+
+	sc, err := storageSchema(s)
+	if err != nil {
+		return err
+	}
+	m, err := sc.Migrator()
+	if err != nil {
+		return err
+	}
+	current, latest, err := m.Versions(ctx)
+	if err != nil {
+		report("schema", err)
+	} else {
+		err = m.Check(ctx)
+		report(fmt.Sprintf("schema (database %d, binary %d)", current, latest), err)
+	}
+
+	problems, err := sc.Check(ctx)
+	if err != nil {
+		report("integrity", err)
+	}
+	for _, p := range problems {
+		report("integrity", errors.New(p))
+	}
+	if err == nil && len(problems) == 0 {
+		report("integrity", nil)
+	}
+
+	if failed {
+		return errCheckFailed
+	}
+	return nil
+}
@@ -2,68 +2,97 @@ package main
 
 import (
 	"TaskList/internal/config"
-	"TaskList/internal/controller"
-	"TaskList/internal/services/auth"
-	"TaskList/internal/services/tasks"
-	"TaskList/internal/storage/sqlite"
-	"github.com/go-chi/chi/v5"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log/slog"
-	"net/http"
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 )
 
+const usage = `usage: tasklist <command> [arguments]
+
+commands:
+  serve [--migrate]                        run the HTTP and gRPC servers (default)
+  migrate up|down|status|redo              manage the database schema
+  user create EMAIL [--password-stdin]     create a user
+  user disable|enable EMAIL                disable or enable a user
+  user reset-password EMAIL [--password-stdin]
+                                           set a new password for a user
+  db check                                 check schema version and database integrity
+
+configuration is read from ./config/local.yaml
+`
+
+var errUsage = errors.New("usage")
+
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:])
+	stop()
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Print(usage)
+		return 0
+	}
 
 	cfg, err := config.New()
 	if err != nil {
 		slog.Error("failed init config", slog.String("err", err.Error()))
-		os.Exit(1)
+		return 1
 	}
 
-	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	}))
-
-	log.Info("startup application")
-
-	s, err := sqlite.New(cfg.Storage.Sqlite.PathToDB)
-	if err != nil {
-		log.Error("failed init database", slog.String("err", err.Error()))
-		os.Exit(1)
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "serve":
+		err = serve(ctx, cfg, args)
+	case "migrate":
+		err = migrate(ctx, cfg, args, os.Stdout)
+	case "user":
+		err = user(ctx, cfg, args, os.Stdin, os.Stdout)
+	case "db":
+		err = db(ctx, cfg, args, os.Stdout)
+	default:
+		err = errUsage
 	}
-	log.Info("init database")
-	defer func() {
-		err = s.Close()
-		log.Warn("failed close connection to db", slog.String("err", err.Error()))
-	}()
-
-	r := chi.NewRouter()
-	log.Info("init router")
-
-	as := auth.NewServices(s, s, log, cfg)
-
-	ts := tasks.NewServices(s, s, s, cfg, log)
-	log.Info("init services")
 
-	c := controller.NewController(as, ts, r, log, cfg)
-	log.Info("new controller")
-	c.Handler()
-	log.Info("handler init")
-
-	log.Info("run server", slog.String("address", cfg.Http.Address))
-
-	srv := http.Server{
-		Addr:         cfg.Http.Address,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 5 * time.Second,
-		IdleTimeout:  10 * time.Second,
-		Handler:      r,
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage):
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	default:
+		fmt.Fprintf(os.Stderr, "tasklist %s: %s\n", cmd, err)
+		return 1
 	}
+}
 
-	if err := srv.ListenAndServe(); err != nil {
-		log.Error("stopping server", slog.String("error", err.Error()))
-		os.Exit(1)
+// parseFlags parses flags mixed with positional arguments like "user create EMAIL --password-stdin"
+func parseFlags(fls *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fls.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fls.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
 	}
+}
 
+// adminLogger writes service logs of admin commands to stderr, stdout is left for the command output
+func adminLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
 }
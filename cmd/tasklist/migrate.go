@@ -0,0 +1,78 @@
+package main
+
+import (
+	"TaskList/internal/config"
+	"context"
+	"fmt"
+	"github.com/pressly/goose/v3"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+func migrate(ctx context.Context, cfg *config.Config, args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	s, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStorage(s, out)
+
+	sc, err := storageSchema(s)
+	if err != nil {
+		return err
+	}
+	m, err := sc.Migrator()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		res, err := m.Up(ctx)
+		printResults(out, res)
+		if err != nil {
+			return err
+		}
+		if len(res) == 0 {
+			fmt.Fprintln(out, "no migrations to apply")
+		}
+		return nil
+	case "down":
+		res, err := m.Down(ctx)
+		if res != nil {
+			printResults(out, []*goose.MigrationResult{res})
+		}
+		return err
+	case "redo":
+		res, err := m.Redo(ctx)
+		printResults(out, res)
+		return err
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "APPLIED AT\tMIGRATION")
+		for _, st := range statuses {
+			applied := "pending"
+			if st.State == goose.StateApplied {
+				applied = st.AppliedAt.UTC().Format(time.DateTime)
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", applied, st.Source.Path)
+		}
+		return tw.Flush()
+	default:
+		return errUsage
+	}
+}
+
+func printResults(out io.Writer, res []*goose.MigrationResult) {
+	for _, r := range res {
+		fmt.Fprintln(out, r)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/controller"
+	"TaskList/internal/graphql"
+	"TaskList/internal/grpcapi"
+	"TaskList/internal/lib/events"
+	"TaskList/internal/services/auth"
+	"TaskList/internal/services/authz"
+	"TaskList/internal/services/caldav"
+	"TaskList/internal/services/calendar"
+	"TaskList/internal/services/comments"
+	"TaskList/internal/services/fields"
+	"TaskList/internal/services/notifications"
+	"TaskList/internal/services/projects"
+	"TaskList/internal/services/realtime"
+	"TaskList/internal/services/smartlists"
+	"TaskList/internal/services/tasks"
+	"TaskList/internal/services/tasksync"
+	"TaskList/internal/services/webhooks"
+	"TaskList/internal/services/workflow"
+	"TaskList/internal/services/workspaces"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+// serve runs the servers until ctx is done, the schema must match the migrations of the binary
+func serve(ctx context.Context, cfg *config.Config, args []string) error {
+	fls := flag.NewFlagSet("serve", flag.ContinueOnError)
+	autoMigrate := fls.Bool("migrate", cfg.Storage.AutoMigrate, "apply pending migrations before start")
+	if err := fls.Parse(args); err != nil {
+		return errUsage
+	}
+
+	log := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
+
+	log.Info("startup application")
+
+	s, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	log.Info("init database")
+	defer func() {
+		if err := s.Close(); err != nil {
+			log.Warn("failed close connection to db", slog.String("err", err.Error()))
+		}
+	}()
+
+	if sc, ok := s.(schema); ok {
+		if err = prepareSchema(ctx, sc, *autoMigrate, log); err != nil {
+			return err
+		}
+	} else {
+		log.Warn("storage is in memory, data is lost on exit")
+	}
+
+	if p, ok := s.(preparer); ok {
+		if err = p.Prepare(ctx); err != nil {
+			return err
+		}
+	}
+
+	r := chi.NewRouter()
+	log.Info("init router")
+
+	as := auth.NewServices(s, s, s, s, log, cfg)
+
+	ps := projects.NewServices(s, s, s, cfg, log)
+
+	ws := workflow.NewServices(s, s, s, s, s, cfg, log)
+
+	fs := fields.NewServices(s, s, s, s, s, cfg, log)
+
+	az := authz.NewServices(s, s, s, cfg, log)
+
+	wss := workspaces.NewServices(s, s, s, s, cfg, log)
+
+	bus := events.NewBus(log)
+
+	ts := tasks.NewServices(s, s, s, s, ws, fs, az, s, bus, cfg, log)
+
+	hub := events.NewHub(cfg.Events.ReplayBuffer, ts, log)
+	bus.Subscribe(hub.Publish)
+
+	ns := notifications.NewServices(s, s, s, s, ts, cfg, log)
+	bus.Subscribe(ns.HandleEvent)
+
+	rts := realtime.NewServices(ts, cfg, log)
+	bus.Subscribe(rts.HandleEvent)
+
+	whs := webhooks.NewServices(s, s, s, ts, cfg, log)
+	bus.Subscribe(whs.HandleEvent)
+	go whs.Run(ctx)
+
+	cms := comments.NewServices(s, s, s, ts, bus, cfg, log)
+
+	cs := calendar.NewServices(s, ts, s, cfg, log)
+
+	ss := smartlists.NewServices(s, s, s, ts, cfg, log)
+
+	sys := tasksync.NewServices(s, s, ts, az, s, cfg, log)
+
+	dav := caldav.NewServices(ts, s, s, az, ws, cfg, log)
+
+	gh := graphql.NewHandler(ts, as, ps, az, s, cfg, log)
+	log.Info("init services")
+
+	c := controller.NewController(controller.Services{
+		Auth:          as,
+		Tasks:         ts,
+		Calendar:      cs,
+		Projects:      ps,
+		Workflow:      ws,
+		Fields:        fs,
+		SmartLists:    ss,
+		Workspaces:    wss,
+		Authz:         az,
+		Comments:      cms,
+		Notifications: ns,
+		Webhooks:      whs,
+		Stream:        hub,
+		Realtime:      rts,
+		Sync:          sys,
+		CalDAV:        dav,
+		GraphQL:       gh,
+	}, r, log, cfg)
+	log.Info("new controller")
+	if err := c.Handler(); err != nil {
+		return fmt.Errorf("failed init handler: %w", err)
+	}
+	log.Info("handler init")
+
+	gs := grpcapi.New(as, ts, az, hub, cfg, log)
+	lis, err := net.Listen("tcp", cfg.GRPC.Address)
+	if err != nil {
+		return fmt.Errorf("failed listen grpc: %w", err)
+	}
+
+	srv := http.Server{
+		Addr:         cfg.Http.Address,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 5 * time.Second,
+		IdleTimeout:  10 * time.Second,
+		Handler:      r,
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		log.Info("run grpc server", slog.String("address", cfg.GRPC.Address))
+		errs <- gs.Serve(lis)
+	}()
+	go func() {
+		log.Info("run server", slog.String("address", cfg.Http.Address))
+		errs <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err = <-errs:
+		log.Error("stopping server", slog.String("error", err.Error()))
+	case <-ctx.Done():
+		log.Info("shutting down")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	// streams like task watch do not end by themselves, they are cut after the timeout
+	stopped := make(chan struct{})
+	go func() {
+		gs.GracefulStop()
+		close(stopped)
+	}()
+	if serr := srv.Shutdown(shutdownCtx); serr != nil && !errors.Is(serr, http.ErrServerClosed) {
+		log.Warn("failed shutdown server", slog.String("err", serr.Error()))
+	}
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		gs.Stop()
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// prepareSchema applies pending migrations when asked and refuses to serve with an outdated schema
+func prepareSchema(ctx context.Context, sc schema, autoMigrate bool, log *slog.Logger) error {
+	m, err := sc.Migrator()
+	if err != nil {
+		return err
+	}
+	if autoMigrate {
+		res, err := m.Up(ctx)
+		for _, r := range res {
+			log.Info("migration applied", slog.String("file", r.Source.Path), slog.Duration("duration", r.Duration))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return m.Check(ctx)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/graphql"
+	"TaskList/internal/lib/migrator"
+	"TaskList/internal/services/auth"
+	"TaskList/internal/services/authz"
+	"TaskList/internal/services/caldav"
+	"TaskList/internal/services/calendar"
+	"TaskList/internal/services/comments"
+	"TaskList/internal/services/fields"
+	"TaskList/internal/services/notifications"
+	"TaskList/internal/services/projects"
+	"TaskList/internal/services/smartlists"
+	"TaskList/internal/services/tasks"
+	"TaskList/internal/services/tasksync"
+	"TaskList/internal/services/webhooks"
+	"TaskList/internal/services/workflow"
+	"TaskList/internal/services/workspaces"
+	"TaskList/internal/storage/memory"
+	"TaskList/internal/storage/postgres"
+	"TaskList/internal/storage/sqlite"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var errNoSchema = errors.New("storage has no schema, the memory driver keeps data only while the process runs")
+
+// storage is everything the services need from the database, every driver implements it
+type storage interface {
+	auth.Provider
+	auth.Saver
+	auth.Updater
+	auth.AppPasswords
+	projects.Saver
+	projects.Provider
+	projects.Updater
+	workflow.StatusSaver
+	workflow.StatusProvider
+	workflow.StatusUpdater
+	workflow.ProjectProvider
+	workflow.TaskProvider
+	fields.Saver
+	fields.Provider
+	fields.Deleter
+	fields.ProjectProvider
+	fields.UserProvider
+	authz.MemberProvider
+	authz.WorkspaceProvider
+	authz.ProjectProvider
+	workspaces.Saver
+	workspaces.Provider
+	workspaces.Updater
+	workspaces.UserProvider
+	tasks.Saver
+	tasks.Provider
+	tasks.Updater
+	tasks.UserProvider
+	tasks.Transactor
+	notifications.Saver
+	notifications.Provider
+	notifications.Updater
+	notifications.UserProvider
+	webhooks.Saver
+	webhooks.Provider
+	webhooks.Updater
+	comments.Saver
+	comments.Provider
+	comments.UserProvider
+	calendar.TaskProvider
+	calendar.FeedTokens
+	smartlists.Saver
+	smartlists.Provider
+	smartlists.Updater
+	tasksync.Provider
+	tasksync.Saver
+	caldav.Projects
+	caldav.ObjectNames
+	graphql.Batch
+
+	Ping() error
+	Close() error
+}
+
+// schema is implemented by the sql drivers, the memory driver has nothing to migrate or check
+type schema interface {
+	Migrator() (*migrator.Migrator, error)
+	Check(ctx context.Context) ([]string, error)
+}
+
+// preparer is implemented by drivers which prepare their frequent queries once,
+// serve prepares them after the schema is checked
+type preparer interface {
+	Prepare(ctx context.Context) error
+}
+
+var (
+	_ storage  = (*sqlite.Storage)(nil)
+	_ storage  = (*postgres.Storage)(nil)
+	_ storage  = (*memory.Storage)(nil)
+	_ schema   = (*sqlite.Storage)(nil)
+	_ schema   = (*postgres.Storage)(nil)
+	_ preparer = (*sqlite.Storage)(nil)
+)
+
+func openStorage(cfg *config.Config) (storage, error) {
+	switch cfg.Storage.Driver {
+	case "", "sqlite":
+		s, err := sqlite.New(cfg.Storage.Sqlite.PathToDB, sqlite.Options{
+			JournalMode:  cfg.Storage.Sqlite.JournalMode,
+			Synchronous:  cfg.Storage.Sqlite.Synchronous,
+			ForeignKeys:  cfg.Storage.Sqlite.ForeignKeys,
+			BusyTimeout:  cfg.Storage.Sqlite.BusyTimeout,
+			MaxReadConns: cfg.Storage.Sqlite.MaxReadConns,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case "postgres":
+		s, err := postgres.New(cfg.Storage.Postgres.DSN, postgres.Options{
+			MaxOpenConns:    cfg.Storage.Postgres.MaxOpenConns,
+			ConnMaxLifetime: cfg.Storage.Postgres.ConnMaxLifetime,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	case "memory":
+		return memory.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
+
+// storageSchema returns the schema of the storage, errNoSchema for the memory driver
+func storageSchema(s storage) (schema, error) {
+	sc, ok := s.(schema)
+	if !ok {
+		return nil, errNoSchema
+	}
+	return sc, nil
+}
+
+func closeStorage(s storage, w io.Writer) {
+	if err := s.Close(); err != nil {
+		fmt.Fprintf(w, "failed close connection to db: %s\n", err)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/services/auth"
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"strings"
+)
+
+const minPasswordLen = 8
+
+func user(ctx context.Context, cfg *config.Config, args []string, in io.Reader, out io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	cmd := args[0]
+	fls := flag.NewFlagSet("user "+cmd, flag.ContinueOnError)
+	fls.SetOutput(io.Discard)
+	passwordStdin := fls.Bool("password-stdin", false, "read the password from stdin")
+	positional, err := parseFlags(fls, args[1:])
+	if err != nil || len(positional) != 1 {
+		return errUsage
+	}
+	email := positional[0]
+	if err = validator.New().Var(email, "required,email"); err != nil {
+		return fmt.Errorf("invalid email %q", email)
+	}
+
+	var password string
+	switch cmd {
+	case "create", "reset-password":
+		if password, err = readPassword(in, out, *passwordStdin); err != nil {
+			return err
+		}
+	case "disable", "enable":
+		if *passwordStdin {
+			return errUsage
+		}
+	default:
+		return errUsage
+	}
+
+	s, err := openStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStorage(s, os.Stderr)
+
+	// users created in the memory storage would be lost when the command exits
+	sc, err := storageSchema(s)
+	if err != nil {
+		return err
+	}
+	m, err := sc.Migrator()
+	if err != nil {
+		return err
+	}
+	if err = m.Check(ctx); err != nil {
+		return err
+	}
+
+	as := auth.NewServices(s, s, s, s, adminLogger(), cfg)
+
+	switch cmd {
+	case "create":
+		id, err := as.Registration(ctx, email, password)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "user %s created with id %d\n", email, id)
+	case "reset-password":
+		if err = as.ResetPassword(ctx, email, password); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "password of %s changed, tokens issued before stay valid until they expire\n", email)
+	case "disable":
+		if err = as.SetDisabled(ctx, email, true); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "user %s disabled, tokens issued before are rejected\n", email)
+	case "enable":
+		if err = as.SetDisabled(ctx, email, false); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "user %s enabled\n", email)
+	}
+
+	return nil
+}
+
+// readPassword reads the first line of stdin or asks twice on the terminal
+func readPassword(in io.Reader, out io.Writer, fromStdin bool) (string, error) {
+	var password string
+	if fromStdin {
+		line, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("failed read password: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	} else {
+		f, ok := in.(*os.File)
+		if !ok || !term.IsTerminal(int(f.Fd())) {
+			return "", errors.New("stdin is not a terminal, use --password-stdin")
+		}
+
+		first, err := prompt(f, out, "Password: ")
+		if err != nil {
+			return "", err
+		}
+		second, err := prompt(f, out, "Repeat password: ")
+		if err != nil {
+			return "", err
+		}
+		if first != second {
+			return "", errors.New("passwords do not match")
+		}
+		password = first
+	}
+
+	if len(password) < minPasswordLen {
+		return "", fmt.Errorf("password must be at least %d characters", minPasswordLen)
+	}
+	return password, nil
+}
+
+func prompt(f *os.File, out io.Writer, label string) (string, error) {
+	fmt.Fprint(out, label)
+	b, err := term.ReadPassword(int(f.Fd()))
+	fmt.Fprintln(out)
+	if err != nil {
+		return "", fmt.Errorf("failed read password: %w", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,229 @@
+// Package cli is the command line client of the REST API, cmd/tasklist-cli runs it,
+// Config is shared with cmd/tasklist-tui.
+// App writes only to its streams and talks to the server over its http client,
+// so it runs against an httptest server as well as a real one
+package cli
+
+import (
+	"TaskList/pkg/client"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Exit codes of Run
+const (
+	ExitOK       = 0
+	ExitError    = 1
+	ExitUsage    = 2
+	ExitAuth     = 3
+	ExitNotFound = 4
+)
+
+const usage = `Usage: tasklist-cli [--config FILE] [--server URL] COMMAND [ARGS]
+
+Commands:
+  login    log in and store the token
+  add      create a task, prints its id
+  list     list tasks
+  show     show a task
+  done     move tasks to the done status of their workflow
+  edit     change fields of a task
+  delete   delete tasks
+
+Run tasklist-cli COMMAND -h for the flags of the command.
+
+Exit codes: 0 success, 1 error, 2 usage error, 3 not logged in or session expired, 4 not found.
+The config is $TASKLIST_CONFIG or tasklist/cli.json in the user config dir,
+$TASKLIST_SERVER overrides the server stored by login.
+`
+
+var (
+	// errUsage is returned for invalid arguments after the usage is printed
+	errUsage = errors.New("usage")
+	errHelp  = errors.New("help")
+)
+
+type App struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// ConfigPath is the config file, DefaultConfigPath when empty
+	ConfigPath string
+	HTTPClient *http.Client
+	Now        func() time.Time
+}
+
+// New returns the app on the process streams
+func New() *App {
+	return &App{
+		Stdin:      os.Stdin,
+		Stdout:     os.Stdout,
+		Stderr:     os.Stderr,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		Now:        time.Now,
+	}
+}
+
+type command func(ctx context.Context, a *App, env *env, args []string) error
+
+var commands = map[string]command{
+	"login":  runLogin,
+	"add":    runAdd,
+	"list":   runList,
+	"ls":     runList,
+	"show":   runShow,
+	"done":   runDone,
+	"edit":   runEdit,
+	"delete": runDelete,
+	"rm":     runDelete,
+}
+
+// env is the state shared by commands
+type env struct {
+	configPath string
+	config     Config
+	client     *client.Client
+}
+
+// Run runs the command and returns the process exit code
+func (a *App) Run(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet("tasklist-cli", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configPath := fs.String("config", a.ConfigPath, "config file")
+	server := fs.String("server", "", "server url")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			_, _ = fmt.Fprint(a.Stdout, usage)
+			return ExitOK
+		}
+		_, _ = fmt.Fprintf(a.Stderr, "tasklist-cli: %s\n\n%s", err, usage)
+		return ExitUsage
+	}
+
+	args = fs.Args()
+	if len(args) == 0 || args[0] == "help" {
+		_, _ = fmt.Fprint(a.Stdout, usage)
+		if len(args) == 0 {
+			return ExitUsage
+		}
+		return ExitOK
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		_, _ = fmt.Fprintf(a.Stderr, "tasklist-cli: unknown command %q\n\n%s", args[0], usage)
+		return ExitUsage
+	}
+
+	e, err := a.env(*configPath, *server)
+	if err == nil {
+		err = cmd(ctx, a, e, args[1:])
+	}
+
+	return a.exitCode(args[0], err)
+}
+
+func (a *App) env(configPath string, server string) (*env, error) {
+	configPath, cfg, err := ResolveConfig(configPath, server)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []client.Option{client.WithToken(cfg.Token)}
+	if a.HTTPClient != nil {
+		opts = append(opts, client.WithHTTPClient(a.HTTPClient))
+	}
+	if a.Now != nil {
+		opts = append(opts, client.WithClock(a.Now))
+	}
+
+	return &env{configPath: configPath, config: cfg, client: client.New(cfg.Server, opts...)}, nil
+}
+
+func (a *App) exitCode(name string, err error) int {
+	if err == nil || errors.Is(err, errHelp) {
+		return ExitOK
+	}
+	if errors.Is(err, errUsage) {
+		return ExitUsage
+	}
+
+	auth := errors.Is(err, client.ErrNoToken) || errors.Is(err, client.ErrTokenExpired) || errors.Is(err, client.ErrUnauthorized)
+
+	msg := err.Error()
+	switch {
+	case errors.Is(err, client.ErrNoToken):
+		msg = "not logged in, run: tasklist-cli login"
+	case auth:
+		msg = "session expired, run: tasklist-cli login"
+	}
+	_, _ = fmt.Fprintf(a.Stderr, "tasklist-cli %s: %s\n", name, msg)
+
+	switch {
+	case auth:
+		return ExitAuth
+	case errors.Is(err, client.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, client.ErrBadRequest):
+		return ExitUsage
+	default:
+		return ExitError
+	}
+}
+
+// parseFlags parses flags mixed with positional arguments like "add Pay rent --tag home",
+// the usage of the command is printed on -h and on invalid flags
+func (a *App) parseFlags(fs *flag.FlagSet, args []string, synopsis string) ([]string, error) {
+	fs.SetOutput(a.Stderr)
+	fs.Usage = func() {
+		_, _ = fmt.Fprintf(a.Stderr, "Usage: tasklist-cli %s %s\n", fs.Name(), synopsis)
+		fs.PrintDefaults()
+	}
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, errHelp
+			}
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// usageError prints the message and the usage of the command
+func (a *App) usageError(fs *flag.FlagSet, format string, args ...any) error {
+	_, _ = fmt.Fprintf(a.Stderr, "tasklist-cli %s: %s\n", fs.Name(), fmt.Sprintf(format, args...))
+	fs.Usage()
+	return errUsage
+}
+
+// stringList is a repeatable flag, values may also be comma separated
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(v string) error {
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*s = append(*s, item)
+		}
+	}
+	return nil
+}
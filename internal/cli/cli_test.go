@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"TaskList/pkg/client"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+
+// testToken is an unsigned JWT, the client reads only exp and the fake server compares the whole token
+func testToken(exp time.Time) string {
+	enc := base64.RawURLEncoding
+	payload := fmt.Sprintf(`{"uid":1,"exp":%d}`, exp.Unix())
+	return enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc.EncodeToString([]byte(payload)) + ".sig"
+}
+
+var validToken = testToken(now.Add(time.Hour))
+
+// server is the part of the REST API the commands use
+type server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	tasks    map[int64]*client.Task
+	requests []string
+}
+
+func newServer(t *testing.T) *server {
+	project := int64(7)
+	s := &server{tasks: map[int64]*client.Task{
+		1: {ID: 1, UserID: 1, Title: "Pay rent", Status: "Pending", Priority: "high", Tags: []string{"home"}, CreatedAt: now, UpdatedAt: now},
+		2: {ID: 2, UserID: 1, ProjectID: &project, Title: "Write report", Status: "Review", CreatedAt: now, UpdatedAt: now},
+	}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Email, Password string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Email != "me@example.com" || req.Password != "secret" {
+			respond(w, http.StatusUnauthorized, map[string]string{"status": "Error", "error": "invalid credentials"})
+			return
+		}
+		respond(w, http.StatusOK, map[string]string{"status": "OK", "token": validToken})
+	})
+	mux.HandleFunc("GET /api/v1/tasks", s.auth(func(w http.ResponseWriter, r *http.Request) {
+		var tasks []client.Task
+		for id := range int64(len(s.tasks)) {
+			tasks = append(tasks, *s.tasks[id+1])
+		}
+		respond(w, http.StatusOK, map[string]any{"status": "OK", "tasks": tasks})
+	}))
+	mux.HandleFunc("GET /api/v1/tasks/{id}", s.auth(func(w http.ResponseWriter, r *http.Request) {
+		task, ok := s.task(r)
+		if !ok {
+			respond(w, http.StatusNotFound, map[string]string{"status": "Error", "error": "task not found"})
+			return
+		}
+		respond(w, http.StatusOK, map[string]any{"status": "OK", "tasks": []client.Task{*task}})
+	}))
+	mux.HandleFunc("PATCH /api/v1/tasks/{id}", s.auth(func(w http.ResponseWriter, r *http.Request) {
+		task, ok := s.task(r)
+		if !ok {
+			respond(w, http.StatusNotFound, map[string]string{"status": "Error", "error": "task not found"})
+			return
+		}
+		var patch client.UpdateTaskRequest
+		_ = json.NewDecoder(r.Body).Decode(&patch)
+		if patch.Status != nil {
+			task.Status = *patch.Status
+		}
+		respond(w, http.StatusOK, map[string]any{"status": "OK", "tasks": []client.Task{*task}})
+	}))
+	mux.HandleFunc("GET /api/v1/statuses", s.auth(func(w http.ResponseWriter, r *http.Request) {
+		done := "Done"
+		if r.URL.Query().Get("project_id") == "7" {
+			done = "Closed"
+		}
+		respond(w, http.StatusOK, map[string]any{"status": "OK", "statuses": []client.WorkflowStatus{
+			{Name: "Pending", Category: "todo"},
+			{Name: "Review", Category: "in_progress", Position: 1},
+			{Name: done, Category: "done", Position: 2},
+		}})
+	}))
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// auth answers 401 with a plain JSON string like the JWT middleware
+func (s *server) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+validToken {
+			respond(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *server) task(r *http.Request) (*client.Task, bool) {
+	id, _ := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	task, ok := s.tasks[id]
+	return task, ok
+}
+
+func respond(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+type run struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+func runApp(t *testing.T, configPath string, stdin string, args ...string) run {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	a := &App{
+		Stdin:      strings.NewReader(stdin),
+		Stdout:     &stdout,
+		Stderr:     &stderr,
+		ConfigPath: configPath,
+		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+		Now:        func() time.Time { return now },
+	}
+	code := a.Run(context.Background(), args)
+
+	return run{code: code, stdout: stdout.String(), stderr: stderr.String()}
+}
+
+// loggedIn writes the config with the token as login does
+func loggedIn(t *testing.T, s *server, token string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "cli.json")
+	if err := SaveConfig(path, Config{Server: s.URL, Email: "me@example.com", Token: token}); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+	return path
+}
+
+func TestLogin(t *testing.T) {
+	s := newServer(t)
+	path := filepath.Join(t.TempDir(), "tasklist", "cli.json")
+
+	r := runApp(t, path, "secret\n", "--server", s.URL, "login", "--email", "me@example.com", "--password-stdin")
+	if r.code != ExitOK {
+		t.Fatalf("login exit code = %d, stderr: %s", r.code, r.stderr)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("config is not written: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("config mode = %o, want 600", info.Mode().Perm())
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	want := Config{Server: s.URL, Email: "me@example.com", Token: validToken}
+	if cfg != want {
+		t.Errorf("config = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestLoginWrongPassword(t *testing.T) {
+	s := newServer(t)
+	path := filepath.Join(t.TempDir(), "cli.json")
+
+	r := runApp(t, path, "wrong\n", "--server", s.URL, "login", "--email", "me@example.com", "--password-stdin")
+	if r.code != ExitAuth {
+		t.Errorf("login exit code = %d, want %d", r.code, ExitAuth)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("config is written after failed login: %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	s := newServer(t)
+	path := loggedIn(t, s, validToken)
+
+	r := runApp(t, path, "", "list")
+	if r.code != ExitOK {
+		t.Fatalf("list exit code = %d, stderr: %s", r.code, r.stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(r.stdout), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "ID") {
+		t.Fatalf("list table:\n%s", r.stdout)
+	}
+	if !strings.Contains(lines[1], "Pay rent") || !strings.Contains(lines[1], "high") || !strings.Contains(lines[1], "home") {
+		t.Errorf("first row = %q", lines[1])
+	}
+
+	r = runApp(t, path, "", "list", "-o", "json")
+	var tasks []client.Task
+	if err := json.Unmarshal([]byte(r.stdout), &tasks); err != nil {
+		t.Fatalf("list -o json output is not json: %v\n%s", err, r.stdout)
+	}
+	if len(tasks) != 2 || tasks[1].Title != "Write report" {
+		t.Errorf("tasks = %+v", tasks)
+	}
+}
+
+func TestShow(t *testing.T) {
+	s := newServer(t)
+	path := loggedIn(t, s, validToken)
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+		want string
+	}{
+		{"table", []string{"show", "2"}, ExitOK, "Project:     7"},
+		{"json", []string{"show", "1", "-o", "json"}, ExitOK, `"title": "Pay rent"`},
+		{"not found", []string{"show", "99"}, ExitNotFound, ""},
+		{"invalid id", []string{"show", "abc"}, ExitUsage, ""},
+		{"two ids", []string{"show", "1", "2"}, ExitUsage, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := runApp(t, path, "", tt.args...)
+			if r.code != tt.code {
+				t.Fatalf("exit code = %d, want %d, stderr: %s", r.code, tt.code, r.stderr)
+			}
+			if !strings.Contains(r.stdout, tt.want) {
+				t.Errorf("output does not contain %q:\n%s", tt.want, r.stdout)
+			}
+		})
+	}
+}
+
+// done uses the done status of the workflow of every task project
+func TestDone(t *testing.T) {
+	s := newServer(t)
+	path := loggedIn(t, s, validToken)
+
+	r := runApp(t, path, "", "done", "1", "2")
+	if r.code != ExitOK {
+		t.Fatalf("done exit code = %d, stderr: %s", r.code, r.stderr)
+	}
+	if s.tasks[1].Status != "Done" || s.tasks[2].Status != "Closed" {
+		t.Errorf("statuses = %q, %q, want Done, Closed", s.tasks[1].Status, s.tasks[2].Status)
+	}
+
+	r = runApp(t, path, "", "done", "1", "99")
+	if r.code != ExitNotFound || !strings.Contains(r.stderr, "task 99") {
+		t.Errorf("done of missing task exit code = %d, stderr: %s", r.code, r.stderr)
+	}
+}
+
+func TestSessionExpired(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		// sent is whether the request reaches the server
+		sent bool
+		want string
+	}{
+		{"expired token", testToken(now.Add(-time.Minute)), false, "session expired"},
+		{"token rejected by server", testToken(now.Add(2 * time.Hour)), true, "session expired"},
+		{"not logged in", "", false, "not logged in"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t)
+			path := loggedIn(t, s, tt.token)
+
+			r := runApp(t, path, "", "list")
+			if r.code != ExitAuth {
+				t.Errorf("exit code = %d, want %d", r.code, ExitAuth)
+			}
+			if !strings.Contains(r.stderr, tt.want) {
+				t.Errorf("stderr = %q, want %q", r.stderr, tt.want)
+			}
+			if sent := len(s.requests) > 0; sent != tt.sent {
+				t.Errorf("requests = %v, sent want %v", s.requests, tt.sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,442 @@
+package cli
+
+import (
+	"TaskList/pkg/client"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+func runLogin(ctx context.Context, a *App, e *env, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	email := fs.String("email", e.config.Email, "account email, asked when empty")
+	passwordStdin := fs.Bool("password-stdin", false, "read the password from stdin")
+
+	if _, err := a.parseFlags(fs, args, "[--email EMAIL] [--password-stdin]"); err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(a.Stdin)
+	if *email == "" {
+		_, _ = fmt.Fprint(a.Stderr, "Email: ")
+		line, err := readLine(in)
+		if err != nil {
+			return err
+		}
+		*email = line
+	}
+
+	password, err := a.readPassword(in, *passwordStdin)
+	if err != nil {
+		return err
+	}
+
+	token, err := e.client.Login(ctx, *email, password)
+	if err != nil {
+		return err
+	}
+
+	e.config.Email = *email
+	e.config.Token = token
+	if err = SaveConfig(e.configPath, e.config); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(a.Stderr, "Logged in to %s as %s\n", e.config.Server, *email)
+	return nil
+}
+
+// readPassword reads without echo from the terminal, scripts pipe the password to stdin
+func (a *App) readPassword(in *bufio.Reader, fromStdin bool) (string, error) {
+	if f, ok := a.Stdin.(*os.File); ok && !fromStdin && term.IsTerminal(int(f.Fd())) {
+		_, _ = fmt.Fprint(a.Stderr, "Password: ")
+		password, err := term.ReadPassword(int(f.Fd()))
+		_, _ = fmt.Fprintln(a.Stderr)
+		return string(password), err
+	}
+	return readLine(in)
+}
+
+func runAdd(ctx context.Context, a *App, e *env, args []string) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	description := fs.String("desc", "", "description")
+	priority := fs.String("priority", "", "low, medium or high")
+	due := fs.String("due", "", "due date: 2026-03-02, \"2026-03-02 18:00\" or RFC 3339")
+	project := fs.Int64("project", 0, "project id")
+	quick := fs.Bool("quick", false, "let the server parse the line: \"Pay rent tomorrow 9am #home !high\"")
+	var tags stringList
+	fs.Var(&tags, "tag", "tag, repeatable")
+
+	positional, err := a.parseFlags(fs, args, "TITLE... [flags]")
+	if err != nil {
+		return err
+	}
+	title := strings.Join(positional, " ")
+	if title == "" {
+		return a.usageError(fs, "title is required")
+	}
+
+	var id int64
+	if *quick {
+		id, err = e.client.QuickAdd(ctx, title)
+	} else {
+		req := client.TaskRequest{Title: title, Description: *description, Priority: *priority, Tags: tags}
+		if *project != 0 {
+			req.ProjectID = project
+		}
+		if *due != "" {
+			t, perr := parseDue(*due)
+			if perr != nil {
+				return a.usageError(fs, "%s", perr)
+			}
+			req.Due = &t
+		}
+		id, err = e.client.CreateTask(ctx, req)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(a.Stdout, id)
+	return nil
+}
+
+func runList(ctx context.Context, a *App, e *env, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	scope := fs.String("scope", "", "owned (default), assigned, shared or all")
+	project := fs.Int64("project", 0, "project id")
+	workspace := fs.Int64("workspace", 0, "workspace id")
+	search := fs.String("q", "", "text in title or description")
+	due := fs.String("due", "", "overdue, today, upcoming or none")
+	sort := fs.String("sort", "", "due, priority, created, updated or title, - prefix sorts descending")
+	output := fs.String("o", formatTable, "output format: table or json")
+	var statuses, tags stringList
+	fs.Var(&statuses, "status", "status name, repeatable")
+	fs.Var(&tags, "tag", "tag, repeatable")
+
+	if _, err := a.parseFlags(fs, args, "[flags]"); err != nil {
+		return err
+	}
+	if err := checkFormat(a, fs, *output); err != nil {
+		return err
+	}
+
+	filter := client.TaskFilter{Scope: *scope, Statuses: statuses, Tags: tags, Text: *search, Due: *due, Sort: *sort}
+	if *project != 0 {
+		filter.ProjectID = project
+	}
+	if *workspace != 0 {
+		filter.WorkspaceID = workspace
+	}
+
+	tasks, err := e.client.Tasks(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	if *output == formatJSON {
+		if tasks == nil {
+			tasks = []client.Task{}
+		}
+		return writeJSON(a.Stdout, tasks)
+	}
+	return writeTable(a.Stdout, tasks)
+}
+
+func runShow(ctx context.Context, a *App, e *env, args []string) error {
+	fs := flag.NewFlagSet("show", flag.ContinueOnError)
+	output := fs.String("o", formatTable, "output format: table or json")
+
+	positional, err := a.parseFlags(fs, args, "ID [-o json]")
+	if err != nil {
+		return err
+	}
+	if err = checkFormat(a, fs, *output); err != nil {
+		return err
+	}
+	ids, err := parseIDs(a, fs, positional)
+	if err != nil {
+		return err
+	}
+	if len(ids) != 1 {
+		return a.usageError(fs, "exactly one id is required")
+	}
+
+	task, err := e.client.Task(ctx, ids[0])
+	if err != nil {
+		return err
+	}
+
+	if *output == formatJSON {
+		return writeJSON(a.Stdout, task)
+	}
+	return writeTask(a.Stdout, task)
+}
+
+// runDone moves the tasks to the first done status of their project workflow
+func runDone(ctx context.Context, a *App, e *env, args []string) error {
+	fs := flag.NewFlagSet("done", flag.ContinueOnError)
+
+	positional, err := a.parseFlags(fs, args, "ID...")
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(a, fs, positional)
+	if err != nil {
+		return err
+	}
+
+	// workflows by project, 0 is the default workflow
+	doneStatus := make(map[int64]string)
+	for _, id := range ids {
+		task, err := e.client.Task(ctx, id)
+		if err != nil {
+			return fmt.Errorf("task %d: %w", id, err)
+		}
+
+		var project int64
+		if task.ProjectID != nil {
+			project = *task.ProjectID
+		}
+		status, ok := doneStatus[project]
+		if !ok {
+			statuses, err := e.client.Statuses(ctx, task.ProjectID)
+			if err != nil {
+				return fmt.Errorf("task %d: %w", id, err)
+			}
+			for _, s := range statuses {
+				if s.Category == "done" {
+					status = s.Name
+					break
+				}
+			}
+			if status == "" {
+				return fmt.Errorf("task %d: workflow has no done status", id)
+			}
+			doneStatus[project] = status
+		}
+
+		if _, err = e.client.UpdateTask(ctx, id, client.UpdateTaskRequest{Status: &status}); err != nil {
+			return fmt.Errorf("task %d: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+func runEdit(ctx context.Context, a *App, e *env, args []string) error {
+	fs := flag.NewFlagSet("edit", flag.ContinueOnError)
+	title := fs.String("title", "", "new title")
+	description := fs.String("desc", "", "new description, empty clears it")
+	status := fs.String("status", "", "workflow status name")
+	priority := fs.String("priority", "", "low, medium or high, empty clears it")
+	due := fs.String("due", "", "due date: 2026-03-02, \"2026-03-02 18:00\" or RFC 3339")
+	clearDue := fs.Bool("clear-due", false, "remove the due date")
+	output := fs.String("o", "", "print the updated task: table or json")
+	var tags stringList
+	fs.Var(&tags, "tag", "replaces tags, repeatable, empty value removes all tags")
+
+	positional, err := a.parseFlags(fs, args, "ID [flags]")
+	if err != nil {
+		return err
+	}
+	if *output != "" {
+		if err = checkFormat(a, fs, *output); err != nil {
+			return err
+		}
+	}
+	ids, err := parseIDs(a, fs, positional)
+	if err != nil {
+		return err
+	}
+	if len(ids) != 1 {
+		return a.usageError(fs, "exactly one id is required")
+	}
+
+	patch := client.UpdateTaskRequest{ClearDue: *clearDue}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "title":
+			patch.Title = title
+		case "desc":
+			patch.Description = description
+		case "status":
+			patch.Status = status
+		case "priority":
+			patch.Priority = priority
+		case "tag":
+			t := []string(tags)
+			if t == nil {
+				t = []string{}
+			}
+			patch.Tags = &t
+		}
+	})
+	if *due != "" {
+		if *clearDue {
+			return a.usageError(fs, "--due and --clear-due are exclusive")
+		}
+		t, err := parseDue(*due)
+		if err != nil {
+			return a.usageError(fs, "%s", err)
+		}
+		patch.Due = &t
+	}
+	if patch == (client.UpdateTaskRequest{}) {
+		return a.usageError(fs, "nothing to change")
+	}
+
+	task, err := e.client.UpdateTask(ctx, ids[0], patch)
+	if err != nil {
+		return err
+	}
+
+	switch *output {
+	case formatJSON:
+		return writeJSON(a.Stdout, task)
+	case formatTable:
+		return writeTask(a.Stdout, task)
+	}
+	return nil
+}
+
+func runDelete(ctx context.Context, a *App, e *env, args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+
+	positional, err := a.parseFlags(fs, args, "ID...")
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(a, fs, positional)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err = e.client.DeleteTask(ctx, id); err != nil {
+			return fmt.Errorf("task %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func parseIDs(a *App, fs *flag.FlagSet, args []string) ([]int64, error) {
+	if len(args) == 0 {
+		return nil, a.usageError(fs, "task id is required")
+	}
+
+	ids := make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, a.usageError(fs, "invalid task id %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func checkFormat(a *App, fs *flag.FlagSet, format string) error {
+	if format != formatTable && format != formatJSON {
+		return a.usageError(fs, "unknown output format %q", format)
+	}
+	return nil
+}
+
+// parseDue accepts a date, a date with time in the local zone or RFC 3339
+func parseDue(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid due date %q", v)
+}
+
+func readLine(in *bufio.Reader) (string, error) {
+	line, err := in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("failed read input: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeTable(w io.Writer, tasks []client.Task) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tSTATUS\tPRIORITY\tDUE\tTITLE\tTAGS")
+	for _, t := range tasks {
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			t.ID, t.Status, orDash(t.Priority), formatDue(t.Due), t.Title, strings.Join(t.Tags, ","))
+	}
+	return tw.Flush()
+}
+
+func writeTask(w io.Writer, t client.Task) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	project := "-"
+	if t.ProjectID != nil {
+		project = strconv.FormatInt(*t.ProjectID, 10)
+	}
+
+	rows := [][2]string{
+		{"ID", strconv.FormatInt(t.ID, 10)},
+		{"Title", t.Title},
+		{"Status", t.Status},
+		{"Priority", orDash(t.Priority)},
+		{"Due", formatDue(t.Due)},
+		{"Tags", orDash(strings.Join(t.Tags, ", "))},
+		{"Project", project},
+		{"Recurrence", orDash(t.Recurrence)},
+		{"Created", t.CreatedAt.Local().Format(time.DateTime)},
+		{"Updated", t.UpdatedAt.Local().Format(time.DateTime)},
+	}
+	for _, r := range rows {
+		_, _ = fmt.Fprintf(tw, "%s:\t%s\n", r[0], r[1])
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if t.Description != "" {
+		_, _ = fmt.Fprintf(w, "\n%s\n", t.Description)
+	}
+	return nil
+}
+
+func formatDue(due *time.Time) string {
+	if due == nil {
+		return "-"
+	}
+	return due.Local().Format("2006-01-02 15:04")
+}
+
+func orDash(v string) string {
+	if v == "" {
+		return "-"
+	}
+	return v
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	defaultServer = "http://localhost:8080"
+	envConfig     = "TASKLIST_CONFIG"
+	envServer     = "TASKLIST_SERVER"
+)
+
+// Config is stored in the user config dir, it holds the token so it is readable only by the user
+type Config struct {
+	Server string `json:"server"`
+	Email  string `json:"email,omitempty"`
+	Token  string `json:"token,omitempty"`
+}
+
+// DefaultConfigPath is $TASKLIST_CONFIG or tasklist/cli.json in the user config dir
+func DefaultConfigPath() (string, error) {
+	if p := os.Getenv(envConfig); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tasklist", "cli.json"), nil
+}
+
+// ResolveConfig loads the config from the path or DefaultConfigPath, the server is taken
+// from the argument, $TASKLIST_SERVER, the config and the default in that order
+func ResolveConfig(configPath string, server string) (string, Config, error) {
+	if configPath == "" {
+		p, err := DefaultConfigPath()
+		if err != nil {
+			return "", Config{}, err
+		}
+		configPath = p
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		return "", Config{}, err
+	}
+
+	switch {
+	case server != "":
+		cfg.Server = server
+	case os.Getenv(envServer) != "":
+		cfg.Server = os.Getenv(envServer)
+	case cfg.Server == "":
+		cfg.Server = defaultServer
+	}
+
+	return configPath, cfg, nil
+}
+
+// LoadConfig returns empty config when the file does not exist yet
+func LoadConfig(path string) (Config, error) {
+	cfg := Config{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("failed read config: %w", err)
+	}
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// SaveConfig writes the config through a temporary file so a failed write keeps the old token
+func SaveConfig(path string, cfg Config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed create config dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cli-*.json")
+	if err != nil {
+		return fmt.Errorf("failed write config: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	// CreateTemp already uses 0600, chmod keeps it when umask or the platform differ
+	if err = tmp.Chmod(0o600); err == nil {
+		_, err = tmp.Write(append(data, '\n'))
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("failed write config: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed write config: %w", err)
+	}
+
+	return nil
+}
@@ -19,10 +19,54 @@ type Config struct {
 		Address string `yaml:"address"`
 	}
 
+	GRPC struct {
+		Address string `yaml:"address" env-default:"localhost:9090"`
+	}
+
+	Events struct {
+		// ReplayBuffer is the number of latest events kept for resuming streams with Last-Event-ID
+		ReplayBuffer int           `yaml:"replay_buffer" env-default:"1000"`
+		Heartbeat    time.Duration `yaml:"heartbeat" env-default:"15s"`
+	}
+
+	Webhooks struct {
+		Timeout     time.Duration `yaml:"timeout" env-default:"10s"`
+		MaxAttempts int           `yaml:"max_attempts" env-default:"8"`
+		// Backoff is the delay before the first retry, it doubles with every failed attempt
+		Backoff time.Duration `yaml:"backoff" env-default:"30s"`
+		// AllowPrivate lets webhooks target loopback, private and link-local addresses,
+		// only for receivers in a trusted network
+		AllowPrivate bool `yaml:"allow_private" env-default:"false"`
+	}
+
+	Sync struct {
+		// PageSize is the number of changed tasks returned by one sync
+		PageSize   int `yaml:"page_size" env-default:"500"`
+		MaxChanges int `yaml:"max_changes" env-default:"500"`
+	}
+
 	Storage struct {
+		// Driver is sqlite, postgres or memory
+		Driver string `yaml:"driver" env:"STORAGE_DRIVER" env-default:"sqlite"`
 		Sqlite struct {
 			PathToDB string `yaml:"path"`
+			// JournalMode WAL lets readers work while the writer commits
+			JournalMode string `yaml:"journal_mode" env-default:"WAL"`
+			// Synchronous NORMAL is safe with WAL, FULL also survives power loss
+			Synchronous string        `yaml:"synchronous" env-default:"NORMAL"`
+			ForeignKeys bool          `yaml:"foreign_keys" env-default:"true"`
+			BusyTimeout time.Duration `yaml:"busy_timeout" env-default:"5s"`
+			// MaxReadConns limits the reader pool, writes always go through one connection
+			MaxReadConns int `yaml:"max_read_conns" env-default:"4"`
+		}
+		Postgres struct {
+			// DSN is a postgres:// URL or key=value connection string
+			DSN             string        `yaml:"dsn" env:"POSTGRES_DSN"`
+			MaxOpenConns    int           `yaml:"max_open_conns" env-default:"10"`
+			ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env-default:"30m"`
 		}
+		// AutoMigrate applies pending migrations on serve, otherwise serve refuses to start with them
+		AutoMigrate bool `yaml:"auto_migrate" env:"AUTO_MIGRATE" env-default:"false"`
 	}
 }
 
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/models"
+	"errors"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type AppPassword struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created"`
+	LastUsedAt *time.Time `json:"last_used,omitempty"`
+	// Password is returned only when the app password is created
+	Password string `json:"password,omitempty"`
+}
+
+type AppPasswordRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type AppPasswordResponse struct {
+	response.Response
+	AppPassword *AppPassword `json:"app_password,omitempty"`
+}
+
+type AppPasswordsResponse struct {
+	response.Response
+	AppPasswords []AppPassword `json:"app_passwords,omitempty"`
+}
+
+// CreateAppPassword generates a password for CalDAV clients, it is shown only in this response
+func (c Controller) CreateAppPassword(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.CreateAppPassword"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	req := &AppPasswordRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	p, password, err := c.auth.CreateAppPassword(r.Context(), uid, req.Name)
+	if err != nil {
+		log.Error("failed create app password", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+		return
+	}
+
+	res := appPasswordFromModel(p)
+	res.Password = password
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &AppPasswordResponse{
+		Response:    response.OK(),
+		AppPassword: &res,
+	})
+}
+
+func (c Controller) AppPasswords(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.AppPasswords"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	passwords, err := c.auth.AppPasswords(r.Context(), uid)
+	if err != nil {
+		log.Error("failed get app passwords", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+		return
+	}
+
+	res := make([]AppPassword, len(passwords))
+	for i, p := range passwords {
+		res[i] = appPasswordFromModel(p)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &AppPasswordsResponse{
+		Response:     response.OK(),
+		AppPasswords: res,
+	})
+}
+
+func (c Controller) DeleteAppPassword(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.DeleteAppPassword"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	id, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	if err := c.auth.DeleteAppPassword(r.Context(), uid, id); err != nil {
+		if errors.Is(err, models.ErrAppPasswordNotFound) {
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("app password not found"))
+			return
+		}
+
+		log.Error("failed delete app password", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+		return
+	}
+
+	log.Info("app password deleted", slog.Int64("id", id))
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func appPasswordFromModel(p models.AppPassword) AppPassword {
+	return AppPassword{
+		ID:         p.ID,
+		Name:       p.Name,
+		CreatedAt:  p.CreatedAt,
+		LastUsedAt: p.LastUsedAt,
+	}
+}
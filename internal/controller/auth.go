@@ -16,6 +16,12 @@ import (
 type Auth interface {
 	Registration(ctx context.Context, email string, password string) (int64, error)
 	Login(ctx context.Context, email string, password []byte) (string, error)
+	SetTimezone(ctx context.Context, userID int64, timezone string) error
+	CreateAppPassword(ctx context.Context, userID int64, name string) (models.AppPassword, string, error)
+	AppPasswords(ctx context.Context, userID int64) ([]models.AppPassword, error)
+	DeleteAppPassword(ctx context.Context, userID int64, id int64) error
+	AuthenticateAppPassword(ctx context.Context, email string, password string) (*models.User, error)
+	CheckActive(ctx context.Context, userID int64) error
 }
 
 type AuthRequest struct {
@@ -98,6 +104,20 @@ func (c Controller) Login(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, models.ErrUserDisabled) {
+			c.log.Warn(
+				"disabled user login",
+				slog.String("op", op),
+				slog.String("email", a.Email),
+			)
+
+			render.Status(r, http.StatusForbidden)
+			render.JSON(w, r, LoginResponse{
+				Response: response.Error("user is disabled"),
+			})
+			return
+		}
+
 		c.log.Error(
 			"failed login user",
 			slog.String("op", op),
@@ -218,6 +238,69 @@ func (c Controller) Registration(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+type UpdateUserRequest struct {
+	Timezone string `json:"timezone" validate:"required"`
+}
+
+type UpdateUserResponse struct {
+	response.Response
+}
+
+// UpdateUser changes user settings
+func (c Controller) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.UpdateUser"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	req := &UpdateUserRequest{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		log.Warn("failed decode json", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, UpdateUserResponse{
+			Response: response.Error("failed decode json"),
+		})
+		return
+	}
+
+	if err := validateRequest(req); err != nil {
+		log.Warn("invalid request", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, UpdateUserResponse{
+			Response: response.Error(err.Error()),
+		})
+		return
+	}
+
+	if err := c.auth.SetTimezone(r.Context(), uid, req.Timezone); err != nil {
+		if errors.Is(err, models.ErrInvalidTimezone) {
+			log.Warn("invalid timezone", slog.String("timezone", req.Timezone))
+
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, UpdateUserResponse{
+				Response: response.Error("invalid timezone"),
+			})
+			return
+		}
+
+		log.Error("failed update user", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, UpdateUserResponse{
+			Response: response.Error("internal error"),
+		})
+		return
+	}
+
+	log.Info("user updated", slog.String("timezone", req.Timezone))
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, UpdateUserResponse{
+		Response: response.OK(),
+	})
+}
+
 func validateRequest(a interface{}) error {
 	var errMsgs []string
 	validate := validator.New()
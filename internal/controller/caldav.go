@@ -0,0 +1,412 @@
+package controller
+
+import (
+	"TaskList/internal/lib/dav"
+	"TaskList/internal/lib/ical"
+	"TaskList/internal/middlewares"
+	"TaskList/internal/models"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+const (
+	davPrefix        = "/dav"
+	davPrincipalPath = davPrefix + "/principals/me/"
+	davHomePath      = davPrefix + "/calendars/"
+
+	todoContentType = "text/calendar; charset=utf-8; component=VTODO"
+)
+
+type CalDAV interface {
+	Calendars(ctx context.Context, userID int64) ([]models.Calendar, error)
+	Calendar(ctx context.Context, userID int64, calendarID string) (models.Calendar, error)
+	Objects(ctx context.Context, userID int64, calendarID string) ([]models.CalendarObject, error)
+	Object(ctx context.Context, userID int64, calendarID string, name string) (models.CalendarObject, error)
+	PutObject(ctx context.Context, userID int64, calendarID string, name string, data []byte, pre models.Preconditions) (bool, error)
+	DeleteObject(ctx context.Context, userID int64, calendarID string, name string, pre models.Preconditions) error
+}
+
+var (
+	propResourceType       = dav.Name(dav.NSDAV, "resourcetype")
+	propDisplayName        = dav.Name(dav.NSDAV, "displayname")
+	propCurrentPrincipal   = dav.Name(dav.NSDAV, "current-user-principal")
+	propPrincipalURL       = dav.Name(dav.NSDAV, "principal-URL")
+	propGetETag            = dav.Name(dav.NSDAV, "getetag")
+	propGetContentType     = dav.Name(dav.NSDAV, "getcontenttype")
+	propSupportedReports   = dav.Name(dav.NSDAV, "supported-report-set")
+	propCalendarHomeSet    = dav.Name(dav.NSCalDAV, "calendar-home-set")
+	propSupportedCompSet   = dav.Name(dav.NSCalDAV, "supported-calendar-component-set")
+	propCalendarData       = dav.Name(dav.NSCalDAV, "calendar-data")
+	propGetCTag            = dav.Name(dav.NSCalendarServer, "getctag")
+	reportCalendarQuery    = dav.Name(dav.NSCalDAV, "calendar-query")
+	reportCalendarMultiget = dav.Name(dav.NSCalDAV, "calendar-multiget")
+)
+
+// davRoutes serves the CalDAV subset: discovery, one calendar per project with tasks as VTODO,
+// calendar-query and calendar-multiget reports. Clients authenticate with app passwords
+func (c Controller) davRoutes() {
+	chi.RegisterMethod("PROPFIND")
+	chi.RegisterMethod("REPORT")
+
+	wellKnown := func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, davPrefix+"/", http.StatusMovedPermanently)
+	}
+	c.router.Get("/.well-known/caldav", wellKnown)
+	c.router.MethodFunc("PROPFIND", "/.well-known/caldav", wellKnown)
+
+	c.router.Route(davPrefix, func(r chi.Router) {
+		r.Use(middlewares.BasicAuth(c.auth, "TaskList"))
+		r.Options("/*", c.DAVOptions)
+		r.MethodFunc("PROPFIND", "/", c.PropfindPrincipal)
+		r.MethodFunc("PROPFIND", "/principals/me/", c.PropfindPrincipal)
+		r.MethodFunc("PROPFIND", "/calendars/", c.PropfindCalendars)
+		r.MethodFunc("PROPFIND", "/calendars/{calendarID}/", c.PropfindCalendar)
+		r.MethodFunc("REPORT", "/calendars/{calendarID}/", c.CalendarReport)
+		r.MethodFunc("PROPFIND", "/calendars/{calendarID}/{name}", c.PropfindCalendarObject)
+		r.Get("/calendars/{calendarID}/{name}", c.CalendarObject)
+		r.Put("/calendars/{calendarID}/{name}", c.PutCalendarObject)
+		r.Delete("/calendars/{calendarID}/{name}", c.DeleteCalendarObject)
+	})
+}
+
+func (c Controller) DAVOptions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("DAV", "1, 3, calendar-access")
+	w.Header().Set("Allow", "OPTIONS, GET, PUT, DELETE, PROPFIND, REPORT")
+	w.WriteHeader(http.StatusOK)
+}
+
+// PropfindPrincipal answers discovery of the current user principal and calendar home
+func (c Controller) PropfindPrincipal(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.PropfindPrincipal"
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", userIDFromJWTClaims(r)))
+
+	req, ok := c.parsePropfind(w, r, log)
+	if !ok {
+		return
+	}
+
+	c.writeMultistatus(w, log, dav.NewResponse(r.URL.Path, req, principalProps(r.URL.Path == davPrincipalPath)))
+}
+
+// PropfindCalendars lists calendars of the user with Depth 1
+func (c Controller) PropfindCalendars(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.PropfindCalendars"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	req, ok := c.parsePropfind(w, r, log)
+	if !ok {
+		return
+	}
+
+	responses := []dav.Response{dav.NewResponse(davHomePath, req, []dav.Property{
+		dav.Raw(propResourceType, `<collection xmlns="DAV:"/>`),
+		dav.Text(propDisplayName, "Calendars"),
+		dav.Href(propCurrentPrincipal, davPrincipalPath),
+	})}
+
+	if depth(r) > 0 {
+		calendars, err := c.caldav.Calendars(r.Context(), uid)
+		if err != nil {
+			c.davError(w, log, err)
+			return
+		}
+		for _, cal := range calendars {
+			responses = append(responses, dav.NewResponse(calendarHref(cal.ID), req, calendarProps(cal)))
+		}
+	}
+
+	c.writeMultistatus(w, log, responses...)
+}
+
+// PropfindCalendar returns the calendar and with Depth 1 its objects
+func (c Controller) PropfindCalendar(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.PropfindCalendar"
+	uid := userIDFromJWTClaims(r)
+	calendarID := chi.URLParam(r, "calendarID")
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid), slog.String("calendar", calendarID))
+
+	req, ok := c.parsePropfind(w, r, log)
+	if !ok {
+		return
+	}
+
+	cal, err := c.caldav.Calendar(r.Context(), uid, calendarID)
+	if err != nil {
+		c.davError(w, log, err)
+		return
+	}
+
+	responses := []dav.Response{dav.NewResponse(calendarHref(cal.ID), req, calendarProps(cal))}
+
+	if depth(r) > 0 {
+		objects, err := c.caldav.Objects(r.Context(), uid, calendarID)
+		if err != nil {
+			c.davError(w, log, err)
+			return
+		}
+		for _, obj := range objects {
+			responses = append(responses, dav.NewResponse(objectHref(calendarID, obj.Name), req, objectProps(obj, req)))
+		}
+	}
+
+	c.writeMultistatus(w, log, responses...)
+}
+
+func (c Controller) PropfindCalendarObject(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.PropfindCalendarObject"
+	uid := userIDFromJWTClaims(r)
+	calendarID, name := chi.URLParam(r, "calendarID"), chi.URLParam(r, "name")
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid), slog.String("calendar", calendarID))
+
+	req, ok := c.parsePropfind(w, r, log)
+	if !ok {
+		return
+	}
+
+	obj, err := c.caldav.Object(r.Context(), uid, calendarID, name)
+	if err != nil {
+		c.davError(w, log, err)
+		return
+	}
+
+	c.writeMultistatus(w, log, dav.NewResponse(objectHref(calendarID, obj.Name), req, objectProps(obj, req)))
+}
+
+// CalendarReport answers calendar-multiget by hrefs and calendar-query by comp-filter,
+// text-match and time-range conditions are ignored so clients may get more objects than they asked for
+func (c Controller) CalendarReport(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.CalendarReport"
+	uid := userIDFromJWTClaims(r)
+	calendarID := chi.URLParam(r, "calendarID")
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid), slog.String("calendar", calendarID))
+
+	report, err := dav.ParseReport(io.LimitReader(r.Body, maxCalendarImportSize))
+	if err != nil {
+		log.Warn("invalid report", slog.String("err", err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	objects, err := c.caldav.Objects(r.Context(), uid, calendarID)
+	if err != nil {
+		c.davError(w, log, err)
+		return
+	}
+
+	var responses []dav.Response
+	switch report.Name {
+	case reportCalendarMultiget:
+		byName := make(map[string]models.CalendarObject, len(objects))
+		for _, obj := range objects {
+			byName[obj.Name] = obj
+		}
+		for _, href := range report.Hrefs {
+			p := hrefPath(href)
+			obj, ok := byName[path.Base(p)]
+			if !ok || path.Dir(p) != davHomePath+calendarID {
+				responses = append(responses, dav.NotFound(href))
+				continue
+			}
+			responses = append(responses, dav.NewResponse(href, report.Props, objectProps(obj, report.Props)))
+		}
+	case reportCalendarQuery:
+		for _, obj := range objects {
+			if report.Filter != nil && !matchObject(*report.Filter, obj) {
+				continue
+			}
+			responses = append(responses, dav.NewResponse(objectHref(calendarID, obj.Name), report.Props, objectProps(obj, report.Props)))
+		}
+	default:
+		log.Warn("unsupported report", slog.String("report", report.Name.Local))
+		http.Error(w, "unsupported report", http.StatusForbidden)
+		return
+	}
+
+	c.writeMultistatus(w, log, responses...)
+}
+
+func (c Controller) CalendarObject(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.CalendarObject"
+	uid := userIDFromJWTClaims(r)
+	calendarID := chi.URLParam(r, "calendarID")
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid), slog.String("calendar", calendarID))
+
+	obj, err := c.caldav.Object(r.Context(), uid, calendarID, chi.URLParam(r, "name"))
+	if err != nil {
+		c.davError(w, log, err)
+		return
+	}
+
+	w.Header().Set("ETag", obj.ETag)
+	writeCalendar(w, obj.Data, "")
+}
+
+// PutCalendarObject creates a task from VTODO or replaces the task fields,
+// the new ETag is not returned so clients fetch the object as the server stored it
+func (c Controller) PutCalendarObject(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.PutCalendarObject"
+	uid := userIDFromJWTClaims(r)
+	calendarID := chi.URLParam(r, "calendarID")
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid), slog.String("calendar", calendarID))
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCalendarImportSize))
+	if err != nil {
+		log.Warn("failed read calendar object", slog.String("err", err.Error()))
+		http.Error(w, "calendar object is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	created, err := c.caldav.PutObject(r.Context(), uid, calendarID, chi.URLParam(r, "name"), data, preconditions(r))
+	if err != nil {
+		c.davError(w, log, err)
+		return
+	}
+
+	if created {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c Controller) DeleteCalendarObject(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.DeleteCalendarObject"
+	uid := userIDFromJWTClaims(r)
+	calendarID := chi.URLParam(r, "calendarID")
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid), slog.String("calendar", calendarID))
+
+	if err := c.caldav.DeleteObject(r.Context(), uid, calendarID, chi.URLParam(r, "name"), preconditions(r)); err != nil {
+		c.davError(w, log, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c Controller) parsePropfind(w http.ResponseWriter, r *http.Request, log *slog.Logger) (dav.Props, bool) {
+	req, err := dav.ParsePropfind(io.LimitReader(r.Body, maxCalendarImportSize))
+	if err != nil {
+		log.Warn("invalid propfind", slog.String("err", err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return dav.Props{}, false
+	}
+	return req, true
+}
+
+func (c Controller) writeMultistatus(w http.ResponseWriter, log *slog.Logger, responses ...dav.Response) {
+	if err := dav.Write(w, dav.Multistatus{Responses: responses}); err != nil {
+		log.Error("failed write multistatus", slog.String("err", err.Error()))
+	}
+}
+
+// davError answers with plain text errors, CalDAV clients do not read JSON bodies
+func (c Controller) davError(w http.ResponseWriter, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, models.ErrCalendarNotFound),
+		errors.Is(err, models.ErrCalendarObjectNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrPreconditionFailed):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	case errors.Is(err, models.ErrInvalidCalendarObject):
+		log.Warn("invalid calendar object", slog.String("err", err.Error()))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		if status, msg, ok := taskErrorStatus(err); ok {
+			http.Error(w, msg, status)
+			return
+		}
+
+		log.Error("failed caldav request", slog.String("err", err.Error()))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+func principalProps(principal bool) []dav.Property {
+	resourceType := `<collection xmlns="DAV:"/>`
+	if principal {
+		resourceType += `<principal xmlns="DAV:"/>`
+	}
+	return []dav.Property{
+		dav.Raw(propResourceType, resourceType),
+		dav.Href(propCurrentPrincipal, davPrincipalPath),
+		dav.Href(propPrincipalURL, davPrincipalPath),
+		dav.Href(propCalendarHomeSet, davHomePath),
+	}
+}
+
+func calendarProps(cal models.Calendar) []dav.Property {
+	return []dav.Property{
+		dav.Raw(propResourceType, `<collection xmlns="DAV:"/><calendar xmlns="urn:ietf:params:xml:ns:caldav"/>`),
+		dav.Text(propDisplayName, cal.Name),
+		dav.Href(propCurrentPrincipal, davPrincipalPath),
+		dav.Raw(propSupportedCompSet, `<comp xmlns="urn:ietf:params:xml:ns:caldav" name="VTODO"/>`),
+		dav.Raw(propSupportedReports,
+			`<supported-report xmlns="DAV:"><report><calendar-query xmlns="urn:ietf:params:xml:ns:caldav"/></report></supported-report>`+
+				`<supported-report xmlns="DAV:"><report><calendar-multiget xmlns="urn:ietf:params:xml:ns:caldav"/></report></supported-report>`),
+		dav.Text(propGetCTag, cal.CTag),
+		dav.Text(propGetETag, `"`+cal.CTag+`"`),
+	}
+}
+
+// objectProps returns calendar-data only when it is requested, allprop does not include it
+func objectProps(obj models.CalendarObject, req dav.Props) []dav.Property {
+	props := []dav.Property{
+		dav.Raw(propResourceType, ""),
+		dav.Text(propGetETag, obj.ETag),
+		dav.Text(propGetContentType, todoContentType),
+	}
+	for _, name := range req.Names {
+		if name == propCalendarData {
+			props = append(props, dav.Text(propCalendarData, string(obj.Data)))
+		}
+	}
+	return props
+}
+
+func matchObject(f dav.CompFilter, obj models.CalendarObject) bool {
+	cals, err := ical.Decode(bytes.NewReader(obj.Data))
+	if err != nil || len(cals) == 0 {
+		return false
+	}
+	return f.Match(cals[0])
+}
+
+// depth returns 0 or 1, infinity is answered as 1 because calendars have no nested collections
+func depth(r *http.Request) int {
+	if r.Header.Get("Depth") == "0" {
+		return 0
+	}
+	return 1
+}
+
+func preconditions(r *http.Request) models.Preconditions {
+	return models.Preconditions{
+		IfMatch:     strings.TrimPrefix(r.Header.Get("If-Match"), "W/"),
+		IfNoneMatch: strings.TrimPrefix(r.Header.Get("If-None-Match"), "W/"),
+	}
+}
+
+func calendarHref(calendarID string) string {
+	return davHomePath + url.PathEscape(calendarID) + "/"
+}
+
+func objectHref(calendarID string, name string) string {
+	return calendarHref(calendarID) + url.PathEscape(name)
+}
+
+// hrefPath returns the decoded path of the href which may be absolute URL
+func hrefPath(href string) string {
+	if u, err := url.Parse(href); err == nil {
+		return u.Path
+	}
+	return href
+}
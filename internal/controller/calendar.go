@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/lib/ical"
+	"TaskList/internal/models"
+	"TaskList/internal/services/calendar"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+const (
+	calendarContentType = "text/calendar; charset=utf-8"
+
+	maxCalendarImportSize = 1 << 20
+)
+
+type Calendar interface {
+	Export(ctx context.Context, userID int64) ([]byte, error)
+	Feed(ctx context.Context, token string) ([]byte, error)
+	RegenerateFeedToken(ctx context.Context, userID int64) (string, error)
+	Import(ctx context.Context, userID int64, r io.Reader) ([]int64, error)
+}
+
+type FeedTokenResponse struct {
+	response.Response
+	Token string `json:"token,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+type ImportCalendarResponse struct {
+	response.Response
+	IDs []int64 `json:"ids,omitempty"`
+}
+
+// ExportCalendar returns all user tasks as .ics file
+func (c Controller) ExportCalendar(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.ExportCalendar"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	data, err := c.calendar.Export(r.Context(), uid)
+	if err != nil {
+		log.Error("failed export calendar", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("failed export calendar"))
+		return
+	}
+
+	writeCalendar(w, data, "tasks.ics")
+}
+
+// CalendarFeed is a subscribable calendar protected by the secret token in the path
+func (c Controller) CalendarFeed(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.CalendarFeed"
+	log := c.log.With(slog.String("op", op))
+
+	data, err := c.calendar.Feed(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			log.Warn("unknown feed token")
+
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("calendar not found"))
+			return
+		}
+
+		log.Error("failed get calendar feed", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("failed get calendar"))
+		return
+	}
+
+	writeCalendar(w, data, "")
+}
+
+// RegenerateFeedToken creates new secret feed url, old url stops working
+func (c Controller) RegenerateFeedToken(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.RegenerateFeedToken"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	token, err := c.calendar.RegenerateFeedToken(r.Context(), uid)
+	if err != nil {
+		log.Error("failed regenerate feed token", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, &FeedTokenResponse{
+			Response: response.Error("failed regenerate feed token"),
+		})
+		return
+	}
+
+	log.Info("feed token regenerated")
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &FeedTokenResponse{
+		Response: response.OK(),
+		Token:    token,
+		URL:      scheme + "://" + r.Host + "/calendar/" + token + ".ics",
+	})
+}
+
+// ImportCalendar creates tasks from VTODO components of the .ics request body
+func (c Controller) ImportCalendar(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.ImportCalendar"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	body := http.MaxBytesReader(w, r.Body, maxCalendarImportSize)
+	defer func() {
+		if err := body.Close(); err != nil {
+			log.Warn("failed close body")
+		}
+	}()
+
+	ids, err := c.calendar.Import(r.Context(), uid, body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+
+		switch {
+		case errors.As(err, &maxBytesErr):
+			log.Warn("calendar too large", slog.String("err", err.Error()))
+
+			render.Status(r, http.StatusRequestEntityTooLarge)
+			render.JSON(w, r, &ImportCalendarResponse{
+				Response: response.Error("calendar too large"),
+			})
+		case errors.Is(err, ical.ErrInvalidCalendar), errors.Is(err, calendar.ErrNoTodos):
+			log.Warn("invalid calendar", slog.String("err", err.Error()))
+
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, &ImportCalendarResponse{
+				Response: response.Error(err.Error()),
+			})
+		default:
+			log.Error("failed import calendar", slog.String("err", err.Error()))
+
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, &ImportCalendarResponse{
+				Response: response.Error("failed import calendar"),
+				IDs:      ids,
+			})
+		}
+		return
+	}
+
+	log.Info("success import calendar", slog.Int("count", len(ids)))
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &ImportCalendarResponse{
+		Response: response.OK(),
+		IDs:      ids,
+	})
+}
+
+func writeCalendar(w http.ResponseWriter, data []byte, filename string) {
+	w.Header().Set("Content-Type", calendarContentType)
+	if filename != "" {
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type Comments interface {
+	AddComment(ctx context.Context, taskID int64, userID int64, body string) (models.Comment, error)
+	Comments(ctx context.Context, taskID int64, userID int64) ([]models.Comment, error)
+}
+
+type Comment struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Email     string    `json:"email"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created"`
+}
+
+// CommentRequest body may mention users by email: "@bob@example.com please review"
+type CommentRequest struct {
+	Body string `json:"body" validate:"required"`
+}
+
+type CommentsResponse struct {
+	response.Response
+	Comments []Comment `json:"comments,omitempty"`
+}
+
+type CreateCommentResponse struct {
+	response.Response
+	Comment *Comment `json:"comment,omitempty"`
+}
+
+func (c Controller) TaskComments(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.TaskComments"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	taskID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	comments, err := c.comments.Comments(r.Context(), taskID, uid)
+	if err != nil {
+		c.commentError(w, r, log, err)
+		return
+	}
+
+	res := make([]Comment, len(comments))
+	for i, cm := range comments {
+		res[i] = commentFromModel(cm)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &CommentsResponse{
+		Response: response.OK(),
+		Comments: res,
+	})
+}
+
+func (c Controller) AddComment(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.AddComment"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	taskID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	req := &CommentRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	comment, err := c.comments.AddComment(r.Context(), taskID, uid, req.Body)
+	if err != nil {
+		c.commentError(w, r, log, err)
+		return
+	}
+
+	res := commentFromModel(comment)
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &CreateCommentResponse{
+		Response: response.OK(),
+		Comment:  &res,
+	})
+}
+
+func (c Controller) commentError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	if errors.Is(err, models.ErrEmptyComment) || errors.Is(err, models.ErrInvalidComment) {
+		log.Warn("invalid comment", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error(err.Error()))
+		return
+	}
+	c.taskError(w, r, log, err)
+}
+
+func commentFromModel(cm models.Comment) Comment {
+	return Comment{
+		ID:        cm.ID,
+		UserID:    cm.UserID,
+		Email:     cm.Email,
+		Body:      cm.Body,
+		CreatedAt: cm.CreatedAt,
+	}
+}
@@ -2,44 +2,230 @@ package controller
 
 import (
 	"TaskList/internal/config"
+	"TaskList/internal/graphql"
 	"TaskList/internal/middlewares"
+	"TaskList/internal/models"
 	"github.com/go-chi/chi/v5"
 	"log/slog"
+	"net/http"
 )
 
+const envLocal = "local"
+
 type Controller struct {
-	auth   Auth
-	task   Tasks
-	router *chi.Mux
-	log    *slog.Logger
-	cfg    *config.Config
+	auth          Auth
+	task          Tasks
+	calendar      Calendar
+	projects      Projects
+	workflow      Workflow
+	fields        Fields
+	smartLists    SmartLists
+	workspaces    Workspaces
+	authz         Authz
+	comments      Comments
+	notifications Notifications
+	webhooks      Webhooks
+	stream        EventStream
+	realtime      Realtime
+	sync          Sync
+	caldav        CalDAV
+	graphql       http.Handler
+	router        *chi.Mux
+	log           *slog.Logger
+	cfg           *config.Config
+}
+
+// Services are the services and handlers the routes call, a nil service is fine until its route is called
+type Services struct {
+	Auth          Auth
+	Tasks         Tasks
+	Calendar      Calendar
+	Projects      Projects
+	Workflow      Workflow
+	Fields        Fields
+	SmartLists    SmartLists
+	Workspaces    Workspaces
+	Authz         Authz
+	Comments      Comments
+	Notifications Notifications
+	Webhooks      Webhooks
+	Stream        EventStream
+	Realtime      Realtime
+	Sync          Sync
+	CalDAV        CalDAV
+	GraphQL       http.Handler
 }
 
-func NewController(
-	auth Auth,
-	task Tasks,
-	router *chi.Mux,
-	log *slog.Logger,
-	cfg *config.Config,
-) *Controller {
+func NewController(s Services, router *chi.Mux, log *slog.Logger, cfg *config.Config) *Controller {
 	return &Controller{
-		auth:   auth,
-		task:   task,
-		router: router,
-		log:    log,
-		cfg:    cfg,
+		auth:          s.Auth,
+		task:          s.Tasks,
+		calendar:      s.Calendar,
+		projects:      s.Projects,
+		workflow:      s.Workflow,
+		fields:        s.Fields,
+		smartLists:    s.SmartLists,
+		workspaces:    s.Workspaces,
+		authz:         s.Authz,
+		comments:      s.Comments,
+		notifications: s.Notifications,
+		webhooks:      s.Webhooks,
+		stream:        s.Stream,
+		realtime:      s.Realtime,
+		sync:          s.Sync,
+		caldav:        s.CalDAV,
+		graphql:       s.GraphQL,
+		router:        router,
+		log:           log,
+		cfg:           cfg,
 	}
 }
 
-func (c Controller) Handler() {
+// workspaceAccess is the per-request workspace membership check of the authorization layer
+func (c Controller) workspaceAccess(action models.Action) func(http.Handler) http.Handler {
+	return middlewares.WorkspaceAccess(c.authz, action)
+}
+
+// Handler registers all routes, the error means the server is built without an embedded asset
+func (c Controller) Handler() error {
 	c.router.Post("/login", c.Login)
 	c.router.Post("/registration", c.Registration)
+	c.router.Get("/calendar/{token}.ics", c.CalendarFeed)
 
 	c.router.Route("/api/v1/tasks", func(r chi.Router) {
-		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret))
+		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
 		r.Get("/", c.Tasks)
+		r.Get("/export.ics", c.ExportCalendar)
+		r.Post("/quick", c.QuickAdd)
+		r.Post("/import", c.ImportCalendar)
 		r.Get("/{id}", c.Task)
-		r.Patch("/{id}", c.ChangeStatusTask)
+		r.Patch("/{id}", c.UpdateTask)
+		r.Delete("/{id}", c.DeleteTask)
+		r.Put("/{id}/fields", c.SetTaskFields)
+		r.Put("/{id}/assignee", c.AssignTask)
+		r.Get("/{id}/shares", c.TaskShares)
+		r.Put("/{id}/shares", c.ShareTask)
+		r.Delete("/{id}/shares/{userID}", c.UnshareTask)
+		r.Get("/{id}/comments", c.TaskComments)
+		r.Post("/{id}/comments", c.AddComment)
+		r.Get("/{id}/watchers", c.TaskWatchers)
+		r.Put("/{id}/watch", c.WatchTask)
+		r.Delete("/{id}/watch", c.UnwatchTask)
 		r.Post("/", c.CreateTask)
 	})
+
+	c.router.Route("/api/v1/projects", func(r chi.Router) {
+		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
+		r.Get("/", c.Projects)
+		r.Post("/", c.CreateProject)
+		r.Get("/{id}", c.Project)
+		r.Patch("/{id}", c.RenameProject)
+		r.Delete("/{id}", c.DeleteProject)
+		r.Get("/{id}/fields", c.CustomFields)
+		r.Post("/{id}/fields", c.CreateCustomField)
+		r.Delete("/{id}/fields/{fieldID}", c.DeleteCustomField)
+	})
+
+	c.router.Route("/api/v1/workspaces", func(r chi.Router) {
+		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
+		r.Get("/", c.Workspaces)
+		r.Post("/", c.CreateWorkspace)
+		r.With(c.workspaceAccess(models.ActionView)).Get("/{id}", c.Workspace)
+		r.With(c.workspaceAccess(models.ActionManageWorkspace)).Patch("/{id}", c.RenameWorkspace)
+		r.With(c.workspaceAccess(models.ActionDeleteWorkspace)).Delete("/{id}", c.DeleteWorkspace)
+		r.With(c.workspaceAccess(models.ActionView)).Get("/{id}/projects", c.WorkspaceProjects)
+		r.With(c.workspaceAccess(models.ActionView)).Get("/{id}/members", c.WorkspaceMembers)
+		r.With(c.workspaceAccess(models.ActionManageMembers)).Patch("/{id}/members/{userID}", c.ChangeMemberRole)
+		r.With(c.workspaceAccess(models.ActionView)).Delete("/{id}/members/{userID}", c.RemoveMember)
+		r.With(c.workspaceAccess(models.ActionManageMembers)).Get("/{id}/invitations", c.Invitations)
+		r.With(c.workspaceAccess(models.ActionManageMembers)).Post("/{id}/invitations", c.Invite)
+		r.With(c.workspaceAccess(models.ActionManageMembers)).Delete("/{id}/invitations/{invitationID}", c.RevokeInvitation)
+	})
+
+	c.router.Route("/api/v1/invitations", func(r chi.Router) {
+		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
+		r.Post("/{token}/accept", c.AcceptInvitation)
+	})
+
+	c.router.Route("/api/v1/events", func(r chi.Router) {
+		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
+		r.Get("/", c.Events)
+	})
+
+	c.router.Route("/api/v1/sync", func(r chi.Router) {
+		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
+		r.Post("/", c.Sync)
+	})
+
+	c.router.Route("/api/v1/ws", func(r chi.Router) {
+		r.Use(middlewares.TokenFromQuery, middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
+		r.Get("/", c.WebSocket)
+	})
+
+	c.router.Route("/api/v1/notifications", func(r chi.Router) {
+		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
+		r.Get("/", c.Notifications)
+		r.Post("/read-all", c.MarkAllNotificationsRead)
+		r.Get("/preferences", c.NotificationPreferences)
+		r.Put("/preferences", c.SetNotificationPreferences)
+		r.Patch("/{id}", c.MarkNotification)
+	})
+
+	c.router.Route("/api/v1/webhooks", func(r chi.Router) {
+		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
+		r.Get("/", c.Webhooks)
+		r.Post("/", c.CreateWebhook)
+		r.Get("/{id}", c.Webhook)
+		r.Patch("/{id}", c.UpdateWebhook)
+		r.Delete("/{id}", c.DeleteWebhook)
+		r.Get("/{id}/deliveries", c.WebhookDeliveries)
+		r.Post("/{id}/deliveries/{deliveryID}/redeliver", c.Redeliver)
+	})
+
+	c.router.Route("/api/v1/smart-lists", func(r chi.Router) {
+		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
+		r.Get("/", c.SmartLists)
+		r.Post("/", c.CreateSmartList)
+		r.Get("/{id}", c.SmartList)
+		r.Patch("/{id}", c.UpdateSmartList)
+		r.Delete("/{id}", c.DeleteSmartList)
+		r.Get("/{id}/tasks", c.SmartListTasks)
+	})
+
+	c.router.Route("/api/v1/statuses", func(r chi.Router) {
+		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
+		r.Get("/", c.Statuses)
+		r.Post("/", c.CreateStatus)
+		r.Patch("/{id}", c.UpdateStatus)
+		r.Delete("/{id}", c.DeleteStatus)
+	})
+
+	c.router.Route("/api/v1/board", func(r chi.Router) {
+		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
+		r.Get("/", c.Board)
+	})
+
+	c.router.Route("/api/v1/user", func(r chi.Router) {
+		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
+		r.Patch("/", c.UpdateUser)
+		r.Get("/app-passwords", c.AppPasswords)
+		r.Post("/app-passwords", c.CreateAppPassword)
+		r.Delete("/app-passwords/{id}", c.DeleteAppPassword)
+	})
+
+	c.router.Route("/api/v1/calendar", func(r chi.Router) {
+		r.Use(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth))
+		r.Post("/token", c.RegenerateFeedToken)
+	})
+
+	c.router.Route("/graphql", func(r chi.Router) {
+		r.With(middlewares.AuthJWT(c.cfg.JWT.Secret, c.auth)).Post("/", c.graphql.ServeHTTP)
+		// GraphiQL sends the token from its headers editor, the page itself is public
+		if c.cfg.App.Env == envLocal {
+			r.Get("/playground", graphql.Playground("/graphql"))
+		}
+	})
+
+	c.davRoutes()
+	return c.docRoutes()
 }
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"TaskList/internal/lib/events"
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/models"
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// EventStream is the in-process hub of task events
+type EventStream interface {
+	Subscribe(userID int64, lastID string) (*events.Subscription, []events.Message, bool)
+	Unsubscribe(sub *events.Subscription)
+}
+
+// streamReset is sent when events after Last-Event-ID are lost, the client must reload tasks
+const streamReset = "reset"
+
+type TaskEventData struct {
+	Type           models.EventType `json:"type"`
+	ActorID        int64            `json:"actor_id"`
+	Task           Task             `json:"task"`
+	PreviousStatus models.Status    `json:"previous_status,omitempty"`
+	OccurredAt     time.Time        `json:"occurred_at"`
+}
+
+// Events streams task events visible to the user as Server-Sent Events.
+// Event name is the event type and data is TaskEventData, Last-Event-ID header
+// or ?last_event_id= resumes the stream from the replay buffer
+func (c Controller) Events(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Events"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	rc := http.NewResponseController(w)
+	// the stream lives longer than the server read and write timeouts
+	err := rc.SetWriteDeadline(time.Time{})
+	if err == nil {
+		err = rc.SetReadDeadline(time.Time{})
+	}
+	if err != nil {
+		log.Error("failed disable deadlines", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("streaming is not supported"))
+		return
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+
+	sub, replay, ok := c.stream.Subscribe(uid, lastID)
+	defer c.stream.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprint(w, "retry: 3000\n\n"); err != nil {
+		return
+	}
+	if !ok {
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: {}\n\n", streamReset); err != nil {
+			return
+		}
+	}
+	for _, msg := range replay {
+		if err := c.writeEvent(w, msg); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	log.Info("event stream opened", slog.String("last_event_id", lastID))
+
+	heartbeat := time.NewTicker(c.cfg.Events.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			log.Info("event stream closed")
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case msg, open := <-sub.C:
+			if !open {
+				// too slow to keep up, the client reconnects with Last-Event-ID
+				log.Warn("event stream dropped")
+				return
+			}
+			if err := c.writeEvent(w, msg); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes the message, the hub sends only messages the user can see
+func (c Controller) writeEvent(w http.ResponseWriter, msg events.Message) error {
+	e := msg.Event
+
+	data, err := json.Marshal(taskEventData(e))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, e.Type, data)
+	return err
+}
+
+func taskEventData(e models.TaskEvent) TaskEventData {
+	return TaskEventData{
+		Type:           e.Type,
+		ActorID:        e.ActorID,
+		Task:           taskFromModel(e.Task),
+		PreviousStatus: e.PreviousStatus,
+		OccurredAt:     e.OccurredAt,
+	}
+}
@@ -0,0 +1,274 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/models"
+	"TaskList/internal/services/fields"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+type Fields interface {
+	Fields(ctx context.Context, userID int64, projectID int64) ([]models.CustomField, error)
+	CreateField(ctx context.Context, field models.CustomField) (int64, error)
+	DeleteField(ctx context.Context, userID int64, projectID int64, fieldID int64) error
+}
+
+type CustomField struct {
+	ID       int64            `json:"id"`
+	Name     string           `json:"name"`
+	Type     models.FieldType `json:"type"`
+	Options  []string         `json:"options,omitempty"`
+	Position int              `json:"position"`
+}
+
+type CustomFieldRequest struct {
+	Name     string   `json:"name" validate:"required,max=64"`
+	Type     string   `json:"type" validate:"required,oneof=text number date select checkbox user"`
+	Options  []string `json:"options,omitempty"`
+	Position int      `json:"position,omitempty" validate:"gte=0"`
+}
+
+type CustomFieldsResponse struct {
+	response.Response
+	Fields []CustomField `json:"fields,omitempty"`
+}
+
+type CreateCustomFieldResponse struct {
+	response.Response
+	ID int64 `json:"id,omitempty"`
+}
+
+func (c Controller) CustomFields(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.CustomFields"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	projectID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	scope, ok := c.projectScope(w, r, log, &projectID, models.ActionView)
+	if !ok {
+		return
+	}
+
+	defs, err := c.fields.Fields(r.Context(), scope, projectID)
+	if err != nil {
+		c.fieldError(w, r, log, err)
+		return
+	}
+
+	res := make([]CustomField, len(defs))
+	for i, d := range defs {
+		res[i] = CustomField{
+			ID:       d.ID,
+			Name:     d.Name,
+			Type:     d.Type,
+			Options:  d.Options,
+			Position: d.Position,
+		}
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &CustomFieldsResponse{
+		Response: response.OK(),
+		Fields:   res,
+	})
+}
+
+func (c Controller) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.CreateCustomField"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	projectID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	req := &CustomFieldRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	scope, ok := c.projectScope(w, r, log, &projectID, models.ActionManageProjects)
+	if !ok {
+		return
+	}
+
+	id, err := c.fields.CreateField(r.Context(), models.CustomField{
+		UserID:    scope,
+		ProjectID: projectID,
+		Name:      req.Name,
+		Type:      models.FieldType(req.Type),
+		Options:   req.Options,
+		Position:  req.Position,
+	})
+	if err != nil {
+		c.fieldError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &CreateCustomFieldResponse{
+		Response: response.OK(),
+		ID:       id,
+	})
+}
+
+func (c Controller) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.DeleteCustomField"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	projectID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	fieldID, ok := c.int64FromURL(w, r, log, "fieldID")
+	if !ok {
+		return
+	}
+
+	scope, ok := c.projectScope(w, r, log, &projectID, models.ActionManageProjects)
+	if !ok {
+		return
+	}
+
+	if err := c.fields.DeleteField(r.Context(), scope, projectID, fieldID); err != nil {
+		c.fieldError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+// SetTaskFields sets custom field values of the task: {"customer": "ACME", "estimate": 3, "url": null}
+func (c Controller) SetTaskFields(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.SetTaskFields"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	taskID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	req := map[string]any{}
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		log.Warn("failed parse json", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error("incorrect request body"))
+		return
+	}
+
+	values, err := fieldValuesFromJSON(req)
+	if err != nil {
+		c.fieldError(w, r, log, err)
+		return
+	}
+
+	if err = c.task.SetTaskFields(r.Context(), taskID, uid, values); err != nil {
+		if status, msg, ok := taskErrorStatus(err); ok {
+			render.Status(r, status)
+			render.JSON(w, r, response.Error(msg))
+			return
+		}
+		c.fieldError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func (c Controller) fieldError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, models.ErrProjectNotFound):
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error("project not found"))
+	case errors.Is(err, models.ErrFieldAlreadyExists):
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, response.Error("custom field already exists"))
+	case errors.Is(err, models.ErrFieldNotFound),
+		errors.Is(err, models.ErrInvalidFieldType),
+		errors.Is(err, models.ErrInvalidFieldValue),
+		errors.Is(err, fields.ErrTaskWithoutProject):
+		log.Warn("invalid custom field", slog.String("err", err.Error()))
+
+		status := http.StatusBadRequest
+		if errors.Is(err, models.ErrFieldNotFound) && r.Method == http.MethodDelete {
+			status = http.StatusNotFound
+		}
+		render.Status(r, status)
+		render.JSON(w, r, response.Error(err.Error()))
+	default:
+		log.Error("failed process custom field", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+	}
+}
+
+// fieldValuesFromJSON converts decoded json values to raw strings, null clears the field
+func fieldValuesFromJSON(m map[string]any) (map[string]*string, error) {
+	res := make(map[string]*string, len(m))
+	for k, v := range m {
+		var s string
+		switch val := v.(type) {
+		case nil:
+			res[k] = nil
+			continue
+		case string:
+			s = val
+		case float64:
+			s = strconv.FormatFloat(val, 'f', -1, 64)
+		case bool:
+			s = strconv.FormatBool(val)
+		default:
+			return nil, fmt.Errorf("%w: %s must be a scalar", models.ErrInvalidFieldValue, k)
+		}
+		res[k] = &s
+	}
+	return res, nil
+}
+
+// customFieldsToJSON converts stored values to typed json values
+func customFieldsToJSON(values []models.FieldValue) map[string]any {
+	if len(values) == 0 {
+		return nil
+	}
+
+	res := make(map[string]any, len(values))
+	for _, v := range values {
+		switch v.Type {
+		case models.FieldNumber:
+			if n, err := strconv.ParseFloat(v.Value, 64); err == nil {
+				res[v.Name] = n
+				continue
+			}
+		case models.FieldCheckbox:
+			if b, err := strconv.ParseBool(v.Value); err == nil {
+				res[v.Name] = b
+				continue
+			}
+		case models.FieldUser:
+			if id, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+				res[v.Name] = id
+				continue
+			}
+		}
+		res[v.Name] = v.Value
+	}
+	return res
+}
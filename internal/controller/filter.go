@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"TaskList/internal/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TaskFilter is json form of the saved filter, the same criteria are accepted by GET /api/v1/tasks as query params
+type TaskFilter struct {
+	Scope       models.TaskScope        `json:"scope,omitempty"`
+	WorkspaceID *int64                  `json:"workspace_id,omitempty"`
+	ProjectID   *int64                  `json:"project_id,omitempty"`
+	Statuses    []models.Status         `json:"statuses,omitempty"`
+	Categories  []models.StatusCategory `json:"categories,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Text        string                  `json:"text,omitempty"`
+	Due         models.DueWindow        `json:"due,omitempty"`
+	DueDays     int                     `json:"due_days,omitempty"`
+	DueFrom     *time.Time              `json:"due_from,omitempty"`
+	DueTo       *time.Time              `json:"due_to,omitempty"`
+	Fields      []FieldCondition        `json:"fields,omitempty"`
+	Sort        string                  `json:"sort,omitempty"`
+	Desc        bool                    `json:"desc,omitempty"`
+}
+
+type FieldCondition struct {
+	Name  string `json:"name"`
+	Op    string `json:"op,omitempty"`
+	Value string `json:"value"`
+}
+
+func (f TaskFilter) toModel() models.TaskFilter {
+	res := models.TaskFilter{
+		Scope:       f.Scope,
+		WorkspaceID: f.WorkspaceID,
+		ProjectID:   f.ProjectID,
+		Statuses:    f.Statuses,
+		Categories:  f.Categories,
+		Tags:        f.Tags,
+		Text:        f.Text,
+		Due:         f.Due,
+		DueDays:     f.DueDays,
+		DueFrom:     f.DueFrom,
+		DueTo:       f.DueTo,
+		Sort:        f.Sort,
+		Desc:        f.Desc,
+	}
+	if res.Due == models.DueAny && (f.DueFrom != nil || f.DueTo != nil) {
+		res.Due = models.DueRange
+	}
+	for _, c := range f.Fields {
+		op := c.Op
+		if op == "" {
+			op = "eq"
+		}
+		res.Fields = append(res.Fields, models.FieldCondition{Name: c.Name, Op: op, Value: c.Value})
+	}
+	return res
+}
+
+func taskFilterFromModel(f models.TaskFilter) TaskFilter {
+	res := TaskFilter{
+		Scope:       f.Scope,
+		WorkspaceID: f.WorkspaceID,
+		ProjectID:   f.ProjectID,
+		Statuses:    f.Statuses,
+		Categories:  f.Categories,
+		Tags:        f.Tags,
+		Text:        f.Text,
+		Due:         f.Due,
+		DueDays:     f.DueDays,
+		DueFrom:     f.DueFrom,
+		DueTo:       f.DueTo,
+		Sort:        f.Sort,
+		Desc:        f.Desc,
+	}
+	for _, c := range f.Fields {
+		res.Fields = append(res.Fields, FieldCondition{Name: c.Name, Op: c.Op, Value: c.Value})
+	}
+	return res
+}
+
+// taskFilterFromQuery parses query like
+// ?scope=assigned&workspace_id=1&project_id=1&status=Review&category=todo&tag=home&q=rent&due=upcoming&due_days=3
+// &cf.estimate[gte]=3&cf.customer=ACME&sort=-cf.estimate
+func taskFilterFromQuery(r *http.Request) (models.TaskFilter, error) {
+	f := models.TaskFilter{}
+	q := r.URL.Query()
+
+	if v := q.Get("scope"); v != "owned" {
+		f.Scope = models.TaskScope(v)
+	}
+
+	if v := q.Get("workspace_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return f, errors.New("invalid workspace_id")
+		}
+		f.WorkspaceID = &id
+	}
+
+	if v := q.Get("project_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return f, errors.New("invalid project_id")
+		}
+		f.ProjectID = &id
+	}
+
+	for _, v := range splitValues(q["status"]) {
+		f.Statuses = append(f.Statuses, models.Status(v))
+	}
+	for _, v := range splitValues(q["category"]) {
+		f.Categories = append(f.Categories, models.StatusCategory(v))
+	}
+	f.Tags = splitValues(q["tag"])
+	f.Text = q.Get("q")
+
+	f.Due = models.DueWindow(q.Get("due"))
+	if v := q.Get("due_days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil {
+			return f, errors.New("invalid due_days")
+		}
+		f.DueDays = days
+	}
+	for name, dst := range map[string]**time.Time{"due_from": &f.DueFrom, "due_to": &f.DueTo} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s, expected RFC 3339 time", name)
+			}
+			*dst = &t
+			if f.Due == models.DueAny {
+				f.Due = models.DueRange
+			}
+		}
+	}
+
+	for key, values := range q {
+		if !strings.HasPrefix(key, "cf.") {
+			continue
+		}
+
+		name := strings.TrimPrefix(key, "cf.")
+		op := "eq"
+		if i := strings.Index(name, "["); i > 0 && strings.HasSuffix(name, "]") {
+			op = name[i+1 : len(name)-1]
+			name = name[:i]
+		}
+
+		for _, v := range values {
+			f.Fields = append(f.Fields, models.FieldCondition{Name: name, Op: op, Value: v})
+		}
+	}
+
+	f.Sort = q.Get("sort")
+	if strings.HasPrefix(f.Sort, "-") {
+		f.Sort = strings.TrimPrefix(f.Sort, "-")
+		f.Desc = true
+	}
+	if strings.EqualFold(q.Get("order"), "desc") {
+		f.Desc = true
+	}
+
+	return f, f.Validate()
+}
+
+// splitValues supports both ?tag=a&tag=b and ?tag=a,b
+func splitValues(values []string) []string {
+	var res []string
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				res = append(res, s)
+			}
+		}
+	}
+	return res
+}
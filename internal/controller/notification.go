@@ -0,0 +1,303 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultNotificationsLimit = 50
+	maxNotificationsLimit     = 200
+)
+
+type Notifications interface {
+	Notifications(ctx context.Context, userID int64, unreadOnly bool, limit int) ([]models.Notification, int, error)
+	MarkRead(ctx context.Context, userID int64, id int64, read bool) error
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+	Preferences(ctx context.Context, userID int64) (models.NotificationPreferences, error)
+	SetPreferences(ctx context.Context, userID int64, prefs models.NotificationPreferences) (models.NotificationPreferences, error)
+	Watch(ctx context.Context, taskID int64, userID int64) error
+	Unwatch(ctx context.Context, taskID int64, userID int64) error
+	Watchers(ctx context.Context, taskID int64, userID int64) ([]models.Watcher, error)
+}
+
+type Notification struct {
+	ID        int64            `json:"id"`
+	TaskID    int64            `json:"task_id"`
+	ActorID   int64            `json:"actor_id"`
+	Event     models.EventType `json:"event"`
+	Message   string           `json:"message"`
+	Read      bool             `json:"read"`
+	ReadAt    *time.Time       `json:"read_at,omitempty"`
+	CreatedAt time.Time        `json:"created"`
+}
+
+type NotificationsResponse struct {
+	response.Response
+	Unread        int            `json:"unread"`
+	Notifications []Notification `json:"notifications,omitempty"`
+}
+
+type MarkNotificationRequest struct {
+	Read *bool `json:"read" validate:"required"`
+}
+
+type MarkAllReadResponse struct {
+	response.Response
+	Updated int64 `json:"updated"`
+}
+
+// NotificationPreferencesResponse maps event type to whether it notifies: {"task.updated": false}
+type NotificationPreferencesResponse struct {
+	response.Response
+	Preferences map[models.EventType]bool `json:"preferences,omitempty"`
+}
+
+type Watcher struct {
+	UserID    int64     `json:"user_id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created"`
+}
+
+type WatchersResponse struct {
+	response.Response
+	Watchers []Watcher `json:"watchers,omitempty"`
+}
+
+// Notifications returns the inbox newest first, ?unread=true returns only unread, ?limit= up to 200
+func (c Controller) Notifications(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Notifications"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	q := r.URL.Query()
+
+	var unreadOnly bool
+	if v := q.Get("unread"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid unread"))
+			return
+		}
+		unreadOnly = b
+	}
+
+	limit := defaultNotificationsLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxNotificationsLimit {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("limit must be between 1 and "+strconv.Itoa(maxNotificationsLimit)))
+			return
+		}
+		limit = n
+	}
+
+	list, unread, err := c.notifications.Notifications(r.Context(), uid, unreadOnly, limit)
+	if err != nil {
+		c.notificationError(w, r, log, err)
+		return
+	}
+
+	res := make([]Notification, len(list))
+	for i, n := range list {
+		res[i] = Notification{
+			ID:        n.ID,
+			TaskID:    n.TaskID,
+			ActorID:   n.ActorID,
+			Event:     n.Event,
+			Message:   n.Message,
+			Read:      n.ReadAt != nil,
+			ReadAt:    n.ReadAt,
+			CreatedAt: n.CreatedAt,
+		}
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &NotificationsResponse{
+		Response:      response.OK(),
+		Unread:        unread,
+		Notifications: res,
+	})
+}
+
+// MarkNotification marks the notification read or unread: {"read": false}
+func (c Controller) MarkNotification(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.MarkNotification"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	id, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	req := &MarkNotificationRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	if err := c.notifications.MarkRead(r.Context(), uid, id, *req.Read); err != nil {
+		c.notificationError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func (c Controller) MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.MarkAllNotificationsRead"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	n, err := c.notifications.MarkAllRead(r.Context(), uid)
+	if err != nil {
+		c.notificationError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &MarkAllReadResponse{
+		Response: response.OK(),
+		Updated:  n,
+	})
+}
+
+func (c Controller) NotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.NotificationPreferences"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	prefs, err := c.notifications.Preferences(r.Context(), uid)
+	if err != nil {
+		c.notificationError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &NotificationPreferencesResponse{
+		Response:    response.OK(),
+		Preferences: prefs,
+	})
+}
+
+// SetNotificationPreferences changes only the given events: {"task.updated": false, "task.commented": true}
+func (c Controller) SetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.SetNotificationPreferences"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	req := map[models.EventType]bool{}
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		log.Warn("failed parse json", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error("incorrect request body"))
+		return
+	}
+
+	prefs, err := c.notifications.SetPreferences(r.Context(), uid, req)
+	if err != nil {
+		c.notificationError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &NotificationPreferencesResponse{
+		Response:    response.OK(),
+		Preferences: prefs,
+	})
+}
+
+func (c Controller) TaskWatchers(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.TaskWatchers"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	taskID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	watchers, err := c.notifications.Watchers(r.Context(), taskID, uid)
+	if err != nil {
+		c.taskError(w, r, log, err)
+		return
+	}
+
+	res := make([]Watcher, len(watchers))
+	for i, wt := range watchers {
+		res[i] = Watcher{UserID: wt.UserID, Email: wt.Email, CreatedAt: wt.CreatedAt}
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &WatchersResponse{
+		Response: response.OK(),
+		Watchers: res,
+	})
+}
+
+func (c Controller) WatchTask(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.WatchTask"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	taskID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	if err := c.notifications.Watch(r.Context(), taskID, uid); err != nil {
+		c.taskError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func (c Controller) UnwatchTask(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.UnwatchTask"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	taskID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	if err := c.notifications.Unwatch(r.Context(), taskID, uid); err != nil {
+		c.taskError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func (c Controller) notificationError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, models.ErrNotificationNotFound):
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error("notification not found"))
+	case errors.Is(err, models.ErrInvalidEventType):
+		log.Warn("invalid preferences", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error(err.Error()))
+	default:
+		log.Error("failed process notifications", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+	}
+}
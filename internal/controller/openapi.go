@@ -0,0 +1,879 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/lib/openapi"
+	"TaskList/internal/models"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	secBearer      = "bearerAuth"
+	secAccessToken = "accessToken"
+	secBasic       = "basicAuth"
+
+	docsPath  = "/docs"
+	specPath  = "/openapi.json"
+	redocPath = docsPath + "/redoc.standalone.js"
+	// redocFile is Redoc v2.5.0 standalone bundle, make redoc fetches it before the build
+	redocFile = "assets/redoc.standalone.js"
+)
+
+// assets are served with the docs page, so it works without access to a CDN
+//
+//go:embed assets
+var assets embed.FS
+
+var (
+	exampleTime = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	exampleID   = int64(42)
+
+	exampleTask = Task{
+		ID:             42,
+		UserID:         1,
+		ProjectID:      &exampleID,
+		Title:          "Pay rent",
+		Description:    "Transfer before the 5th",
+		Status:         models.Pending,
+		StatusCategory: models.CategoryTodo,
+		Priority:       models.PriorityHigh,
+		Tags:           []string{"home"},
+		Recurrence:     "FREQ=MONTHLY",
+		CustomFields:   map[string]any{"estimate": 2},
+		Due:            &exampleTime,
+		CreatedAt:      exampleTime,
+		UpdatedAt:      exampleTime,
+	}
+	exampleTaskRequest = TaskRequest{
+		Title:    "Pay rent",
+		Priority: "high",
+		Tags:     []string{"home"},
+		Due:      &exampleTime,
+	}
+	exampleStatus = WorkflowStatus{ID: 3, Name: "Review", Category: models.CategoryInProgress, Position: 1, WIPLimit: 5}
+	exampleFilter = TaskFilter{Scope: models.ScopeAll, Tags: []string{"home"}, Due: models.DueUpcoming, DueDays: 7}
+)
+
+// OpenAPI serves the OpenAPI 3.1 document of the http api
+func (c Controller) OpenAPI(spec []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(spec)
+	}
+}
+
+var docsPage = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>TaskList API</title>
+  <style>body { margin: 0; }</style>
+</head>
+<body>
+  <redoc spec-url="{{.Spec}}"></redoc>
+  <script src="{{.Script}}"></script>
+</body>
+</html>
+`))
+
+// APIDocs renders the OpenAPI document with Redoc
+func (c Controller) APIDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = docsPage.Execute(w, struct{ Spec, Script string }{specPath, redocPath})
+}
+
+// Redoc serves the embedded Redoc bundle of the docs page
+func (c Controller) Redoc(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeFileFS(w, r, assets, redocFile)
+}
+
+// docRoutes serves the document and reports routes of the router the document does not describe,
+// it is called after all routes are registered and fails when the server is built without the Redoc bundle
+func (c Controller) docRoutes() error {
+	const op = "controller.docRoutes"
+	log := c.log.With(slog.String("op", op))
+
+	doc := apiDocument()
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		// the document is built from static types, it fails only on programming errors
+		panic("openapi: " + err.Error())
+	}
+
+	c.router.Get(specPath, c.OpenAPI(spec))
+	c.router.Get(docsPath, c.APIDocs)
+	c.router.Get(redocPath, c.Redoc)
+
+	missing, err := doc.Undocumented(c.router)
+	if err != nil {
+		log.Error("failed walk routes", slog.String("err", err.Error()))
+	}
+	for _, route := range missing {
+		log.Warn("route is not described in openapi document", slog.String("route", route))
+	}
+
+	if _, err = fs.Stat(assets, redocFile); err != nil {
+		return fmt.Errorf("%s: redoc bundle is not embedded, run make redoc before the build: %w", op, err)
+	}
+	return nil
+}
+
+// apiDocument describes every route registered in Handler, keep it next to the route changes
+func apiDocument() *openapi.Document {
+	d := openapi.New(openapi.Info{
+		Title:   "TaskList API",
+		Version: "1.0.0",
+		Description: "Task manager with projects, workspaces, workflows and sharing.\n\n" +
+			"JSON responses have `status` `OK` or `Error`, errors carry the message in `error`. " +
+			"Authenticated routes take the JWT from `POST /login` as `Authorization: Bearer <token>`, " +
+			"the JWT middleware answers 401 with the reason as a JSON string.",
+	})
+
+	d.Components.SecuritySchemes[secBearer] = &openapi.SecurityScheme{
+		Type: "http", Scheme: "bearer", BearerFormat: "JWT",
+		Description: "Token returned by POST /login",
+	}
+	d.Components.SecuritySchemes[secAccessToken] = &openapi.SecurityScheme{
+		Type: "apiKey", In: "query", Name: "access_token",
+		Description: "The JWT as query parameter for clients which can not set headers, like browser WebSocket",
+	}
+	d.Components.SecuritySchemes[secBasic] = &openapi.SecurityScheme{
+		Type: "http", Scheme: "basic",
+		Description: "Email and an app password from POST /api/v1/user/app-passwords",
+	}
+
+	d.Enum(models.StatusCategory(""), "todo", "in_progress", "done")
+	d.Enum(models.Priority(""), "", "low", "medium", "high")
+	d.Enum(models.TaskScope(""), "", "assigned", "shared", "all")
+	d.Enum(models.DueWindow(""), "", "overdue", "today", "upcoming", "none", "range")
+	d.Enum(models.Permission(""), "viewer", "editor", "owner")
+	d.Enum(models.Role(""), "owner", "admin", "member", "guest")
+	d.Enum(models.FieldType(""), "text", "number", "date", "select", "checkbox", "user")
+	d.Enum(models.DeliveryStatus(""), "pending", "succeeded", "failed")
+	d.Enum(models.TaskField(""), "title", "description", "status", "priority", "tags", "recurrence", "due",
+		"assignee", "project", "custom_fields")
+	d.Enum(models.EventType(""), "task.created", "task.updated", "task.status_changed", "task.deleted",
+		"task.assigned", "task.commented", "task.mentioned")
+
+	d.PathParam("id", int64(0), "Resource id")
+	d.PathParam("userID", int64(0), "User id")
+	d.PathParam("fieldID", int64(0), "Custom field id")
+	d.PathParam("invitationID", int64(0), "Invitation id")
+	d.PathParam("deliveryID", int64(0), "Webhook delivery id")
+	d.PathParam("token", "", "Secret token")
+	d.PathParam("calendarID", "", "inbox or the project id")
+	d.PathParam("name", "", "Calendar object name, {uid}.ics")
+	d.PathParam("path", "", "Any path under /dav")
+
+	d.ErrorResponse(http.StatusBadRequest, "BadRequest", "Invalid request body, parameter or rejected change",
+		response.Response{}, response.Error("invalid id"))
+	d.ErrorResponse(http.StatusUnauthorized, "Unauthorized", "Missing, invalid or expired JWT",
+		"", "token has invalid claims: token is expired")
+	d.ErrorResponse(http.StatusForbidden, "Forbidden", "The user may not perform the action",
+		response.Response{}, response.Error("access denied"))
+	d.ErrorResponse(http.StatusNotFound, "NotFound", "The resource does not exist or is not visible to the user",
+		response.Response{}, response.Error("task not found"))
+	d.ErrorResponse(http.StatusConflict, "Conflict", "The resource already exists or a limit is reached",
+		response.Response{}, response.Error("wip limit exceeded"))
+	d.ErrorResponse(http.StatusGone, "Gone", "The token expired or is no longer valid",
+		response.Response{}, response.Error("invitation expired"))
+	d.ErrorResponse(http.StatusRequestEntityTooLarge, "TooLarge", "The body is larger than 1 MiB",
+		response.Response{}, response.Error("calendar too large"))
+	d.ErrorResponse(http.StatusInternalServerError, "InternalError", "Unexpected server error",
+		response.Response{}, response.Error("internal error"))
+
+	d.Tags = []openapi.Tag{
+		{Name: "auth", Description: "Registration, login and user settings"},
+		{Name: "tasks"},
+		{Name: "sharing", Description: "Assignees, shares and watchers of tasks"},
+		{Name: "comments"},
+		{Name: "projects"},
+		{Name: "fields", Description: "Custom fields of projects"},
+		{Name: "workflow", Description: "Statuses and the board"},
+		{Name: "workspaces", Description: "Workspaces, members and invitations"},
+		{Name: "smart-lists", Description: "Saved task filters"},
+		{Name: "notifications"},
+		{Name: "webhooks"},
+		{Name: "calendar", Description: "iCalendar import, export and feed"},
+		{Name: "realtime", Description: "Server-Sent Events and WebSocket"},
+		{Name: "sync", Description: "Offline sync"},
+		{Name: "graphql"},
+		{Name: "caldav", Description: "CalDAV server for calendar clients"},
+		{Name: "docs"},
+	}
+
+	docAuth(d)
+	docTasks(d)
+	docSharing(d)
+	docProjects(d)
+	docWorkflow(d)
+	docWorkspaces(d)
+	docSmartLists(d)
+	docNotifications(d)
+	docWebhooks(d)
+	docCalendar(d)
+	docRealtime(d)
+	docGraphQL(d)
+	docCalDAV(d)
+
+	d.Add(http.MethodGet, specPath, "openapi", "OpenAPI document").Tag("docs").
+		Response(http.StatusOK, "application/json", "This document", &openapi.Schema{Type: "object"}, nil)
+	d.Add(http.MethodGet, docsPath, "docs", "API reference page").Tag("docs").
+		Response(http.StatusOK, "text/html", "Redoc page for this document", &openapi.Schema{Type: "string"}, nil)
+	d.Add(http.MethodGet, redocPath, "redoc", "Redoc bundle of the reference page").Tag("docs").
+		Response(http.StatusOK, "text/javascript", "Embedded Redoc standalone bundle", &openapi.Schema{Type: "string"}, nil)
+
+	return d
+}
+
+func docAuth(d *openapi.Document) {
+	credentials := AuthRequest{Email: "user@example.com", Password: "secret-password"}
+
+	d.Add(http.MethodPost, "/login", "login", "Log in").Tag("auth").
+		Describe("Returns the JWT for the Authorization header, disabled users get 403").
+		JSONBody(AuthRequest{}, credentials).
+		JSON(http.StatusOK, "Logged in", LoginResponse{}, LoginResponse{Response: response.OK(), Token: "eyJhbGciOiJIUzI1NiJ9..."}).
+		Errors(http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/registration", "register", "Register").Tag("auth").
+		JSONBody(AuthRequest{}, credentials).
+		JSON(http.StatusCreated, "User created", RegisterResponse{}, RegisterResponse{Response: response.OK(), ID: 1}).
+		Errors(http.StatusBadRequest, http.StatusInternalServerError)
+
+	d.Add(http.MethodPatch, "/api/v1/user", "updateUser", "Change user settings").Tag("auth").Auth(secBearer).
+		Describe("Timezone is an IANA name, dates like today and tomorrow are resolved in it").
+		JSONBody(UpdateUserRequest{}, UpdateUserRequest{Timezone: "Europe/Moscow"}).
+		JSON(http.StatusOK, "Settings changed", UpdateUserResponse{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/user/app-passwords", "appPasswords", "List app passwords").Tag("auth").Auth(secBearer).
+		JSON(http.StatusOK, "App passwords without the passwords", AppPasswordsResponse{}, AppPasswordsResponse{
+			Response:     response.OK(),
+			AppPasswords: []AppPassword{{ID: 1, Name: "Thunderbird", CreatedAt: exampleTime, LastUsedAt: &exampleTime}},
+		}).
+		Errors(http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/user/app-passwords", "createAppPassword", "Create app password").Tag("auth").Auth(secBearer).
+		Describe("App passwords authenticate CalDAV clients, the password is shown only in this response").
+		JSONBody(AppPasswordRequest{}, AppPasswordRequest{Name: "Thunderbird"}).
+		JSON(http.StatusCreated, "Created", AppPasswordResponse{}, AppPasswordResponse{
+			Response:    response.OK(),
+			AppPassword: &AppPassword{ID: 1, Name: "Thunderbird", CreatedAt: exampleTime, Password: "abcd-efgh-ijkl-mnop"},
+		}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodDelete, "/api/v1/user/app-passwords/{id}", "deleteAppPassword", "Delete app password").Tag("auth").Auth(secBearer).
+		JSON(http.StatusOK, "Deleted", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+}
+
+func docTasks(d *openapi.Document) {
+	tasks := TasksResponse{Response: response.OK(), Tasks: []Task{exampleTask}}
+
+	d.Add(http.MethodGet, "/api/v1/tasks", "tasks", "List tasks").Tag("tasks").Auth(secBearer).
+		Describe("Filters tasks visible to the user. Custom fields are filtered with `cf.<name>=<value>` "+
+			"or `cf.<name>[<op>]=<value>` where op is eq, ne, gt, gte, lt, lte or contains, "+
+			"list parameters accept repeated or comma separated values").
+		Query("scope", models.TaskScope(""), "owned (default), assigned, shared or all").
+		Query("workspace_id", int64(0), "Tasks of the workspace").
+		Query("project_id", int64(0), "Tasks of the project").
+		Query("status", []string{}, "Workflow status names").
+		Query("category", []models.StatusCategory{}, "Status categories").
+		Query("tag", []string{}, "Tags, all must match").
+		Query("q", "", "Text search in title and description").
+		Query("due", models.DueWindow(""), "Due window").
+		Query("due_days", 0, "Days ahead for the upcoming window").
+		Query("due_from", time.Time{}, "Start of the due range, RFC 3339").
+		Query("due_to", time.Time{}, "End of the due range, RFC 3339").
+		Query("sort", "", "due, priority, created, updated, title or cf.<name>, - prefix sorts descending").
+		Query("order", "", "desc sorts descending").
+		JSON(http.StatusOK, "Tasks", TasksResponse{}, tasks).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/tasks", "createTask", "Create task").Tag("tasks").Auth(secBearer).
+		Describe("Creates the task in the project or the inbox, editing the project requires edit access").
+		JSONBody(TaskRequest{}, exampleTaskRequest).
+		JSON(http.StatusCreated, "Task created", CreateTaskResponse{}, CreateTaskResponse{Response: response.OK(), ID: 42}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusConflict, http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/tasks/quick", "quickAddTask", "Create task from one line").Tag("tasks").Auth(secBearer).
+		Describe("Parses the line like `Pay rent tomorrow 9am #home !high every month`, dry_run only returns the interpretation").
+		JSONBody(QuickAddRequest{}, QuickAddRequest{Text: "Pay rent tomorrow 9am #home !high every month"}).
+		JSON(http.StatusCreated, "Task created", QuickAddResponse{}, QuickAddResponse{
+			Response: response.OK(),
+			ID:       42,
+			Parsed: &QuickAddParsed{
+				Title: "Pay rent", Due: &exampleTime, Tags: []string{"home"}, Priority: "high", Recurrence: "FREQ=MONTHLY",
+			},
+		}).
+		JSON(http.StatusOK, "Dry run, nothing is created", QuickAddResponse{}, nil).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/tasks/{id}", "task", "Get task").Tag("tasks").Auth(secBearer).
+		JSON(http.StatusOK, "The task as the only item of tasks", TasksResponse{}, tasks).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+
+	d.Add(http.MethodPatch, "/api/v1/tasks/{id}", "updateTask", "Update task").Tag("tasks").Auth(secBearer).
+		Describe("Changes only the given fields, status moves the task to another workflow column").
+		JSONBody(UpdateTaskRequest{}, json.RawMessage(`{"title": "Pay rent and bills", "status": "Done"}`)).
+		JSON(http.StatusOK, "Updated task", TasksResponse{}, tasks).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusConflict, http.StatusInternalServerError)
+
+	d.Add(http.MethodDelete, "/api/v1/tasks/{id}", "deleteTask", "Delete task").Tag("tasks").Auth(secBearer).
+		JSON(http.StatusOK, "Deleted", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodPut, "/api/v1/tasks/{id}/fields", "setTaskFields", "Set custom field values").Tag("tasks", "fields").Auth(secBearer).
+		Describe("Values by field name, null clears the field. Fields must be defined in the task project").
+		JSONBody(map[string]any{}, map[string]any{"customer": "ACME", "estimate": 3, "url": nil}).
+		JSON(http.StatusOK, "Values set", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/tasks/{id}/comments", "taskComments", "List comments").Tag("comments").Auth(secBearer).
+		JSON(http.StatusOK, "Comments oldest first", CommentsResponse{}, CommentsResponse{
+			Response: response.OK(),
+			Comments: []Comment{{ID: 1, UserID: 2, Email: "bob@example.com", Body: "Done?", CreatedAt: exampleTime}},
+		}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/tasks/{id}/comments", "addComment", "Add comment").Tag("comments").Auth(secBearer).
+		Describe("Mentioned users who can see the task are notified: `@bob@example.com please review`").
+		JSONBody(CommentRequest{}, CommentRequest{Body: "@bob@example.com please review"}).
+		JSON(http.StatusCreated, "Comment added", CreateCommentResponse{}, CreateCommentResponse{
+			Response: response.OK(),
+			Comment:  &Comment{ID: 2, UserID: 1, Email: "alice@example.com", Body: "@bob@example.com please review", CreatedAt: exampleTime},
+		}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+}
+
+func docSharing(d *openapi.Document) {
+	d.Add(http.MethodPut, "/api/v1/tasks/{id}/assignee", "assignTask", "Assign task").Tag("sharing").Auth(secBearer).
+		Describe("The assignee must be able to see the task, empty email removes the assignee").
+		JSONBody(AssignTaskRequest{}, AssignTaskRequest{Email: "bob@example.com"}).
+		JSON(http.StatusOK, "Assigned", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	shares := TaskSharesResponse{
+		Response: response.OK(),
+		Shares:   []TaskShare{{UserID: 2, Email: "bob@example.com", Permission: models.PermissionEditor, CreatedAt: exampleTime}},
+	}
+
+	d.Add(http.MethodGet, "/api/v1/tasks/{id}/shares", "taskShares", "List shares").Tag("sharing").Auth(secBearer).
+		JSON(http.StatusOK, "Shares", TaskSharesResponse{}, shares).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodPut, "/api/v1/tasks/{id}/shares", "shareTask", "Share task").Tag("sharing").Auth(secBearer).
+		Describe("Shares the task by email or changes the permission of the existing share").
+		JSONBody(ShareTaskRequest{}, ShareTaskRequest{Email: "bob@example.com", Permission: "editor"}).
+		JSON(http.StatusOK, "The share", TaskSharesResponse{}, shares).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodDelete, "/api/v1/tasks/{id}/shares/{userID}", "unshareTask", "Unshare task").Tag("sharing").Auth(secBearer).
+		JSON(http.StatusOK, "Unshared", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/tasks/{id}/watchers", "taskWatchers", "List watchers").Tag("sharing", "notifications").Auth(secBearer).
+		JSON(http.StatusOK, "Watchers", WatchersResponse{}, WatchersResponse{
+			Response: response.OK(),
+			Watchers: []Watcher{{UserID: 2, Email: "bob@example.com", CreatedAt: exampleTime}},
+		}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+
+	d.Add(http.MethodPut, "/api/v1/tasks/{id}/watch", "watchTask", "Watch task").Tag("sharing", "notifications").Auth(secBearer).
+		Describe("Watchers are notified about changes of the task").
+		JSON(http.StatusOK, "Watching", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+
+	d.Add(http.MethodDelete, "/api/v1/tasks/{id}/watch", "unwatchTask", "Stop watching task").Tag("sharing", "notifications").Auth(secBearer).
+		JSON(http.StatusOK, "Not watching", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+}
+
+func docProjects(d *openapi.Document) {
+	workspaceID := int64(7)
+	projects := ProjectsResponse{
+		Response: response.OK(),
+		Projects: []Project{{ID: 42, WorkspaceID: &workspaceID, Name: "Home", CreatedAt: exampleTime, UpdatedAt: exampleTime}},
+	}
+
+	d.Add(http.MethodGet, "/api/v1/projects", "projects", "List projects").Tag("projects").Auth(secBearer).
+		Describe("Own projects and projects of the user's workspaces").
+		JSON(http.StatusOK, "Projects", ProjectsResponse{}, projects).
+		Errors(http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/projects", "createProject", "Create project").Tag("projects").Auth(secBearer).
+		JSONBody(ProjectRequest{}, ProjectRequest{Name: "Home", WorkspaceID: &workspaceID}).
+		JSON(http.StatusCreated, "Project created", CreateProjectResponse{}, CreateProjectResponse{Response: response.OK(), ID: 42}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/projects/{id}", "project", "Get project").Tag("projects").Auth(secBearer).
+		JSON(http.StatusOK, "The project as the only item of projects", ProjectsResponse{}, projects).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+
+	d.Add(http.MethodPatch, "/api/v1/projects/{id}", "renameProject", "Rename project").Tag("projects").Auth(secBearer).
+		JSONBody(ProjectRequest{}, ProjectRequest{Name: "House"}).
+		JSON(http.StatusOK, "Renamed", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodDelete, "/api/v1/projects/{id}", "deleteProject", "Delete project").Tag("projects").Auth(secBearer).
+		Describe("Deletes the project and its workflow, tasks stay without project").
+		JSON(http.StatusOK, "Deleted", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/projects/{id}/fields", "customFields", "List custom fields").Tag("fields").Auth(secBearer).
+		JSON(http.StatusOK, "Fields by position", CustomFieldsResponse{}, CustomFieldsResponse{
+			Response: response.OK(),
+			Fields: []CustomField{
+				{ID: 1, Name: "estimate", Type: models.FieldNumber},
+				{ID: 2, Name: "stage", Type: models.FieldSelect, Options: []string{"draft", "final"}, Position: 1},
+			},
+		}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/projects/{id}/fields", "createCustomField", "Create custom field").Tag("fields").Auth(secBearer).
+		Describe("Options are required for select fields").
+		JSONBody(CustomFieldRequest{}, CustomFieldRequest{Name: "stage", Type: "select", Options: []string{"draft", "final"}}).
+		JSON(http.StatusCreated, "Field created", CreateCustomFieldResponse{}, CreateCustomFieldResponse{Response: response.OK(), ID: 2}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusConflict, http.StatusInternalServerError)
+
+	d.Add(http.MethodDelete, "/api/v1/projects/{id}/fields/{fieldID}", "deleteCustomField", "Delete custom field").Tag("fields").Auth(secBearer).
+		Describe("Deletes the field with its values").
+		JSON(http.StatusOK, "Deleted", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+}
+
+func docWorkflow(d *openapi.Document) {
+	projectQuery := "Project of the workflow, the default workflow of the user without it"
+
+	d.Add(http.MethodGet, "/api/v1/statuses", "statuses", "List statuses").Tag("workflow").Auth(secBearer).
+		Query("project_id", int64(0), projectQuery).
+		JSON(http.StatusOK, "Statuses by position", StatusesResponse{}, StatusesResponse{
+			Response: response.OK(),
+			Statuses: []WorkflowStatus{exampleStatus},
+		}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/statuses", "createStatus", "Create status").Tag("workflow").Auth(secBearer).
+		JSONBody(CreateStatusRequest{}, CreateStatusRequest{Name: "Review", Category: "in_progress", Position: 1, WIPLimit: 5}).
+		JSON(http.StatusCreated, "Status created", CreateStatusResponse{}, CreateStatusResponse{Response: response.OK(), ID: 3}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusConflict, http.StatusInternalServerError)
+
+	d.Add(http.MethodPatch, "/api/v1/statuses/{id}", "updateStatus", "Update status").Tag("workflow").Auth(secBearer).
+		Describe("Renaming the status renames it in its tasks, wip_limit 0 removes the limit").
+		Query("project_id", int64(0), projectQuery).
+		JSONBody(UpdateStatusRequest{}, UpdateStatusRequest{Name: "In review"}).
+		JSON(http.StatusOK, "Updated", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusConflict, http.StatusInternalServerError)
+
+	d.Add(http.MethodDelete, "/api/v1/statuses/{id}", "deleteStatus", "Delete status").Tag("workflow").Auth(secBearer).
+		Describe("Tasks of the status move to the first remaining status").
+		Query("project_id", int64(0), projectQuery).
+		JSON(http.StatusOK, "Deleted", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusConflict, http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/board", "board", "Get board").Tag("workflow").Auth(secBearer).
+		Describe("Tasks grouped by workflow columns, over_limit is set when a column exceeds its WIP limit").
+		Query("project_id", int64(0), projectQuery).
+		JSON(http.StatusOK, "Columns", BoardResponse{}, BoardResponse{
+			Response: response.OK(),
+			Columns:  []BoardColumn{{Status: exampleStatus, Count: 1, Tasks: []Task{exampleTask}}},
+		}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+}
+
+func docWorkspaces(d *openapi.Document) {
+	workspaces := WorkspacesResponse{
+		Response:   response.OK(),
+		Workspaces: []Workspace{{ID: 7, OwnerID: 1, Name: "Family", Role: models.RoleOwner, CreatedAt: exampleTime, UpdatedAt: exampleTime}},
+	}
+	invitation := Invitation{ID: 1, Email: "bob@example.com", Role: models.RoleMember, CreatedAt: exampleTime, ExpiresAt: exampleTime.AddDate(0, 0, 7)}
+
+	d.Add(http.MethodGet, "/api/v1/workspaces", "workspaces", "List workspaces").Tag("workspaces").Auth(secBearer).
+		JSON(http.StatusOK, "Workspaces with the role of the user", WorkspacesResponse{}, workspaces).
+		Errors(http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/workspaces", "createWorkspace", "Create workspace").Tag("workspaces").Auth(secBearer).
+		JSONBody(WorkspaceRequest{}, WorkspaceRequest{Name: "Family"}).
+		JSON(http.StatusCreated, "Workspace created, the user is its owner", CreateWorkspaceResponse{},
+			CreateWorkspaceResponse{Response: response.OK(), ID: 7}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/workspaces/{id}", "workspace", "Get workspace").Tag("workspaces").Auth(secBearer).
+		JSON(http.StatusOK, "The workspace as the only item of workspaces", WorkspacesResponse{}, workspaces).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodPatch, "/api/v1/workspaces/{id}", "renameWorkspace", "Rename workspace").Tag("workspaces").Auth(secBearer).
+		Describe("Requires admin role").
+		JSONBody(WorkspaceRequest{}, WorkspaceRequest{Name: "Home"}).
+		JSON(http.StatusOK, "Renamed", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodDelete, "/api/v1/workspaces/{id}", "deleteWorkspace", "Delete workspace").Tag("workspaces").Auth(secBearer).
+		Describe("Only the owner deletes the workspace, its projects and tasks are deleted too").
+		JSON(http.StatusOK, "Deleted", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/workspaces/{id}/projects", "workspaceProjects", "List workspace projects").Tag("workspaces", "projects").Auth(secBearer).
+		JSON(http.StatusOK, "Projects", ProjectsResponse{}, nil).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/workspaces/{id}/members", "workspaceMembers", "List members").Tag("workspaces").Auth(secBearer).
+		JSON(http.StatusOK, "Members", WorkspaceMembersResponse{}, WorkspaceMembersResponse{
+			Response: response.OK(),
+			Members:  []WorkspaceMember{{UserID: 2, Email: "bob@example.com", Role: models.RoleMember, CreatedAt: exampleTime}},
+		}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodPatch, "/api/v1/workspaces/{id}/members/{userID}", "changeMemberRole", "Change member role").Tag("workspaces").Auth(secBearer).
+		Describe("The actor must outrank both the current and the new role").
+		JSONBody(ChangeRoleRequest{}, ChangeRoleRequest{Role: "admin"}).
+		JSON(http.StatusOK, "Role changed", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodDelete, "/api/v1/workspaces/{id}/members/{userID}", "removeMember", "Remove member").Tag("workspaces").Auth(secBearer).
+		Describe("Admins remove members, members leave the workspace by removing themselves").
+		JSON(http.StatusOK, "Removed", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/workspaces/{id}/invitations", "invitations", "List invitations").Tag("workspaces").Auth(secBearer).
+		JSON(http.StatusOK, "Pending invitations without tokens", InvitationsResponse{}, InvitationsResponse{
+			Response:    response.OK(),
+			Invitations: []Invitation{invitation},
+		}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	invitation.Token = "c2VjcmV0LWludml0YXRpb24"
+	d.Add(http.MethodPost, "/api/v1/workspaces/{id}/invitations", "invite", "Invite by email").Tag("workspaces").Auth(secBearer).
+		Describe("The token is returned once so the inviter can deliver it").
+		JSONBody(InvitationRequest{}, InvitationRequest{Email: "bob@example.com", Role: "member"}).
+		JSON(http.StatusCreated, "Invitation created", InvitationsResponse{}, InvitationsResponse{
+			Response:    response.OK(),
+			Invitations: []Invitation{invitation},
+		}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusConflict, http.StatusInternalServerError)
+
+	d.Add(http.MethodDelete, "/api/v1/workspaces/{id}/invitations/{invitationID}", "revokeInvitation", "Revoke invitation").Tag("workspaces").Auth(secBearer).
+		JSON(http.StatusOK, "Revoked", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/invitations/{token}/accept", "acceptInvitation", "Accept invitation").Tag("workspaces").Auth(secBearer).
+		Describe("The invitation must be addressed to the email of the user").
+		JSON(http.StatusOK, "Joined", AcceptInvitationResponse{}, AcceptInvitationResponse{Response: response.OK(), WorkspaceID: 7}).
+		Errors(http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusGone,
+			http.StatusInternalServerError)
+}
+
+func docSmartLists(d *openapi.Document) {
+	list := SmartList{ID: "5", Name: "Home this week", Filter: exampleFilter, CreatedAt: &exampleTime, UpdatedAt: &exampleTime}
+	lists := SmartListsResponse{Response: response.OK(), SmartLists: []SmartList{list}}
+
+	d.Add(http.MethodGet, "/api/v1/smart-lists", "smartLists", "List smart lists").Tag("smart-lists").Auth(secBearer).
+		Describe("Built-in lists today, upcoming and overdue come first, saved lists have numeric ids").
+		JSON(http.StatusOK, "Smart lists", SmartListsResponse{}, lists).
+		Errors(http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/smart-lists", "createSmartList", "Save smart list").Tag("smart-lists").Auth(secBearer).
+		JSONBody(SmartListRequest{}, SmartListRequest{Name: "Home this week", Filter: exampleFilter}).
+		JSON(http.StatusCreated, "Saved", CreateSmartListResponse{}, CreateSmartListResponse{Response: response.OK(), ID: 5}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/smart-lists/{id}", "smartList", "Get smart list").Tag("smart-lists").Auth(secBearer).
+		JSON(http.StatusOK, "The list as the only item of smart_lists", SmartListsResponse{}, lists).
+		Errors(http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+
+	d.Add(http.MethodPatch, "/api/v1/smart-lists/{id}", "updateSmartList", "Update smart list").Tag("smart-lists").Auth(secBearer).
+		Describe("Renames the list and/or replaces its filter, built-in lists are read only").
+		JSONBody(UpdateSmartListRequest{}, UpdateSmartListRequest{Name: "Home"}).
+		JSON(http.StatusOK, "Updated", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodDelete, "/api/v1/smart-lists/{id}", "deleteSmartList", "Delete smart list").Tag("smart-lists").Auth(secBearer).
+		JSON(http.StatusOK, "Deleted", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
+			http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/smart-lists/{id}/tasks", "smartListTasks", "Tasks of smart list").Tag("smart-lists", "tasks").Auth(secBearer).
+		JSON(http.StatusOK, "The list and its tasks", SmartListTasksResponse{}, SmartListTasksResponse{
+			Response:  response.OK(),
+			SmartList: &list,
+			Tasks:     []Task{exampleTask},
+		}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+}
+
+func docNotifications(d *openapi.Document) {
+	preferences := NotificationPreferencesResponse{
+		Response:    response.OK(),
+		Preferences: map[models.EventType]bool{models.EventTaskUpdated: false, models.EventTaskCommented: true},
+	}
+
+	d.Add(http.MethodGet, "/api/v1/notifications", "notifications", "List notifications").Tag("notifications").Auth(secBearer).
+		Query("unread", false, "Only unread notifications").
+		Query("limit", 0, "Up to 200, 50 by default").
+		JSON(http.StatusOK, "Notifications newest first with the unread count", NotificationsResponse{}, NotificationsResponse{
+			Response: response.OK(),
+			Unread:   1,
+			Notifications: []Notification{{
+				ID: 1, TaskID: 42, ActorID: 2, Event: models.EventTaskCommented,
+				Message: "bob@example.com commented on Pay rent", CreatedAt: exampleTime,
+			}},
+		}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodPatch, "/api/v1/notifications/{id}", "markNotification", "Mark notification").Tag("notifications").Auth(secBearer).
+		JSONBody(MarkNotificationRequest{}, json.RawMessage(`{"read": true}`)).
+		JSON(http.StatusOK, "Marked", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/notifications/read-all", "markAllNotificationsRead", "Mark all read").Tag("notifications").Auth(secBearer).
+		JSON(http.StatusOK, "Number of marked notifications", MarkAllReadResponse{}, MarkAllReadResponse{Response: response.OK(), Updated: 3}).
+		Errors(http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/notifications/preferences", "notificationPreferences", "Get preferences").Tag("notifications").Auth(secBearer).
+		JSON(http.StatusOK, "Whether each event type notifies", NotificationPreferencesResponse{}, preferences).
+		Errors(http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodPut, "/api/v1/notifications/preferences", "setNotificationPreferences", "Set preferences").Tag("notifications").Auth(secBearer).
+		Describe("Changes only the given event types").
+		JSONBody(map[models.EventType]bool{}, preferences.Preferences).
+		JSON(http.StatusOK, "All preferences", NotificationPreferencesResponse{}, preferences).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError)
+}
+
+func docWebhooks(d *openapi.Document) {
+	webhook := Webhook{
+		ID: 1, URL: "https://example.com/hooks/tasks", Events: []models.EventType{models.EventTaskCreated},
+		Active: true, CreatedAt: exampleTime, UpdatedAt: exampleTime,
+	}
+	webhooks := WebhooksResponse{Response: response.OK(), Webhooks: []Webhook{webhook}}
+	code := http.StatusOK
+	deliveries := DeliveriesResponse{
+		Response: response.OK(),
+		Deliveries: []WebhookDelivery{{
+			ID: 10, Event: models.EventTaskCreated, Status: models.DeliverySucceeded, Attempts: 1, ResponseCode: &code,
+			Payload: json.RawMessage(`{"type": "task.created", "task": {"id": 42}}`), LastAttemptAt: &exampleTime, CreatedAt: exampleTime,
+		}},
+	}
+
+	d.Add(http.MethodGet, "/api/v1/webhooks", "webhooks", "List webhooks").Tag("webhooks").Auth(secBearer).
+		JSON(http.StatusOK, "Webhooks", WebhooksResponse{}, webhooks).
+		Errors(http.StatusUnauthorized, http.StatusInternalServerError)
+
+	webhook.Secret = "whsec_5f2b..."
+	d.Add(http.MethodPost, "/api/v1/webhooks", "createWebhook", "Create webhook").Tag("webhooks").Auth(secBearer).
+		Describe("Deliveries are signed in X-TaskList-Signature: sha256=hex(HMAC-SHA256(secret, timestamp + \".\" + body)) "+
+			"with X-TaskList-Timestamp in unix seconds, the secret is returned only in this response. "+
+			"Failed deliveries are retried with exponential backoff").
+		JSONBody(WebhookRequest{}, WebhookRequest{URL: webhook.URL, Events: webhook.Events}).
+		JSON(http.StatusCreated, "Webhook created", WebhooksResponse{}, WebhooksResponse{Response: response.OK(), Webhooks: []Webhook{webhook}}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/webhooks/{id}", "webhook", "Get webhook").Tag("webhooks").Auth(secBearer).
+		JSON(http.StatusOK, "The webhook as the only item of webhooks", WebhooksResponse{}, webhooks).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+
+	d.Add(http.MethodPatch, "/api/v1/webhooks/{id}", "updateWebhook", "Update webhook").Tag("webhooks").Auth(secBearer).
+		Describe("Changes only the given fields").
+		JSONBody(UpdateWebhookRequest{}, json.RawMessage(`{"active": false}`)).
+		JSON(http.StatusOK, "Updated webhook", WebhooksResponse{}, webhooks).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+
+	d.Add(http.MethodDelete, "/api/v1/webhooks/{id}", "deleteWebhook", "Delete webhook").Tag("webhooks").Auth(secBearer).
+		JSON(http.StatusOK, "Deleted", response.Response{}, response.OK()).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/webhooks/{id}/deliveries", "webhookDeliveries", "List deliveries").Tag("webhooks").Auth(secBearer).
+		Query("limit", 0, "Up to 200, 50 by default").
+		JSON(http.StatusOK, "Delivery log newest first", DeliveriesResponse{}, deliveries).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/webhooks/{id}/deliveries/{deliveryID}/redeliver", "redeliver", "Redeliver").Tag("webhooks").Auth(secBearer).
+		Describe("Sends the payload of the delivery again as a new delivery").
+		JSON(http.StatusAccepted, "The new delivery, sent in the background", DeliveriesResponse{}, nil).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError)
+}
+
+func docCalendar(d *openapi.Document) {
+	ics := &openapi.Schema{Type: "string", Description: "iCalendar, RFC 5545"}
+
+	d.Add(http.MethodGet, "/api/v1/tasks/export.ics", "exportCalendar", "Export tasks").Tag("calendar").Auth(secBearer).
+		Describe("All tasks of the user as VTODO components").
+		Response(http.StatusOK, calendarContentType, "Calendar file", ics, nil).
+		Errors(http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/tasks/import", "importCalendar", "Import tasks").Tag("calendar").Auth(secBearer).
+		Describe("Creates tasks from VTODO components of the body").
+		Body(calendarContentType, "Calendar up to 1 MiB", ics, nil).
+		JSON(http.StatusCreated, "Ids of the created tasks", ImportCalendarResponse{}, ImportCalendarResponse{Response: response.OK(), IDs: []int64{42, 43}}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusRequestEntityTooLarge, http.StatusInternalServerError)
+
+	d.Add(http.MethodPost, "/api/v1/calendar/token", "regenerateFeedToken", "Regenerate feed url").Tag("calendar").Auth(secBearer).
+		Describe("Creates a new secret feed url, the old url stops working").
+		JSON(http.StatusOK, "The feed token and url", FeedTokenResponse{}, FeedTokenResponse{
+			Response: response.OK(),
+			Token:    "f3c1e2d4",
+			URL:      "https://tasks.example.com/calendar/f3c1e2d4.ics",
+		}).
+		Errors(http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/calendar/{token}.ics", "calendarFeed", "Calendar feed").Tag("calendar").
+		Describe("Subscribable calendar of the user protected by the secret token in the path").
+		Response(http.StatusOK, calendarContentType, "Calendar file", ics, nil).
+		Errors(http.StatusNotFound, http.StatusInternalServerError)
+}
+
+func docRealtime(d *openapi.Document) {
+	d.Add(http.MethodGet, "/api/v1/events", "events", "Task events stream").Tag("realtime").Auth(secBearer).
+		Describe("Server-Sent Events of tasks visible to the user. The event name is the event type and data is TaskEventData. "+
+			"The stream is resumed from the replay buffer after the last event id, "+
+			"a reset event means events were lost and the client must reload tasks").
+		Header("Last-Event-ID", "", "Id of the last received event").
+		Query("last_event_id", "", "Last-Event-ID for clients which can not set headers").
+		Response(http.StatusOK, "text/event-stream", "Event stream", d.Schema(TaskEventData{}), nil).
+		Errors(http.StatusUnauthorized, http.StatusInternalServerError)
+
+	d.Add(http.MethodGet, "/api/v1/ws", "webSocket", "Realtime WebSocket").Tag("realtime").Auth(secBearer, secAccessToken).
+		Describe("Upgrades to WebSocket. The client sends WSRequest messages: subscribe and unsubscribe with project_id or task_id, "+
+			"create with task, update with task_id and changes, move with task_id and status, ping. "+
+			"The server answers with WSMessage carrying the request id and pushes events of subscriptions").
+		Response(http.StatusSwitchingProtocols, "application/json", "WebSocket session, messages are WSMessage",
+			d.Schema(WSMessage{}), WSMessage{ID: "1", Type: "event", Event: &TaskEventData{
+				Type: models.EventTaskCreated, ActorID: 2, Task: exampleTask, OccurredAt: exampleTime,
+			}}).
+		Response(http.StatusBadRequest, "text/plain", "Not a WebSocket handshake", &openapi.Schema{Type: "string"}, nil).
+		Errors(http.StatusUnauthorized)
+	// WSRequest is referenced from the description only, add it to the schemas
+	d.Schema(WSRequest{})
+
+	d.Add(http.MethodPost, "/api/v1/sync", "sync", "Offline sync").Tag("sync").Auth(secBearer).
+		Describe("Applies changes made offline and returns server changes after sync_token. "+
+			"The client stores field versions of tasks and sends them with updates, fields changed on the server since "+
+			"are reported as conflicts. Without sync_token all visible tasks are returned, has_more asks to sync again").
+		JSONBody(SyncRequest{}, SyncRequest{
+			Token: "120",
+			Changes: []SyncChangeRequest{
+				{Op: "create", ClientID: "c-1", Task: &exampleTaskRequest},
+				{Op: "delete", TaskID: 40, BaseVersion: 118},
+			},
+		}).
+		JSON(http.StatusOK, "Server changes and results of the client changes", SyncResponse{}, SyncResponse{
+			Response:   response.OK(),
+			Token:      "125",
+			Tasks:      []SyncTask{{Task: exampleTask, Version: 124, Versions: map[models.TaskField]int64{models.TaskFieldTitle: 124}}},
+			Tombstones: []Tombstone{{ID: 40, Version: 125}},
+			Results: []SyncResult{
+				{ClientID: "c-1", TaskID: 42, Status: string(models.SyncApplied)},
+				{TaskID: 40, Status: string(models.SyncApplied)},
+			},
+		}).
+		Errors(http.StatusBadRequest, http.StatusUnauthorized, http.StatusGone, http.StatusInternalServerError)
+}
+
+func docGraphQL(d *openapi.Document) {
+	d.Add(http.MethodPost, "/graphql", "graphql", "GraphQL").Tag("graphql").Auth(secBearer).
+		Describe("Queries and mutations over tasks, projects and the user, the schema is served by introspection. "+
+			"Errors have extensions.code").
+		Body("application/json", "GraphQL request", &openapi.Schema{
+			Type:     "object",
+			Required: []string{"query"},
+			Properties: map[string]*openapi.Schema{
+				"query":         {Type: "string"},
+				"operationName": {Type: "string"},
+				"variables":     {Type: "object"},
+			},
+		}, map[string]any{"query": "{ tasks(first: 10) { edges { node { id title } } } }"}).
+		Response(http.StatusOK, "application/json", "GraphQL response", &openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"data":   {Type: "object"},
+				"errors": {Type: "array", Items: &openapi.Schema{Type: "object"}},
+			},
+		}, nil).
+		Errors(http.StatusUnauthorized)
+
+	d.Add(http.MethodGet, "/graphql/playground", "graphqlPlayground", "GraphiQL playground").Tag("graphql").
+		Describe("Served only in the local environment, the JWT is set in the headers editor").
+		Response(http.StatusOK, "text/html", "GraphiQL page", &openapi.Schema{Type: "string"}, nil)
+}
+
+func docCalDAV(d *openapi.Document) {
+	ics := &openapi.Schema{Type: "string", Description: "VCALENDAR with one VTODO"}
+	text := &openapi.Schema{Type: "string"}
+	objectPath := davHomePath + "{calendarID}/{name}"
+
+	d.Add(http.MethodGet, "/.well-known/caldav", "caldavWellKnown", "CalDAV discovery").Tag("caldav").
+		Describe("Redirects GET and PROPFIND to the CalDAV root, RFC 6764").
+		Response(http.StatusMovedPermanently, "", "Redirect to /dav/", nil, nil).
+		ResponseHeader(http.StatusMovedPermanently, "Location", "/dav/")
+
+	d.Add(http.MethodOptions, davPrefix+"/{path}", "caldavOptions", "CalDAV capabilities").Tag("caldav").Auth(secBasic).
+		Describe("CalDAV clients use PROPFIND on /dav/, /dav/principals/me/, /dav/calendars/ and /dav/calendars/{calendarID}/ "+
+			"for discovery and REPORT calendar-query or calendar-multiget on calendars. OpenAPI can not describe "+
+			"these methods, they answer 207 Multi-Status XML").
+		Response(http.StatusOK, "", "Supported DAV classes and methods", nil, nil).
+		ResponseHeader(http.StatusOK, "DAV", "1, 3, calendar-access").
+		ResponseHeader(http.StatusOK, "Allow", "OPTIONS, GET, PUT, DELETE, PROPFIND, REPORT").
+		Response(http.StatusUnauthorized, "", "Missing or wrong app password", nil, nil)
+
+	d.Add(http.MethodGet, objectPath, "caldavObject", "Get calendar object").Tag("caldav").Auth(secBasic).
+		Response(http.StatusOK, calendarContentType, "The task as VTODO", ics, nil).
+		ResponseHeader(http.StatusOK, "ETag", "Version of the object").
+		Response(http.StatusUnauthorized, "", "Missing or wrong app password", nil, nil).
+		Response(http.StatusNotFound, "text/plain", "No such calendar or object", text, nil)
+
+	d.Add(http.MethodPut, objectPath, "caldavPutObject", "Put calendar object").Tag("caldav").Auth(secBasic).
+		Describe("Creates the task from VTODO or replaces its fields, the category of STATUS moves the task in the workflow").
+		Header("If-Match", "", "ETag the client saw, the update fails if the object changed").
+		Header("If-None-Match", "", "* creates the object only if it does not exist").
+		Body(todoContentType, "The task as VTODO", ics, nil).
+		Response(http.StatusCreated, "", "Task created", nil, nil).
+		Response(http.StatusNoContent, "", "Task updated", nil, nil).
+		Response(http.StatusBadRequest, "text/plain", "Invalid calendar object", text, nil).
+		Response(http.StatusUnauthorized, "", "Missing or wrong app password", nil, nil).
+		Response(http.StatusPreconditionFailed, "text/plain", "The ETag does not match", text, nil).
+		Response(http.StatusRequestEntityTooLarge, "text/plain", "The object is larger than 1 MiB", text, nil)
+
+	d.Add(http.MethodDelete, objectPath, "caldavDeleteObject", "Delete calendar object").Tag("caldav").Auth(secBasic).
+		Header("If-Match", "", "ETag the client saw").
+		Response(http.StatusNoContent, "", "Task deleted", nil, nil).
+		Response(http.StatusUnauthorized, "", "Missing or wrong app password", nil, nil).
+		Response(http.StatusNotFound, "text/plain", "No such calendar or object", text, nil).
+		Response(http.StatusPreconditionFailed, "text/plain", "The ETag does not match", text, nil)
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"TaskList/internal/config"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestRouter registers all routes, services are not called while routes are registered
+func newTestRouter(t *testing.T) *chi.Mux {
+	t.Helper()
+
+	router := chi.NewRouter()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{}
+	cfg.JWT.Secret = "secret"
+
+	c := NewController(Services{GraphQL: http.NotFoundHandler()}, router, log, cfg)
+	// routes are registered without the Redoc bundle as well, TestRedocServed reports the missing bundle
+	_ = c.Handler()
+
+	return router
+}
+
+func TestRoutesDocumented(t *testing.T) {
+	router := newTestRouter(t)
+
+	missing, err := apiDocument().Undocumented(router)
+	if err != nil {
+		t.Fatalf("Undocumented() error = %v", err)
+	}
+	for _, route := range missing {
+		t.Errorf("route %s is not described in the openapi document", route)
+	}
+}
+
+// the bundle the docs page loads must be embedded into the server
+func TestRedocServed(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, redocPath, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s status = %d, run make redoc to embed the bundle", redocPath, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.Contains(ct, "javascript") {
+		t.Errorf("GET %s Content-Type = %q, want javascript", redocPath, ct)
+	}
+	if rec.Body.Len() == 0 {
+		t.Errorf("GET %s body is empty", redocPath)
+	}
+}
+
+// the docs page must work without access to external hosts
+func TestDocsPageSelfContained(t *testing.T) {
+	router := newTestRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, docsPath, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s status = %d", docsPath, rec.Code)
+	}
+	page := rec.Body.String()
+	if strings.Contains(page, "://") {
+		t.Errorf("docs page loads external resources:\n%s", page)
+	}
+	if !strings.Contains(page, `src="`+redocPath+`"`) || !strings.Contains(page, `spec-url="`+specPath+`"`) {
+		t.Errorf("docs page does not reference the embedded bundle and the spec:\n%s", page)
+	}
+}
@@ -0,0 +1,220 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/models"
+	"TaskList/internal/services/projects"
+	"context"
+	"errors"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type Projects interface {
+	CreateProject(ctx context.Context, project models.Project) (int64, error)
+	Projects(ctx context.Context, userID int64) ([]models.Project, error)
+	Project(ctx context.Context, projectID int64, userID int64) (models.Project, error)
+	RenameProject(ctx context.Context, project models.Project) error
+	DeleteProject(ctx context.Context, projectID int64, userID int64) error
+}
+
+type Project struct {
+	ID          int64     `json:"id"`
+	WorkspaceID *int64    `json:"workspace_id,omitempty"`
+	Name        string    `json:"name"`
+	CreatedAt   time.Time `json:"created"`
+	UpdatedAt   time.Time `json:"updated"`
+}
+
+type ProjectRequest struct {
+	Name string `json:"name" validate:"required"`
+	// WorkspaceID creates the project in the workspace, ignored on rename
+	WorkspaceID *int64 `json:"workspace_id,omitempty"`
+}
+
+type CreateProjectResponse struct {
+	response.Response
+	ID int64 `json:"id,omitempty"`
+}
+
+type ProjectsResponse struct {
+	response.Response
+	Projects []Project `json:"projects,omitempty"`
+}
+
+func (c Controller) Projects(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Projects"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	p, err := c.projects.Projects(r.Context(), uid)
+	if err != nil {
+		log.Error("failed getting projects", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, &ProjectsResponse{
+			Response: response.Error("failed getting projects"),
+		})
+		return
+	}
+
+	res := make([]Project, len(p))
+	for i, v := range p {
+		res[i] = projectFromModel(v)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &ProjectsResponse{
+		Response: response.OK(),
+		Projects: res,
+	})
+}
+
+func (c Controller) Project(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Project"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	projectID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	scope, ok := c.projectScope(w, r, log, &projectID, models.ActionView)
+	if !ok {
+		return
+	}
+
+	p, err := c.projects.Project(r.Context(), projectID, scope)
+	if err != nil {
+		c.projectError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &ProjectsResponse{
+		Response: response.OK(),
+		Projects: []Project{projectFromModel(p)},
+	})
+}
+
+func (c Controller) CreateProject(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.CreateProject"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	req := &ProjectRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	owner := uid
+	if req.WorkspaceID != nil {
+		scope, err := c.authz.WorkspaceScope(r.Context(), uid, *req.WorkspaceID, models.ActionManageProjects)
+		if err != nil {
+			c.workspaceError(w, r, log, err)
+			return
+		}
+		owner = scope
+	}
+
+	id, err := c.projects.CreateProject(r.Context(), models.Project{UserID: owner, WorkspaceID: req.WorkspaceID, Name: req.Name})
+	if err != nil {
+		c.projectError(w, r, log, err)
+		return
+	}
+
+	log.Info("project created", slog.Int64("project_id", id))
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &CreateProjectResponse{
+		Response: response.OK(),
+		ID:       id,
+	})
+}
+
+func (c Controller) RenameProject(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.RenameProject"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	projectID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	req := &ProjectRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	scope, ok := c.projectScope(w, r, log, &projectID, models.ActionManageProjects)
+	if !ok {
+		return
+	}
+
+	err := c.projects.RenameProject(r.Context(), models.Project{ID: projectID, UserID: scope, Name: req.Name})
+	if err != nil {
+		c.projectError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func (c Controller) DeleteProject(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.DeleteProject"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	projectID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	scope, ok := c.projectScope(w, r, log, &projectID, models.ActionManageProjects)
+	if !ok {
+		return
+	}
+
+	if err := c.projects.DeleteProject(r.Context(), projectID, scope); err != nil {
+		c.projectError(w, r, log, err)
+		return
+	}
+
+	log.Info("project deleted", slog.Int64("project_id", projectID))
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func (c Controller) projectError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, models.ErrProjectNotFound):
+		log.Warn("project not found")
+
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error("project not found"))
+	case errors.Is(err, projects.ErrEmptyName):
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error(err.Error()))
+	default:
+		log.Error("failed process project", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+	}
+}
+
+func projectFromModel(p models.Project) Project {
+	return Project{
+		ID:          p.ID,
+		WorkspaceID: p.WorkspaceID,
+		Name:        p.Name,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
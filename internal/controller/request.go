@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// idFromURL parses {id} path parameter and writes error response if it is invalid
+func (c Controller) idFromURL(w http.ResponseWriter, r *http.Request, log *slog.Logger) (int64, bool) {
+	return c.int64FromURL(w, r, log, "id")
+}
+
+func (c Controller) int64FromURL(w http.ResponseWriter, r *http.Request, log *slog.Logger, name string) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	if err != nil {
+		log.Warn("failed parse id", slog.String("param", name), slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error("invalid "+name))
+		return 0, false
+	}
+	return id, true
+}
+
+// projectIDFromQuery parses optional ?project_id= query parameter
+func (c Controller) projectIDFromQuery(w http.ResponseWriter, r *http.Request, log *slog.Logger) (*int64, bool) {
+	v := r.URL.Query().Get("project_id")
+	if v == "" {
+		return nil, true
+	}
+
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		log.Warn("failed parse project id", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error("invalid project_id"))
+		return nil, false
+	}
+	return &id, true
+}
+
+// decodeAndValidate decodes json body into v and validates it, writes error response on failure
+func (c Controller) decodeAndValidate(w http.ResponseWriter, r *http.Request, log *slog.Logger, v interface{}) bool {
+	if err := render.DecodeJSON(r.Body, v); err != nil {
+		if errors.Is(err, io.EOF) {
+			log.Warn("error EOF", slog.String("err", err.Error()))
+
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("request body is empty"))
+			return false
+		}
+		log.Warn("failed parse json", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error("incorrect request body"))
+		return false
+	}
+
+	if err := validateRequest(v); err != nil {
+		log.Warn("incorrect body", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error(err.Error()))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/models"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type TaskShare struct {
+	UserID     int64             `json:"user_id"`
+	Email      string            `json:"email"`
+	Permission models.Permission `json:"permission"`
+	CreatedAt  time.Time         `json:"created"`
+}
+
+type ShareTaskRequest struct {
+	Email      string `json:"email" validate:"required,email"`
+	Permission string `json:"permission" validate:"required,oneof=viewer editor"`
+}
+
+// AssignTaskRequest empty email removes assignee
+type AssignTaskRequest struct {
+	Email string `json:"email" validate:"omitempty,email"`
+}
+
+type TaskSharesResponse struct {
+	response.Response
+	Shares []TaskShare `json:"shares,omitempty"`
+}
+
+func (c Controller) AssignTask(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.AssignTask"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	taskID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	req := &AssignTaskRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	if err := c.task.AssignTask(r.Context(), taskID, uid, req.Email); err != nil {
+		c.taskError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func (c Controller) TaskShares(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.TaskShares"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	taskID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	shares, err := c.task.TaskShares(r.Context(), taskID, uid)
+	if err != nil {
+		c.taskError(w, r, log, err)
+		return
+	}
+
+	res := make([]TaskShare, len(shares))
+	for i, s := range shares {
+		res[i] = taskShareFromModel(s)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &TaskSharesResponse{
+		Response: response.OK(),
+		Shares:   res,
+	})
+}
+
+// ShareTask shares the task by email or changes permission of existing share
+func (c Controller) ShareTask(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.ShareTask"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	taskID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	req := &ShareTaskRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	share, err := c.task.ShareTask(r.Context(), taskID, uid, req.Email, models.Permission(req.Permission))
+	if err != nil {
+		c.taskError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &TaskSharesResponse{
+		Response: response.OK(),
+		Shares:   []TaskShare{taskShareFromModel(share)},
+	})
+}
+
+func (c Controller) UnshareTask(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.UnshareTask"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	taskID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	userID, ok := c.int64FromURL(w, r, log, "userID")
+	if !ok {
+		return
+	}
+
+	if err := c.task.UnshareTask(r.Context(), taskID, uid, userID); err != nil {
+		c.taskError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func taskShareFromModel(s models.TaskShare) TaskShare {
+	return TaskShare{
+		UserID:     s.UserID,
+		Email:      s.Email,
+		Permission: s.Permission,
+		CreatedAt:  s.CreatedAt,
+	}
+}
@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/models"
+	"TaskList/internal/services/smartlists"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type SmartLists interface {
+	SmartLists(ctx context.Context, userID int64) ([]models.SmartList, error)
+	SmartList(ctx context.Context, userID int64, id string) (models.SmartList, error)
+	CreateSmartList(ctx context.Context, list models.SmartList) (int64, error)
+	UpdateSmartList(ctx context.Context, id string, list models.SmartList) error
+	DeleteSmartList(ctx context.Context, userID int64, id string) error
+	Tasks(ctx context.Context, userID int64, id string) (models.SmartList, []models.Task, error)
+}
+
+// SmartList id is a number for saved lists and a key (today, upcoming, overdue) for built-in ones
+type SmartList struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	BuiltIn   bool       `json:"built_in"`
+	Filter    TaskFilter `json:"filter"`
+	CreatedAt *time.Time `json:"created,omitempty"`
+	UpdatedAt *time.Time `json:"updated,omitempty"`
+}
+
+type SmartListRequest struct {
+	Name   string     `json:"name" validate:"required"`
+	Filter TaskFilter `json:"filter"`
+}
+
+type UpdateSmartListRequest struct {
+	Name   string      `json:"name"`
+	Filter *TaskFilter `json:"filter"`
+}
+
+type CreateSmartListResponse struct {
+	response.Response
+	ID int64 `json:"id,omitempty"`
+}
+
+type SmartListsResponse struct {
+	response.Response
+	SmartLists []SmartList `json:"smart_lists,omitempty"`
+}
+
+type SmartListTasksResponse struct {
+	response.Response
+	SmartList *SmartList `json:"smart_list,omitempty"`
+	Tasks     []Task     `json:"tasks,omitempty"`
+}
+
+func (c Controller) SmartLists(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.SmartLists"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	lists, err := c.smartLists.SmartLists(r.Context(), uid)
+	if err != nil {
+		c.smartListError(w, r, log, err)
+		return
+	}
+
+	res := make([]SmartList, len(lists))
+	for i, v := range lists {
+		res[i] = smartListFromModel(v)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &SmartListsResponse{
+		Response:   response.OK(),
+		SmartLists: res,
+	})
+}
+
+func (c Controller) SmartList(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.SmartList"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	list, err := c.smartLists.SmartList(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		c.smartListError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &SmartListsResponse{
+		Response:   response.OK(),
+		SmartLists: []SmartList{smartListFromModel(list)},
+	})
+}
+
+func (c Controller) CreateSmartList(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.CreateSmartList"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	req := &SmartListRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	id, err := c.smartLists.CreateSmartList(r.Context(), models.SmartList{
+		UserID: uid,
+		Name:   req.Name,
+		Filter: req.Filter.toModel(),
+	})
+	if err != nil {
+		c.smartListError(w, r, log, err)
+		return
+	}
+
+	log.Info("smart list created", slog.Int64("smart_list_id", id))
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &CreateSmartListResponse{
+		Response: response.OK(),
+		ID:       id,
+	})
+}
+
+// UpdateSmartList renames the list and/or replaces its filter
+func (c Controller) UpdateSmartList(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.UpdateSmartList"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	id := chi.URLParam(r, "id")
+
+	req := &UpdateSmartListRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	list, err := c.smartLists.SmartList(r.Context(), uid, id)
+	if err != nil {
+		c.smartListError(w, r, log, err)
+		return
+	}
+
+	if req.Name != "" {
+		list.Name = req.Name
+	}
+	if req.Filter != nil {
+		list.Filter = req.Filter.toModel()
+	}
+
+	if err := c.smartLists.UpdateSmartList(r.Context(), id, list); err != nil {
+		c.smartListError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func (c Controller) DeleteSmartList(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.DeleteSmartList"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	id := chi.URLParam(r, "id")
+	if err := c.smartLists.DeleteSmartList(r.Context(), uid, id); err != nil {
+		c.smartListError(w, r, log, err)
+		return
+	}
+
+	log.Info("smart list deleted", slog.String("smart_list_id", id))
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+// SmartListTasks evaluates the stored query of the list
+func (c Controller) SmartListTasks(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.SmartListTasks"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	list, t, err := c.smartLists.Tasks(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		c.smartListError(w, r, log, err)
+		return
+	}
+
+	res := make([]Task, len(t))
+	for i, v := range t {
+		res[i] = taskFromModel(v)
+	}
+
+	sl := smartListFromModel(list)
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &SmartListTasksResponse{
+		Response:  response.OK(),
+		SmartList: &sl,
+		Tasks:     res,
+	})
+}
+
+func (c Controller) smartListError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, models.ErrSmartListNotFound):
+		log.Warn("smart list not found")
+
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error("smart list not found"))
+	case errors.Is(err, models.ErrSmartListReadOnly):
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, response.Error(err.Error()))
+	case errors.Is(err, smartlists.ErrEmptyName), errors.Is(err, models.ErrInvalidFilter):
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error(err.Error()))
+	default:
+		log.Error("failed process smart list", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+	}
+}
+
+func smartListFromModel(l models.SmartList) SmartList {
+	res := SmartList{
+		ID:      l.Key,
+		Name:    l.Name,
+		BuiltIn: l.BuiltIn,
+		Filter:  taskFilterFromModel(l.Filter),
+	}
+	if !l.BuiltIn {
+		res.ID = strconv.FormatInt(l.ID, 10)
+		res.CreatedAt = &l.CreatedAt
+		res.UpdatedAt = &l.UpdatedAt
+	}
+	return res
+}
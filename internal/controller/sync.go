@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+)
+
+type Sync interface {
+	Sync(ctx context.Context, userID int64, token string, changes []models.SyncChange) (models.Sync, error)
+}
+
+// SyncRequest sends changes made offline, sync_token is the token of the previous sync,
+// without it all visible tasks are returned
+type SyncRequest struct {
+	Token   string              `json:"sync_token,omitempty"`
+	Changes []SyncChangeRequest `json:"changes,omitempty" validate:"dive"`
+}
+
+// SyncChangeRequest is one offline change: create with client_id and task,
+// update with task_id, changes and versions of the changed fields,
+// delete with task_id and base_version of the task
+type SyncChangeRequest struct {
+	Op          string             `json:"op" validate:"required,oneof=create update delete"`
+	ClientID    string             `json:"client_id,omitempty"`
+	TaskID      int64              `json:"task_id,omitempty"`
+	Task        *TaskRequest       `json:"task,omitempty"`
+	Changes     *UpdateTaskRequest `json:"changes,omitempty"`
+	Versions    map[string]int64   `json:"versions,omitempty"`
+	BaseVersion int64              `json:"base_version,omitempty"`
+}
+
+type SyncTask struct {
+	Task
+	Version  int64                      `json:"version"`
+	Versions map[models.TaskField]int64 `json:"versions"`
+}
+
+type Tombstone struct {
+	ID      int64 `json:"id"`
+	Version int64 `json:"version"`
+}
+
+type SyncResult struct {
+	ClientID  string             `json:"client_id,omitempty"`
+	TaskID    int64              `json:"task_id,omitempty"`
+	Status    string             `json:"status"`
+	Conflicts []models.TaskField `json:"conflicts,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
+type SyncResponse struct {
+	response.Response
+	Token      string       `json:"sync_token,omitempty"`
+	HasMore    bool         `json:"has_more,omitempty"`
+	Tasks      []SyncTask   `json:"tasks,omitempty"`
+	Tombstones []Tombstone  `json:"tombstones,omitempty"`
+	Results    []SyncResult `json:"results,omitempty"`
+}
+
+// Sync applies offline changes of the client and returns server changes after its sync token,
+// the client stores tasks with their field versions and sends the versions back with its changes
+func (c Controller) Sync(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Sync"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	req := &SyncRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	changes := make([]models.SyncChange, 0, len(req.Changes))
+	for _, ch := range req.Changes {
+		change, err := ch.toModel()
+		if err != nil {
+			log.Warn("invalid sync change", slog.String("err", err.Error()))
+
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return
+		}
+		changes = append(changes, change)
+	}
+
+	res, err := c.sync.Sync(r.Context(), uid, req.Token, changes)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidSyncToken):
+			log.Warn("invalid sync token", slog.String("token", req.Token))
+
+			render.Status(r, http.StatusGone)
+			render.JSON(w, r, response.Error("invalid sync token, sync without token"))
+		case errors.Is(err, models.ErrInvalidSyncOp):
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+		default:
+			log.Error("failed sync", slog.String("err", err.Error()))
+
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("internal error"))
+		}
+		return
+	}
+
+	out := SyncResponse{
+		Response:   response.OK(),
+		Token:      res.Token,
+		HasMore:    res.HasMore,
+		Tasks:      make([]SyncTask, len(res.Tasks)),
+		Tombstones: make([]Tombstone, len(res.Tombstones)),
+		Results:    make([]SyncResult, len(res.Results)),
+	}
+	for i, t := range res.Tasks {
+		out.Tasks[i] = SyncTask{Task: taskFromModel(t.Task), Version: t.Versions.Version(), Versions: t.Versions}
+	}
+	for i, t := range res.Tombstones {
+		out.Tombstones[i] = Tombstone{ID: t.TaskID, Version: t.Version}
+	}
+	for i, sr := range res.Results {
+		out.Results[i] = SyncResult{
+			ClientID:  sr.ClientID,
+			TaskID:    sr.TaskID,
+			Status:    string(sr.Status),
+			Conflicts: sr.Conflicts,
+		}
+		if sr.Err != nil {
+			out.Results[i].Error = c.syncErrorMessage(log, sr.Err)
+		}
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &out)
+}
+
+func (s SyncChangeRequest) toModel() (models.SyncChange, error) {
+	change := models.SyncChange{
+		Op:          models.SyncOp(s.Op),
+		ClientID:    s.ClientID,
+		TaskID:      s.TaskID,
+		BaseVersion: s.BaseVersion,
+	}
+
+	switch change.Op {
+	case models.SyncOpCreate:
+		if s.Task == nil {
+			return models.SyncChange{}, errors.New("task is required to create")
+		}
+		task, err := s.Task.toModel()
+		if err != nil {
+			return models.SyncChange{}, err
+		}
+		change.Task = task
+	case models.SyncOpUpdate:
+		if s.TaskID == 0 || s.Changes == nil {
+			return models.SyncChange{}, errors.New("task_id and changes are required to update")
+		}
+		change.Patch = s.Changes.toPatch()
+		if s.Changes.Status != nil {
+			status := models.Status(*s.Changes.Status)
+			change.Status = &status
+		}
+		change.Versions = make(models.TaskVersions, len(s.Versions))
+		for f, v := range s.Versions {
+			change.Versions[models.TaskField(f)] = v
+		}
+	case models.SyncOpDelete:
+		if s.TaskID == 0 {
+			return models.SyncChange{}, errors.New("task_id is required to delete")
+		}
+	}
+
+	return change, nil
+}
+
+// syncErrorMessage hides unexpected errors of single changes, the sync itself still succeeds
+func (c Controller) syncErrorMessage(log *slog.Logger, err error) string {
+	if _, msg, ok := taskErrorStatus(err); ok {
+		return msg
+	}
+	if errors.Is(err, models.ErrInvalidSyncOp) {
+		return err.Error()
+	}
+
+	log.Error("failed apply sync change", slog.String("err", err.Error()))
+	return "internal error"
+}
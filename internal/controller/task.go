@@ -3,8 +3,10 @@ package controller
 import (
 	"TaskList/internal/lib/http/response"
 	"TaskList/internal/lib/jwt"
+	"TaskList/internal/lib/quickadd"
 	"TaskList/internal/middlewares"
 	"TaskList/internal/models"
+	"TaskList/internal/services/fields"
 	"context"
 	"errors"
 	"github.com/go-chi/chi/v5"
@@ -25,6 +27,7 @@ type Tasks interface {
 	Tasks(
 		ctx context.Context,
 		userID int64,
+		filter models.TaskFilter,
 	) ([]models.Task, error)
 
 	TasksByID(
@@ -39,21 +42,80 @@ type Tasks interface {
 		userID int64,
 		newStatus string,
 	) error
+
+	QuickAdd(
+		ctx context.Context,
+		userID int64,
+		line string,
+		dryRun bool,
+	) (models.Task, quickadd.Result, error)
+
+	SetTaskFields(
+		ctx context.Context,
+		taskID int64,
+		userID int64,
+		values map[string]*string,
+	) error
+
+	AssignTask(ctx context.Context, taskID int64, userID int64, email string) error
+	ShareTask(ctx context.Context, taskID int64, userID int64, email string, permission models.Permission) (models.TaskShare, error)
+	TaskShares(ctx context.Context, taskID int64, userID int64) ([]models.TaskShare, error)
+	UnshareTask(ctx context.Context, taskID int64, userID int64, sharedWith int64) error
+	UpdateTask(ctx context.Context, taskID int64, userID int64, patch models.TaskPatch) (models.Task, error)
+	DeleteTask(ctx context.Context, taskID int64, userID int64) error
+	CanView(ctx context.Context, task models.Task, userID int64) (bool, error)
 }
 
 type Task struct {
-	ID          int64         `json:"id"`
-	UserID      int64         `json:"user_id"`
-	Title       string        `json:"title"`
-	Description string        `json:"description,omitempty"`
-	Status      models.Status `json:"status"`
-	CreatedAt   time.Time     `json:"created"`
-	UpdatedAt   time.Time     `json:"updated"`
+	ID             int64                 `json:"id"`
+	UserID         int64                 `json:"user_id"`
+	AssigneeID     *int64                `json:"assignee_id,omitempty"`
+	ProjectID      *int64                `json:"project_id,omitempty"`
+	Title          string                `json:"title"`
+	Description    string                `json:"description,omitempty"`
+	Status         models.Status         `json:"status"`
+	StatusCategory models.StatusCategory `json:"status_category,omitempty"`
+	Priority       models.Priority       `json:"priority,omitempty"`
+	Tags           []string              `json:"tags,omitempty"`
+	Recurrence     string                `json:"recurrence,omitempty"`
+	CustomFields   map[string]any        `json:"custom_fields,omitempty"`
+	Due            *time.Time            `json:"due,omitempty"`
+	CreatedAt      time.Time             `json:"created"`
+	UpdatedAt      time.Time             `json:"updated"`
 }
 
 type TaskRequest struct {
-	Title       string `json:"title" validate:"required"`
-	Description string `json:"description,omitempty"`
+	ProjectID   *int64     `json:"project_id,omitempty"`
+	Title       string     `json:"title" validate:"required"`
+	Description string     `json:"description,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	Priority    string     `json:"priority,omitempty" validate:"omitempty,oneof=low medium high"`
+	Tags        []string   `json:"tags,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
+	Due         *time.Time `json:"due,omitempty"`
+	// CustomFields are values by field name, fields must be defined in the task project
+	CustomFields map[string]any `json:"custom_fields,omitempty"`
+}
+
+type QuickAddRequest struct {
+	Text   string `json:"text" validate:"required"`
+	DryRun bool   `json:"dry_run,omitempty"`
+}
+
+// QuickAddParsed is interpretation of the quick add line for confirmation on the client
+type QuickAddParsed struct {
+	Title      string     `json:"title"`
+	Due        *time.Time `json:"due,omitempty"`
+	AllDay     bool       `json:"all_day,omitempty"`
+	Tags       []string   `json:"tags,omitempty"`
+	Priority   string     `json:"priority,omitempty"`
+	Recurrence string     `json:"recurrence,omitempty"`
+}
+
+type QuickAddResponse struct {
+	response.Response
+	ID     int64           `json:"id,omitempty"`
+	Parsed *QuickAddParsed `json:"parsed,omitempty"`
 }
 
 type CreateTaskResponse struct {
@@ -66,12 +128,58 @@ type TasksResponse struct {
 	Tasks []Task `json:"tasks,omitempty"`
 }
 
-type ChangeStatusRequest struct {
-	Status string `json:"status"`
+// toModel converts the request to a task without owner
+func (t TaskRequest) toModel() (models.Task, error) {
+	cf, err := fieldValuesFromJSON(t.CustomFields)
+	if err != nil {
+		return models.Task{}, err
+	}
+	customFields := make([]models.FieldValue, 0, len(cf))
+	for name, v := range cf {
+		if v != nil {
+			customFields = append(customFields, models.FieldValue{Name: name, Value: *v})
+		}
+	}
+
+	return models.Task{
+		ProjectID:    t.ProjectID,
+		Title:        t.Title,
+		Status:       models.Status(t.Status),
+		Description:  t.Description,
+		Priority:     models.Priority(t.Priority),
+		Tags:         t.Tags,
+		Recurrence:   t.Recurrence,
+		CustomFields: customFields,
+		DueAt:        t.Due,
+	}, nil
+}
+
+// UpdateTaskRequest changes only the given fields, clear_due removes the due date
+type UpdateTaskRequest struct {
+	Title       *string    `json:"title,omitempty" validate:"omitempty,min=1"`
+	Description *string    `json:"description,omitempty"`
+	Status      *string    `json:"status,omitempty"`
+	Priority    *string    `json:"priority,omitempty" validate:"omitempty,oneof=low medium high"`
+	Tags        *[]string  `json:"tags,omitempty"`
+	Recurrence  *string    `json:"recurrence,omitempty"`
+	Due         *time.Time `json:"due,omitempty"`
+	ClearDue    bool       `json:"clear_due,omitempty"`
 }
 
-type ChangeStatusResponse struct {
-	response.Response
+func (r UpdateTaskRequest) toPatch() models.TaskPatch {
+	p := models.TaskPatch{
+		Title:       r.Title,
+		Description: r.Description,
+		Tags:        r.Tags,
+		Recurrence:  r.Recurrence,
+		DueAt:       r.Due,
+		ClearDue:    r.ClearDue,
+	}
+	if r.Priority != nil {
+		priority := models.Priority(*r.Priority)
+		p.Priority = &priority
+	}
+	return p
 }
 
 // CreateTask ...
@@ -123,13 +231,35 @@ func (c Controller) CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newTaskID, err := c.task.CreateTask(context.Background(), models.Task{
-		UserID:      uid,
-		Title:       t.Title,
-		Description: t.Description,
-	})
+	task, err := t.toModel()
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, &CreateTaskResponse{
+			Response: response.Error(err.Error()),
+		})
+		return
+	}
+
+	// tasks of workspace projects are stored in the workspace owner's scope
+	scope, ok := c.projectScope(w, r, log, t.ProjectID, models.ActionEdit)
+	if !ok {
+		return
+	}
+	task.UserID = scope
+	task.CreatedBy = uid
 
+	newTaskID, err := c.task.CreateTask(context.Background(), task)
 	if err != nil {
+		if status, msg, ok := taskErrorStatus(err); ok {
+			log.Warn("task not created", slog.String("err", err.Error()))
+
+			render.Status(r, status)
+			render.JSON(w, r, &CreateTaskResponse{
+				Response: response.Error(msg),
+			})
+			return
+		}
+
 		log.Error(
 			"failed creating task",
 			slog.Int64("uid", uid),
@@ -157,6 +287,79 @@ func (c Controller) CreateTask(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// QuickAdd creates task from single line like "Pay rent tomorrow 9am #home !high every month"
+func (c Controller) QuickAdd(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.QuickAdd"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	req := &QuickAddRequest{}
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		log.Warn("failed parse json", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, &QuickAddResponse{
+			Response: response.Error("incorrect request body"),
+		})
+		return
+	}
+
+	if err := validateRequest(req); err != nil {
+		log.Warn("incorrect body", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, &QuickAddResponse{
+			Response: response.Error(err.Error()),
+		})
+		return
+	}
+
+	task, parsed, err := c.task.QuickAdd(r.Context(), uid, req.Text, req.DryRun)
+	if err != nil {
+		if errors.Is(err, quickadd.ErrEmptyTitle) {
+			log.Warn("empty title in quick add", slog.String("text", req.Text))
+
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, &QuickAddResponse{
+				Response: response.Error(err.Error()),
+			})
+			return
+		}
+
+		log.Error("failed quick add", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, &QuickAddResponse{
+			Response: response.Error("task not created"),
+		})
+		return
+	}
+
+	res := &QuickAddResponse{
+		Response: response.OK(),
+		ID:       task.ID,
+		Parsed: &QuickAddParsed{
+			Title:      parsed.Title,
+			Due:        parsed.Due,
+			AllDay:     parsed.AllDay,
+			Tags:       parsed.Tags,
+			Priority:   parsed.Priority,
+			Recurrence: parsed.Recurrence,
+		},
+	}
+
+	if req.DryRun {
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, res)
+		return
+	}
+
+	log.Info("success quick add", slog.Int64("new_task_id", task.ID))
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, res)
+}
+
 // Tasks get all tasks for user id
 // todo: add pagination
 func (c Controller) Tasks(w http.ResponseWriter, r *http.Request) {
@@ -166,7 +369,18 @@ func (c Controller) Tasks(w http.ResponseWriter, r *http.Request) {
 
 	log.Info("getting get tasks", slog.Int64("user_id", uid))
 
-	t, err := c.task.Tasks(context.Background(), uid)
+	filter, err := taskFilterFromQuery(r)
+	if err != nil {
+		log.Warn("invalid filter", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, &TasksResponse{
+			Response: response.Error(err.Error()),
+		})
+		return
+	}
+
+	t, err := c.task.Tasks(context.Background(), uid, filter)
 	if err != nil {
 		log.Error(
 			"failed getting tasks",
@@ -189,15 +403,7 @@ func (c Controller) Tasks(w http.ResponseWriter, r *http.Request) {
 		Tasks: func() []Task {
 			res := make([]Task, len(t), cap(t))
 			for i, v := range t {
-				res[i] = Task{
-					ID:          v.ID,
-					UserID:      v.UserID,
-					Title:       v.Title,
-					Description: v.Description,
-					Status:      v.Status,
-					CreatedAt:   v.CreatedAt,
-					UpdatedAt:   v.UpdatedAt,
-				}
+				res[i] = taskFromModel(v)
 			}
 			return res
 		}(),
@@ -226,6 +432,16 @@ func (c Controller) Task(w http.ResponseWriter, r *http.Request) {
 
 	task, err := c.task.TasksByID(context.Background(), taskID, uid)
 	if err != nil {
+		if errors.Is(err, models.ErrTaskNotFound) {
+			log.Warn("task not found", slog.Int64("task_id", taskID))
+
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, &TasksResponse{
+				Response: response.Error("task not found"),
+			})
+			return
+		}
+
 		log.Error(
 			"failed get task for id",
 			slog.Int64("task_id", taskID),
@@ -245,57 +461,146 @@ func (c Controller) Task(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, TasksResponse{
 		Response: response.OK(),
 		Tasks: []Task{
-			{
-				ID:          task.ID,
-				UserID:      task.UserID,
-				Title:       task.Title,
-				Description: task.Description,
-				Status:      task.Status,
-				CreatedAt:   task.CreatedAt,
-				UpdatedAt:   task.UpdatedAt,
-			},
+			taskFromModel(task),
 		},
 	})
 }
 
-func (c Controller) ChangeStatusTask(w http.ResponseWriter, r *http.Request) {
-	const op = "controller.ChangeStatusTask"
+// UpdateTask changes the given fields of the task, status moves it to another workflow column
+func (c Controller) UpdateTask(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.UpdateTask"
 	uid := userIDFromJWTClaims(r)
-
 	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
 
-	log.Info("try change status")
+	taskID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
 
-	taskID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
-	if err != nil {
-		log.Error("failed parse task id", slog.String("err", err.Error()))
+	req := &UpdateTaskRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
 
-		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, &TasksResponse{
-			Response: response.Error("failed get task id"),
-		})
+	patch := req.toPatch()
+	if patch.Empty() && req.Status == nil {
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error(models.ErrNothingToApply.Error()))
 		return
 	}
 
-	s := &ChangeStatusRequest{}
+	if !patch.Empty() {
+		if _, err := c.task.UpdateTask(r.Context(), taskID, uid, patch); err != nil {
+			c.taskError(w, r, log, err)
+			return
+		}
+	}
 
-	if err := render.DecodeJSON(r.Body, s); err != nil {
-		log.Error("failed read json")
+	if req.Status != nil {
+		if err := c.task.ChangeTaskStatus(r.Context(), taskID, uid, *req.Status); err != nil {
+			c.taskError(w, r, log, err)
+			return
+		}
+	}
 
-		render.Status(r, http.StatusBadRequest)
+	task, err := c.task.TasksByID(r.Context(), taskID, uid)
+	if err != nil {
+		c.taskError(w, r, log, err)
 		return
 	}
 
-	if err = c.task.ChangeTaskStatus(context.Background(), taskID, uid, s.Status); err != nil {
-		log.Error("failed change status")
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &TasksResponse{
+		Response: response.OK(),
+		Tasks:    []Task{taskFromModel(task)},
+	})
+}
 
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, &ChangeStatusResponse{response.Error("failed change status")})
+func taskFromModel(t models.Task) Task {
+	return Task{
+		ID:             t.ID,
+		UserID:         t.UserID,
+		AssigneeID:     t.AssigneeID,
+		ProjectID:      t.ProjectID,
+		Title:          t.Title,
+		Description:    t.Description,
+		Status:         t.Status,
+		StatusCategory: t.StatusCategory,
+		Priority:       t.Priority,
+		Tags:           t.Tags,
+		Recurrence:     t.Recurrence,
+		CustomFields:   customFieldsToJSON(t.CustomFields),
+		Due:            t.DueAt,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
+	}
+}
+
+func (c Controller) DeleteTask(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.DeleteTask"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	taskID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	if err := c.task.DeleteTask(r.Context(), taskID, uid); err != nil {
+		c.taskError(w, r, log, err)
 		return
 	}
 
 	render.Status(r, http.StatusOK)
-	render.JSON(w, r, &ChangeStatusResponse{response.OK()})
+	render.JSON(w, r, response.OK())
+}
+
+// taskError writes response for errors of the tasks service
+func (c Controller) taskError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	if status, msg, ok := taskErrorStatus(err); ok {
+		log.Warn("task request rejected", slog.String("err", err.Error()))
+
+		render.Status(r, status)
+		render.JSON(w, r, response.Error(msg))
+		return
+	}
+
+	log.Error("failed process task", slog.String("err", err.Error()))
+
+	render.Status(r, http.StatusInternalServerError)
+	render.JSON(w, r, response.Error("internal error"))
+}
+
+// taskErrorStatus maps expected domain errors to http status and message
+func taskErrorStatus(err error) (int, string, bool) {
+	switch {
+	case errors.Is(err, models.ErrTaskNotFound):
+		return http.StatusNotFound, "task not found", true
+	case errors.Is(err, models.ErrForbidden):
+		return http.StatusForbidden, "access denied", true
+	case errors.Is(err, models.ErrUserNotFound):
+		return http.StatusNotFound, "user not found", true
+	case errors.Is(err, models.ErrShareNotFound):
+		return http.StatusNotFound, "share not found", true
+	case errors.Is(err, models.ErrInvalidPermission),
+		errors.Is(err, models.ErrShareWithOwner),
+		errors.Is(err, models.ErrAssigneeNoAccess):
+		return http.StatusBadRequest, err.Error(), true
+	case errors.Is(err, models.ErrProjectNotFound):
+		return http.StatusNotFound, "project not found", true
+	case errors.Is(err, models.ErrUnknownStatus),
+		errors.Is(err, models.ErrInvalidTask),
+		errors.Is(err, models.ErrNothingToApply):
+		return http.StatusBadRequest, err.Error(), true
+	case errors.Is(err, models.ErrWIPLimitExceeded):
+		return http.StatusConflict, err.Error(), true
+	case errors.Is(err, models.ErrFieldNotFound),
+		errors.Is(err, models.ErrInvalidFieldValue),
+		errors.Is(err, fields.ErrTaskWithoutProject):
+		return http.StatusBadRequest, err.Error(), true
+	default:
+		return 0, "", false
+	}
 }
 
 func userIDFromJWTClaims(r *http.Request) int64 {
@@ -0,0 +1,336 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 200
+)
+
+type Webhooks interface {
+	CreateWebhook(ctx context.Context, hook models.Webhook) (models.Webhook, error)
+	Webhooks(ctx context.Context, userID int64) ([]models.Webhook, error)
+	Webhook(ctx context.Context, userID int64, id int64) (models.Webhook, error)
+	UpdateWebhook(ctx context.Context, hook models.Webhook) (models.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID int64, id int64) error
+	Deliveries(ctx context.Context, userID int64, id int64, limit int) ([]models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, userID int64, id int64, deliveryID int64) (models.WebhookDelivery, error)
+}
+
+type Webhook struct {
+	ID     int64              `json:"id"`
+	URL    string             `json:"url"`
+	Events []models.EventType `json:"events"`
+	Active bool               `json:"active"`
+	// Secret is returned only on creation
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created"`
+	UpdatedAt time.Time `json:"updated"`
+}
+
+type WebhookRequest struct {
+	URL    string             `json:"url" validate:"required,url"`
+	Events []models.EventType `json:"events" validate:"required,min=1"`
+}
+
+// UpdateWebhookRequest changes only the given fields
+type UpdateWebhookRequest struct {
+	URL    *string            `json:"url,omitempty" validate:"omitempty,url"`
+	Events []models.EventType `json:"events,omitempty"`
+	Active *bool              `json:"active,omitempty"`
+}
+
+type WebhooksResponse struct {
+	response.Response
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+}
+
+type WebhookDelivery struct {
+	ID            int64                 `json:"id"`
+	Event         models.EventType      `json:"event"`
+	Status        models.DeliveryStatus `json:"status"`
+	Attempts      int                   `json:"attempts"`
+	ResponseCode  *int                  `json:"response_code,omitempty"`
+	Error         string                `json:"error,omitempty"`
+	RedeliveryOf  *int64                `json:"redelivery_of,omitempty"`
+	Payload       json.RawMessage       `json:"payload"`
+	NextAttemptAt *time.Time            `json:"next_attempt_at,omitempty"`
+	LastAttemptAt *time.Time            `json:"last_attempt_at,omitempty"`
+	CreatedAt     time.Time             `json:"created"`
+}
+
+type DeliveriesResponse struct {
+	response.Response
+	Deliveries []WebhookDelivery `json:"deliveries,omitempty"`
+}
+
+func (c Controller) Webhooks(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Webhooks"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	hooks, err := c.webhooks.Webhooks(r.Context(), uid)
+	if err != nil {
+		c.webhookError(w, r, log, err)
+		return
+	}
+
+	res := make([]Webhook, len(hooks))
+	for i, h := range hooks {
+		res[i] = webhookFromModel(h)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &WebhooksResponse{
+		Response: response.OK(),
+		Webhooks: res,
+	})
+}
+
+func (c Controller) Webhook(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Webhook"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	id, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	hook, err := c.webhooks.Webhook(r.Context(), uid, id)
+	if err != nil {
+		c.webhookError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &WebhooksResponse{
+		Response: response.OK(),
+		Webhooks: []Webhook{webhookFromModel(hook)},
+	})
+}
+
+// CreateWebhook registers the endpoint, the response has the signing secret which is not shown again
+func (c Controller) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.CreateWebhook"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	req := &WebhookRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	hook, err := c.webhooks.CreateWebhook(r.Context(), models.Webhook{
+		UserID: uid,
+		URL:    req.URL,
+		Events: req.Events,
+	})
+	if err != nil {
+		c.webhookError(w, r, log, err)
+		return
+	}
+
+	res := webhookFromModel(hook)
+	res.Secret = hook.Secret
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &WebhooksResponse{
+		Response: response.OK(),
+		Webhooks: []Webhook{res},
+	})
+}
+
+func (c Controller) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.UpdateWebhook"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	id, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	req := &UpdateWebhookRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	hook, err := c.webhooks.Webhook(r.Context(), uid, id)
+	if err != nil {
+		c.webhookError(w, r, log, err)
+		return
+	}
+
+	if req.URL != nil {
+		hook.URL = *req.URL
+	}
+	if req.Events != nil {
+		hook.Events = req.Events
+	}
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+
+	hook, err = c.webhooks.UpdateWebhook(r.Context(), hook)
+	if err != nil {
+		c.webhookError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &WebhooksResponse{
+		Response: response.OK(),
+		Webhooks: []Webhook{webhookFromModel(hook)},
+	})
+}
+
+func (c Controller) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.DeleteWebhook"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	id, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	if err := c.webhooks.DeleteWebhook(r.Context(), uid, id); err != nil {
+		c.webhookError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+// WebhookDeliveries returns the delivery log newest first, ?limit= up to 200
+func (c Controller) WebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.WebhookDeliveries"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	id, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	limit := defaultDeliveriesLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxDeliveriesLimit {
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("limit must be between 1 and "+strconv.Itoa(maxDeliveriesLimit)))
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := c.webhooks.Deliveries(r.Context(), uid, id, limit)
+	if err != nil {
+		c.webhookError(w, r, log, err)
+		return
+	}
+
+	res := make([]WebhookDelivery, len(deliveries))
+	for i, d := range deliveries {
+		res[i] = deliveryFromModel(d)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &DeliveriesResponse{
+		Response:   response.OK(),
+		Deliveries: res,
+	})
+}
+
+// Redeliver sends the payload of the delivery again as a new delivery
+func (c Controller) Redeliver(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Redeliver"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	id, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	deliveryID, ok := c.int64FromURL(w, r, log, "deliveryID")
+	if !ok {
+		return
+	}
+
+	d, err := c.webhooks.Redeliver(r.Context(), uid, id, deliveryID)
+	if err != nil {
+		c.webhookError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusAccepted)
+	render.JSON(w, r, &DeliveriesResponse{
+		Response:   response.OK(),
+		Deliveries: []WebhookDelivery{deliveryFromModel(d)},
+	})
+}
+
+func (c Controller) webhookError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, models.ErrWebhookNotFound):
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error("webhook not found"))
+	case errors.Is(err, models.ErrDeliveryNotFound):
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error("delivery not found"))
+	case errors.Is(err, models.ErrInvalidWebhookURL),
+		errors.Is(err, models.ErrPrivateWebhookURL),
+		errors.Is(err, models.ErrNoWebhookEvents),
+		errors.Is(err, models.ErrInvalidEventType):
+		log.Warn("invalid webhook", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error(err.Error()))
+	default:
+		log.Error("failed process webhook", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+	}
+}
+
+func webhookFromModel(h models.Webhook) Webhook {
+	return Webhook{
+		ID:        h.ID,
+		URL:       h.URL,
+		Events:    h.Events,
+		Active:    h.Active,
+		CreatedAt: h.CreatedAt,
+		UpdatedAt: h.UpdatedAt,
+	}
+}
+
+func deliveryFromModel(d models.WebhookDelivery) WebhookDelivery {
+	return WebhookDelivery{
+		ID:            d.ID,
+		Event:         d.Event,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		ResponseCode:  d.ResponseCode,
+		Error:         d.Error,
+		RedeliveryOf:  d.RedeliveryOf,
+		Payload:       d.Payload,
+		NextAttemptAt: d.NextAttemptAt,
+		LastAttemptAt: d.LastAttemptAt,
+		CreatedAt:     d.CreatedAt,
+	}
+}
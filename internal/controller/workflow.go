@@ -0,0 +1,290 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+)
+
+type Workflow interface {
+	Workflow(ctx context.Context, userID int64, projectID *int64) ([]models.WorkflowStatus, error)
+	CreateStatus(ctx context.Context, ws models.WorkflowStatus) (int64, error)
+	UpdateStatus(ctx context.Context, ws models.WorkflowStatus) error
+	DeleteStatus(ctx context.Context, userID int64, projectID *int64, statusID int64) error
+	Board(ctx context.Context, userID int64, projectID *int64) ([]models.BoardColumn, error)
+}
+
+type WorkflowStatus struct {
+	ID        int64                 `json:"id,omitempty"`
+	ProjectID *int64                `json:"project_id,omitempty"`
+	Name      models.Status         `json:"name"`
+	Category  models.StatusCategory `json:"category"`
+	Position  int                   `json:"position"`
+	WIPLimit  int                   `json:"wip_limit,omitempty"`
+}
+
+type CreateStatusRequest struct {
+	ProjectID *int64 `json:"project_id,omitempty"`
+	Name      string `json:"name" validate:"required,max=64"`
+	Category  string `json:"category" validate:"required,oneof=todo in_progress done"`
+	Position  int    `json:"position,omitempty" validate:"gte=0"`
+	WIPLimit  int    `json:"wip_limit,omitempty" validate:"gte=0"`
+}
+
+type UpdateStatusRequest struct {
+	Name     string `json:"name,omitempty" validate:"max=64"`
+	Category string `json:"category,omitempty" validate:"omitempty,oneof=todo in_progress done"`
+	Position int    `json:"position,omitempty" validate:"gte=0"`
+	WIPLimit *int   `json:"wip_limit,omitempty" validate:"omitempty,gte=0"`
+}
+
+type StatusesResponse struct {
+	response.Response
+	Statuses []WorkflowStatus `json:"statuses,omitempty"`
+}
+
+type CreateStatusResponse struct {
+	response.Response
+	ID int64 `json:"id,omitempty"`
+}
+
+type BoardColumn struct {
+	Status    WorkflowStatus `json:"status"`
+	Count     int            `json:"count"`
+	OverLimit bool           `json:"over_limit,omitempty"`
+	Tasks     []Task         `json:"tasks"`
+}
+
+type BoardResponse struct {
+	response.Response
+	Columns []BoardColumn `json:"columns,omitempty"`
+}
+
+// Statuses returns workflow used for the project (?project_id=) or for tasks without project
+func (c Controller) Statuses(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Statuses"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	projectID, ok := c.projectIDFromQuery(w, r, log)
+	if !ok {
+		return
+	}
+
+	scope, ok := c.projectScope(w, r, log, projectID, models.ActionView)
+	if !ok {
+		return
+	}
+
+	statuses, err := c.workflow.Workflow(r.Context(), scope, projectID)
+	if err != nil {
+		c.workflowError(w, r, log, err)
+		return
+	}
+
+	res := make([]WorkflowStatus, len(statuses))
+	for i, s := range statuses {
+		res[i] = workflowStatusFromModel(s)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &StatusesResponse{
+		Response: response.OK(),
+		Statuses: res,
+	})
+}
+
+func (c Controller) CreateStatus(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.CreateStatus"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	req := &CreateStatusRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	scope, ok := c.projectScope(w, r, log, req.ProjectID, models.ActionManageProjects)
+	if !ok {
+		return
+	}
+
+	id, err := c.workflow.CreateStatus(r.Context(), models.WorkflowStatus{
+		UserID:    scope,
+		ProjectID: req.ProjectID,
+		Name:      models.Status(req.Name),
+		Category:  models.StatusCategory(req.Category),
+		Position:  req.Position,
+		WIPLimit:  req.WIPLimit,
+	})
+	if err != nil {
+		c.workflowError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &CreateStatusResponse{
+		Response: response.OK(),
+		ID:       id,
+	})
+}
+
+func (c Controller) UpdateStatus(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.UpdateStatus"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	statusID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	req := &UpdateStatusRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	// negative limit keeps the current value
+	wip := -1
+	if req.WIPLimit != nil {
+		wip = *req.WIPLimit
+	}
+
+	projectID, ok := c.projectIDFromQuery(w, r, log)
+	if !ok {
+		return
+	}
+
+	scope, ok := c.projectScope(w, r, log, projectID, models.ActionManageProjects)
+	if !ok {
+		return
+	}
+
+	err := c.workflow.UpdateStatus(r.Context(), models.WorkflowStatus{
+		ID:        statusID,
+		UserID:    scope,
+		ProjectID: projectID,
+		Name:      models.Status(req.Name),
+		Category:  models.StatusCategory(req.Category),
+		Position:  req.Position,
+		WIPLimit:  wip,
+	})
+	if err != nil {
+		c.workflowError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func (c Controller) DeleteStatus(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.DeleteStatus"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	statusID, ok := c.idFromURL(w, r, log)
+	if !ok {
+		return
+	}
+
+	projectID, ok := c.projectIDFromQuery(w, r, log)
+	if !ok {
+		return
+	}
+
+	scope, ok := c.projectScope(w, r, log, projectID, models.ActionManageProjects)
+	if !ok {
+		return
+	}
+
+	if err := c.workflow.DeleteStatus(r.Context(), scope, projectID, statusID); err != nil {
+		c.workflowError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+// Board returns tasks grouped by workflow columns
+func (c Controller) Board(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Board"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	projectID, ok := c.projectIDFromQuery(w, r, log)
+	if !ok {
+		return
+	}
+
+	scope, ok := c.projectScope(w, r, log, projectID, models.ActionView)
+	if !ok {
+		return
+	}
+
+	columns, err := c.workflow.Board(r.Context(), scope, projectID)
+	if err != nil {
+		c.workflowError(w, r, log, err)
+		return
+	}
+
+	res := make([]BoardColumn, len(columns))
+	for i, col := range columns {
+		tasks := make([]Task, len(col.Tasks))
+		for j, t := range col.Tasks {
+			tasks[j] = taskFromModel(t)
+		}
+		res[i] = BoardColumn{
+			Status:    workflowStatusFromModel(col.Status),
+			Count:     len(tasks),
+			OverLimit: col.Status.WIPLimit > 0 && len(tasks) > col.Status.WIPLimit,
+			Tasks:     tasks,
+		}
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &BoardResponse{
+		Response: response.OK(),
+		Columns:  res,
+	})
+}
+
+func (c Controller) workflowError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, models.ErrProjectNotFound):
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error("project not found"))
+	case errors.Is(err, models.ErrStatusNotFound):
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error("status not found"))
+	case errors.Is(err, models.ErrStatusAlreadyExists):
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, response.Error("status already exists"))
+	case errors.Is(err, models.ErrUnknownStatus), errors.Is(err, models.ErrInvalidCategory):
+		log.Warn("invalid status", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error(err.Error()))
+	default:
+		log.Error("failed process workflow", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+	}
+}
+
+func workflowStatusFromModel(s models.WorkflowStatus) WorkflowStatus {
+	return WorkflowStatus{
+		ID:        s.ID,
+		ProjectID: s.ProjectID,
+		Name:      s.Name,
+		Category:  s.Category,
+		Position:  s.Position,
+		WIPLimit:  s.WIPLimit,
+	}
+}
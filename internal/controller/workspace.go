@@ -0,0 +1,467 @@
+package controller
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/middlewares"
+	"TaskList/internal/models"
+	"TaskList/internal/services/workspaces"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Authz is the authorization layer consulted by handlers before calling services
+type Authz interface {
+	middlewares.WorkspaceAuthorizer
+	WorkspaceScope(ctx context.Context, userID int64, workspaceID int64, action models.Action) (int64, error)
+	ProjectScope(ctx context.Context, userID int64, projectID *int64, action models.Action) (int64, error)
+}
+
+type Workspaces interface {
+	CreateWorkspace(ctx context.Context, userID int64, name string) (int64, error)
+	Workspaces(ctx context.Context, userID int64) ([]models.Workspace, error)
+	Workspace(ctx context.Context, workspaceID int64, userID int64) (models.Workspace, error)
+	RenameWorkspace(ctx context.Context, workspaceID int64, name string) error
+	DeleteWorkspace(ctx context.Context, workspaceID int64) error
+	Members(ctx context.Context, workspaceID int64) ([]models.WorkspaceMember, error)
+	Projects(ctx context.Context, workspaceID int64) ([]models.Project, error)
+	ChangeRole(ctx context.Context, actor models.WorkspaceMember, userID int64, role models.Role) error
+	RemoveMember(ctx context.Context, actor models.WorkspaceMember, userID int64) error
+	Invite(ctx context.Context, actor models.WorkspaceMember, email string, role models.Role) (models.Invitation, error)
+	Invitations(ctx context.Context, workspaceID int64) ([]models.Invitation, error)
+	RevokeInvitation(ctx context.Context, workspaceID int64, invitationID int64) error
+	AcceptInvitation(ctx context.Context, userID int64, token string) (int64, error)
+}
+
+type Workspace struct {
+	ID        int64       `json:"id"`
+	OwnerID   int64       `json:"owner_id"`
+	Name      string      `json:"name"`
+	Role      models.Role `json:"role,omitempty"`
+	CreatedAt time.Time   `json:"created"`
+	UpdatedAt time.Time   `json:"updated"`
+}
+
+type WorkspaceMember struct {
+	UserID    int64       `json:"user_id"`
+	Email     string      `json:"email"`
+	Role      models.Role `json:"role"`
+	CreatedAt time.Time   `json:"created"`
+}
+
+type Invitation struct {
+	ID        int64       `json:"id"`
+	Email     string      `json:"email"`
+	Role      models.Role `json:"role"`
+	Token     string      `json:"token,omitempty"`
+	CreatedAt time.Time   `json:"created"`
+	ExpiresAt time.Time   `json:"expires"`
+}
+
+type WorkspaceRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin member guest"`
+}
+
+type InvitationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=admin member guest"`
+}
+
+type CreateWorkspaceResponse struct {
+	response.Response
+	ID int64 `json:"id,omitempty"`
+}
+
+type WorkspacesResponse struct {
+	response.Response
+	Workspaces []Workspace `json:"workspaces,omitempty"`
+}
+
+type WorkspaceMembersResponse struct {
+	response.Response
+	Members []WorkspaceMember `json:"members,omitempty"`
+}
+
+type InvitationsResponse struct {
+	response.Response
+	Invitations []Invitation `json:"invitations,omitempty"`
+}
+
+type AcceptInvitationResponse struct {
+	response.Response
+	WorkspaceID int64 `json:"workspace_id,omitempty"`
+}
+
+func (c Controller) Workspaces(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Workspaces"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	list, err := c.workspaces.Workspaces(r.Context(), uid)
+	if err != nil {
+		c.workspaceError(w, r, log, err)
+		return
+	}
+
+	res := make([]Workspace, len(list))
+	for i, v := range list {
+		res[i] = workspaceFromModel(v)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &WorkspacesResponse{
+		Response:   response.OK(),
+		Workspaces: res,
+	})
+}
+
+func (c Controller) CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.CreateWorkspace"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	req := &WorkspaceRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	id, err := c.workspaces.CreateWorkspace(r.Context(), uid, req.Name)
+	if err != nil {
+		c.workspaceError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &CreateWorkspaceResponse{
+		Response: response.OK(),
+		ID:       id,
+	})
+}
+
+func (c Controller) Workspace(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Workspace"
+	member := memberFromContext(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", member.UserID))
+
+	ws, err := c.workspaces.Workspace(r.Context(), member.WorkspaceID, member.UserID)
+	if err != nil {
+		c.workspaceError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &WorkspacesResponse{
+		Response:   response.OK(),
+		Workspaces: []Workspace{workspaceFromModel(ws)},
+	})
+}
+
+func (c Controller) RenameWorkspace(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.RenameWorkspace"
+	member := memberFromContext(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", member.UserID))
+
+	req := &WorkspaceRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	if err := c.workspaces.RenameWorkspace(r.Context(), member.WorkspaceID, req.Name); err != nil {
+		c.workspaceError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func (c Controller) DeleteWorkspace(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.DeleteWorkspace"
+	member := memberFromContext(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", member.UserID))
+
+	if err := c.workspaces.DeleteWorkspace(r.Context(), member.WorkspaceID); err != nil {
+		c.workspaceError(w, r, log, err)
+		return
+	}
+
+	log.Info("workspace deleted", slog.Int64("workspace_id", member.WorkspaceID))
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func (c Controller) WorkspaceProjects(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.WorkspaceProjects"
+	member := memberFromContext(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", member.UserID))
+
+	p, err := c.workspaces.Projects(r.Context(), member.WorkspaceID)
+	if err != nil {
+		c.workspaceError(w, r, log, err)
+		return
+	}
+
+	res := make([]Project, len(p))
+	for i, v := range p {
+		res[i] = projectFromModel(v)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &ProjectsResponse{
+		Response: response.OK(),
+		Projects: res,
+	})
+}
+
+func (c Controller) WorkspaceMembers(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.WorkspaceMembers"
+	member := memberFromContext(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", member.UserID))
+
+	members, err := c.workspaces.Members(r.Context(), member.WorkspaceID)
+	if err != nil {
+		c.workspaceError(w, r, log, err)
+		return
+	}
+
+	res := make([]WorkspaceMember, len(members))
+	for i, m := range members {
+		res[i] = WorkspaceMember{UserID: m.UserID, Email: m.Email, Role: m.Role, CreatedAt: m.CreatedAt}
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &WorkspaceMembersResponse{
+		Response: response.OK(),
+		Members:  res,
+	})
+}
+
+func (c Controller) ChangeMemberRole(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.ChangeMemberRole"
+	member := memberFromContext(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", member.UserID))
+
+	userID, ok := c.int64FromURL(w, r, log, "userID")
+	if !ok {
+		return
+	}
+
+	req := &ChangeRoleRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	if err := c.workspaces.ChangeRole(r.Context(), member, userID, models.Role(req.Role)); err != nil {
+		c.workspaceError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+// RemoveMember removes member, members can leave the workspace by removing themselves
+func (c Controller) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.RemoveMember"
+	member := memberFromContext(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", member.UserID))
+
+	userID, ok := c.int64FromURL(w, r, log, "userID")
+	if !ok {
+		return
+	}
+
+	if err := c.workspaces.RemoveMember(r.Context(), member, userID); err != nil {
+		c.workspaceError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func (c Controller) Invitations(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Invitations"
+	member := memberFromContext(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", member.UserID))
+
+	list, err := c.workspaces.Invitations(r.Context(), member.WorkspaceID)
+	if err != nil {
+		c.workspaceError(w, r, log, err)
+		return
+	}
+
+	res := make([]Invitation, len(list))
+	for i, inv := range list {
+		res[i] = invitationFromModel(inv)
+		res[i].Token = ""
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &InvitationsResponse{
+		Response:    response.OK(),
+		Invitations: res,
+	})
+}
+
+// Invite creates invitation, the token is returned once so the inviter can deliver it
+func (c Controller) Invite(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.Invite"
+	member := memberFromContext(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", member.UserID))
+
+	req := &InvitationRequest{}
+	if !c.decodeAndValidate(w, r, log, req) {
+		return
+	}
+
+	inv, err := c.workspaces.Invite(r.Context(), member, req.Email, models.Role(req.Role))
+	if err != nil {
+		c.workspaceError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &InvitationsResponse{
+		Response:    response.OK(),
+		Invitations: []Invitation{invitationFromModel(inv)},
+	})
+}
+
+func (c Controller) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.RevokeInvitation"
+	member := memberFromContext(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", member.UserID))
+
+	invitationID, ok := c.int64FromURL(w, r, log, "invitationID")
+	if !ok {
+		return
+	}
+
+	if err := c.workspaces.RevokeInvitation(r.Context(), member.WorkspaceID, invitationID); err != nil {
+		c.workspaceError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response.OK())
+}
+
+func (c Controller) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.AcceptInvitation"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	workspaceID, err := c.workspaces.AcceptInvitation(r.Context(), uid, chi.URLParam(r, "token"))
+	if err != nil {
+		c.workspaceError(w, r, log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &AcceptInvitationResponse{
+		Response:    response.OK(),
+		WorkspaceID: workspaceID,
+	})
+}
+
+// projectScope consults the authorization layer for the project and returns the user whose data holds it,
+// writes error response if the action is not allowed
+func (c Controller) projectScope(
+	w http.ResponseWriter,
+	r *http.Request,
+	log *slog.Logger,
+	projectID *int64,
+	action models.Action,
+) (int64, bool) {
+	scope, err := c.authz.ProjectScope(r.Context(), userIDFromJWTClaims(r), projectID, action)
+	if err != nil {
+		c.workspaceError(w, r, log, err)
+		return 0, false
+	}
+	return scope, true
+}
+
+func (c Controller) workspaceError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) {
+	switch {
+	case errors.Is(err, models.ErrWorkspaceNotFound),
+		errors.Is(err, models.ErrProjectNotFound),
+		errors.Is(err, models.ErrMemberNotFound),
+		errors.Is(err, models.ErrInvitationNotFound):
+		log.Warn("not found", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusNotFound)
+		render.JSON(w, r, response.Error(notFoundMessage(err)))
+	case errors.Is(err, models.ErrForbidden):
+		log.Warn("access denied", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, response.Error("access denied"))
+	case errors.Is(err, models.ErrAlreadyMember):
+		render.Status(r, http.StatusConflict)
+		render.JSON(w, r, response.Error(models.ErrAlreadyMember.Error()))
+	case errors.Is(err, models.ErrInvitationExpired):
+		render.Status(r, http.StatusGone)
+		render.JSON(w, r, response.Error(models.ErrInvitationExpired.Error()))
+	case errors.Is(err, models.ErrInvitationEmail):
+		render.Status(r, http.StatusForbidden)
+		render.JSON(w, r, response.Error(models.ErrInvitationEmail.Error()))
+	case errors.Is(err, models.ErrInvalidRole), errors.Is(err, workspaces.ErrEmptyName):
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error(err.Error()))
+	default:
+		log.Error("failed process workspace", slog.String("err", err.Error()))
+
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, response.Error("internal error"))
+	}
+}
+
+func notFoundMessage(err error) string {
+	for _, e := range []error{
+		models.ErrWorkspaceNotFound,
+		models.ErrProjectNotFound,
+		models.ErrMemberNotFound,
+		models.ErrInvitationNotFound,
+	} {
+		if errors.Is(err, e) {
+			return e.Error()
+		}
+	}
+	return "not found"
+}
+
+func memberFromContext(r *http.Request) models.WorkspaceMember {
+	return r.Context().Value(middlewares.KeyMember).(models.WorkspaceMember)
+}
+
+func workspaceFromModel(ws models.Workspace) Workspace {
+	return Workspace{
+		ID:        ws.ID,
+		OwnerID:   ws.OwnerID,
+		Name:      ws.Name,
+		Role:      ws.Role,
+		CreatedAt: ws.CreatedAt,
+		UpdatedAt: ws.UpdatedAt,
+	}
+}
+
+func invitationFromModel(inv models.Invitation) Invitation {
+	return Invitation{
+		ID:        inv.ID,
+		Email:     inv.Email,
+		Role:      inv.Role,
+		Token:     inv.Token,
+		CreatedAt: inv.CreatedAt,
+		ExpiresAt: inv.ExpiresAt,
+	}
+}
@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"TaskList/internal/models"
+	"TaskList/internal/services/realtime"
+	"context"
+	"github.com/gorilla/websocket"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	wsWriteWait      = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingPeriod     = wsPongWait * 9 / 10
+	wsMaxMessageSize = 64 << 10
+)
+
+type Realtime interface {
+	Register(userID int64) *realtime.Session
+	Unregister(s *realtime.Session)
+}
+
+// WSRequest is a client message, ID is returned in the response to correlate it.
+// Types: subscribe and unsubscribe with project_id or task_id, create with task,
+// update with task_id and changes, move with task_id and status, ping
+type WSRequest struct {
+	ID        string             `json:"id,omitempty"`
+	Type      string             `json:"type"`
+	ProjectID *int64             `json:"project_id,omitempty"`
+	TaskID    *int64             `json:"task_id,omitempty"`
+	Task      *TaskRequest       `json:"task,omitempty"`
+	Changes   *UpdateTaskRequest `json:"changes,omitempty"`
+	Status    string             `json:"status,omitempty"`
+}
+
+// WSMessage is a server message: result or error of the request with its ID, or event
+type WSMessage struct {
+	ID    string         `json:"id,omitempty"`
+	Type  string         `json:"type"`
+	Error string         `json:"error,omitempty"`
+	Task  *Task          `json:"task,omitempty"`
+	Event *TaskEventData `json:"event,omitempty"`
+}
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	// the connection is authenticated by the token, not by cookies, so any origin may connect
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// WebSocket upgrades the authenticated request to a realtime session of the user.
+// Events of subscribed projects and tasks are pushed to every session of the user,
+// a session which does not read its events fast enough is closed
+func (c Controller) WebSocket(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.WebSocket"
+	uid := userIDFromJWTClaims(r)
+	log := c.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Warn("failed upgrade connection", slog.String("err", err.Error()))
+		return
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	session := c.realtime.Register(uid)
+	defer c.realtime.Unregister(session)
+
+	log.Info("websocket session opened")
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	defer cancel()
+
+	out := make(chan WSMessage)
+	go c.wsRead(ctx, cancel, conn, session, out, log)
+
+	ping := time.NewTicker(wsPingPeriod)
+	defer ping.Stop()
+
+	for {
+		var msg WSMessage
+		select {
+		case <-ctx.Done():
+			log.Info("websocket session closed")
+			return
+		case <-session.Dropped():
+			log.Warn("websocket session too slow")
+			c.wsClose(conn, websocket.CloseTryAgainLater, "too slow to receive events")
+			return
+		case <-ping.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err = conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+			continue
+		case msg = <-out:
+		case e := <-session.Events():
+			data := taskEventData(e)
+			msg = WSMessage{Type: "event", Event: &data}
+		}
+
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		if err = conn.WriteJSON(msg); err != nil {
+			log.Warn("failed write message", slog.String("err", err.Error()))
+			return
+		}
+	}
+}
+
+// wsRead handles client requests until the connection fails, responses are written by the session loop
+func (c Controller) wsRead(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	conn *websocket.Conn,
+	session *realtime.Session,
+	out chan<- WSMessage,
+	log *slog.Logger,
+) {
+	defer cancel()
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var req WSRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			log.Debug("websocket read stopped", slog.String("err", err.Error()))
+			return
+		}
+
+		res := c.wsHandle(ctx, session, req, log)
+		select {
+		case out <- res:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c Controller) wsHandle(ctx context.Context, session *realtime.Session, req WSRequest, log *slog.Logger) WSMessage {
+	uid := session.UserID
+	res := WSMessage{ID: req.ID, Type: "result"}
+
+	var (
+		task models.Task
+		err  error
+	)
+	switch req.Type {
+	case "ping":
+		return res
+	case "subscribe", "unsubscribe":
+		if (req.ProjectID == nil) == (req.TaskID == nil) {
+			return wsError(req, "either project_id or task_id is required")
+		}
+		err = c.wsSubscribe(ctx, session, req)
+	case "create":
+		if req.Task == nil {
+			return wsError(req, "task is required")
+		}
+		if err = validateRequest(req.Task); err != nil {
+			return wsError(req, err.Error())
+		}
+		task, err = c.wsCreate(ctx, uid, *req.Task)
+	case "update":
+		if req.TaskID == nil || req.Changes == nil {
+			return wsError(req, "task_id and changes are required")
+		}
+		if err = validateRequest(req.Changes); err != nil {
+			return wsError(req, err.Error())
+		}
+		task, err = c.task.UpdateTask(ctx, *req.TaskID, uid, req.Changes.toPatch())
+	case "move":
+		if req.TaskID == nil || req.Status == "" {
+			return wsError(req, "task_id and status are required")
+		}
+		if err = c.task.ChangeTaskStatus(ctx, *req.TaskID, uid, req.Status); err == nil {
+			task, err = c.task.TasksByID(ctx, *req.TaskID, uid)
+		}
+	default:
+		return wsError(req, "unknown message type")
+	}
+
+	if err != nil {
+		if _, msg, ok := taskErrorStatus(err); ok {
+			return wsError(req, msg)
+		}
+		log.Error("failed handle websocket request", slog.String("type", req.Type), slog.String("err", err.Error()))
+		return wsError(req, "internal error")
+	}
+
+	if task.ID != 0 {
+		t := taskFromModel(task)
+		res.Task = &t
+	}
+	return res
+}
+
+func (c Controller) wsSubscribe(ctx context.Context, session *realtime.Session, req WSRequest) error {
+	subscribe := req.Type == "subscribe"
+
+	if req.ProjectID != nil {
+		if subscribe {
+			if _, err := c.authz.ProjectScope(ctx, session.UserID, req.ProjectID, models.ActionView); err != nil {
+				return err
+			}
+			session.SubscribeProject(*req.ProjectID)
+		} else {
+			session.UnsubscribeProject(*req.ProjectID)
+		}
+		return nil
+	}
+
+	if subscribe {
+		if _, err := c.task.TasksByID(ctx, *req.TaskID, session.UserID); err != nil {
+			return err
+		}
+		session.SubscribeTask(*req.TaskID)
+	} else {
+		session.UnsubscribeTask(*req.TaskID)
+	}
+
+	return nil
+}
+
+func (c Controller) wsCreate(ctx context.Context, uid int64, req TaskRequest) (models.Task, error) {
+	task, err := req.toModel()
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	task.UserID, err = c.authz.ProjectScope(ctx, uid, task.ProjectID, models.ActionEdit)
+	if err != nil {
+		return models.Task{}, err
+	}
+	task.CreatedBy = uid
+
+	id, err := c.task.CreateTask(ctx, task)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	return c.task.TasksByID(ctx, id, uid)
+}
+
+func (c Controller) wsClose(conn *websocket.Conn, code int, reason string) {
+	_ = conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(wsWriteWait),
+	)
+}
+
+func wsError(req WSRequest, msg string) WSMessage {
+	return WSMessage{ID: req.ID, Type: "error", Error: msg}
+}
@@ -0,0 +1,215 @@
+// Package graphql serves the task domain over GraphQL, resolvers call the same services as the REST controller
+package graphql
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/lib/dataloader"
+	"TaskList/internal/lib/jwt"
+	"TaskList/internal/middlewares"
+	"TaskList/internal/models"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	gql "github.com/graph-gophers/graphql-go"
+	"log/slog"
+	"net/http"
+)
+
+const (
+	maxRequestSize = 1 << 20
+	maxDepth       = 10
+)
+
+//go:embed schema.graphql
+var schema string
+
+type Tasks interface {
+	CreateTask(ctx context.Context, task models.Task) (int64, error)
+	Tasks(ctx context.Context, userID int64, filter models.TaskFilter) ([]models.Task, error)
+	TasksByID(ctx context.Context, taskID int64, userID int64) (models.Task, error)
+	UpdateTask(ctx context.Context, taskID int64, userID int64, patch models.TaskPatch) (models.Task, error)
+	ChangeTaskStatus(ctx context.Context, taskID int64, userID int64, newStatus string) error
+	DeleteTask(ctx context.Context, taskID int64, userID int64) error
+	AssignTask(ctx context.Context, taskID int64, userID int64, email string) error
+}
+
+type Auth interface {
+	SetTimezone(ctx context.Context, userID int64, timezone string) error
+	CreateAppPassword(ctx context.Context, userID int64, name string) (models.AppPassword, string, error)
+	DeleteAppPassword(ctx context.Context, userID int64, id int64) error
+}
+
+type Projects interface {
+	Projects(ctx context.Context, userID int64) ([]models.Project, error)
+}
+
+// Authz resolves the scope new tasks are stored in, implemented by authz service
+type Authz interface {
+	ProjectScope(ctx context.Context, userID int64, projectID *int64, action models.Action) (int64, error)
+}
+
+// Batch loads relations of many tasks at once, tasks come from tasks service so they are already authorized
+type Batch interface {
+	SelectUsersByIDs(ctx context.Context, ids []int64) ([]models.User, error)
+	SelectCommentsByTaskIDs(ctx context.Context, taskIDs []int64) ([]models.Comment, error)
+}
+
+type Handler struct {
+	schema   *gql.Schema
+	projects Projects
+	batch    Batch
+	log      *slog.Logger
+}
+
+func NewHandler(t Tasks, a Auth, p Projects, authz Authz, b Batch, cfg *config.Config, log *slog.Logger) *Handler {
+	r := &Resolver{tasks: t, auth: a, projects: p, authz: authz, log: log}
+	return &Handler{
+		schema:   gql.MustParseSchema(schema, r, gql.UseFieldResolvers(), gql.MaxDepth(maxDepth)),
+		projects: p,
+		batch:    b,
+		log:      log,
+	}
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// ServeHTTP executes the query with loaders of the request, it must be behind AuthJWT
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := request{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		http.Error(w, "incorrect request body", http.StatusBadRequest)
+		return
+	}
+
+	uid := r.Context().Value(middlewares.KeyClaims).(*jwt.CustomClaims).UID
+	ctx := context.WithValue(r.Context(), loadersKey{}, h.newLoaders(uid))
+
+	res := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		h.log.Error("failed write graphql response", slog.String("err", err.Error()))
+	}
+}
+
+type loadersKey struct{}
+
+// loaders batch relations of tasks resolved in one request
+type loaders struct {
+	userID   int64
+	users    *dataloader.Loader[int64, models.User]
+	projects *dataloader.Loader[int64, models.Project]
+	comments *dataloader.Loader[int64, []models.Comment]
+}
+
+func (h *Handler) newLoaders(userID int64) *loaders {
+	return &loaders{
+		userID: userID,
+		users: dataloader.New(func(ctx context.Context, ids []int64) (map[int64]models.User, error) {
+			users, err := h.batch.SelectUsersByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			res := make(map[int64]models.User, len(users))
+			for _, u := range users {
+				res[u.ID] = u
+			}
+			return res, nil
+		}),
+		// projects the user can see are loaded at once, projects of tasks shared without the project are not found
+		projects: dataloader.New(func(ctx context.Context, _ []int64) (map[int64]models.Project, error) {
+			projects, err := h.projects.Projects(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+			res := make(map[int64]models.Project, len(projects))
+			for _, p := range projects {
+				res[p.ID] = p
+			}
+			return res, nil
+		}),
+		comments: dataloader.New(func(ctx context.Context, taskIDs []int64) (map[int64][]models.Comment, error) {
+			comments, err := h.batch.SelectCommentsByTaskIDs(ctx, taskIDs)
+			if err != nil {
+				return nil, err
+			}
+			res := make(map[int64][]models.Comment, len(taskIDs))
+			for _, id := range taskIDs {
+				res[id] = nil
+			}
+			for _, c := range comments {
+				res[c.TaskID] = append(res[c.TaskID], c)
+			}
+			return res, nil
+		}),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// prime registers relations of the tasks so the first resolved relation loads them for all tasks
+func (l *loaders) prime(tasks ...models.Task) {
+	for _, t := range tasks {
+		l.users.Prime(t.UserID, t.CreatedBy)
+		if t.AssigneeID != nil {
+			l.users.Prime(*t.AssigneeID)
+		}
+		l.comments.Prime(t.ID)
+	}
+}
+
+// Error is returned to clients with the code in extensions
+type Error struct {
+	Message string
+	Code    string
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
+
+func (e Error) Extensions() map[string]any {
+	return map[string]any{"code": e.Code}
+}
+
+var notFoundErrors = []error{
+	models.ErrTaskNotFound,
+	models.ErrProjectNotFound,
+	models.ErrWorkspaceNotFound,
+	models.ErrUserNotFound,
+	models.ErrAppPasswordNotFound,
+}
+
+// publicError keeps messages of domain errors and hides unexpected ones
+func publicError(log *slog.Logger, err error) error {
+	for _, nf := range notFoundErrors {
+		if errors.Is(err, nf) {
+			return Error{Message: nf.Error(), Code: "NOT_FOUND"}
+		}
+	}
+
+	switch {
+	case errors.Is(err, models.ErrForbidden):
+		return Error{Message: "access denied", Code: "FORBIDDEN"}
+	case errors.Is(err, models.ErrUnknownStatus),
+		errors.Is(err, models.ErrInvalidTask),
+		errors.Is(err, models.ErrNothingToApply),
+		errors.Is(err, models.ErrAssigneeNoAccess),
+		errors.Is(err, models.ErrInvalidTimezone),
+		errors.Is(err, models.ErrFieldNotFound),
+		errors.Is(err, models.ErrInvalidFieldValue):
+		return Error{Message: err.Error(), Code: "BAD_REQUEST"}
+	case errors.Is(err, models.ErrWIPLimitExceeded):
+		return Error{Message: err.Error(), Code: "CONFLICT"}
+	default:
+		log.Error("failed resolve graphql field", slog.String("err", err.Error()))
+		return Error{Message: "internal error", Code: "INTERNAL"}
+	}
+}
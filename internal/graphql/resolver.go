@@ -0,0 +1,304 @@
+package graphql
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	gql "github.com/graph-gophers/graphql-go"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+const maxPageSize = 200
+
+var errInvalidCursor = Error{Message: "invalid cursor", Code: "BAD_REQUEST"}
+
+// Resolver is the root of queries and mutations
+type Resolver struct {
+	tasks    Tasks
+	auth     Auth
+	projects Projects
+	authz    Authz
+	log      *slog.Logger
+}
+
+func (r *Resolver) Me(ctx context.Context) (*userResolver, error) {
+	l := loadersFrom(ctx)
+	return r.user(ctx, l.userID)
+}
+
+func (r *Resolver) Task(ctx context.Context, args struct{ ID gql.ID }) (*taskResolver, error) {
+	l := loadersFrom(ctx)
+
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := r.tasks.TasksByID(ctx, id, l.userID)
+	if err != nil {
+		if errors.Is(err, models.ErrTaskNotFound) {
+			return nil, nil
+		}
+		return nil, publicError(r.log, err)
+	}
+
+	l.prime(task)
+	return &taskResolver{task: task, r: r}, nil
+}
+
+type tasksArgs struct {
+	Filter *taskFilterInput
+	First  int32
+	After  *string
+}
+
+func (r *Resolver) Tasks(ctx context.Context, args tasksArgs) (*taskConnectionResolver, error) {
+	l := loadersFrom(ctx)
+
+	filter := models.TaskFilter{}
+	if args.Filter != nil {
+		var err error
+		if filter, err = args.Filter.toModel(); err != nil {
+			return nil, err
+		}
+	}
+
+	first := int(args.First)
+	if first <= 0 || first > maxPageSize {
+		first = maxPageSize
+	}
+	offset := 0
+	if args.After != nil {
+		var err error
+		if offset, err = decodeCursor(*args.After); err != nil {
+			return nil, err
+		}
+	}
+
+	tasks, err := r.tasks.Tasks(ctx, l.userID, filter)
+	if err != nil {
+		return nil, publicError(r.log, err)
+	}
+
+	// offset comes from the client and may be near max int, it is clamped before first is added
+	offset = min(offset, len(tasks))
+	page := tasks[offset : offset+min(first, len(tasks)-offset)]
+	l.prime(page...)
+
+	res := &taskConnectionResolver{total: len(tasks), hasNext: offset+len(page) < len(tasks)}
+	for _, t := range page {
+		res.nodes = append(res.nodes, &taskResolver{task: t, r: r})
+	}
+	if len(page) > 0 {
+		cursor := encodeCursor(offset + len(page))
+		res.endCursor = &cursor
+	}
+
+	return res, nil
+}
+
+func (r *Resolver) Projects(ctx context.Context) ([]*projectResolver, error) {
+	projects, err := r.projects.Projects(ctx, loadersFrom(ctx).userID)
+	if err != nil {
+		return nil, publicError(r.log, err)
+	}
+
+	res := make([]*projectResolver, len(projects))
+	for i, p := range projects {
+		res[i] = &projectResolver{project: p}
+	}
+	return res, nil
+}
+
+type createTaskArgs struct {
+	Input createTaskInput
+}
+
+func (r *Resolver) CreateTask(ctx context.Context, args createTaskArgs) (*taskResolver, error) {
+	l := loadersFrom(ctx)
+
+	task, err := args.Input.toModel()
+	if err != nil {
+		return nil, err
+	}
+
+	// tasks of workspace projects are stored in the workspace owner's scope
+	task.UserID, err = r.authz.ProjectScope(ctx, l.userID, task.ProjectID, models.ActionEdit)
+	if err != nil {
+		return nil, publicError(r.log, err)
+	}
+	task.CreatedBy = l.userID
+
+	id, err := r.tasks.CreateTask(ctx, task)
+	if err != nil {
+		return nil, publicError(r.log, err)
+	}
+
+	return r.reload(ctx, id)
+}
+
+type updateTaskArgs struct {
+	ID    gql.ID
+	Input updateTaskInput
+}
+
+func (r *Resolver) UpdateTask(ctx context.Context, args updateTaskArgs) (*taskResolver, error) {
+	l := loadersFrom(ctx)
+
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := args.Input.toPatch()
+	if err != nil {
+		return nil, err
+	}
+	if patch.Empty() && args.Input.Status == nil {
+		return nil, publicError(r.log, models.ErrNothingToApply)
+	}
+
+	if !patch.Empty() {
+		if _, err = r.tasks.UpdateTask(ctx, id, l.userID, patch); err != nil {
+			return nil, publicError(r.log, err)
+		}
+	}
+	if args.Input.Status != nil {
+		if err = r.tasks.ChangeTaskStatus(ctx, id, l.userID, *args.Input.Status); err != nil {
+			return nil, publicError(r.log, err)
+		}
+	}
+
+	return r.reload(ctx, id)
+}
+
+func (r *Resolver) DeleteTask(ctx context.Context, args struct{ ID gql.ID }) (bool, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err = r.tasks.DeleteTask(ctx, id, loadersFrom(ctx).userID); err != nil {
+		return false, publicError(r.log, err)
+	}
+	return true, nil
+}
+
+type assignTaskArgs struct {
+	ID    gql.ID
+	Email *string
+}
+
+func (r *Resolver) AssignTask(ctx context.Context, args assignTaskArgs) (*taskResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	email := ""
+	if args.Email != nil {
+		email = *args.Email
+	}
+	if err = r.tasks.AssignTask(ctx, id, loadersFrom(ctx).userID, email); err != nil {
+		return nil, publicError(r.log, err)
+	}
+
+	return r.reload(ctx, id)
+}
+
+func (r *Resolver) SetTimezone(ctx context.Context, args struct{ Timezone string }) (*userResolver, error) {
+	l := loadersFrom(ctx)
+
+	if err := r.auth.SetTimezone(ctx, l.userID, args.Timezone); err != nil {
+		return nil, publicError(r.log, err)
+	}
+	return r.user(ctx, l.userID)
+}
+
+func (r *Resolver) CreateAppPassword(ctx context.Context, args struct{ Name string }) (*newAppPasswordResolver, error) {
+	name := strings.TrimSpace(args.Name)
+	if name == "" || len(name) > 100 {
+		return nil, Error{Message: "name must be 1 to 100 characters", Code: "BAD_REQUEST"}
+	}
+
+	p, password, err := r.auth.CreateAppPassword(ctx, loadersFrom(ctx).userID, name)
+	if err != nil {
+		return nil, publicError(r.log, err)
+	}
+	return &newAppPasswordResolver{p: p, password: password}, nil
+}
+
+func (r *Resolver) DeleteAppPassword(ctx context.Context, args struct{ ID gql.ID }) (bool, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if err = r.auth.DeleteAppPassword(ctx, loadersFrom(ctx).userID, id); err != nil {
+		return false, publicError(r.log, err)
+	}
+	return true, nil
+}
+
+// reload returns the task as stored after a mutation
+func (r *Resolver) reload(ctx context.Context, id int64) (*taskResolver, error) {
+	l := loadersFrom(ctx)
+
+	task, err := r.tasks.TasksByID(ctx, id, l.userID)
+	if err != nil {
+		return nil, publicError(r.log, err)
+	}
+
+	l.prime(task)
+	return &taskResolver{task: task, r: r}, nil
+}
+
+func (r *Resolver) user(ctx context.Context, id int64) (*userResolver, error) {
+	u, ok, err := loadersFrom(ctx).users.Load(ctx, id)
+	if err != nil {
+		return nil, publicError(r.log, err)
+	}
+	if !ok {
+		return nil, publicError(r.log, models.ErrUserNotFound)
+	}
+	return &userResolver{user: u}, nil
+}
+
+func parseID(id gql.ID) (int64, error) {
+	n, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil || n <= 0 {
+		return 0, Error{Message: fmt.Sprintf("invalid id %q", string(id)), Code: "BAD_REQUEST"}
+	}
+	return n, nil
+}
+
+func optionalID(id *gql.ID) (*int64, error) {
+	if id == nil {
+		return nil, nil
+	}
+	n, err := parseID(*id)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(b), "offset:"))
+	if err != nil || offset < 0 || !strings.HasPrefix(string(b), "offset:") {
+		return 0, errInvalidCursor
+	}
+	return offset, nil
+}
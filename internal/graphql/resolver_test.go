@@ -0,0 +1,75 @@
+package graphql
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"io"
+	"log/slog"
+	"math"
+	"testing"
+)
+
+// service is embedded under another name, the Tasks field would clash with the Tasks method
+type service = Tasks
+
+// tasks returns the same list to every user
+type tasks struct {
+	service
+	list []models.Task
+}
+
+func (t tasks) Tasks(ctx context.Context, userID int64, filter models.TaskFilter) ([]models.Task, error) {
+	return t.list, nil
+}
+
+func TestTasksPage(t *testing.T) {
+	r := &Resolver{
+		tasks: tasks{list: []models.Task{{ID: 1}, {ID: 2}, {ID: 3}}},
+		log:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	ctx := context.WithValue(context.Background(), loadersKey{}, (&Handler{}).newLoaders(1))
+
+	tests := []struct {
+		name    string
+		first   int32
+		after   int
+		wantIDs []int64
+		hasNext bool
+	}{
+		{name: "first page", first: 2, after: -1, wantIDs: []int64{1, 2}, hasNext: true},
+		{name: "last page", first: 2, after: 2, wantIDs: []int64{3}},
+		{name: "past the end", first: 2, after: 5},
+		{name: "huge offset", first: 2, after: math.MaxInt},
+		{name: "huge offset and page", first: math.MaxInt32, after: math.MaxInt - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tasksArgs{First: tt.first}
+			if tt.after >= 0 {
+				cursor := encodeCursor(tt.after)
+				args.After = &cursor
+			}
+
+			res, err := r.Tasks(ctx, args)
+			if err != nil {
+				t.Fatalf("Tasks() error = %v", err)
+			}
+
+			var ids []int64
+			for _, n := range res.nodes {
+				ids = append(ids, n.task.ID)
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("ids = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+			if res.hasNext != tt.hasNext || res.total != 3 {
+				t.Errorf("hasNext = %v, total = %d, want %v, 3", res.hasNext, res.total, tt.hasNext)
+			}
+		})
+	}
+}
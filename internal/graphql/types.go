@@ -0,0 +1,412 @@
+package graphql
+
+import (
+	"TaskList/internal/models"
+	"context"
+	gql "github.com/graph-gophers/graphql-go"
+	"strconv"
+	"strings"
+)
+
+type userResolver struct {
+	user models.User
+}
+
+func (u *userResolver) ID() gql.ID {
+	return formatID(u.user.ID)
+}
+
+func (u *userResolver) Email() string {
+	return u.user.Email
+}
+
+type projectResolver struct {
+	project models.Project
+}
+
+func (p *projectResolver) ID() gql.ID {
+	return formatID(p.project.ID)
+}
+
+func (p *projectResolver) Name() string {
+	return p.project.Name
+}
+
+func (p *projectResolver) WorkspaceID() *gql.ID {
+	if p.project.WorkspaceID == nil {
+		return nil
+	}
+	id := formatID(*p.project.WorkspaceID)
+	return &id
+}
+
+func (p *projectResolver) Created() gql.Time {
+	return gql.Time{Time: p.project.CreatedAt}
+}
+
+func (p *projectResolver) Updated() gql.Time {
+	return gql.Time{Time: p.project.UpdatedAt}
+}
+
+type taskResolver struct {
+	task models.Task
+	r    *Resolver
+}
+
+func (t *taskResolver) ID() gql.ID {
+	return formatID(t.task.ID)
+}
+
+func (t *taskResolver) Title() string {
+	return t.task.Title
+}
+
+func (t *taskResolver) Description() string {
+	return t.task.Description
+}
+
+func (t *taskResolver) Status() string {
+	return string(t.task.Status)
+}
+
+func (t *taskResolver) StatusCategory() string {
+	return strings.ToUpper(string(t.task.StatusCategory))
+}
+
+func (t *taskResolver) Priority() string {
+	if t.task.Priority == models.PriorityNone {
+		return "NONE"
+	}
+	return strings.ToUpper(string(t.task.Priority))
+}
+
+func (t *taskResolver) Tags() []string {
+	if t.task.Tags == nil {
+		return []string{}
+	}
+	return t.task.Tags
+}
+
+func (t *taskResolver) Recurrence() string {
+	return t.task.Recurrence
+}
+
+func (t *taskResolver) Due() *gql.Time {
+	if t.task.DueAt == nil {
+		return nil
+	}
+	return &gql.Time{Time: *t.task.DueAt}
+}
+
+func (t *taskResolver) Created() gql.Time {
+	return gql.Time{Time: t.task.CreatedAt}
+}
+
+func (t *taskResolver) Updated() gql.Time {
+	return gql.Time{Time: t.task.UpdatedAt}
+}
+
+func (t *taskResolver) CustomFields() []*fieldValueResolver {
+	res := make([]*fieldValueResolver, len(t.task.CustomFields))
+	for i, v := range t.task.CustomFields {
+		res[i] = &fieldValueResolver{value: v}
+	}
+	return res
+}
+
+func (t *taskResolver) Project(ctx context.Context) (*projectResolver, error) {
+	if t.task.ProjectID == nil {
+		return nil, nil
+	}
+
+	p, ok, err := loadersFrom(ctx).projects.Load(ctx, *t.task.ProjectID)
+	if err != nil {
+		return nil, publicError(t.r.log, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return &projectResolver{project: p}, nil
+}
+
+func (t *taskResolver) Owner(ctx context.Context) (*userResolver, error) {
+	return t.r.user(ctx, t.task.UserID)
+}
+
+func (t *taskResolver) CreatedBy(ctx context.Context) (*userResolver, error) {
+	return t.r.user(ctx, t.task.CreatedBy)
+}
+
+func (t *taskResolver) Assignee(ctx context.Context) (*userResolver, error) {
+	if t.task.AssigneeID == nil {
+		return nil, nil
+	}
+	return t.r.user(ctx, *t.task.AssigneeID)
+}
+
+func (t *taskResolver) Comments(ctx context.Context) ([]*commentResolver, error) {
+	l := loadersFrom(ctx)
+
+	comments, _, err := l.comments.Load(ctx, t.task.ID)
+	if err != nil {
+		return nil, publicError(t.r.log, err)
+	}
+
+	res := make([]*commentResolver, len(comments))
+	for i, c := range comments {
+		l.users.Prime(c.UserID)
+		res[i] = &commentResolver{comment: c, r: t.r}
+	}
+	return res, nil
+}
+
+type fieldValueResolver struct {
+	value models.FieldValue
+}
+
+func (f *fieldValueResolver) Name() string {
+	return f.value.Name
+}
+
+func (f *fieldValueResolver) Value() string {
+	return f.value.Value
+}
+
+type commentResolver struct {
+	comment models.Comment
+	r       *Resolver
+}
+
+func (c *commentResolver) ID() gql.ID {
+	return formatID(c.comment.ID)
+}
+
+func (c *commentResolver) Body() string {
+	return c.comment.Body
+}
+
+func (c *commentResolver) Author(ctx context.Context) (*userResolver, error) {
+	return c.r.user(ctx, c.comment.UserID)
+}
+
+func (c *commentResolver) Created() gql.Time {
+	return gql.Time{Time: c.comment.CreatedAt}
+}
+
+type taskConnectionResolver struct {
+	nodes     []*taskResolver
+	total     int
+	hasNext   bool
+	endCursor *string
+}
+
+func (c *taskConnectionResolver) Nodes() []*taskResolver {
+	if c.nodes == nil {
+		return []*taskResolver{}
+	}
+	return c.nodes
+}
+
+func (c *taskConnectionResolver) TotalCount() int32 {
+	return int32(c.total)
+}
+
+func (c *taskConnectionResolver) PageInfo() *pageInfoResolver {
+	return &pageInfoResolver{endCursor: c.endCursor, hasNext: c.hasNext}
+}
+
+type pageInfoResolver struct {
+	endCursor *string
+	hasNext   bool
+}
+
+func (p *pageInfoResolver) EndCursor() *string {
+	return p.endCursor
+}
+
+func (p *pageInfoResolver) HasNextPage() bool {
+	return p.hasNext
+}
+
+type newAppPasswordResolver struct {
+	p        models.AppPassword
+	password string
+}
+
+func (a *newAppPasswordResolver) ID() gql.ID {
+	return formatID(a.p.ID)
+}
+
+func (a *newAppPasswordResolver) Name() string {
+	return a.p.Name
+}
+
+func (a *newAppPasswordResolver) Password() string {
+	return a.password
+}
+
+func (a *newAppPasswordResolver) Created() gql.Time {
+	return gql.Time{Time: a.p.CreatedAt}
+}
+
+type taskFilterInput struct {
+	Scope       *string
+	WorkspaceID *gql.ID
+	ProjectID   *gql.ID
+	Statuses    *[]string
+	Categories  *[]string
+	Tags        *[]string
+	Text        *string
+	Due         *string
+	DueDays     *int32
+	DueFrom     *gql.Time
+	DueTo       *gql.Time
+	Sort        *string
+	Desc        *bool
+}
+
+func (f taskFilterInput) toModel() (models.TaskFilter, error) {
+	var (
+		res models.TaskFilter
+		err error
+	)
+
+	if res.WorkspaceID, err = optionalID(f.WorkspaceID); err != nil {
+		return models.TaskFilter{}, err
+	}
+	if res.ProjectID, err = optionalID(f.ProjectID); err != nil {
+		return models.TaskFilter{}, err
+	}
+	if f.Scope != nil && *f.Scope != "OWNED" {
+		res.Scope = models.TaskScope(strings.ToLower(*f.Scope))
+	}
+	if f.Statuses != nil {
+		for _, s := range *f.Statuses {
+			res.Statuses = append(res.Statuses, models.Status(s))
+		}
+	}
+	if f.Categories != nil {
+		for _, c := range *f.Categories {
+			res.Categories = append(res.Categories, models.StatusCategory(strings.ToLower(c)))
+		}
+	}
+	if f.Tags != nil {
+		res.Tags = *f.Tags
+	}
+	if f.Text != nil {
+		res.Text = *f.Text
+	}
+	if f.Due != nil && *f.Due != "ANY" {
+		res.Due = models.DueWindow(strings.ToLower(*f.Due))
+	}
+	if f.DueDays != nil {
+		res.DueDays = int(*f.DueDays)
+	}
+	if f.DueFrom != nil {
+		res.DueFrom = &f.DueFrom.Time
+	}
+	if f.DueTo != nil {
+		res.DueTo = &f.DueTo.Time
+	}
+	if res.Due == models.DueAny && (res.DueFrom != nil || res.DueTo != nil) {
+		res.Due = models.DueRange
+	}
+	if f.Sort != nil {
+		res.Sort = *f.Sort
+	}
+	if f.Desc != nil {
+		res.Desc = *f.Desc
+	}
+
+	return res, nil
+}
+
+type createTaskInput struct {
+	ProjectID   *gql.ID
+	Title       string
+	Description *string
+	Status      *string
+	Priority    *string
+	Tags        *[]string
+	Recurrence  *string
+	Due         *gql.Time
+}
+
+func (in createTaskInput) toModel() (models.Task, error) {
+	projectID, err := optionalID(in.ProjectID)
+	if err != nil {
+		return models.Task{}, err
+	}
+	if strings.TrimSpace(in.Title) == "" {
+		return models.Task{}, Error{Message: "title is required", Code: "BAD_REQUEST"}
+	}
+
+	task := models.Task{ProjectID: projectID, Title: in.Title}
+	if in.Description != nil {
+		task.Description = *in.Description
+	}
+	if in.Status != nil {
+		task.Status = models.Status(*in.Status)
+	}
+	if in.Priority != nil {
+		task.Priority = priorityFromEnum(*in.Priority)
+	}
+	if in.Tags != nil {
+		task.Tags = *in.Tags
+	}
+	if in.Recurrence != nil {
+		task.Recurrence = *in.Recurrence
+	}
+	if in.Due != nil {
+		due := in.Due.Time.UTC()
+		task.DueAt = &due
+	}
+
+	return task, nil
+}
+
+type updateTaskInput struct {
+	Title       *string
+	Description *string
+	Status      *string
+	Priority    *string
+	Tags        *[]string
+	Recurrence  *string
+	Due         *gql.Time
+	ClearDue    *bool
+}
+
+func (in updateTaskInput) toPatch() (models.TaskPatch, error) {
+	if in.Title != nil && strings.TrimSpace(*in.Title) == "" {
+		return models.TaskPatch{}, Error{Message: "title must not be empty", Code: "BAD_REQUEST"}
+	}
+
+	p := models.TaskPatch{
+		Title:       in.Title,
+		Description: in.Description,
+		Tags:        in.Tags,
+		Recurrence:  in.Recurrence,
+		ClearDue:    in.ClearDue != nil && *in.ClearDue,
+	}
+	if in.Priority != nil {
+		priority := priorityFromEnum(*in.Priority)
+		p.Priority = &priority
+	}
+	if in.Due != nil {
+		due := in.Due.Time.UTC()
+		p.DueAt = &due
+	}
+
+	return p, nil
+}
+
+func priorityFromEnum(p string) models.Priority {
+	if p == "NONE" {
+		return models.PriorityNone
+	}
+	return models.Priority(strings.ToLower(p))
+}
+
+func formatID(id int64) gql.ID {
+	return gql.ID(strconv.FormatInt(id, 10))
+}
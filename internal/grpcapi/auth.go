@@ -0,0 +1,146 @@
+package grpcapi
+
+import (
+	tasklistv1 "TaskList/api/tasklist/v1"
+	"TaskList/internal/lib/jwt"
+	"TaskList/internal/middlewares"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+const bearerPrefix = "Bearer "
+
+// publicMethods are called without JWT
+var publicMethods = map[string]struct{}{
+	tasklistv1.AuthService_Register_FullMethodName: {},
+	tasklistv1.AuthService_Login_FullMethodName:    {},
+}
+
+// authInterceptor puts JWT claims into the context under the same key as AuthJWT middleware
+type authInterceptor struct {
+	secret []byte
+	users  Auth
+	log    *slog.Logger
+}
+
+func (a authInterceptor) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if _, ok := publicMethods[info.FullMethod]; ok {
+		return handler(ctx, req)
+	}
+
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a authInterceptor) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+func (a authInterceptor) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get("authorization")
+	if len(values) == 0 || !strings.HasPrefix(values[0], bearerPrefix) {
+		return nil, status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+
+	claims, err := jwt.ValidateToken(strings.TrimPrefix(values[0], bearerPrefix), a.secret)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if exp, err := claims.GetExpirationTime(); err != nil || exp == nil || !exp.After(time.Now()) {
+		return nil, status.Error(codes.Unauthenticated, "token is expired")
+	}
+	// the token outlives disabling of the user, so the user is checked on every call
+	if err = a.users.CheckActive(ctx, claims.UID); err != nil {
+		if errors.Is(err, models.ErrUserDisabled) || errors.Is(err, models.ErrUserNotFound) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, statusError(a.log, "grpcapi.authenticate", err)
+	}
+
+	return context.WithValue(ctx, middlewares.KeyClaims, claims), nil
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func userIDFromContext(ctx context.Context) int64 {
+	return ctx.Value(middlewares.KeyClaims).(*jwt.CustomClaims).UID
+}
+
+type authServer struct {
+	tasklistv1.UnimplementedAuthServiceServer
+	auth Auth
+	log  *slog.Logger
+}
+
+func (s *authServer) Register(ctx context.Context, req *tasklistv1.RegisterRequest) (*tasklistv1.RegisterResponse, error) {
+	const op = "grpcapi.Register"
+
+	if err := validateCredentials(req.GetEmail(), req.GetPassword()); err != nil {
+		return nil, err
+	}
+
+	id, err := s.auth.Registration(ctx, req.GetEmail(), req.GetPassword())
+	if err != nil {
+		if errors.Is(err, models.ErrUserAlreadyExists) {
+			return nil, status.Error(codes.AlreadyExists, "user already exists")
+		}
+		return nil, statusError(s.log, op, err)
+	}
+
+	return &tasklistv1.RegisterResponse{Id: id}, nil
+}
+
+func (s *authServer) Login(ctx context.Context, req *tasklistv1.LoginRequest) (*tasklistv1.LoginResponse, error) {
+	const op = "grpcapi.Login"
+
+	if err := validateCredentials(req.GetEmail(), req.GetPassword()); err != nil {
+		return nil, err
+	}
+
+	token, err := s.auth.Login(ctx, req.GetEmail(), []byte(req.GetPassword()))
+	if err != nil {
+		s.log.Warn("failed login", slog.String("op", op), slog.String("err", err.Error()))
+		if errors.Is(err, models.ErrUserDisabled) {
+			return nil, status.Error(codes.PermissionDenied, "user is disabled")
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid email or password")
+	}
+
+	return &tasklistv1.LoginResponse{Token: token}, nil
+}
+
+// validateCredentials applies the rules of the REST AuthRequest
+func validateCredentials(email string, password string) error {
+	if _, err := mail.ParseAddress(email); err != nil {
+		return status.Error(codes.InvalidArgument, "invalid email")
+	}
+	if len(password) < 8 {
+		return status.Error(codes.InvalidArgument, "password must be at least 8 characters")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package grpcapi
+
+import (
+	tasklistv1 "TaskList/api/tasklist/v1"
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestRecoverUnary(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	info := &grpc.UnaryServerInfo{FullMethod: tasklistv1.TaskService_ListTasks_FullMethodName}
+
+	_, err := recoverUnary(log)(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		panic("slice bounds out of range")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("error = %v, want Internal", err)
+	}
+}
+
+func TestRecoverStream(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	info := &grpc.StreamServerInfo{FullMethod: tasklistv1.TaskService_WatchTasks_FullMethodName}
+
+	err := recoverStream(log)(nil, nil, info, func(srv any, ss grpc.ServerStream) error {
+		panic("nil map")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("error = %v, want Internal", err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package grpcapi serves tasks over gRPC for internal services, handlers call the same services as the REST controller
+package grpcapi
+
+import (
+	tasklistv1 "TaskList/api/tasklist/v1"
+	"TaskList/internal/config"
+	"TaskList/internal/lib/events"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"log/slog"
+)
+
+type Tasks interface {
+	CreateTask(ctx context.Context, task models.Task) (int64, error)
+	Tasks(ctx context.Context, userID int64, filter models.TaskFilter) ([]models.Task, error)
+	TasksByID(ctx context.Context, taskID int64, userID int64) (models.Task, error)
+	UpdateTask(ctx context.Context, taskID int64, userID int64, patch models.TaskPatch) (models.Task, error)
+	ChangeTaskStatus(ctx context.Context, taskID int64, userID int64, newStatus string) error
+	DeleteTask(ctx context.Context, taskID int64, userID int64) error
+}
+
+type Auth interface {
+	Registration(ctx context.Context, email string, password string) (int64, error)
+	Login(ctx context.Context, email string, password []byte) (string, error)
+	CheckActive(ctx context.Context, userID int64) error
+}
+
+// Authz resolves the scope new tasks are stored in, implemented by authz service
+type Authz interface {
+	ProjectScope(ctx context.Context, userID int64, projectID *int64, action models.Action) (int64, error)
+}
+
+// EventStream is the in-process hub of task events
+type EventStream interface {
+	Subscribe(userID int64, lastID string) (*events.Subscription, []events.Message, bool)
+	Unsubscribe(sub *events.Subscription)
+}
+
+// New returns gRPC server with AuthService and TaskService,
+// every TaskService call must carry "authorization: Bearer <jwt>" metadata
+func New(a Auth, t Tasks, authz Authz, stream EventStream, cfg *config.Config, log *slog.Logger) *grpc.Server {
+	interceptor := authInterceptor{secret: []byte(cfg.JWT.Secret), users: a, log: log}
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(recoverUnary(log), interceptor.unary),
+		grpc.ChainStreamInterceptor(recoverStream(log), interceptor.stream),
+	)
+
+	tasklistv1.RegisterAuthServiceServer(srv, &authServer{auth: a, log: log})
+	tasklistv1.RegisterTaskServiceServer(srv, &taskServer{tasks: t, authz: authz, stream: stream, log: log})
+
+	return srv
+}
+
+// statusError maps domain errors to gRPC codes and hides unexpected ones
+func statusError(log *slog.Logger, op string, err error) error {
+	switch {
+	case errors.Is(err, models.ErrTaskNotFound):
+		return status.Error(codes.NotFound, "task not found")
+	case errors.Is(err, models.ErrProjectNotFound):
+		return status.Error(codes.NotFound, "project not found")
+	case errors.Is(err, models.ErrWorkspaceNotFound):
+		return status.Error(codes.NotFound, "workspace not found")
+	case errors.Is(err, models.ErrForbidden):
+		return status.Error(codes.PermissionDenied, "access denied")
+	case errors.Is(err, models.ErrUnknownStatus),
+		errors.Is(err, models.ErrInvalidTask),
+		errors.Is(err, models.ErrNothingToApply),
+		errors.Is(err, models.ErrFieldNotFound),
+		errors.Is(err, models.ErrInvalidFieldValue):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, models.ErrWIPLimitExceeded):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		log.Error("failed grpc call", slog.String("op", op), slog.String("err", err.Error()))
+		return status.Error(codes.Internal, "internal error")
+	}
+}
@@ -0,0 +1,340 @@
+package grpcapi
+
+import (
+	tasklistv1 "TaskList/api/tasklist/v1"
+	"TaskList/internal/lib/events"
+	"TaskList/internal/models"
+	"context"
+	"encoding/base64"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+
+	// eventReset is sent when events after last_event_id are lost
+	eventReset = "reset"
+)
+
+type taskServer struct {
+	tasklistv1.UnimplementedTaskServiceServer
+	tasks  Tasks
+	authz  Authz
+	stream EventStream
+	log    *slog.Logger
+}
+
+func (s *taskServer) CreateTask(ctx context.Context, req *tasklistv1.CreateTaskRequest) (*tasklistv1.Task, error) {
+	const op = "grpcapi.CreateTask"
+	uid := userIDFromContext(ctx)
+
+	if strings.TrimSpace(req.GetTitle()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+
+	task := models.Task{
+		ProjectID:   req.ProjectId,
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		Status:      models.Status(req.GetStatus()),
+		Priority:    priorityFromProto(req.GetPriority()),
+		Tags:        req.GetTags(),
+		Recurrence:  req.GetRecurrence(),
+	}
+	if req.Due != nil {
+		due := req.Due.AsTime()
+		task.DueAt = &due
+	}
+
+	// tasks of workspace projects are stored in the workspace owner's scope
+	scope, err := s.authz.ProjectScope(ctx, uid, task.ProjectID, models.ActionEdit)
+	if err != nil {
+		return nil, statusError(s.log, op, err)
+	}
+	task.UserID = scope
+	task.CreatedBy = uid
+
+	id, err := s.tasks.CreateTask(ctx, task)
+	if err != nil {
+		return nil, statusError(s.log, op, err)
+	}
+
+	return s.task(ctx, op, id, uid)
+}
+
+func (s *taskServer) GetTask(ctx context.Context, req *tasklistv1.GetTaskRequest) (*tasklistv1.Task, error) {
+	return s.task(ctx, "grpcapi.GetTask", req.GetId(), userIDFromContext(ctx))
+}
+
+func (s *taskServer) ListTasks(ctx context.Context, req *tasklistv1.ListTasksRequest) (*tasklistv1.ListTasksResponse, error) {
+	const op = "grpcapi.ListTasks"
+	uid := userIDFromContext(ctx)
+
+	filter := models.TaskFilter{
+		Scope:       scopeFromProto(req.GetScope()),
+		WorkspaceID: req.WorkspaceId,
+		ProjectID:   req.ProjectId,
+		Tags:        req.GetTags(),
+		Text:        req.GetText(),
+		Sort:        req.GetSort(),
+		Desc:        req.GetDesc(),
+	}
+	for _, st := range req.GetStatuses() {
+		filter.Statuses = append(filter.Statuses, models.Status(st))
+	}
+
+	size := int(req.GetPageSize())
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	size = min(size, maxPageSize)
+
+	offset, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.tasks.Tasks(ctx, uid, filter)
+	if err != nil {
+		return nil, statusError(s.log, op, err)
+	}
+
+	// offset comes from the client and may be near max int, it is clamped before size is added
+	offset = min(offset, len(tasks))
+	page := tasks[offset : offset+min(size, len(tasks)-offset)]
+
+	res := &tasklistv1.ListTasksResponse{
+		Tasks:     make([]*tasklistv1.Task, len(page)),
+		TotalSize: int32(len(tasks)),
+	}
+	for i, t := range page {
+		res.Tasks[i] = taskToProto(t)
+	}
+	if next := offset + len(page); next < len(tasks) {
+		res.NextPageToken = encodePageToken(next)
+	}
+
+	return res, nil
+}
+
+func (s *taskServer) UpdateTask(ctx context.Context, req *tasklistv1.UpdateTaskRequest) (*tasklistv1.Task, error) {
+	const op = "grpcapi.UpdateTask"
+	uid := userIDFromContext(ctx)
+
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		return nil, status.Error(codes.InvalidArgument, "title must not be empty")
+	}
+
+	patch := models.TaskPatch{
+		Title:       req.Title,
+		Description: req.Description,
+		Recurrence:  req.Recurrence,
+		ClearDue:    req.GetClearDue(),
+	}
+	if req.Priority != nil {
+		priority := priorityFromProto(*req.Priority)
+		patch.Priority = &priority
+	}
+	if req.GetUpdateTags() {
+		tags := req.GetTags()
+		patch.Tags = &tags
+	}
+	if req.Due != nil {
+		due := req.Due.AsTime()
+		patch.DueAt = &due
+	}
+
+	task, err := s.tasks.UpdateTask(ctx, req.GetId(), uid, patch)
+	if err != nil {
+		return nil, statusError(s.log, op, err)
+	}
+
+	return taskToProto(task), nil
+}
+
+func (s *taskServer) ChangeTaskStatus(ctx context.Context, req *tasklistv1.ChangeTaskStatusRequest) (*tasklistv1.Task, error) {
+	const op = "grpcapi.ChangeTaskStatus"
+	uid := userIDFromContext(ctx)
+
+	if err := s.tasks.ChangeTaskStatus(ctx, req.GetId(), uid, req.GetStatus()); err != nil {
+		return nil, statusError(s.log, op, err)
+	}
+
+	return s.task(ctx, op, req.GetId(), uid)
+}
+
+func (s *taskServer) DeleteTask(ctx context.Context, req *tasklistv1.DeleteTaskRequest) (*emptypb.Empty, error) {
+	const op = "grpcapi.DeleteTask"
+
+	if err := s.tasks.DeleteTask(ctx, req.GetId(), userIDFromContext(ctx)); err != nil {
+		return nil, statusError(s.log, op, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// WatchTasks sends events of the hub visible to the user, a slow client is disconnected
+// with Unavailable and resumes with last_event_id like the SSE stream
+func (s *taskServer) WatchTasks(req *tasklistv1.WatchTasksRequest, stream grpc.ServerStreamingServer[tasklistv1.TaskEvent]) error {
+	const op = "grpcapi.WatchTasks"
+	ctx := stream.Context()
+	uid := userIDFromContext(ctx)
+	log := s.log.With(slog.String("op", op), slog.Int64("user_id", uid))
+
+	sub, replay, ok := s.stream.Subscribe(uid, req.GetLastEventId())
+	defer s.stream.Unsubscribe(sub)
+
+	if !ok {
+		if err := stream.Send(&tasklistv1.TaskEvent{Type: eventReset}); err != nil {
+			return err
+		}
+	}
+	for _, msg := range replay {
+		if err := s.sendEvent(stream, msg); err != nil {
+			return err
+		}
+	}
+
+	log.Info("task watch opened", slog.String("last_event_id", req.GetLastEventId()))
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("task watch closed")
+			return nil
+		case msg, open := <-sub.C:
+			if !open {
+				log.Warn("task watch dropped")
+				return status.Error(codes.Unavailable, "too slow to keep up, resume with last_event_id")
+			}
+			if err := s.sendEvent(stream, msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendEvent sends the message, the hub sends only messages the user can see
+func (s *taskServer) sendEvent(stream grpc.ServerStreamingServer[tasklistv1.TaskEvent], msg events.Message) error {
+	e := msg.Event
+
+	return stream.Send(&tasklistv1.TaskEvent{
+		Id:             msg.ID,
+		Type:           string(e.Type),
+		ActorId:        e.ActorID,
+		Task:           taskToProto(e.Task),
+		PreviousStatus: string(e.PreviousStatus),
+		OccurredAt:     timestamppb.New(e.OccurredAt),
+	})
+}
+
+func (s *taskServer) task(ctx context.Context, op string, taskID int64, userID int64) (*tasklistv1.Task, error) {
+	task, err := s.tasks.TasksByID(ctx, taskID, userID)
+	if err != nil {
+		return nil, statusError(s.log, op, err)
+	}
+	return taskToProto(task), nil
+}
+
+func taskToProto(t models.Task) *tasklistv1.Task {
+	res := &tasklistv1.Task{
+		Id:             t.ID,
+		UserId:         t.UserID,
+		CreatedBy:      t.CreatedBy,
+		AssigneeId:     t.AssigneeID,
+		ProjectId:      t.ProjectID,
+		WorkspaceId:    t.WorkspaceID,
+		Title:          t.Title,
+		Description:    t.Description,
+		Status:         string(t.Status),
+		StatusCategory: categoryToProto(t.StatusCategory),
+		Priority:       priorityToProto(t.Priority),
+		Tags:           t.Tags,
+		Recurrence:     t.Recurrence,
+		Created:        timestamppb.New(t.CreatedAt),
+		Updated:        timestamppb.New(t.UpdatedAt),
+	}
+	if t.DueAt != nil {
+		res.Due = timestamppb.New(*t.DueAt)
+	}
+	return res
+}
+
+func priorityToProto(p models.Priority) tasklistv1.Priority {
+	switch p {
+	case models.PriorityLow:
+		return tasklistv1.Priority_PRIORITY_LOW
+	case models.PriorityMedium:
+		return tasklistv1.Priority_PRIORITY_MEDIUM
+	case models.PriorityHigh:
+		return tasklistv1.Priority_PRIORITY_HIGH
+	default:
+		return tasklistv1.Priority_PRIORITY_UNSPECIFIED
+	}
+}
+
+func priorityFromProto(p tasklistv1.Priority) models.Priority {
+	switch p {
+	case tasklistv1.Priority_PRIORITY_LOW:
+		return models.PriorityLow
+	case tasklistv1.Priority_PRIORITY_MEDIUM:
+		return models.PriorityMedium
+	case tasklistv1.Priority_PRIORITY_HIGH:
+		return models.PriorityHigh
+	default:
+		return models.PriorityNone
+	}
+}
+
+func categoryToProto(c models.StatusCategory) tasklistv1.StatusCategory {
+	switch c {
+	case models.CategoryTodo:
+		return tasklistv1.StatusCategory_STATUS_CATEGORY_TODO
+	case models.CategoryInProgress:
+		return tasklistv1.StatusCategory_STATUS_CATEGORY_IN_PROGRESS
+	case models.CategoryDone:
+		return tasklistv1.StatusCategory_STATUS_CATEGORY_DONE
+	default:
+		return tasklistv1.StatusCategory_STATUS_CATEGORY_UNSPECIFIED
+	}
+}
+
+func scopeFromProto(s tasklistv1.TaskScope) models.TaskScope {
+	switch s {
+	case tasklistv1.TaskScope_TASK_SCOPE_ASSIGNED:
+		return models.ScopeAssigned
+	case tasklistv1.TaskScope_TASK_SCOPE_SHARED:
+		return models.ScopeShared
+	case tasklistv1.TaskScope_TASK_SCOPE_ALL:
+		return models.ScopeAll
+	default:
+		return models.ScopeOwned
+	}
+}
+
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil && strings.HasPrefix(string(b), "offset:") {
+		if offset, err := strconv.Atoi(strings.TrimPrefix(string(b), "offset:")); err == nil && offset >= 0 {
+			return offset, nil
+		}
+	}
+
+	return 0, status.Error(codes.InvalidArgument, "invalid page token")
+}
@@ -0,0 +1,72 @@
+package grpcapi
+
+import (
+	tasklistv1 "TaskList/api/tasklist/v1"
+	"TaskList/internal/lib/jwt"
+	"TaskList/internal/middlewares"
+	"TaskList/internal/models"
+	"context"
+	"io"
+	"log/slog"
+	"math"
+	"testing"
+)
+
+// service is embedded under another name, the Tasks field would clash with the Tasks method
+type service = Tasks
+
+// tasks returns the same list to every user
+type tasks struct {
+	service
+	list []models.Task
+}
+
+func (t tasks) Tasks(ctx context.Context, userID int64, filter models.TaskFilter) ([]models.Task, error) {
+	return t.list, nil
+}
+
+func TestListTasksPage(t *testing.T) {
+	s := &taskServer{
+		tasks: tasks{list: []models.Task{{ID: 1}, {ID: 2}, {ID: 3}}},
+		log:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	ctx := context.WithValue(context.Background(), middlewares.KeyClaims, &jwt.CustomClaims{UID: 1})
+
+	tests := []struct {
+		name     string
+		size     int32
+		token    string
+		wantIDs  []int64
+		wantNext string
+	}{
+		{name: "first page", size: 2, wantIDs: []int64{1, 2}, wantNext: encodePageToken(2)},
+		{name: "last page", size: 2, token: encodePageToken(2), wantIDs: []int64{3}},
+		{name: "past the end", size: 2, token: encodePageToken(5)},
+		{name: "huge offset", size: 2, token: encodePageToken(math.MaxInt)},
+		{name: "huge offset and max page", size: maxPageSize, token: encodePageToken(math.MaxInt - 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.ListTasks(ctx, &tasklistv1.ListTasksRequest{PageSize: tt.size, PageToken: tt.token})
+			if err != nil {
+				t.Fatalf("ListTasks() error = %v", err)
+			}
+
+			var ids []int64
+			for _, task := range res.GetTasks() {
+				ids = append(ids, task.GetId())
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("ids = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+			if res.GetNextPageToken() != tt.wantNext || res.GetTotalSize() != 3 {
+				t.Errorf("next = %q, total = %d, want %q, 3", res.GetNextPageToken(), res.GetTotalSize(), tt.wantNext)
+			}
+		})
+	}
+}
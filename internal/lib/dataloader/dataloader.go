@@ -0,0 +1,84 @@
+// Package dataloader batches loads of many keys into one fetch within a request
+package dataloader
+
+import (
+	"context"
+	"sync"
+)
+
+// FetchFunc loads values of the keys, keys missing in the result are not found.
+// It may return values of other keys too, they are cached for later loads
+type FetchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects keys with Prime and fetches all pending keys at the first Load,
+// so resolving a list of N items costs one fetch instead of N. It is not shared between requests
+type Loader[K comparable, V any] struct {
+	mu      sync.Mutex
+	fetch   FetchFunc[K, V]
+	pending map[K]struct{}
+	values  map[K]V
+	missing map[K]struct{}
+}
+
+func New[K comparable, V any](fetch FetchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:   fetch,
+		pending: make(map[K]struct{}),
+		values:  make(map[K]V),
+		missing: make(map[K]struct{}),
+	}
+}
+
+// Prime registers keys which will be fetched together with the next load
+func (l *Loader[K, V]) Prime(keys ...K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, k := range keys {
+		if !l.known(k) {
+			l.pending[k] = struct{}{}
+		}
+	}
+}
+
+// Load returns the value of the key, ok is false when the fetch did not return it
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.known(key) {
+		l.pending[key] = struct{}{}
+
+		keys := make([]K, 0, len(l.pending))
+		for k := range l.pending {
+			keys = append(keys, k)
+		}
+
+		values, err := l.fetch(ctx, keys)
+		if err != nil {
+			var zero V
+			return zero, false, err
+		}
+
+		for k, v := range values {
+			l.values[k] = v
+		}
+		for _, k := range keys {
+			if _, ok := values[k]; !ok {
+				l.missing[k] = struct{}{}
+			}
+		}
+		clear(l.pending)
+	}
+
+	v, ok := l.values[key]
+	return v, ok, nil
+}
+
+func (l *Loader[K, V]) known(key K) bool {
+	if _, ok := l.values[key]; ok {
+		return true
+	}
+	_, ok := l.missing[key]
+	return ok
+}
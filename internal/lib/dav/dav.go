@@ -0,0 +1,250 @@
+// Package dav parses WebDAV and CalDAV request bodies and builds multistatus responses
+package dav
+
+import (
+	"TaskList/internal/lib/ical"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+const (
+	NSDAV            = "DAV:"
+	NSCalDAV         = "urn:ietf:params:xml:ns:caldav"
+	NSCalendarServer = "http://calendarserver.org/ns/"
+)
+
+var (
+	ErrInvalidRequest = errors.New("invalid dav request")
+)
+
+// Props are properties requested by PROPFIND or REPORT, AllProp is set for allprop and empty PROPFIND body
+type Props struct {
+	Names   []xml.Name
+	AllProp bool
+}
+
+type element struct {
+	XMLName xml.Name
+}
+
+type propRequest struct {
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     *struct {
+		Props []element `xml:",any"`
+	} `xml:"DAV: prop"`
+}
+
+func (p propRequest) props() Props {
+	if p.Prop == nil {
+		return Props{AllProp: true}
+	}
+
+	res := Props{Names: make([]xml.Name, len(p.Prop.Props))}
+	for i, e := range p.Prop.Props {
+		res.Names[i] = e.XMLName
+	}
+	return res
+}
+
+func ParsePropfind(r io.Reader) (Props, error) {
+	var req struct {
+		XMLName xml.Name `xml:"DAV: propfind"`
+		propRequest
+	}
+
+	if err := xml.NewDecoder(r).Decode(&req); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Props{AllProp: true}, nil
+		}
+		return Props{}, ErrInvalidRequest
+	}
+
+	return req.props(), nil
+}
+
+// Report is calendar-query with Filter or calendar-multiget with Hrefs
+type Report struct {
+	Name   xml.Name
+	Props  Props
+	Hrefs  []string
+	Filter *CompFilter
+}
+
+// CompFilter is CalDAV comp-filter, text-match and time-range conditions are not supported and always match
+type CompFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *struct{}    `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	Comps        []CompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	Props        []PropFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+}
+
+type PropFilter struct {
+	Name         string    `xml:"name,attr"`
+	IsNotDefined *struct{} `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+}
+
+func ParseReport(r io.Reader) (Report, error) {
+	var req struct {
+		XMLName xml.Name
+		propRequest
+		Hrefs  []string `xml:"DAV: href"`
+		Filter *struct {
+			Comp CompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+		} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+	}
+
+	if err := xml.NewDecoder(r).Decode(&req); err != nil {
+		return Report{}, ErrInvalidRequest
+	}
+
+	res := Report{Name: req.XMLName, Props: req.props(), Hrefs: req.Hrefs}
+	if req.Filter != nil {
+		res.Filter = &req.Filter.Comp
+	}
+	return res, nil
+}
+
+// Match reports whether the component satisfies the filter
+func (f CompFilter) Match(c *ical.Component) bool {
+	if c.Name != f.Name {
+		return false
+	}
+
+	for _, p := range f.Props {
+		_, defined := c.Prop(p.Name)
+		if defined == (p.IsNotDefined != nil) {
+			return false
+		}
+	}
+
+	for _, cf := range f.Comps {
+		children := c.Children(cf.Name)
+		if cf.IsNotDefined != nil {
+			if len(children) > 0 {
+				return false
+			}
+			continue
+		}
+
+		matched := false
+		for _, child := range children {
+			if cf.Match(child) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+type Multistatus struct {
+	XMLName   xml.Name   `xml:"DAV: multistatus"`
+	Responses []Response `xml:"DAV: response"`
+}
+
+type Response struct {
+	Href      string     `xml:"DAV: href"`
+	Status    string     `xml:"DAV: status,omitempty"`
+	Propstats []Propstat `xml:"DAV: propstat,omitempty"`
+}
+
+type Propstat struct {
+	Prop   Prop   `xml:"DAV: prop"`
+	Status string `xml:"DAV: status"`
+}
+
+type Prop struct {
+	Props []Property `xml:",any"`
+}
+
+// Property is a property value, Inner is raw XML of the value
+type Property struct {
+	XMLName xml.Name
+	Inner   string `xml:",innerxml"`
+}
+
+func Name(space, local string) xml.Name {
+	return xml.Name{Space: space, Local: local}
+}
+
+func Text(name xml.Name, value string) Property {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(value))
+	return Property{XMLName: name, Inner: b.String()}
+}
+
+func Raw(name xml.Name, inner string) Property {
+	return Property{XMLName: name, Inner: inner}
+}
+
+func Href(name xml.Name, href string) Property {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(href))
+	return Raw(name, `<href xmlns="DAV:">`+b.String()+`</href>`)
+}
+
+func Status(code int) string {
+	return "HTTP/1.1 " + strconv.Itoa(code) + " " + http.StatusText(code)
+}
+
+// NewResponse answers requested properties with the found ones and 404 for the others,
+// for allprop all found properties are returned
+func NewResponse(href string, req Props, found []Property) Response {
+	res := Response{Href: href}
+
+	if req.AllProp {
+		res.Propstats = append(res.Propstats, Propstat{Prop: Prop{Props: found}, Status: Status(http.StatusOK)})
+		return res
+	}
+
+	byName := make(map[xml.Name]Property, len(found))
+	for _, p := range found {
+		byName[p.XMLName] = p
+	}
+
+	var ok, missing []Property
+	for _, name := range req.Names {
+		if p, exists := byName[name]; exists {
+			ok = append(ok, p)
+		} else {
+			missing = append(missing, Property{XMLName: name})
+		}
+	}
+
+	if len(ok) > 0 {
+		res.Propstats = append(res.Propstats, Propstat{Prop: Prop{Props: ok}, Status: Status(http.StatusOK)})
+	}
+	if len(missing) > 0 {
+		res.Propstats = append(res.Propstats, Propstat{Prop: Prop{Props: missing}, Status: Status(http.StatusNotFound)})
+	}
+	return res
+}
+
+// NotFound is a response for a resource of multiget which does not exist
+func NotFound(href string) Response {
+	return Response{Href: href, Status: Status(http.StatusNotFound)}
+}
+
+func Write(w http.ResponseWriter, ms Multistatus) error {
+	data, err := xml.Marshal(ms)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,55 @@
+package events
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type Handler func(ctx context.Context, e models.TaskEvent)
+
+// Bus delivers task events to subscribers in process, handlers run synchronously
+// in the order of subscription and must not block for long
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+	log      *slog.Logger
+}
+
+func NewBus(log *slog.Logger) *Bus {
+	return &Bus{log: log}
+}
+
+func (b *Bus) Subscribe(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, h)
+}
+
+// Publish delivers the event, the request context cancellation does not stop delivery
+func (b *Bus) Publish(ctx context.Context, e models.TaskEvent) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now().UTC()
+	}
+
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+
+	ctx = context.WithoutCancel(ctx)
+	for _, h := range handlers {
+		b.deliver(ctx, h, e)
+	}
+}
+
+func (b *Bus) deliver(ctx context.Context, h Handler, e models.TaskEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.log.Error("event handler panic", slog.String("event", string(e.Type)), slog.Any("panic", r))
+		}
+	}()
+	h(ctx, e)
+}
@@ -0,0 +1,189 @@
+package events
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const subscriberBuffer = 64
+
+// Message is a published event with its position in the hub stream.
+// ID is "<epoch>-<seq>", epoch changes on restart so stale ids are detected
+type Message struct {
+	ID    string
+	Event models.TaskEvent
+	// users can see the task of the event, sorted
+	users []int64
+}
+
+func (m Message) visibleTo(userID int64) bool {
+	_, found := slices.BinarySearch(m.users, userID)
+	return found
+}
+
+// Audience resolves users who can see the task, implemented by tasks service
+type Audience interface {
+	Viewers(ctx context.Context, task models.Task) ([]int64, error)
+}
+
+// Subscription receives messages published after it was created,
+// C is closed when the subscriber is too slow or unsubscribed
+type Subscription struct {
+	C      <-chan Message
+	userID int64
+	c      chan Message
+	once   sync.Once
+}
+
+func (s *Subscription) close() {
+	s.once.Do(func() { close(s.c) })
+}
+
+// Hub fans out events to live subscribers of users who can see the task
+// and keeps the latest of them for resuming
+type Hub struct {
+	audience Audience
+	log      *slog.Logger
+
+	mu     sync.Mutex
+	epoch  string
+	seq    uint64
+	buffer []Message
+	next   int
+	full   bool
+	subs   map[int64]map[*Subscription]struct{}
+}
+
+func NewHub(size int, audience Audience, log *slog.Logger) *Hub {
+	if size < 1 {
+		size = 1
+	}
+	return &Hub{
+		audience: audience,
+		log:      log,
+		epoch:    strconv.FormatInt(time.Now().UnixNano(), 36),
+		buffer:   make([]Message, size),
+		subs:     make(map[int64]map[*Subscription]struct{}),
+	}
+}
+
+// Publish resolves who can see the task once, stores the event in the replay buffer
+// and sends it to their subscribers, it has Handler signature to be subscribed to Bus
+func (h *Hub) Publish(ctx context.Context, e models.TaskEvent) {
+	const op = "events.Hub.Publish"
+
+	users, err := h.audience.Viewers(ctx, e.Task)
+	if err != nil {
+		// the owner always sees the task, the others miss the event rather than see a foreign one
+		h.log.Error("failed resolve event audience", slog.String("op", op), slog.Int64("task_id", e.Task.ID), slog.String("err", err.Error()))
+		users = []int64{e.Task.UserID}
+	}
+	users = slices.Clone(users)
+	slices.Sort(users)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	msg := Message{ID: h.epoch + "-" + strconv.FormatUint(h.seq, 10), Event: e, users: users}
+
+	h.buffer[h.next] = msg
+	h.next = (h.next + 1) % len(h.buffer)
+	if h.next == 0 {
+		h.full = true
+	}
+
+	for _, userID := range users {
+		for sub := range h.subs[userID] {
+			select {
+			case sub.c <- msg:
+			default:
+				h.remove(sub)
+			}
+		}
+	}
+}
+
+// Subscribe starts a subscription of the user, with lastID it also returns buffered messages
+// of the user published after it.
+// ok is false when the messages after lastID are no longer buffered or lastID is of another epoch,
+// the client missed events and must reload its state
+func (h *Hub) Subscribe(userID int64, lastID string) (sub *Subscription, replay []Message, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c := make(chan Message, subscriberBuffer)
+	sub = &Subscription{C: c, userID: userID, c: c}
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+
+	if lastID == "" {
+		return sub, nil, true
+	}
+
+	seq, err := h.parseID(lastID)
+	if err != nil || seq > h.seq {
+		return sub, nil, false
+	}
+
+	buffered := h.buffered()
+	if seq == h.seq {
+		return sub, nil, true
+	}
+	if len(buffered) == 0 || h.seqOf(buffered[0]) > seq+1 {
+		return sub, nil, false
+	}
+
+	for _, m := range buffered {
+		if h.seqOf(m) > seq && m.visibleTo(userID) {
+			replay = append(replay, m)
+		}
+	}
+	return sub, replay, true
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(sub)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	delete(h.subs[sub.userID], sub)
+	if len(h.subs[sub.userID]) == 0 {
+		delete(h.subs, sub.userID)
+	}
+	sub.close()
+}
+
+// buffered returns buffered messages in publish order
+func (h *Hub) buffered() []Message {
+	if !h.full {
+		return append([]Message(nil), h.buffer[:h.next]...)
+	}
+	return append(append([]Message(nil), h.buffer[h.next:]...), h.buffer[:h.next]...)
+}
+
+func (h *Hub) parseID(id string) (uint64, error) {
+	epoch, seq, found := strings.Cut(id, "-")
+	if !found || epoch != h.epoch {
+		return 0, fmt.Errorf("event id %q is not of the current stream", id)
+	}
+	return strconv.ParseUint(seq, 10, 64)
+}
+
+func (h *Hub) seqOf(m Message) uint64 {
+	_, seq, _ := strings.Cut(m.ID, "-")
+	n, _ := strconv.ParseUint(seq, 10, 64)
+	return n
+}
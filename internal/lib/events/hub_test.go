@@ -0,0 +1,126 @@
+package events
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+// viewers maps task id to users who can see it
+type viewers map[int64][]int64
+
+func (v viewers) Viewers(ctx context.Context, task models.Task) ([]int64, error) {
+	users, ok := v[task.ID]
+	if !ok {
+		return nil, errors.New("task not found")
+	}
+	return users, nil
+}
+
+func newTestHub(size int, v viewers) *Hub {
+	return NewHub(size, v, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func event(taskID, ownerID int64) models.TaskEvent {
+	return models.TaskEvent{Type: models.EventTaskUpdated, Task: models.Task{ID: taskID, UserID: ownerID}}
+}
+
+func received(sub *Subscription) []int64 {
+	var ids []int64
+	for {
+		select {
+		case msg := <-sub.C:
+			ids = append(ids, msg.Event.Task.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestHubRoutesByAudience(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHub(10, viewers{1: {1, 2}, 2: {2}, 3: {3}})
+
+	sub1, _, _ := h.Subscribe(1, "")
+	sub2, _, _ := h.Subscribe(2, "")
+	sub3, _, _ := h.Subscribe(3, "")
+
+	h.Publish(ctx, event(1, 1))
+	h.Publish(ctx, event(2, 2))
+	// audience failure falls back to the owner
+	h.Publish(ctx, event(4, 3))
+
+	for _, tt := range []struct {
+		sub  *Subscription
+		want []int64
+	}{
+		{sub1, []int64{1}},
+		{sub2, []int64{1, 2}},
+		{sub3, []int64{4}},
+	} {
+		got := received(tt.sub)
+		if len(got) != len(tt.want) {
+			t.Fatalf("user %d received tasks %v, want %v", tt.sub.userID, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("user %d received tasks %v, want %v", tt.sub.userID, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestHubReplayOnlyVisible(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHub(10, viewers{1: {1}, 2: {2}})
+
+	first, _, _ := h.Subscribe(1, "")
+	h.Publish(ctx, event(1, 1))
+	last := (<-first.C).ID
+	h.Unsubscribe(first)
+
+	h.Publish(ctx, event(2, 2))
+	h.Publish(ctx, event(1, 1))
+
+	_, replay, ok := h.Subscribe(1, last)
+	if !ok {
+		t.Fatal("Subscribe() ok = false, want resumed stream")
+	}
+	if len(replay) != 1 || replay[0].Event.Task.ID != 1 {
+		t.Errorf("replay = %+v, want only the event of task 1", replay)
+	}
+}
+
+// unrelated traffic must not fill buffers of users who can't see it
+func TestHubSlowSubscriberDropped(t *testing.T) {
+	ctx := context.Background()
+	h := newTestHub(10, viewers{1: {1}, 2: {2}})
+
+	busy, _, _ := h.Subscribe(1, "")
+	idle, _, _ := h.Subscribe(2, "")
+
+	for range subscriberBuffer + 1 {
+		h.Publish(ctx, event(1, 1))
+	}
+
+	n := 0
+	for range busy.C {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("busy subscriber got %d messages before close, want %d", n, subscriberBuffer)
+	}
+
+	h.Publish(ctx, event(2, 2))
+	select {
+	case msg, open := <-idle.C:
+		if !open || msg.Event.Task.ID != 2 {
+			t.Errorf("idle subscriber got %+v, open %v", msg, open)
+		}
+	default:
+		t.Error("idle subscriber got nothing")
+	}
+}
@@ -0,0 +1,358 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	dateTimeLayout    = "20060102T150405"
+	dateTimeUTCLayout = "20060102T150405Z"
+	dateLayout        = "20060102"
+
+	maxLineOctets = 75
+)
+
+var (
+	ErrInvalidCalendar = errors.New("invalid calendar data")
+)
+
+// Property is a single content line, e.g. DUE;TZID=Europe/Moscow:20250301T090000
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Component is a BEGIN:<Name> ... END:<Name> block
+type Component struct {
+	Name       string
+	Props      []Property
+	Components []*Component
+}
+
+func NewComponent(name string) *Component {
+	return &Component{Name: name}
+}
+
+// Add appends a property, value must be already escaped for TEXT values
+func (c *Component) Add(name string, value string) {
+	c.Props = append(c.Props, Property{Name: name, Value: value})
+}
+
+func (c *Component) AddText(name string, value string) {
+	c.Add(name, EscapeText(value))
+}
+
+// AddTextList adds multi-valued TEXT property like CATEGORIES
+func (c *Component) AddTextList(name string, values []string) {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = EscapeText(v)
+	}
+	c.Add(name, strings.Join(escaped, ","))
+}
+
+func (c *Component) AddTime(name string, t time.Time) {
+	c.Add(name, t.UTC().Format(dateTimeUTCLayout))
+}
+
+// Prop returns the first property with the given name
+func (c *Component) Prop(name string) (Property, bool) {
+	for _, p := range c.Props {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Property{}, false
+}
+
+// Text returns unescaped value of the first property with the given name
+func (c *Component) Text(name string) string {
+	p, ok := c.Prop(name)
+	if !ok {
+		return ""
+	}
+	return UnescapeText(p.Value)
+}
+
+// TextList returns unescaped values of all properties with the given name,
+// values of one property are separated by unescaped commas
+func (c *Component) TextList(name string) []string {
+	var res []string
+	for _, p := range c.Props {
+		if p.Name != name {
+			continue
+		}
+		start := 0
+		for i := 0; i <= len(p.Value); i++ {
+			if i < len(p.Value) && (p.Value[i] != ',' || (i > 0 && p.Value[i-1] == '\\')) {
+				continue
+			}
+			if v := UnescapeText(p.Value[start:i]); v != "" {
+				res = append(res, v)
+			}
+			start = i + 1
+		}
+	}
+	return res
+}
+
+// Time parses DATE or DATE-TIME value of the property,
+// floating times are interpreted in loc
+func (c *Component) Time(name string, loc *time.Location) (time.Time, bool, error) {
+	p, ok := c.Prop(name)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	t, err := p.Time(loc)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t, true, nil
+}
+
+// Children returns nested components with the given name
+func (c *Component) Children(name string) []*Component {
+	var res []*Component
+	for _, v := range c.Components {
+		if v.Name == name {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func (p Property) Time(loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if tzid, ok := p.Params["TZID"]; ok {
+		l, err := time.LoadLocation(tzid)
+		if err == nil {
+			loc = l
+		}
+	}
+
+	switch {
+	case p.Params["VALUE"] == "DATE" || len(p.Value) == len(dateLayout):
+		return time.ParseInLocation(dateLayout, p.Value, loc)
+	case strings.HasSuffix(p.Value, "Z"):
+		return time.Parse(dateTimeUTCLayout, p.Value)
+	default:
+		return time.ParseInLocation(dateTimeLayout, p.Value, loc)
+	}
+}
+
+// Encode writes component in RFC 5545 format with CRLF line endings and line folding
+func Encode(w io.Writer, c *Component) error {
+	bw := bufio.NewWriter(w)
+	if err := encode(bw, c); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func encode(w *bufio.Writer, c *Component) error {
+	if err := writeLine(w, "BEGIN:"+c.Name); err != nil {
+		return err
+	}
+	for _, p := range c.Props {
+		if err := writeLine(w, p.String()); err != nil {
+			return err
+		}
+	}
+	for _, v := range c.Components {
+		if err := encode(w, v); err != nil {
+			return err
+		}
+	}
+	return writeLine(w, "END:"+c.Name)
+}
+
+func (p Property) String() string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+
+	keys := make([]string, 0, len(p.Params))
+	for k := range p.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := p.Params[k]
+		b.WriteString(";")
+		b.WriteString(k)
+		b.WriteString("=")
+		if strings.ContainsAny(v, ":;,") {
+			b.WriteString(`"` + v + `"`)
+		} else {
+			b.WriteString(v)
+		}
+	}
+	b.WriteString(":")
+	b.WriteString(p.Value)
+	return b.String()
+}
+
+func writeLine(w *bufio.Writer, line string) error {
+	n := 0
+	for _, r := range line {
+		l := len(string(r))
+		if n+l > maxLineOctets {
+			if _, err := w.WriteString("\r\n "); err != nil {
+				return err
+			}
+			n = 1
+		}
+		if _, err := w.WriteRune(r); err != nil {
+			return err
+		}
+		n += l
+	}
+	_, err := w.WriteString("\r\n")
+	return err
+}
+
+// Decode reads all top level components (usually a single VCALENDAR)
+func Decode(r io.Reader) ([]*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res   []*Component
+		stack []*Component
+	)
+
+	for i, line := range lines {
+		p, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch p.Name {
+		case "BEGIN":
+			c := NewComponent(strings.ToUpper(p.Value))
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, c)
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(p.Value) {
+				return nil, fmt.Errorf("line %d: unexpected END:%s: %w", i+1, p.Value, ErrInvalidCalendar)
+			}
+			c := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				res = append(res, c)
+			}
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: property outside component: %w", i+1, ErrInvalidCalendar)
+			}
+			c := stack[len(stack)-1]
+			c.Props = append(c.Props, p)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("unclosed component %s: %w", stack[len(stack)-1].Name, ErrInvalidCalendar)
+	}
+
+	return res, nil
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func parseLine(line string) (Property, error) {
+	p := Property{}
+
+	// name and params are separated from value by the first colon outside quotes
+	inQuotes := false
+	sep := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return p, ErrInvalidCalendar
+	}
+
+	head := line[:sep]
+	p.Value = line[sep+1:]
+
+	parts := strings.Split(head, ";")
+	p.Name = strings.ToUpper(parts[0])
+	if p.Name == "" {
+		return p, ErrInvalidCalendar
+	}
+	for _, param := range parts[1:] {
+		k, v, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		if p.Params == nil {
+			p.Params = map[string]string{}
+		}
+		p.Params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+
+	return p, nil
+}
+
+// EscapeText escapes TEXT value according to RFC 5545 3.3.11
+func EscapeText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return r.Replace(s)
+}
+
+func UnescapeText(s string) string {
+	r := strings.NewReplacer(
+		`\\`, `\`,
+		`\;`, ";",
+		`\,`, ",",
+		`\n`, "\n",
+		`\N`, "\n",
+	)
+	return r.Replace(s)
+}
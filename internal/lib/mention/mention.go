@@ -0,0 +1,25 @@
+// Package mention finds @mentions in task descriptions and comments.
+// Users are identified by email, so a mention is @ followed by the email: "ask @bob@example.com"
+package mention
+
+import (
+	"regexp"
+	"strings"
+)
+
+var re = regexp.MustCompile(`(?:^|[^\w@])@([\w.%+\-]+@[\w\-]+(?:\.[\w\-]+)*\.\p{L}{2,})`)
+
+// Parse returns mentioned emails in lower case without duplicates in order of appearance
+func Parse(text string) []string {
+	var res []string
+	seen := make(map[string]struct{})
+	for _, m := range re.FindAllStringSubmatch(text, -1) {
+		email := strings.ToLower(strings.TrimRight(m[1], "."))
+		if _, ok := seen[email]; ok {
+			continue
+		}
+		seen[email] = struct{}{}
+		res = append(res, email)
+	}
+	return res
+}
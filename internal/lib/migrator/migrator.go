@@ -0,0 +1,116 @@
+// Package migrator applies the embedded goose migrations and checks
+// that the database schema matches the binary
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/pressly/goose/v3"
+	"io/fs"
+)
+
+var ErrSchemaMismatch = errors.New("database schema does not match the binary")
+
+type Migrator struct {
+	provider *goose.Provider
+}
+
+// New uses the goose_db_version table, so databases migrated by the goose CLI keep their history
+func New(db *sql.DB, dialect goose.Dialect, migrations fs.FS) (*Migrator, error) {
+	const op = "lib.migrator.New"
+
+	p, err := goose.NewProvider(dialect, db, migrations)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Migrator{provider: p}, nil
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+	const op = "lib.migrator.Up"
+
+	res, err := m.provider.Up(ctx)
+	if err != nil {
+		return res, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// Down rolls back the latest applied migration
+func (m *Migrator) Down(ctx context.Context) (*goose.MigrationResult, error) {
+	const op = "lib.migrator.Down"
+
+	res, err := m.provider.Down(ctx)
+	if err != nil {
+		return res, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// Redo rolls back the latest applied migration and applies it again
+func (m *Migrator) Redo(ctx context.Context) ([]*goose.MigrationResult, error) {
+	const op = "lib.migrator.Redo"
+
+	down, err := m.provider.Down(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	up, err := m.provider.ApplyVersion(ctx, down.Source.Version, true)
+	if err != nil {
+		return []*goose.MigrationResult{down}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return []*goose.MigrationResult{down, up}, nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	const op = "lib.migrator.Status"
+
+	res, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return res, nil
+}
+
+// Versions returns the version of the database and the latest embedded migration
+func (m *Migrator) Versions(ctx context.Context) (int64, int64, error) {
+	const op = "lib.migrator.Versions"
+
+	current, latest, err := m.provider.GetVersions(ctx)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return current, latest, nil
+}
+
+// Check returns ErrSchemaMismatch when migrations are pending or the database
+// was migrated by a newer binary
+func (m *Migrator) Check(ctx context.Context) error {
+	const op = "lib.migrator.Check"
+
+	current, latest, err := m.Versions(ctx)
+	if err != nil {
+		return err
+	}
+	if current > latest {
+		return fmt.Errorf("%w: database version %d is newer than the latest migration %d of the binary",
+			ErrSchemaMismatch, current, latest)
+	}
+
+	pending, err := m.provider.HasPending(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if pending {
+		return fmt.Errorf("%w: database version %d, binary expects %d, run: tasklist migrate up",
+			ErrSchemaMismatch, current, latest)
+	}
+
+	return nil
+}
@@ -0,0 +1,316 @@
+// Package openapi builds OpenAPI 3.1 documents, schemas of request and response bodies
+// are generated from the Go types so the document follows the handlers
+package openapi
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	enums      map[reflect.Type][]any
+	pathParams map[string]Parameter
+	errors     map[int]string
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	Responses       map[string]*Response       `json:"responses,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// PathItem maps lower case http methods to operations
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+
+	doc *Document
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+	Example     any     `json:"example,omitempty"`
+}
+
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema  *Schema `json:"schema,omitempty"`
+	Example any     `json:"example,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+}
+
+// Methods are http methods an OpenAPI path item can describe, other methods like PROPFIND
+// are documented in the descriptions of the paths
+var Methods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas:         make(map[string]*Schema),
+			Responses:       make(map[string]*Response),
+			SecuritySchemes: make(map[string]*SecurityScheme),
+		},
+		enums:      make(map[reflect.Type][]any),
+		pathParams: make(map[string]Parameter),
+		errors:     make(map[int]string),
+	}
+}
+
+// Enum sets values of the named type like models.Role, fields of the type get the enum in schemas
+func (d *Document) Enum(v any, values ...any) {
+	d.enums[reflect.TypeOf(v)] = values
+}
+
+// PathParam describes the path parameter for all paths which have it,
+// undescribed parameters are strings
+func (d *Document) PathParam(name string, v any, description string) {
+	d.pathParams[name] = Parameter{Name: name, In: "path", Description: description, Required: true, Schema: d.Schema(v)}
+}
+
+// ErrorResponse adds the shared response which operations reference by status with Errors
+func (d *Document) ErrorResponse(status int, name string, description string, v any, example any) {
+	d.Components.Responses[name] = &Response{
+		Description: description,
+		Content:     map[string]*MediaType{"application/json": {Schema: d.Schema(v), Example: example}},
+	}
+	d.errors[status] = name
+}
+
+// Add adds the operation, parameters of the path are taken from PathParam
+func (d *Document) Add(method string, path string, id string, summary string) *Operation {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+
+	op := &Operation{OperationID: id, Summary: summary, Responses: make(map[string]*Response), doc: d}
+	for _, name := range pathParamNames(path) {
+		p, ok := d.pathParams[name]
+		if !ok {
+			p = Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+		}
+		op.Parameters = append(op.Parameters, p)
+	}
+	(*item)[strings.ToLower(method)] = op
+
+	return op
+}
+
+func (o *Operation) Tag(tags ...string) *Operation {
+	o.Tags = append(o.Tags, tags...)
+	return o
+}
+
+func (o *Operation) Describe(description string) *Operation {
+	o.Description = description
+	return o
+}
+
+// Auth requires one of the security schemes
+func (o *Operation) Auth(schemes ...string) *Operation {
+	for _, s := range schemes {
+		o.Security = append(o.Security, map[string][]string{s: {}})
+	}
+	return o
+}
+
+func (o *Operation) Query(name string, v any, description string) *Operation {
+	o.Parameters = append(o.Parameters, Parameter{Name: name, In: "query", Description: description, Schema: o.doc.Schema(v)})
+	return o
+}
+
+func (o *Operation) Header(name string, v any, description string) *Operation {
+	o.Parameters = append(o.Parameters, Parameter{Name: name, In: "header", Description: description, Schema: o.doc.Schema(v)})
+	return o
+}
+
+// JSONBody sets the request body with schema of v, example is a value of the same type
+func (o *Operation) JSONBody(v any, example any) *Operation {
+	return o.Body("application/json", "", o.doc.Schema(v), example)
+}
+
+func (o *Operation) Body(contentType string, description string, schema *Schema, example any) *Operation {
+	o.RequestBody = &RequestBody{
+		Description: description,
+		Required:    true,
+		Content:     map[string]*MediaType{contentType: {Schema: schema, Example: example}},
+	}
+	return o
+}
+
+// JSON adds the json response with schema of v
+func (o *Operation) JSON(status int, description string, v any, example any) *Operation {
+	return o.Response(status, "application/json", description, o.doc.Schema(v), example)
+}
+
+// Response adds the response, empty content type is a response without body
+func (o *Operation) Response(status int, contentType string, description string, schema *Schema, example any) *Operation {
+	res := &Response{Description: description}
+	if contentType != "" {
+		res.Content = map[string]*MediaType{contentType: {Schema: schema, Example: example}}
+	}
+	o.Responses[fmt.Sprint(status)] = res
+	return o
+}
+
+// ResponseHeader documents the header of the already added response
+func (o *Operation) ResponseHeader(status int, name string, description string) *Operation {
+	res, ok := o.Responses[fmt.Sprint(status)]
+	if !ok {
+		panic(fmt.Sprintf("openapi: %s has no %d response", o.OperationID, status))
+	}
+	if res.Headers == nil {
+		res.Headers = make(map[string]*Header)
+	}
+	res.Headers[name] = &Header{Description: description, Schema: &Schema{Type: "string"}}
+	return o
+}
+
+// Errors references the shared error responses by status
+func (o *Operation) Errors(statuses ...int) *Operation {
+	for _, status := range statuses {
+		name, ok := o.doc.errors[status]
+		if !ok {
+			panic(fmt.Sprintf("openapi: no error response for %d", status))
+		}
+		o.Responses[fmt.Sprint(status)] = &Response{Ref: "#/components/responses/" + name}
+	}
+	return o
+}
+
+// Undocumented returns "METHOD /path" of routes which have no operation in the document.
+// Trailing slashes of chi subrouters are trimmed and wildcards become {path},
+// methods OpenAPI can not describe are skipped
+func (d *Document) Undocumented(routes chi.Routes) ([]string, error) {
+	var missing []string
+
+	err := chi.Walk(routes, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if !slices.Contains(Methods, method) {
+			return nil
+		}
+		path := NormalizePath(route)
+		if item, ok := d.Paths[path]; ok {
+			if _, ok = (*item)[strings.ToLower(method)]; ok {
+				return nil
+			}
+		}
+		missing = append(missing, method+" "+path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(missing)
+	return missing, nil
+}
+
+// NormalizePath converts chi route pattern to OpenAPI path
+func NormalizePath(route string) string {
+	if strings.HasSuffix(route, "/*") {
+		route = strings.TrimSuffix(route, "*") + "{path}"
+	}
+	if len(route) > 1 {
+		route = strings.TrimSuffix(route, "/")
+	}
+	return route
+}
+
+func pathParamNames(path string) []string {
+	var names []string
+	for {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			return names
+		}
+		end := strings.Index(path[start:], "}")
+		if end < 0 {
+			return names
+		}
+		names = append(names, path[start+1:start+end])
+		path = path[start+end+1:]
+	}
+}
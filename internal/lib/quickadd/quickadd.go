@@ -0,0 +1,642 @@
+// Package quickadd parses a single line like "Pay rent tomorrow 9am #home !high every month"
+// into task attributes. English and Russian phrases are supported.
+package quickadd
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	ErrEmptyTitle = errors.New("task title is empty")
+)
+
+// Result is the interpretation of the quick add line
+type Result struct {
+	Title      string
+	Due        *time.Time
+	AllDay     bool
+	Tags       []string
+	Priority   string
+	Recurrence string
+}
+
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+)
+
+var (
+	timeRe  = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	isoRe   = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	dotRe   = regexp.MustCompile(`^(\d{1,2})[./](\d{1,2})(?:[./](\d{2,4}))?$`)
+	numRe   = regexp.MustCompile(`^\d{1,3}$`)
+	tagRe   = regexp.MustCompile(`^#([\p{L}\p{N}_\-/]+)$`)
+	priosRe = regexp.MustCompile(`^!+$`)
+)
+
+var priorities = map[string]string{
+	"1":       PriorityHigh,
+	"2":       PriorityMedium,
+	"3":       PriorityLow,
+	"high":    PriorityHigh,
+	"medium":  PriorityMedium,
+	"low":     PriorityLow,
+	"urgent":  PriorityHigh,
+	"высокий": PriorityHigh,
+	"средний": PriorityMedium,
+	"низкий":  PriorityLow,
+	"срочно":  PriorityHigh,
+	"важно":   PriorityHigh,
+}
+
+var weekdays = map[string]time.Weekday{
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+	"sunday": time.Sunday, "sun": time.Sunday,
+
+	"понедельник": time.Monday, "пн": time.Monday,
+	"вторник": time.Tuesday, "вт": time.Tuesday,
+	"среда": time.Wednesday, "среду": time.Wednesday, "ср": time.Wednesday,
+	"четверг": time.Thursday, "чт": time.Thursday,
+	"пятница": time.Friday, "пятницу": time.Friday, "пт": time.Friday,
+	"суббота": time.Saturday, "субботу": time.Saturday, "сб": time.Saturday,
+	"воскресенье": time.Sunday, "вс": time.Sunday,
+
+	// "до пятницы", "к пятнице"
+	"понедельника": time.Monday, "понедельнику": time.Monday,
+	"вторника": time.Tuesday, "вторнику": time.Tuesday,
+	"среды": time.Wednesday, "среде": time.Wednesday,
+	"четверга": time.Thursday, "четвергу": time.Thursday,
+	"пятницы": time.Friday, "пятнице": time.Friday,
+	"субботы": time.Saturday, "субботе": time.Saturday,
+	"воскресенья": time.Sunday, "воскресенью": time.Sunday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January, "января": time.January,
+	"february": time.February, "feb": time.February, "февраля": time.February,
+	"march": time.March, "mar": time.March, "марта": time.March,
+	"april": time.April, "apr": time.April, "апреля": time.April,
+	"may": time.May, "мая": time.May,
+	"june": time.June, "jun": time.June, "июня": time.June,
+	"july": time.July, "jul": time.July, "июля": time.July,
+	"august": time.August, "aug": time.August, "августа": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September, "сентября": time.September,
+	"october": time.October, "oct": time.October, "октября": time.October,
+	"november": time.November, "nov": time.November, "ноября": time.November,
+	"december": time.December, "dec": time.December, "декабря": time.December,
+}
+
+type unit int
+
+const (
+	unitDay unit = iota
+	unitWeek
+	unitMonth
+	unitYear
+)
+
+var units = map[string]unit{
+	"day": unitDay, "days": unitDay, "день": unitDay, "дня": unitDay, "дней": unitDay,
+	"week": unitWeek, "weeks": unitWeek, "неделю": unitWeek, "недели": unitWeek, "недель": unitWeek, "неделя": unitWeek,
+	"month": unitMonth, "months": unitMonth, "месяц": unitMonth, "месяца": unitMonth, "месяцев": unitMonth,
+	"year": unitYear, "years": unitYear, "год": unitYear, "года": unitYear, "лет": unitYear,
+}
+
+var freqs = map[unit]string{
+	unitDay:   "DAILY",
+	unitWeek:  "WEEKLY",
+	unitMonth: "MONTHLY",
+	unitYear:  "YEARLY",
+}
+
+var rruleDays = map[time.Weekday]string{
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+	time.Sunday:    "SU",
+}
+
+var adverbRecurrence = map[string]unit{
+	"daily":       unitDay,
+	"weekly":      unitWeek,
+	"monthly":     unitMonth,
+	"yearly":      unitYear,
+	"annually":    unitYear,
+	"ежедневно":   unitDay,
+	"еженедельно": unitWeek,
+	"ежемесячно":  unitMonth,
+	"ежегодно":    unitYear,
+}
+
+type parser struct {
+	words []string
+	norm  []string
+	now   time.Time
+	res   Result
+
+	date    *time.Time
+	hour    int
+	minute  int
+	hasTime bool
+}
+
+// Parse interprets the line relative to now, its location is used as the user's timezone
+func Parse(line string, now time.Time) (Result, error) {
+	p := &parser{now: now}
+	p.words = strings.Fields(line)
+	p.norm = make([]string, len(p.words))
+	for i, w := range p.words {
+		p.norm[i] = normalize(w)
+	}
+
+	var title []string
+	for i := 0; i < len(p.words); {
+		n := p.match(i)
+		if n == 0 {
+			title = append(title, p.words[i])
+			i++
+			continue
+		}
+		i += n
+	}
+
+	p.res.Title = strings.TrimSpace(strings.Join(title, " "))
+	if p.res.Title == "" {
+		return Result{}, ErrEmptyTitle
+	}
+
+	p.resolveDue()
+
+	return p.res, nil
+}
+
+// match tries every rule at position i and returns number of consumed words
+func (p *parser) match(i int) int {
+	for _, rule := range []func(int) int{
+		p.matchTag,
+		p.matchPriority,
+		p.matchRecurrence,
+		p.matchRelativeDate,
+		p.matchWeekday,
+		p.matchDate,
+		p.matchTime,
+	} {
+		if n := rule(i); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+func (p *parser) word(i int) string {
+	if i < 0 || i >= len(p.norm) {
+		return ""
+	}
+	return p.norm[i]
+}
+
+func (p *parser) matchTag(i int) int {
+	m := tagRe.FindStringSubmatch(strings.TrimRight(p.words[i], ".,;"))
+	if m == nil {
+		return 0
+	}
+	tag := strings.ToLower(m[1])
+	for _, t := range p.res.Tags {
+		if t == tag {
+			return 1
+		}
+	}
+	p.res.Tags = append(p.res.Tags, tag)
+	return 1
+}
+
+func (p *parser) matchPriority(i int) int {
+	w := strings.ToLower(strings.TrimRight(p.words[i], ".,;"))
+	if !strings.HasPrefix(w, "!") {
+		return 0
+	}
+
+	if priosRe.MatchString(w) {
+		switch len(w) {
+		case 1:
+			p.res.Priority = PriorityLow
+		case 2:
+			p.res.Priority = PriorityMedium
+		default:
+			p.res.Priority = PriorityHigh
+		}
+		return 1
+	}
+
+	prio, ok := priorities[strings.TrimPrefix(w, "!")]
+	if !ok {
+		return 0
+	}
+	p.res.Priority = prio
+	return 1
+}
+
+func (p *parser) matchRecurrence(i int) int {
+	w := p.word(i)
+
+	if u, ok := adverbRecurrence[w]; ok {
+		p.res.Recurrence = "FREQ=" + freqs[u]
+		return 1
+	}
+
+	switch w {
+	case "every", "each", "каждый", "каждую", "каждое", "каждые", "каждая":
+	default:
+		return 0
+	}
+
+	n := 1
+	interval := 1
+	if numRe.MatchString(p.word(i + 1)) {
+		interval, _ = strconv.Atoi(p.word(i + 1))
+		n++
+	} else if p.word(i+1) == "other" {
+		interval = 2
+		n++
+	}
+	if interval < 1 {
+		return 0
+	}
+
+	next := p.word(i + n)
+	if u, ok := units[next]; ok {
+		p.res.Recurrence = "FREQ=" + freqs[u]
+		if interval > 1 {
+			p.res.Recurrence += ";INTERVAL=" + strconv.Itoa(interval)
+		}
+		return n + 1
+	}
+
+	if wd, ok := weekdays[next]; ok {
+		p.res.Recurrence = "FREQ=WEEKLY;BYDAY=" + rruleDays[wd]
+		if interval > 1 {
+			p.res.Recurrence = "FREQ=WEEKLY;INTERVAL=" + strconv.Itoa(interval) + ";BYDAY=" + rruleDays[wd]
+		}
+		if p.date == nil {
+			d := nextWeekday(p.now, wd, false)
+			p.date = &d
+		}
+		return n + 1
+	}
+
+	return 0
+}
+
+// deadline returns 1 when the word at i introduces a due date: "by friday", "до пятницы"
+func (p *parser) deadline(i int) int {
+	switch p.word(i) {
+	case "by", "due", "до", "к":
+		return 1
+	}
+	return 0
+}
+
+func (p *parser) matchRelativeDate(i int) int {
+	today := startOfDay(p.now)
+
+	if n := p.deadline(i); n > 0 {
+		switch p.word(i + n) {
+		case "today", "сегодня", "tomorrow", "tmr", "завтра", "послезавтра":
+			return n + p.matchRelativeDate(i+n)
+		}
+		return 0
+	}
+
+	switch p.word(i) {
+	case "today", "сегодня":
+		p.setDate(today)
+		return 1
+	case "tonight":
+		p.setDate(today)
+		p.setTime(20, 0)
+		return 1
+	case "tomorrow", "tmr", "завтра":
+		p.setDate(today.AddDate(0, 0, 1))
+		return 1
+	case "послезавтра":
+		p.setDate(today.AddDate(0, 0, 2))
+		return 1
+	case "day":
+		if p.word(i+1) == "after" && p.word(i+2) == "tomorrow" {
+			p.setDate(today.AddDate(0, 0, 2))
+			return 3
+		}
+	case "in", "через":
+		// in 3 days, через 2 недели, через неделю
+		n := 1
+		amount := 1
+		if numRe.MatchString(p.word(i + 1)) {
+			amount, _ = strconv.Atoi(p.word(i + 1))
+			n++
+		} else if p.word(i+1) == "a" || p.word(i+1) == "an" {
+			n++
+		}
+		u, ok := units[p.word(i+n)]
+		if !ok {
+			return 0
+		}
+		p.setDate(addUnit(today, u, amount))
+		return n + 1
+	case "next":
+		switch u, ok := units[p.word(i+1)]; {
+		case ok && u == unitWeek:
+			p.setDate(nextWeekday(p.now, time.Monday, true))
+			return 2
+		case ok:
+			p.setDate(addUnit(today, u, 1))
+			return 2
+		}
+	case "на":
+		// на следующей неделе
+		if p.word(i+1) == "следующей" && p.word(i+2) == "неделе" {
+			p.setDate(nextWeekday(p.now, time.Monday, true))
+			return 3
+		}
+	case "в", "во":
+		// в следующем месяце / году
+		if p.word(i+1) == "следующем" {
+			switch p.word(i + 2) {
+			case "месяце":
+				p.setDate(addUnit(today, unitMonth, 1))
+				return 3
+			case "году":
+				p.setDate(addUnit(today, unitYear, 1))
+				return 3
+			}
+		}
+	}
+
+	return 0
+}
+
+func (p *parser) matchWeekday(i int) int {
+	n := p.deadline(i)
+	switch p.word(i) {
+	case "on", "в", "во":
+		n = 1
+	}
+
+	next := false
+	switch p.word(i + n) {
+	case "next", "следующий", "следующую", "следующее", "следующая":
+		n++
+		next = true
+	}
+
+	wd, ok := weekdays[p.word(i+n)]
+	if !ok {
+		return 0
+	}
+	p.setDate(nextWeekday(p.now, wd, next))
+	return n + 1
+}
+
+func (p *parser) matchDate(i int) int {
+	n := p.deadline(i)
+	if p.word(i) == "on" {
+		n = 1
+	}
+	w := p.word(i + n)
+
+	if m := isoRe.FindStringSubmatch(w); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		d, _ := strconv.Atoi(m[3])
+		if t, ok := p.makeDate(y, time.Month(mo), d); ok {
+			p.setDate(t)
+			return n + 1
+		}
+		return 0
+	}
+
+	if m := dotRe.FindStringSubmatch(w); m != nil {
+		d, _ := strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		y := 0
+		if m[3] != "" {
+			y, _ = strconv.Atoi(m[3])
+			if y < 100 {
+				y += 2000
+			}
+		}
+		if t, ok := p.makeDate(y, time.Month(mo), d); ok {
+			p.setDate(t)
+			return n + 1
+		}
+		return 0
+	}
+
+	// 10 march, 10 марта
+	if numRe.MatchString(w) {
+		if mo, ok := months[p.word(i+n+1)]; ok {
+			d, _ := strconv.Atoi(w)
+			if t, ok := p.makeDate(0, mo, d); ok {
+				p.setDate(t)
+				return n + 2
+			}
+		}
+		return 0
+	}
+
+	// march 10
+	if mo, ok := months[w]; ok && numRe.MatchString(strings.TrimSuffix(p.word(i+n+1), "th")) {
+		d, _ := strconv.Atoi(strings.TrimSuffix(p.word(i+n+1), "th"))
+		if t, ok := p.makeDate(0, mo, d); ok {
+			p.setDate(t)
+			return n + 2
+		}
+	}
+
+	return 0
+}
+
+func (p *parser) matchTime(i int) int {
+	n := 0
+	prefixed := false
+	switch p.word(i) {
+	case "at", "в", "@":
+		n = 1
+		prefixed = true
+	}
+	w := p.word(i + n)
+
+	switch w {
+	case "noon", "полдень":
+		p.setTime(12, 0)
+		return n + 1
+	case "midnight", "полночь":
+		p.setTime(0, 0)
+		return n + 1
+	}
+
+	m := timeRe.FindStringSubmatch(w)
+	if m == nil {
+		return 0
+	}
+
+	h, _ := strconv.Atoi(m[1])
+	mi := 0
+	if m[2] != "" {
+		mi, _ = strconv.Atoi(m[2])
+	}
+	suffix := m[3]
+	consumed := n + 1
+
+	if suffix == "" {
+		switch p.word(i + n + 1) {
+		case "am", "утра", "ночи":
+			suffix = "am"
+			consumed++
+		case "pm", "вечера":
+			suffix = "pm"
+			consumed++
+		case "дня":
+			// "в 2 дня" is afternoon, but "через 2 дня" was matched before as relative date
+			suffix = "pm"
+			consumed++
+		}
+	}
+
+	// bare number is a time only with explicit prefix, suffix or minutes
+	if !prefixed && suffix == "" && m[2] == "" {
+		return 0
+	}
+
+	// 12-hour clock has no zero hour, "0am" or "13pm" is not a time
+	if suffix != "" && (h < 1 || h > 12) {
+		return 0
+	}
+
+	switch suffix {
+	case "am":
+		if h == 12 {
+			h = 0
+		}
+	case "pm":
+		if h < 12 {
+			h += 12
+		}
+	}
+
+	if h > 23 || mi > 59 {
+		return 0
+	}
+
+	p.setTime(h, mi)
+	return consumed
+}
+
+func (p *parser) setDate(t time.Time) {
+	p.date = &t
+}
+
+func (p *parser) setTime(h, m int) {
+	p.hour = h
+	p.minute = m
+	p.hasTime = true
+}
+
+// makeDate builds date in user location, year 0 means the nearest future date
+func (p *parser) makeDate(y int, mo time.Month, d int) (time.Time, bool) {
+	if mo < time.January || mo > time.December || d < 1 || d > 31 {
+		return time.Time{}, false
+	}
+
+	year := y
+	if year == 0 {
+		year = p.now.Year()
+	}
+
+	t := time.Date(year, mo, d, 0, 0, 0, 0, p.now.Location())
+	if t.Day() != d {
+		return time.Time{}, false
+	}
+	if y == 0 && t.Before(startOfDay(p.now)) {
+		t = t.AddDate(1, 0, 0)
+	}
+	return t, true
+}
+
+func (p *parser) resolveDue() {
+	switch {
+	case p.date != nil && p.hasTime:
+		d := *p.date
+		due := time.Date(d.Year(), d.Month(), d.Day(), p.hour, p.minute, 0, 0, p.now.Location())
+		p.res.Due = &due
+	case p.date != nil:
+		d := *p.date
+		due := time.Date(d.Year(), d.Month(), d.Day(), 23, 59, 59, 0, p.now.Location())
+		p.res.Due = &due
+		p.res.AllDay = true
+	case p.hasTime:
+		// only time: today if it is still ahead, otherwise tomorrow
+		due := time.Date(p.now.Year(), p.now.Month(), p.now.Day(), p.hour, p.minute, 0, 0, p.now.Location())
+		if !due.After(p.now) {
+			due = due.AddDate(0, 0, 1)
+		}
+		p.res.Due = &due
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// nextWeekday returns the closest future day with the weekday,
+// with next the day is taken from the following (Monday based) week
+func nextWeekday(now time.Time, wd time.Weekday, next bool) time.Time {
+	today := startOfDay(now)
+	if next {
+		monday := today.AddDate(0, 0, 7-mondayOffset(today.Weekday()))
+		return monday.AddDate(0, 0, mondayOffset(wd))
+	}
+
+	days := (int(wd) - int(today.Weekday()) + 7) % 7
+	if days == 0 {
+		days = 7
+	}
+	return today.AddDate(0, 0, days)
+}
+
+func mondayOffset(wd time.Weekday) int {
+	return (int(wd) + 6) % 7
+}
+
+func addUnit(t time.Time, u unit, n int) time.Time {
+	switch u {
+	case unitWeek:
+		return t.AddDate(0, 0, 7*n)
+	case unitMonth:
+		return t.AddDate(0, n, 0)
+	case unitYear:
+		return t.AddDate(n, 0, 0)
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
+
+func normalize(w string) string {
+	w = strings.ToLower(w)
+	return strings.TrimFunc(w, func(r rune) bool {
+		return unicode.IsPunct(r) && r != '#' && r != '!' && r != ':' && r != '@'
+	})
+}
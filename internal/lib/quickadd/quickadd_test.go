@@ -0,0 +1,105 @@
+package quickadd
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// wednesday, 12 march 2025, 10:00 in the user timezone
+var now = time.Date(2025, time.March, 12, 10, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+func at(month time.Month, day, hour, minute, sec int) *time.Time {
+	t := time.Date(2025, month, day, hour, minute, sec, 0, now.Location())
+	return &t
+}
+
+func allDay(month time.Month, day int) *time.Time {
+	return at(month, day, 23, 59, 59)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		line string
+		want Result
+	}{
+		// english
+		{"Pay rent tomorrow 9am #home !high every month", Result{
+			Title: "Pay rent", Due: at(time.March, 13, 9, 0, 0), Tags: []string{"home"},
+			Priority: PriorityHigh, Recurrence: "FREQ=MONTHLY",
+		}},
+		{"report by friday", Result{Title: "report", Due: allDay(time.March, 14), AllDay: true}},
+		{"submit report by tomorrow", Result{Title: "submit report", Due: allDay(time.March, 13), AllDay: true}},
+		{"pay taxes due 2025-04-15", Result{Title: "pay taxes", Due: allDay(time.April, 15), AllDay: true}},
+		{"gym in 2 weeks", Result{Title: "gym", Due: allDay(time.March, 26), AllDay: true}},
+		{"dentist next friday 3pm", Result{Title: "dentist", Due: at(time.March, 21, 15, 0, 0)}},
+		{"standup every monday 10:00", Result{
+			Title: "standup", Due: at(time.March, 17, 10, 0, 0), Recurrence: "FREQ=WEEKLY;BYDAY=MO",
+		}},
+		{"review every 2 weeks", Result{Title: "review", Recurrence: "FREQ=WEEKLY;INTERVAL=2"}},
+		{"lunch at noon", Result{Title: "lunch", Due: at(time.March, 12, 12, 0, 0)}},
+		{"wake up 7am", Result{Title: "wake up", Due: at(time.March, 13, 7, 0, 0)}},
+		{"party 12am", Result{Title: "party", Due: at(time.March, 13, 0, 0, 0)}},
+		{"call at 0am", Result{Title: "call at 0am"}},
+		{"call 13pm", Result{Title: "call 13pm"}},
+		{"buy milk !! #Shop #shop", Result{Title: "buy milk", Priority: PriorityMedium, Tags: []string{"shop"}}},
+		{"read chapter 10", Result{Title: "read chapter 10"}},
+
+		// russian
+		{"Купить молоко завтра в 9 утра #дом", Result{
+			Title: "Купить молоко", Due: at(time.March, 13, 9, 0, 0), Tags: []string{"дом"},
+		}},
+		{"отчёт до пятницы", Result{Title: "отчёт", Due: allDay(time.March, 14), AllDay: true}},
+		{"к пятнице презентация", Result{Title: "презентация", Due: allDay(time.March, 14), AllDay: true}},
+		{"созвон через 2 недели", Result{Title: "созвон", Due: allDay(time.March, 26), AllDay: true}},
+		{"зарядка каждый понедельник", Result{
+			Title: "зарядка", Due: allDay(time.March, 17), AllDay: true, Recurrence: "FREQ=WEEKLY;BYDAY=MO",
+		}},
+		{"встреча в 2 дня", Result{Title: "встреча", Due: at(time.March, 12, 14, 0, 0)}},
+		{"отчёт 15.04 !срочно", Result{Title: "отчёт", Due: allDay(time.April, 15), AllDay: true, Priority: PriorityHigh}},
+		{"поздравить 10 апреля ежегодно", Result{
+			Title: "поздравить", Due: allDay(time.April, 10), AllDay: true, Recurrence: "FREQ=YEARLY",
+		}},
+		{"оплатить интернет ежемесячно", Result{Title: "оплатить интернет", Recurrence: "FREQ=MONTHLY"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := Parse(tt.line, now)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			if got.Title != tt.want.Title {
+				t.Errorf("Title = %q, want %q", got.Title, tt.want.Title)
+			}
+			switch {
+			case got.Due == nil && tt.want.Due != nil, got.Due != nil && tt.want.Due == nil:
+				t.Errorf("Due = %v, want %v", got.Due, tt.want.Due)
+			case got.Due != nil && !got.Due.Equal(*tt.want.Due):
+				t.Errorf("Due = %v, want %v", *got.Due, *tt.want.Due)
+			}
+			if got.AllDay != tt.want.AllDay {
+				t.Errorf("AllDay = %v, want %v", got.AllDay, tt.want.AllDay)
+			}
+			if !slices.Equal(got.Tags, tt.want.Tags) {
+				t.Errorf("Tags = %v, want %v", got.Tags, tt.want.Tags)
+			}
+			if got.Priority != tt.want.Priority {
+				t.Errorf("Priority = %q, want %q", got.Priority, tt.want.Priority)
+			}
+			if got.Recurrence != tt.want.Recurrence {
+				t.Errorf("Recurrence = %q, want %q", got.Recurrence, tt.want.Recurrence)
+			}
+		})
+	}
+}
+
+func TestParseEmptyTitle(t *testing.T) {
+	for _, line := range []string{"", "   ", "#home tomorrow", "завтра !!!"} {
+		if _, err := Parse(line, now); !errors.Is(err, ErrEmptyTitle) {
+			t.Errorf("Parse(%q) error = %v, want %v", line, err, ErrEmptyTitle)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Package txmanager runs several storage calls in one transaction, the transaction is carried
+// in the context so repositories pick it up without changing their signatures
+package txmanager
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	maxRetries    = 8
+	retryDelay    = 10 * time.Millisecond
+	maxRetryDelay = 500 * time.Millisecond
+)
+
+// Querier is implemented by *sql.DB, *sql.Tx and Tx, repositories run their queries on it
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// Manager is bound to one database, transactions of another manager in the context are not joined
+type Manager struct {
+	db *sql.DB
+	// retryable reports whether the transaction failed only because of concurrent writers
+	retryable func(err error) bool
+	// opts are used by transactions of WithinTx, transactions of Begin keep the default of the database
+	opts *sql.TxOptions
+}
+
+type Option func(m *Manager)
+
+// WithIsolation begins transactions of WithinTx with the isolation level, a transaction failed
+// by a concurrent one is run again when retryable reports the error
+func WithIsolation(level sql.IsolationLevel) Option {
+	return func(m *Manager) {
+		m.opts = &sql.TxOptions{Isolation: level}
+	}
+}
+
+type ctxKey struct {
+	m *Manager
+}
+
+// txState is shared by the transaction and its savepoints, sql.Tx must not be used concurrently
+// so fn given to WithinTx must not pass its ctx to other goroutines
+type txState struct {
+	tx         *sql.Tx
+	savepoints int
+	// afterCommit run when the outermost transaction is committed
+	afterCommit []func(ctx context.Context)
+}
+
+func New(db *sql.DB, retryable func(err error) bool, opts ...Option) *Manager {
+	m := &Manager{db: db, retryable: retryable}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Conn returns the transaction from the context or the database itself
+func (m *Manager) Conn(ctx context.Context) Querier {
+	if st := m.state(ctx); st != nil {
+		return st.tx
+	}
+	return m.db
+}
+
+// InTx reports whether the context carries a transaction of the manager
+func (m *Manager) InTx(ctx context.Context) bool {
+	return m.state(ctx) != nil
+}
+
+// Stmt binds the statement prepared on the database to the transaction from the context
+func (m *Manager) Stmt(ctx context.Context, stmt *sql.Stmt) *sql.Stmt {
+	if st := m.state(ctx); st != nil {
+		return st.tx.StmtContext(ctx, stmt)
+	}
+	return stmt
+}
+
+// AfterCommit runs fn once the transaction from the context is committed, with the context
+// the transaction was started with. fn is dropped when the transaction or its savepoint is rolled back,
+// outside of a transaction fn runs at once
+func (m *Manager) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if st := m.state(ctx); st != nil {
+		st.afterCommit = append(st.afterCommit, fn)
+		return
+	}
+	fn(ctx)
+}
+
+func (m *Manager) state(ctx context.Context) *txState {
+	st, _ := ctx.Value(ctxKey{m}).(*txState)
+	return st
+}
+
+// WithinTx runs fn in a transaction, storage calls made with the ctx given to fn join it.
+// Inside another transaction fn runs in a savepoint, so its failure does not roll back the outer work.
+// The outermost transaction is run again when it fails because the database is busy
+func (m *Manager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.state(ctx) != nil {
+		return m.run(ctx, fn)
+	}
+
+	delay := retryDelay
+	for attempt := 0; ; attempt++ {
+		err := m.run(ctx, fn)
+		if err == nil || attempt == maxRetries || m.retryable == nil || !m.retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		// jitter spreads writers which failed together, otherwise they collide again
+		case <-time.After(delay/2 + rand.N(delay/2)):
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+func (m *Manager) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := m.begin(ctx, m.opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err = fn(tx.ctx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Tx is a transaction or a savepoint of the transaction found in the context
+type Tx struct {
+	Querier
+
+	ctx context.Context
+	// parent is the context the transaction was started with, after commit hooks get it
+	parent    context.Context
+	state     *txState
+	savepoint string
+	// hooks is the number of after commit hooks registered before the savepoint
+	hooks int
+	done  bool
+}
+
+// Begin starts a transaction, inside another transaction it creates a savepoint instead.
+// Rollback after Commit does nothing, so it can be deferred
+func (m *Manager) Begin(ctx context.Context) (*Tx, error) {
+	return m.begin(ctx, nil)
+}
+
+func (m *Manager) begin(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	if st := m.state(ctx); st != nil {
+		st.savepoints++
+		name := fmt.Sprintf("sp_%d", st.savepoints)
+		if _, err := st.tx.ExecContext(ctx, `SAVEPOINT `+name); err != nil {
+			return nil, fmt.Errorf("failed create savepoint: %w", err)
+		}
+		return &Tx{Querier: st.tx, ctx: ctx, parent: ctx, state: st, savepoint: name, hooks: len(st.afterCommit)}, nil
+	}
+
+	tx, err := m.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed begin tx: %w", err)
+	}
+	st := &txState{tx: tx}
+
+	return &Tx{Querier: tx, ctx: context.WithValue(ctx, ctxKey{m}, st), parent: ctx, state: st}, nil
+}
+
+// Stmt binds the statement prepared on the database to the transaction, it is closed with the transaction
+func (t *Tx) Stmt(ctx context.Context, stmt *sql.Stmt) *sql.Stmt {
+	return t.state.tx.StmtContext(ctx, stmt)
+}
+
+func (t *Tx) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+
+	if t.savepoint != "" {
+		_, err := t.state.tx.ExecContext(t.ctx, `RELEASE SAVEPOINT `+t.savepoint)
+		return err
+	}
+	if err := t.state.tx.Commit(); err != nil {
+		return err
+	}
+
+	hooks := t.state.afterCommit
+	t.state.afterCommit = nil
+	for _, fn := range hooks {
+		fn(t.parent)
+	}
+	return nil
+}
+
+func (t *Tx) Rollback() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+
+	if t.savepoint != "" {
+		t.state.afterCommit = t.state.afterCommit[:t.hooks]
+		// ROLLBACK TO keeps the savepoint open, it is released so the outer transaction can go on
+		if _, err := t.state.tx.ExecContext(t.ctx, `ROLLBACK TO SAVEPOINT `+t.savepoint); err != nil {
+			return err
+		}
+		_, err := t.state.tx.ExecContext(t.ctx, `RELEASE SAVEPOINT `+t.savepoint)
+		return err
+	}
+	return t.state.tx.Rollback()
+}
@@ -0,0 +1,113 @@
+package txmanager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	_ "github.com/mattn/go-sqlite3"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "tx.db"))
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return New(db, nil)
+}
+
+func TestAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	errFail := errors.New("fail")
+
+	tests := []struct {
+		name string
+		fn   func(m *Manager, record func(string)) error
+		want []string
+	}{
+		{
+			name: "outside of transaction runs at once",
+			fn: func(m *Manager, record func(string)) error {
+				m.AfterCommit(ctx, func(context.Context) { record("now") })
+				return nil
+			},
+			want: []string{"now"},
+		},
+		{
+			name: "runs after commit in order",
+			fn: func(m *Manager, record func(string)) error {
+				return m.WithinTx(ctx, func(ctx context.Context) error {
+					m.AfterCommit(ctx, func(context.Context) { record("first") })
+					record("in tx")
+					m.AfterCommit(ctx, func(context.Context) { record("second") })
+					return nil
+				})
+			},
+			want: []string{"in tx", "first", "second"},
+		},
+		{
+			name: "dropped on rollback",
+			fn: func(m *Manager, record func(string)) error {
+				return m.WithinTx(ctx, func(ctx context.Context) error {
+					m.AfterCommit(ctx, func(context.Context) { record("rolled back") })
+					return errFail
+				})
+			},
+			want: nil,
+		},
+		{
+			name: "dropped with rolled back savepoint only",
+			fn: func(m *Manager, record func(string)) error {
+				return m.WithinTx(ctx, func(ctx context.Context) error {
+					m.AfterCommit(ctx, func(context.Context) { record("outer") })
+					_ = m.WithinTx(ctx, func(ctx context.Context) error {
+						m.AfterCommit(ctx, func(context.Context) { record("savepoint") })
+						return errFail
+					})
+					return m.WithinTx(ctx, func(ctx context.Context) error {
+						m.AfterCommit(ctx, func(context.Context) { record("released") })
+						return nil
+					})
+				})
+			},
+			want: []string{"outer", "released"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(t)
+
+			var got []string
+			_ = tt.fn(m, func(s string) { got = append(got, s) })
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ran %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// hooks get the context of the caller, the committed transaction must not be used by them
+func TestAfterCommitContext(t *testing.T) {
+	m := newTestManager(t)
+
+	var inTx bool
+	err := m.WithinTx(context.Background(), func(ctx context.Context) error {
+		m.AfterCommit(ctx, func(ctx context.Context) { inTx = m.InTx(ctx) })
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTx() error = %v", err)
+	}
+	if inTx {
+		t.Error("after commit hook got the context of the committed transaction")
+	}
+}
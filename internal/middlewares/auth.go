@@ -2,7 +2,9 @@ package middlewares
 
 import (
 	"TaskList/internal/lib/jwt"
+	"TaskList/internal/models"
 	"context"
+	"errors"
 	"github.com/go-chi/render"
 	"net/http"
 	"strings"
@@ -17,7 +19,12 @@ type Key string
 
 const KeyClaims Key = "claims"
 
-func AuthJWT(secretKey string) func(next http.Handler) http.Handler {
+// ActiveChecker tells whether the user of a valid token may still use it, implemented by auth service
+type ActiveChecker interface {
+	CheckActive(ctx context.Context, userID int64) error
+}
+
+func AuthJWT(secretKey string, users ActiveChecker) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			//get token
@@ -44,6 +51,18 @@ func AuthJWT(secretKey string) func(next http.Handler) http.Handler {
 				return
 			}
 
+			// the token outlives disabling of the user, so the user is checked on every request
+			if err = users.CheckActive(r.Context(), claims.UID); err != nil {
+				if errors.Is(err, models.ErrUserDisabled) || errors.Is(err, models.ErrUserNotFound) {
+					render.Status(r, http.StatusUnauthorized)
+					render.JSON(w, r, err.Error())
+					return
+				}
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, "internal error")
+				return
+			}
+
 			//add claims in context
 			r = r.WithContext(context.WithValue(r.Context(), KeyClaims, claims))
 
@@ -51,3 +70,51 @@ func AuthJWT(secretKey string) func(next http.Handler) http.Handler {
 		})
 	}
 }
+
+// TokenFromQuery lets clients which can not set headers, like browser WebSocket,
+// pass the JWT as ?access_token= for AuthJWT
+func TokenFromQuery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("access_token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", prefix+token)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type BasicAuthenticator interface {
+	AuthenticateAppPassword(ctx context.Context, email string, password string) (*models.User, error)
+}
+
+// BasicAuth authenticates clients which support only Basic auth by email and app password,
+// the user is put into the context as claims like AuthJWT does
+func BasicAuth(a BasicAuthenticator, realm string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			email, password, ok := r.BasicAuth()
+			if !ok {
+				unauthorized(w, realm)
+				return
+			}
+
+			user, err := a.AuthenticateAppPassword(r.Context(), email, password)
+			if err != nil {
+				if errors.Is(err, models.ErrInvalidCredentials) {
+					unauthorized(w, realm)
+					return
+				}
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), KeyClaims, &jwt.CustomClaims{UID: user.ID}))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func unauthorized(w http.ResponseWriter, realm string) {
+	w.Header().Set("WWW-Authenticate", `Basic realm="`+realm+`", charset="UTF-8"`)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
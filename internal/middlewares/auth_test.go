@@ -0,0 +1,74 @@
+package middlewares
+
+import (
+	"TaskList/internal/lib/jwt"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var secret = []byte("secret")
+
+// users maps user id to the result of CheckActive, unknown users are active
+type users map[int64]error
+
+func (u users) CheckActive(ctx context.Context, userID int64) error {
+	return u[userID]
+}
+
+func TestAuthJWT(t *testing.T) {
+	checker := users{
+		2: models.ErrUserDisabled,
+		3: models.ErrUserNotFound,
+		4: errors.New("database is closed"),
+	}
+
+	token := func(userID int64, ttl time.Duration) string {
+		s, err := jwt.NewToken(models.User{ID: userID, Email: "me@example.com"}, ttl, secret)
+		if err != nil {
+			t.Fatalf("NewToken() error = %v", err)
+		}
+		return "Bearer " + s
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		code   int
+	}{
+		{"active user", token(1, time.Hour), http.StatusOK},
+		{"disabled user", token(2, time.Hour), http.StatusUnauthorized},
+		{"deleted user", token(3, time.Hour), http.StatusUnauthorized},
+		{"check failed", token(4, time.Hour), http.StatusInternalServerError},
+		{"no header", "", http.StatusUnauthorized},
+		{"foreign signature", "Bearer " + func() string {
+			s, _ := jwt.NewToken(models.User{ID: 1}, time.Hour, []byte("other"))
+			return s
+		}(), http.StatusUnauthorized},
+	}
+
+	handler := AuthJWT(string(secret), checker)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(KeyClaims).(*jwt.CustomClaims); !ok {
+			t.Error("claims are not in the context")
+		}
+	}))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, r)
+
+			if rec.Code != tt.code {
+				t.Errorf("status = %d, want %d, body %s", rec.Code, tt.code, rec.Body)
+			}
+		})
+	}
+}
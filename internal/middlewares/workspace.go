@@ -0,0 +1,57 @@
+package middlewares
+
+import (
+	"TaskList/internal/lib/http/response"
+	"TaskList/internal/lib/jwt"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"net/http"
+	"strconv"
+)
+
+const KeyMember Key = "workspace_member"
+
+type WorkspaceAuthorizer interface {
+	Authorize(ctx context.Context, userID int64, workspaceID int64, action models.Action) (models.WorkspaceMember, error)
+}
+
+// WorkspaceAccess checks the role of the user in the workspace from {id} path parameter allows the action
+// and puts the membership into the context, it must run after AuthJWT
+func WorkspaceAccess(a WorkspaceAuthorizer, action models.Action) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			workspaceID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+			if err != nil {
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid id"))
+				return
+			}
+
+			claims := r.Context().Value(KeyClaims).(*jwt.CustomClaims)
+
+			member, err := a.Authorize(r.Context(), claims.UID, workspaceID, action)
+			switch {
+			case err == nil:
+			case errors.Is(err, models.ErrWorkspaceNotFound):
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, response.Error("workspace not found"))
+				return
+			case errors.Is(err, models.ErrForbidden):
+				render.Status(r, http.StatusForbidden)
+				render.JSON(w, r, response.Error("access denied"))
+				return
+			default:
+				render.Status(r, http.StatusInternalServerError)
+				render.JSON(w, r, response.Error("internal error"))
+				return
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), KeyMember, member))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+)
+
+var (
+	ErrCalendarNotFound       = errors.New("calendar not found")
+	ErrCalendarObjectNotFound = errors.New("calendar object not found")
+	ErrInvalidCalendarObject  = errors.New("invalid calendar object")
+	ErrPreconditionFailed     = errors.New("precondition failed")
+)
+
+// InboxCalendar is the calendar of tasks without project
+const InboxCalendar = "inbox"
+
+// Calendar is a CalDAV collection, every project is a calendar and tasks without project are in the inbox.
+// CTag changes whenever any task of the calendar changes
+type Calendar struct {
+	ID        string
+	Name      string
+	ProjectID *int64
+	CTag      string
+}
+
+// CalendarObject is a task as VTODO resource, Name is the last segment of its URL
+type CalendarObject struct {
+	Name string
+	Task Task
+	Data []byte
+	ETag string
+}
+
+// CalendarObjectName is the resource name and UID chosen by the client which created the task,
+// tasks without it are named after their id
+type CalendarObjectName struct {
+	TaskID int64
+	Name   string
+	UID    string
+}
+
+// Preconditions are If-Match and If-None-Match of the request, "*" matches any existing resource
+type Preconditions struct {
+	IfMatch     string
+	IfNoneMatch string
+}
+
+// Check verifies the preconditions against the ETag of the resource, empty ETag means the resource does not exist
+func (p Preconditions) Check(etag string) error {
+	if p.IfNoneMatch != "" && etag != "" && (p.IfNoneMatch == "*" || p.IfNoneMatch == etag) {
+		return ErrPreconditionFailed
+	}
+	if p.IfMatch != "" && (etag == "" || p.IfMatch != "*" && p.IfMatch != etag) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
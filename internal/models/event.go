@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+type EventType string
+
+const (
+	EventTaskCreated       EventType = "task.created"
+	EventTaskUpdated       EventType = "task.updated"
+	EventTaskStatusChanged EventType = "task.status_changed"
+	EventTaskDeleted       EventType = "task.deleted"
+	EventTaskAssigned      EventType = "task.assigned"
+	EventTaskCommented     EventType = "task.commented"
+	// EventTaskMentioned is not published, it is the notification of users mentioned in a description or comment
+	EventTaskMentioned EventType = "task.mentioned"
+)
+
+// EventTypes are all event types users can be notified about
+func EventTypes() []EventType {
+	return []EventType{
+		EventTaskCreated,
+		EventTaskUpdated,
+		EventTaskStatusChanged,
+		EventTaskDeleted,
+		EventTaskAssigned,
+		EventTaskCommented,
+		EventTaskMentioned,
+	}
+}
+
+func (t EventType) Valid() bool {
+	for _, v := range EventTypes() {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskEvent is published by services after a task change is stored
+type TaskEvent struct {
+	Type    EventType
+	Task    Task
+	ActorID int64
+	// PreviousStatus is set for status changes
+	PreviousStatus Status
+	// PreviousDescription is set for updates which changed the description
+	PreviousDescription *string
+	Comment             *Comment
+	OccurredAt          time.Time
+}
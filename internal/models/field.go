@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type FieldType string
+
+var (
+	FieldText     FieldType = "text"
+	FieldNumber   FieldType = "number"
+	FieldDate     FieldType = "date"
+	FieldSelect   FieldType = "select"
+	FieldCheckbox FieldType = "checkbox"
+	FieldUser     FieldType = "user"
+)
+
+var (
+	ErrFieldNotFound      = errors.New("custom field not found")
+	ErrFieldAlreadyExists = errors.New("custom field already exists")
+	ErrInvalidFieldType   = errors.New("invalid custom field type")
+	ErrInvalidFieldValue  = errors.New("invalid custom field value")
+)
+
+// CustomField is a project scoped definition of an extra task attribute
+type CustomField struct {
+	ID        int64
+	UserID    int64
+	ProjectID int64
+	Name      string
+	Type      FieldType
+	// Options are allowed values of select field
+	Options   []string
+	Position  int
+	CreatedAt time.Time
+}
+
+// FieldValue is a value of the custom field stored in canonical text form:
+// numbers are formatted with strconv, dates as 2006-01-02, checkboxes as true/false, users as id
+type FieldValue struct {
+	FieldID int64
+	Name    string
+	Type    FieldType
+	Value   string
+}
+
+func (t FieldType) Valid() bool {
+	switch t {
+	case FieldText, FieldNumber, FieldDate, FieldSelect, FieldCheckbox, FieldUser:
+		return true
+	default:
+		return false
+	}
+}
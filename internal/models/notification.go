@@ -0,0 +1,48 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrEmptyComment         = errors.New("comment is empty")
+	ErrInvalidComment       = errors.New("invalid comment")
+	ErrInvalidEventType     = errors.New("invalid event type")
+)
+
+type Comment struct {
+	ID        int64
+	TaskID    int64
+	UserID    int64
+	Email     string
+	Body      string
+	CreatedAt time.Time
+}
+
+type Notification struct {
+	ID        int64
+	UserID    int64
+	TaskID    int64
+	ActorID   int64
+	Event     EventType
+	Message   string
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
+
+// NotificationPreferences tells which events notify the user, missing events are enabled
+type NotificationPreferences map[EventType]bool
+
+func (p NotificationPreferences) Enabled(t EventType) bool {
+	enabled, ok := p[t]
+	return !ok || enabled
+}
+
+type Watcher struct {
+	TaskID    int64
+	UserID    int64
+	Email     string
+	CreatedAt time.Time
+}
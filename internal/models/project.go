@@ -0,0 +1,20 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrProjectNotFound = errors.New("project not found")
+)
+
+// Project belongs to UserID, projects of a workspace belong to the workspace owner
+type Project struct {
+	ID          int64
+	UserID      int64
+	WorkspaceID *int64
+	Name        string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrForbidden         = errors.New("access denied")
+	ErrShareNotFound     = errors.New("share not found")
+	ErrInvalidPermission = errors.New("invalid permission")
+	ErrShareWithOwner    = errors.New("task can not be shared with its owner")
+	ErrAssigneeNoAccess  = errors.New("assignee has no access to the task")
+)
+
+// Permission is access level of the user to the task, owner is not stored and belongs to tasks.user_id
+type Permission string
+
+const (
+	PermissionNone   Permission = ""
+	PermissionViewer Permission = "viewer"
+	PermissionEditor Permission = "editor"
+	PermissionOwner  Permission = "owner"
+)
+
+var permissionRank = map[Permission]int{
+	PermissionNone:   0,
+	PermissionViewer: 1,
+	PermissionEditor: 2,
+	PermissionOwner:  3,
+}
+
+// Valid reports whether the permission can be granted by sharing
+func (p Permission) Valid() bool {
+	return p == PermissionViewer || p == PermissionEditor
+}
+
+// Allows reports whether the permission includes required one
+func (p Permission) Allows(required Permission) bool {
+	return permissionRank[p] >= permissionRank[required]
+}
+
+type TaskShare struct {
+	TaskID     int64
+	UserID     int64
+	Email      string
+	Permission Permission
+	CreatedAt  time.Time
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrSmartListNotFound = errors.New("smart list not found")
+	ErrSmartListReadOnly = errors.New("built-in smart list can not be changed")
+)
+
+// SmartList is a named saved filter, built-in lists have Key instead of ID
+type SmartList struct {
+	ID        int64
+	Key       string
+	UserID    int64
+	Name      string
+	Filter    TaskFilter
+	BuiltIn   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+var activeCategories = []StatusCategory{CategoryTodo, CategoryInProgress}
+
+// BuiltInSmartLists are available for every user
+func BuiltInSmartLists() []SmartList {
+	return []SmartList{
+		{
+			Key:     "today",
+			Name:    "Today",
+			BuiltIn: true,
+			Filter:  TaskFilter{Due: DueToday, Categories: activeCategories, Sort: "due"},
+		},
+		{
+			Key:     "upcoming",
+			Name:    "Upcoming",
+			BuiltIn: true,
+			Filter:  TaskFilter{Due: DueUpcoming, DueDays: DefaultUpcomingDays, Categories: activeCategories, Sort: "due"},
+		},
+		{
+			Key:     "overdue",
+			Name:    "Overdue",
+			BuiltIn: true,
+			Filter:  TaskFilter{Due: DueOverdue, Categories: activeCategories, Sort: "due"},
+		},
+		{
+			Key:     "assigned",
+			Name:    "Assigned to me",
+			BuiltIn: true,
+			Filter:  TaskFilter{Scope: ScopeAssigned, Categories: activeCategories, Sort: "due"},
+		},
+	}
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"errors"
+)
+
+var (
+	ErrInvalidSyncToken = errors.New("invalid sync token")
+	ErrInvalidSyncOp    = errors.New("invalid sync operation")
+	ErrSyncClientExists = errors.New("client id is already mapped to a task")
+)
+
+// TaskField is a field of the task versioned for sync
+type TaskField string
+
+const (
+	TaskFieldTitle        TaskField = "title"
+	TaskFieldDescription  TaskField = "description"
+	TaskFieldStatus       TaskField = "status"
+	TaskFieldPriority     TaskField = "priority"
+	TaskFieldTags         TaskField = "tags"
+	TaskFieldRecurrence   TaskField = "recurrence"
+	TaskFieldDue          TaskField = "due"
+	TaskFieldAssignee     TaskField = "assignee"
+	TaskFieldProject      TaskField = "project"
+	TaskFieldCustomFields TaskField = "custom_fields"
+)
+
+func TaskFields() []TaskField {
+	return []TaskField{
+		TaskFieldTitle,
+		TaskFieldDescription,
+		TaskFieldStatus,
+		TaskFieldPriority,
+		TaskFieldTags,
+		TaskFieldRecurrence,
+		TaskFieldDue,
+		TaskFieldAssignee,
+		TaskFieldProject,
+		TaskFieldCustomFields,
+	}
+}
+
+// Fields returns the task fields set by the patch
+func (p TaskPatch) Fields() []TaskField {
+	var fields []TaskField
+	if p.Title != nil {
+		fields = append(fields, TaskFieldTitle)
+	}
+	if p.Description != nil {
+		fields = append(fields, TaskFieldDescription)
+	}
+	if p.Priority != nil {
+		fields = append(fields, TaskFieldPriority)
+	}
+	if p.Tags != nil {
+		fields = append(fields, TaskFieldTags)
+	}
+	if p.Recurrence != nil {
+		fields = append(fields, TaskFieldRecurrence)
+	}
+	if p.DueAt != nil || p.ClearDue {
+		fields = append(fields, TaskFieldDue)
+	}
+	return fields
+}
+
+// Without returns the patch which does not change the field
+func (p TaskPatch) Without(field TaskField) TaskPatch {
+	switch field {
+	case TaskFieldTitle:
+		p.Title = nil
+	case TaskFieldDescription:
+		p.Description = nil
+	case TaskFieldPriority:
+		p.Priority = nil
+	case TaskFieldTags:
+		p.Tags = nil
+	case TaskFieldRecurrence:
+		p.Recurrence = nil
+	case TaskFieldDue:
+		p.DueAt = nil
+		p.ClearDue = false
+	}
+	return p
+}
+
+// TaskChange is the latest change of the task after the sync token,
+// Version is the sequence number of the change in the change log
+type TaskChange struct {
+	TaskID  int64
+	Version int64
+	Deleted bool
+	// AccessChanged are users the task was shared with or unshared from
+	AccessChanged []int64
+}
+
+// TaskVersions are versions of the task fields, a field version is the sequence number of its last change
+type TaskVersions map[TaskField]int64
+
+// Version is the version of the whole task
+func (v TaskVersions) Version() int64 {
+	var max int64
+	for _, version := range v {
+		if version > max {
+			max = version
+		}
+	}
+	return max
+}
+
+type SyncOp string
+
+const (
+	SyncOpCreate SyncOp = "create"
+	SyncOpUpdate SyncOp = "update"
+	SyncOpDelete SyncOp = "delete"
+)
+
+// SyncChange is a change made on the client while it was offline.
+// Versions are the field versions the client saw when it changed the fields,
+// BaseVersion is the task version the client saw when it deleted the task
+type SyncChange struct {
+	Op SyncOp
+	// ClientID identifies created task on the client, creating with the same id twice returns the first task
+	ClientID    string
+	TaskID      int64
+	Task        Task
+	Patch       TaskPatch
+	Status      *Status
+	Versions    TaskVersions
+	BaseVersion int64
+}
+
+type SyncResultStatus string
+
+const (
+	SyncApplied  SyncResultStatus = "applied"
+	SyncConflict SyncResultStatus = "conflict"
+	SyncRejected SyncResultStatus = "rejected"
+)
+
+// SyncResult is the outcome of the client change, Conflicts are fields where
+// the server value was kept because the field changed after the client version
+type SyncResult struct {
+	ClientID  string
+	TaskID    int64
+	Status    SyncResultStatus
+	Conflicts []TaskField
+	Err       error
+}
+
+type SyncTask struct {
+	Task     Task
+	Versions TaskVersions
+}
+
+type Tombstone struct {
+	TaskID  int64
+	Version int64
+}
+
+// Sync is the server state after the client token, Token is passed to the next sync.
+// HasMore is set when the changes are paged and the client should sync again right away
+type Sync struct {
+	Token      string
+	HasMore    bool
+	Tasks      []SyncTask
+	Tombstones []Tombstone
+	Results    []SyncResult
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -12,16 +14,209 @@ var (
 	Done    Status = "Done"
 )
 
+type Priority string
+
 var (
-	ErrTaskNotFound = errors.New("task not found")
+	PriorityNone   Priority = ""
+	PriorityLow    Priority = "low"
+	PriorityMedium Priority = "medium"
+	PriorityHigh   Priority = "high"
 )
 
+func (p Priority) Valid() bool {
+	switch p {
+	case PriorityNone, PriorityLow, PriorityMedium, PriorityHigh:
+		return true
+	}
+	return false
+}
+
+var (
+	ErrTaskNotFound   = errors.New("task not found")
+	ErrInvalidFilter  = errors.New("invalid filter")
+	ErrInvalidTask    = errors.New("invalid task")
+	ErrNothingToApply = errors.New("nothing to update")
+)
+
+// TaskPatch changes the set fields of the task, ClearDue removes the due date
+type TaskPatch struct {
+	Title       *string
+	Description *string
+	Priority    *Priority
+	Tags        *[]string
+	Recurrence  *string
+	DueAt       *time.Time
+	ClearDue    bool
+}
+
+func (p TaskPatch) Empty() bool {
+	return p.Title == nil && p.Description == nil && p.Priority == nil && p.Tags == nil &&
+		p.Recurrence == nil && p.DueAt == nil && !p.ClearDue
+}
+
+// Apply returns the task with the patch applied
+func (p TaskPatch) Apply(t Task) (Task, error) {
+	if p.Title != nil {
+		title := strings.TrimSpace(*p.Title)
+		if title == "" {
+			return Task{}, fmt.Errorf("%w: title is empty", ErrInvalidTask)
+		}
+		t.Title = title
+	}
+	if p.Description != nil {
+		t.Description = *p.Description
+	}
+	if p.Priority != nil {
+		if !p.Priority.Valid() {
+			return Task{}, fmt.Errorf("%w: unknown priority %s", ErrInvalidTask, *p.Priority)
+		}
+		t.Priority = *p.Priority
+	}
+	if p.Tags != nil {
+		t.Tags = *p.Tags
+	}
+	if p.Recurrence != nil {
+		t.Recurrence = *p.Recurrence
+	}
+	switch {
+	case p.ClearDue:
+		t.DueAt = nil
+	case p.DueAt != nil:
+		due := p.DueAt.UTC()
+		t.DueAt = &due
+	}
+	return t, nil
+}
+
 type Task struct {
-	ID          int64
-	UserID      int64
+	ID int64
+	// UserID is the task owner
+	UserID int64
+	// CreatedBy differs from the owner for tasks created by workspace members
+	CreatedBy  int64
+	AssigneeID *int64
+	ProjectID  *int64
+	// WorkspaceID is resolved from the project
+	WorkspaceID *int64
 	Title       string
 	Description string
 	Status      Status
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	// StatusCategory is resolved from the workflow the status belongs to
+	StatusCategory StatusCategory
+	Priority       Priority
+	Tags           []string
+	// Recurrence is RRULE value, e.g. FREQ=MONTHLY
+	Recurrence   string
+	CustomFields []FieldValue
+	DueAt        *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type DueWindow string
+
+var (
+	DueAny      DueWindow = ""
+	DueOverdue  DueWindow = "overdue"
+	DueToday    DueWindow = "today"
+	DueUpcoming DueWindow = "upcoming"
+	DueNone     DueWindow = "none"
+	DueRange    DueWindow = "range"
+)
+
+// DefaultUpcomingDays is used for upcoming window without explicit number of days
+const DefaultUpcomingDays = 7
+
+// TaskScope selects which tasks are listed relative to the user
+type TaskScope string
+
+var (
+	ScopeOwned    TaskScope = ""
+	ScopeAssigned TaskScope = "assigned"
+	ScopeShared   TaskScope = "shared"
+	ScopeAll      TaskScope = "all"
+)
+
+func (s TaskScope) Valid() bool {
+	switch s {
+	case ScopeOwned, ScopeAssigned, ScopeShared, ScopeAll:
+		return true
+	}
+	return false
+}
+
+// TaskFilter narrows and orders list of tasks, relative due windows
+// are evaluated at the moment of the query in the user timezone
+type TaskFilter struct {
+	// Scope is owned tasks by default, WorkspaceID lists tasks of the workspace projects instead
+	Scope       TaskScope
+	WorkspaceID *int64
+	ProjectID   *int64
+	Statuses    []Status
+	Categories  []StatusCategory
+	// Tags are required tags, task must have all of them
+	Tags []string
+	// Text is searched in title and description
+	Text    string
+	Due     DueWindow
+	DueDays int
+	DueFrom *time.Time
+	DueTo   *time.Time
+	Fields  []FieldCondition
+	// Sort is one of created, updated, due, title, priority, status or cf.<field name>
+	Sort string
+	Desc bool
+}
+
+type FieldCondition struct {
+	Name string
+	// Op is one of eq, ne, gt, gte, lt, lte, contains
+	Op    string
+	Value string
+}
+
+func (w DueWindow) Valid() bool {
+	switch w {
+	case DueAny, DueOverdue, DueToday, DueUpcoming, DueNone, DueRange:
+		return true
+	default:
+		return false
+	}
+}
+
+// Validate checks scope, due window, field operators and sort key
+func (f TaskFilter) Validate() error {
+	if !f.Scope.Valid() {
+		return fmt.Errorf("%w: unknown scope %s", ErrInvalidFilter, f.Scope)
+	}
+	if !f.Due.Valid() {
+		return fmt.Errorf("%w: unknown due window %s", ErrInvalidFilter, f.Due)
+	}
+	if f.DueDays < 0 {
+		return fmt.Errorf("%w: due days must not be negative", ErrInvalidFilter)
+	}
+
+	for _, c := range f.Categories {
+		if !c.Valid() {
+			return fmt.Errorf("%w: unknown category %s", ErrInvalidFilter, c)
+		}
+	}
+
+	for _, c := range f.Fields {
+		switch c.Op {
+		case "eq", "ne", "gt", "gte", "lt", "lte", "contains":
+		default:
+			return fmt.Errorf("%w: unknown operator %s for %s", ErrInvalidFilter, c.Op, c.Name)
+		}
+	}
+
+	switch {
+	case f.Sort == "", f.Sort == "created", f.Sort == "updated", f.Sort == "due",
+		f.Sort == "title", f.Sort == "priority", f.Sort == "status",
+		strings.HasPrefix(f.Sort, "cf.") && len(f.Sort) > len("cf."):
+	default:
+		return fmt.Errorf("%w: unknown sort %s", ErrInvalidFilter, f.Sort)
+	}
+
+	return nil
 }
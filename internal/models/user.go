@@ -8,11 +8,46 @@ import (
 var (
 	ErrUserNotFound      = errors.New("user not found")
 	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrInvalidTimezone   = errors.New("invalid timezone")
+	ErrUserDisabled      = errors.New("user is disabled")
+
+	ErrAppPasswordNotFound = errors.New("app password not found")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
 )
 
 type User struct {
 	ID           int64
 	Email        string
 	PasswordHash []byte
+	FeedToken    string
+	Timezone     string
 	CreatedAt    time.Time
+	// DisabledAt is set by the admin command, disabled users can not log in
+	DisabledAt *time.Time
+}
+
+func (u User) Disabled() bool {
+	return u.DisabledAt != nil
+}
+
+// Location returns user timezone, UTC if it is not set or unknown
+func (u User) Location() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// AppPassword is a generated password for clients which support only Basic auth, like CalDAV clients.
+// Only its hash is stored, the password is shown once on creation
+type AppPassword struct {
+	ID         int64
+	UserID     int64
+	Name       string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
 }
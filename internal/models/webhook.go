@@ -0,0 +1,80 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrWebhookNotFound   = errors.New("webhook not found")
+	ErrDeliveryNotFound  = errors.New("delivery not found")
+	ErrInvalidWebhookURL = errors.New("webhook url must be an absolute http or https url")
+	ErrPrivateWebhookURL = errors.New("webhook url must point to a public address")
+	ErrNoWebhookEvents   = errors.New("webhook must subscribe to at least one event")
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// WebhookEventTypes are events which can be delivered to webhooks
+func WebhookEventTypes() []EventType {
+	return []EventType{
+		EventTaskCreated,
+		EventTaskUpdated,
+		EventTaskStatusChanged,
+		EventTaskDeleted,
+		EventTaskAssigned,
+		EventTaskCommented,
+	}
+}
+
+func (t EventType) Webhook() bool {
+	for _, v := range WebhookEventTypes() {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+type Webhook struct {
+	ID     int64
+	UserID int64
+	URL    string
+	// Secret signs payloads, it is shown only when the webhook is created
+	Secret    string
+	Events    []EventType
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (w Webhook) Subscribed(t EventType) bool {
+	for _, e := range w.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDelivery struct {
+	ID           int64
+	WebhookID    int64
+	Event        EventType
+	Payload      []byte
+	Status       DeliveryStatus
+	Attempts     int
+	ResponseCode *int
+	Error        string
+	// RedeliveryOf is the delivery which was manually redelivered
+	RedeliveryOf  *int64
+	NextAttemptAt *time.Time
+	LastAttemptAt *time.Time
+	CreatedAt     time.Time
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+type StatusCategory string
+
+var (
+	CategoryTodo       StatusCategory = "todo"
+	CategoryInProgress StatusCategory = "in_progress"
+	CategoryDone       StatusCategory = "done"
+)
+
+var (
+	ErrStatusNotFound      = errors.New("status not found")
+	ErrStatusAlreadyExists = errors.New("status already exists")
+	ErrUnknownStatus       = errors.New("status is not defined in workflow")
+	ErrInvalidCategory     = errors.New("invalid status category")
+	ErrWIPLimitExceeded    = errors.New("wip limit exceeded")
+)
+
+// WorkflowStatus is a board column, ProjectID nil means the status is defined for all user projects
+type WorkflowStatus struct {
+	ID        int64
+	UserID    int64
+	ProjectID *int64
+	Name      Status
+	Category  StatusCategory
+	Position  int
+	// WIPLimit is max number of tasks in the column, 0 means unlimited
+	WIPLimit  int
+	CreatedAt time.Time
+}
+
+// DefaultWorkflow is used when user has not defined own statuses
+func DefaultWorkflow() []WorkflowStatus {
+	return []WorkflowStatus{
+		{Name: Pending, Category: CategoryTodo, Position: 1},
+		{Name: Done, Category: CategoryDone, Position: 2},
+	}
+}
+
+func (c StatusCategory) Valid() bool {
+	switch c {
+	case CategoryTodo, CategoryInProgress, CategoryDone:
+		return true
+	default:
+		return false
+	}
+}
+
+// BoardColumn is workflow status with its tasks
+type BoardColumn struct {
+	Status WorkflowStatus
+	Tasks  []Task
+}
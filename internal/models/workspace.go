@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrWorkspaceNotFound  = errors.New("workspace not found")
+	ErrMemberNotFound     = errors.New("workspace member not found")
+	ErrAlreadyMember      = errors.New("user is already a workspace member")
+	ErrInvalidRole        = errors.New("invalid role")
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvitationExpired  = errors.New("invitation expired")
+	ErrInvitationEmail    = errors.New("invitation was sent to another email")
+)
+
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+	RoleGuest  Role = "guest"
+)
+
+var roleRank = map[Role]int{
+	RoleGuest:  1,
+	RoleMember: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+// Valid reports whether the role can be given by invitation or role change, owner is given only on creation
+func (r Role) Valid() bool {
+	return r == RoleAdmin || r == RoleMember || r == RoleGuest
+}
+
+// Outranks reports whether the role is strictly higher than other
+func (r Role) Outranks(other Role) bool {
+	return roleRank[r] > roleRank[other]
+}
+
+// Action is an operation checked by the authorization layer
+type Action string
+
+const (
+	ActionView            Action = "view"
+	ActionEdit            Action = "edit"
+	ActionManageProjects  Action = "manage_projects"
+	ActionManageMembers   Action = "manage_members"
+	ActionManageWorkspace Action = "manage_workspace"
+	ActionDeleteWorkspace Action = "delete_workspace"
+)
+
+var roleActions = map[Role][]Action{
+	RoleGuest:  {ActionView},
+	RoleMember: {ActionView, ActionEdit},
+	RoleAdmin:  {ActionView, ActionEdit, ActionManageProjects, ActionManageMembers, ActionManageWorkspace},
+	RoleOwner:  {ActionView, ActionEdit, ActionManageProjects, ActionManageMembers, ActionManageWorkspace, ActionDeleteWorkspace},
+}
+
+func (r Role) Can(action Action) bool {
+	for _, a := range roleActions[r] {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskPermission is access to workspace tasks given by the role
+func (r Role) TaskPermission() Permission {
+	switch r {
+	case RoleOwner, RoleAdmin:
+		return PermissionOwner
+	case RoleMember:
+		return PermissionEditor
+	case RoleGuest:
+		return PermissionViewer
+	default:
+		return PermissionNone
+	}
+}
+
+// Workspace is a shared space, its projects are stored in the owner's scope.
+// Role is the role of the user the workspace was loaded for
+type Workspace struct {
+	ID        int64
+	OwnerID   int64
+	Name      string
+	Role      Role
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type WorkspaceMember struct {
+	WorkspaceID int64
+	UserID      int64
+	Email       string
+	Role        Role
+	CreatedAt   time.Time
+}
+
+type Invitation struct {
+	ID          int64
+	WorkspaceID int64
+	Email       string
+	Role        Role
+	Token       string
+	InvitedBy   int64
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	AcceptedAt  *time.Time
+}
@@ -5,27 +5,59 @@ import (
 	"TaskList/internal/lib/jwt"
 	"TaskList/internal/models"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"golang.org/x/crypto/bcrypt"
 	"log/slog"
+	"strings"
+	"time"
 )
 
+const appPasswordBytes = 24
+
 type Saver interface {
 	CreateUser(ctx context.Context, email string, passHash []byte) (int64, error)
 }
 
 type Provider interface {
 	UserByEmail(ctx context.Context, email string) (*models.User, error)
+	UserByID(ctx context.Context, userID int64) (*models.User, error)
+}
+
+type Updater interface {
+	UpdateTimezone(ctx context.Context, userID int64, timezone string) error
+	UpdatePasswordHash(ctx context.Context, userID int64, passHash []byte) error
+	SetUserDisabled(ctx context.Context, userID int64, disabledAt *time.Time) error
+}
+
+type AppPasswords interface {
+	InsertAppPassword(ctx context.Context, p models.AppPassword, tokenHash string) (int64, error)
+	SelectAppPasswords(ctx context.Context, userID int64) ([]models.AppPassword, error)
+	DeleteAppPassword(ctx context.Context, userID int64, id int64) error
+	UserByAppPassword(ctx context.Context, email string, tokenHash string) (*models.User, error)
 }
 
 type Auth struct {
-	saver    Saver
-	provider Provider
-	log      *slog.Logger
-	cfg      *config.Config
+	saver        Saver
+	provider     Provider
+	updater      Updater
+	appPasswords AppPasswords
+	log          *slog.Logger
+	cfg          *config.Config
 }
 
-func NewServices(provider Provider, saver Saver, logger *slog.Logger, cfg *config.Config) *Auth {
-	return &Auth{provider: provider, saver: saver, log: logger, cfg: cfg}
+func NewServices(
+	provider Provider,
+	saver Saver,
+	updater Updater,
+	appPasswords AppPasswords,
+	logger *slog.Logger,
+	cfg *config.Config,
+) *Auth {
+	return &Auth{provider: provider, saver: saver, updater: updater, appPasswords: appPasswords, log: logger, cfg: cfg}
 }
 
 func (a Auth) Registration(ctx context.Context, email string, password string) (int64, error) {
@@ -53,6 +85,9 @@ func (a Auth) Login(ctx context.Context, email string, password []byte) (string,
 	if err = bcrypt.CompareHashAndPassword(user.PasswordHash, password); err != nil {
 		return "", err
 	}
+	if user.Disabled() {
+		return "", models.ErrUserDisabled
+	}
 
 	token, err = jwt.NewToken(*user, a.cfg.JWT.Exp, []byte(a.cfg.JWT.Secret))
 	if err != nil {
@@ -60,3 +95,140 @@ func (a Auth) Login(ctx context.Context, email string, password []byte) (string,
 	}
 	return token, nil
 }
+
+// SetTimezone changes IANA timezone used for parsing relative dates
+func (a Auth) SetTimezone(ctx context.Context, userID int64, timezone string) error {
+	if timezone == "" || timezone == "Local" {
+		return models.ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("%w: %s", models.ErrInvalidTimezone, timezone)
+	}
+
+	return a.updater.UpdateTimezone(ctx, userID, timezone)
+}
+
+// CreateAppPassword generates a password for Basic auth, it is returned only here
+func (a Auth) CreateAppPassword(ctx context.Context, userID int64, name string) (models.AppPassword, string, error) {
+	const op = "services.auth.CreateAppPassword"
+
+	b := make([]byte, appPasswordBytes)
+	if _, err := rand.Read(b); err != nil {
+		return models.AppPassword{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+	password := hex.EncodeToString(b)
+
+	p := models.AppPassword{
+		UserID:    userID,
+		Name:      strings.TrimSpace(name),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	id, err := a.appPasswords.InsertAppPassword(ctx, p, hashAppPassword(password))
+	if err != nil {
+		return models.AppPassword{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+	p.ID = id
+
+	a.log.Info("app password created", slog.String("op", op), slog.Int64("user_id", userID), slog.Int64("id", id))
+
+	return p, password, nil
+}
+
+func (a Auth) AppPasswords(ctx context.Context, userID int64) ([]models.AppPassword, error) {
+	return a.appPasswords.SelectAppPasswords(ctx, userID)
+}
+
+func (a Auth) DeleteAppPassword(ctx context.Context, userID int64, id int64) error {
+	return a.appPasswords.DeleteAppPassword(ctx, userID, id)
+}
+
+// AuthenticateAppPassword returns the user if the password is one of the user's app passwords,
+// the account password is not accepted
+func (a Auth) AuthenticateAppPassword(ctx context.Context, email string, password string) (*models.User, error) {
+	if email == "" || password == "" {
+		return nil, models.ErrInvalidCredentials
+	}
+	user, err := a.appPasswords.UserByAppPassword(ctx, email, hashAppPassword(password))
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled() {
+		return nil, models.ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// ResetPassword replaces the password of the user, tokens issued before stay valid until they expire
+func (a Auth) ResetPassword(ctx context.Context, email string, password string) error {
+	const op = "services.auth.ResetPassword"
+
+	user, err := a.provider.UserByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = a.updater.UpdatePasswordHash(ctx, user.ID, hash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.Info("password reset", slog.String("op", op), slog.Int64("user_id", user.ID))
+
+	return nil
+}
+
+// SetDisabled disables or enables the user. Disabled users can not log in, use app passwords
+// and the calendar feed, their tokens are rejected by CheckActive until the user is enabled again
+func (a Auth) SetDisabled(ctx context.Context, email string, disabled bool) error {
+	const op = "services.auth.SetDisabled"
+
+	user, err := a.provider.UserByEmail(ctx, email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var disabledAt *time.Time
+	if disabled {
+		now := time.Now().UTC()
+		disabledAt = &now
+	}
+
+	if err = a.updater.SetUserDisabled(ctx, user.ID, disabledAt); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.log.Info("user disabled changed", slog.String("op", op), slog.Int64("user_id", user.ID), slog.Bool("disabled", disabled))
+
+	return nil
+}
+
+// CheckActive returns ErrUserDisabled for disabled users and ErrUserNotFound for deleted ones,
+// the JWT middlewares call it for every request so disabling revokes tokens issued before
+func (a Auth) CheckActive(ctx context.Context, userID int64) error {
+	const op = "services.auth.CheckActive"
+
+	user, err := a.provider.UserByID(ctx, userID)
+	if errors.Is(err, models.ErrUserNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if user.Disabled() {
+		return models.ErrUserDisabled
+	}
+
+	return nil
+}
+
+// hashAppPassword uses plain SHA-256, generated passwords have enough entropy
+// to not need a slow hash and can be looked up by the hash
+func hashAppPassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,146 @@
+package authz
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+type MemberProvider interface {
+	SelectWorkspaceMembers(ctx context.Context, workspaceID int64) ([]models.WorkspaceMember, error)
+	SelectWorkspaceMember(ctx context.Context, workspaceID int64, userID int64) (models.WorkspaceMember, error)
+}
+
+type WorkspaceProvider interface {
+	SelectWorkspaceByID(ctx context.Context, workspaceID int64, userID int64) (models.Workspace, error)
+}
+
+type ProjectProvider interface {
+	SelectProject(ctx context.Context, projectID int64) (models.Project, error)
+}
+
+// Authz is the authorization layer shared by controller handlers and services,
+// membership is looked up per request so role changes apply immediately
+type Authz struct {
+	members    MemberProvider
+	workspaces WorkspaceProvider
+	projects   ProjectProvider
+	cfg        *config.Config
+	log        *slog.Logger
+}
+
+func NewServices(m MemberProvider, w WorkspaceProvider, p ProjectProvider, cfg *config.Config, log *slog.Logger) *Authz {
+	return &Authz{members: m, workspaces: w, projects: p, cfg: cfg, log: log}
+}
+
+// Authorize returns membership of the user if the role allows the action,
+// non members get ErrWorkspaceNotFound so workspace existence is not disclosed
+func (a Authz) Authorize(ctx context.Context, userID int64, workspaceID int64, action models.Action) (models.WorkspaceMember, error) {
+	const op = "services.authz.Authorize"
+
+	m, err := a.members.SelectWorkspaceMember(ctx, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrMemberNotFound) {
+			return models.WorkspaceMember{}, models.ErrWorkspaceNotFound
+		}
+		return models.WorkspaceMember{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !m.Role.Can(action) {
+		a.log.Warn(
+			"action denied",
+			slog.String("op", op),
+			slog.Int64("user_id", userID),
+			slog.Int64("workspace_id", workspaceID),
+			slog.String("role", string(m.Role)),
+			slog.String("action", string(action)),
+		)
+		return models.WorkspaceMember{}, fmt.Errorf("%w: %s is not allowed for %s", models.ErrForbidden, action, m.Role)
+	}
+
+	return m, nil
+}
+
+// WorkspaceScope authorizes the action and returns the workspace owner, whose data holds workspace projects
+func (a Authz) WorkspaceScope(ctx context.Context, userID int64, workspaceID int64, action models.Action) (int64, error) {
+	const op = "services.authz.WorkspaceScope"
+
+	if _, err := a.Authorize(ctx, userID, workspaceID, action); err != nil {
+		return 0, err
+	}
+
+	ws, err := a.workspaces.SelectWorkspaceByID(ctx, workspaceID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ws.OwnerID, nil
+}
+
+// ProjectScope returns the user whose data holds the project: the user for
+// personal projects and tasks without project, the workspace owner for workspace projects
+func (a Authz) ProjectScope(ctx context.Context, userID int64, projectID *int64, action models.Action) (int64, error) {
+	const op = "services.authz.ProjectScope"
+
+	if projectID == nil {
+		return userID, nil
+	}
+
+	p, err := a.projects.SelectProject(ctx, *projectID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if p.WorkspaceID == nil {
+		if p.UserID != userID {
+			return 0, models.ErrProjectNotFound
+		}
+		return userID, nil
+	}
+
+	if _, err = a.Authorize(ctx, userID, *p.WorkspaceID, action); err != nil {
+		if errors.Is(err, models.ErrWorkspaceNotFound) {
+			return 0, models.ErrProjectNotFound
+		}
+		return 0, err
+	}
+
+	return p.UserID, nil
+}
+
+// TaskPermission returns access to tasks of the workspace given by the user role
+func (a Authz) TaskPermission(ctx context.Context, userID int64, workspaceID int64) (models.Permission, error) {
+	const op = "services.authz.TaskPermission"
+
+	m, err := a.members.SelectWorkspaceMember(ctx, workspaceID, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrMemberNotFound) {
+			return models.PermissionNone, nil
+		}
+		return models.PermissionNone, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return m.Role.TaskPermission(), nil
+}
+
+// TaskViewers returns members whose role gives access to tasks of the workspace
+func (a Authz) TaskViewers(ctx context.Context, workspaceID int64) ([]int64, error) {
+	const op = "services.authz.TaskViewers"
+
+	members, err := a.members.SelectWorkspaceMembers(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var ids []int64
+	for _, m := range members {
+		if m.Role.TaskPermission() != models.PermissionNone {
+			ids = append(ids, m.UserID)
+		}
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,418 @@
+package caldav
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/lib/ical"
+	"TaskList/internal/models"
+	"TaskList/internal/services/calendar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const prodID = "-//TaskList//TaskList CalDAV//EN"
+
+// Tasks reads and changes tasks with the usual permission checks, implemented by tasks service
+type Tasks interface {
+	Tasks(ctx context.Context, userID int64, filter models.TaskFilter) ([]models.Task, error)
+	TasksByID(ctx context.Context, taskID int64, userID int64) (models.Task, error)
+	CreateTask(ctx context.Context, task models.Task) (int64, error)
+	UpdateTask(ctx context.Context, taskID int64, userID int64, patch models.TaskPatch) (models.Task, error)
+	ChangeTaskStatus(ctx context.Context, taskID int64, userID int64, newStatus string) error
+	DeleteTask(ctx context.Context, taskID int64, userID int64) error
+}
+
+type Projects interface {
+	SelectProjectsByUserID(ctx context.Context, userID int64) ([]models.Project, error)
+}
+
+type ObjectNames interface {
+	SelectCalendarObjectNames(ctx context.Context, taskIDs []int64) (map[int64]models.CalendarObjectName, error)
+	UpsertCalendarObjectName(ctx context.Context, n models.CalendarObjectName) error
+	DeleteCalendarObjectName(ctx context.Context, taskID int64) error
+}
+
+// Authz resolves the scope new tasks are stored in, implemented by authz service
+type Authz interface {
+	ProjectScope(ctx context.Context, userID int64, projectID *int64, action models.Action) (int64, error)
+}
+
+type Workflow interface {
+	Workflow(ctx context.Context, userID int64, projectID *int64) ([]models.WorkflowStatus, error)
+}
+
+type CalDAV struct {
+	tasks    Tasks
+	projects Projects
+	names    ObjectNames
+	authz    Authz
+	workflow Workflow
+	cfg      *config.Config
+	log      *slog.Logger
+}
+
+func NewServices(t Tasks, p Projects, n ObjectNames, authz Authz, w Workflow, cfg *config.Config, log *slog.Logger) *CalDAV {
+	return &CalDAV{tasks: t, projects: p, names: n, authz: authz, workflow: w, cfg: cfg, log: log}
+}
+
+// collections are calendars of the user with their objects
+type collections struct {
+	calendars []models.Calendar
+	objects   map[string][]models.CalendarObject
+}
+
+// Calendars returns the inbox and a calendar for every project the user can see
+func (c CalDAV) Calendars(ctx context.Context, userID int64) ([]models.Calendar, error) {
+	const op = "services.caldav.Calendars"
+
+	cols, err := c.load(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cols.calendars, nil
+}
+
+func (c CalDAV) Calendar(ctx context.Context, userID int64, calendarID string) (models.Calendar, error) {
+	const op = "services.caldav.Calendar"
+
+	cols, err := c.load(ctx, userID)
+	if err != nil {
+		return models.Calendar{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cols.calendar(calendarID)
+}
+
+func (c CalDAV) Objects(ctx context.Context, userID int64, calendarID string) ([]models.CalendarObject, error) {
+	const op = "services.caldav.Objects"
+
+	cols, err := c.load(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err = cols.calendar(calendarID); err != nil {
+		return nil, err
+	}
+
+	return cols.objects[calendarID], nil
+}
+
+func (c CalDAV) Object(ctx context.Context, userID int64, calendarID string, name string) (models.CalendarObject, error) {
+	const op = "services.caldav.Object"
+
+	cols, err := c.load(ctx, userID)
+	if err != nil {
+		return models.CalendarObject{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return cols.object(calendarID, name)
+}
+
+// PutObject creates a task from the VTODO or replaces fields of the existing one,
+// created reports whether a new task was created
+func (c CalDAV) PutObject(ctx context.Context, userID int64, calendarID string, name string, data []byte, pre models.Preconditions) (bool, error) {
+	const op = "services.caldav.PutObject"
+
+	if !validName(name) {
+		return false, fmt.Errorf("%w: resource name must end with .ics", models.ErrInvalidCalendarObject)
+	}
+
+	todo, err := parseTodo(data)
+	if err != nil {
+		return false, err
+	}
+	task, err := calendar.TodoToTask(todo)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s", models.ErrInvalidCalendarObject, err.Error())
+	}
+
+	cols, err := c.load(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	cal, err := cols.calendar(calendarID)
+	if err != nil {
+		return false, err
+	}
+
+	obj, err := cols.object(calendarID, name)
+	switch {
+	case err == nil:
+		if err = pre.Check(obj.ETag); err != nil {
+			return false, err
+		}
+		return false, c.update(ctx, userID, obj.Task, task)
+	case errors.Is(err, models.ErrCalendarObjectNotFound):
+		if err = pre.Check(""); err != nil {
+			return false, err
+		}
+	default:
+		return false, err
+	}
+
+	task.ProjectID = cal.ProjectID
+	task.UserID, err = c.authz.ProjectScope(ctx, userID, task.ProjectID, models.ActionEdit)
+	if err != nil {
+		return false, err
+	}
+	task.CreatedBy = userID
+
+	id, err := c.tasks.CreateTask(ctx, task)
+	if err != nil {
+		return false, err
+	}
+
+	err = c.names.UpsertCalendarObjectName(ctx, models.CalendarObjectName{TaskID: id, Name: name, UID: todo.Text("UID")})
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	c.log.Info("task created from caldav", slog.String("op", op), slog.Int64("task_id", id), slog.Int64("user_id", userID))
+
+	return true, nil
+}
+
+// update replaces the task fields with the VTODO ones, STATUS moves the task
+// to the first status of the category when the category changed
+func (c CalDAV) update(ctx context.Context, userID int64, current models.Task, task models.Task) error {
+	const op = "services.caldav.update"
+
+	patch := models.TaskPatch{
+		Title:       &task.Title,
+		Description: &task.Description,
+		Priority:    &task.Priority,
+		Tags:        &task.Tags,
+		Recurrence:  &task.Recurrence,
+		DueAt:       task.DueAt,
+		ClearDue:    task.DueAt == nil,
+	}
+	if _, err := c.tasks.UpdateTask(ctx, current.ID, userID, patch); err != nil {
+		return err
+	}
+
+	if task.StatusCategory == current.StatusCategory {
+		return nil
+	}
+
+	statuses, err := c.workflow.Workflow(ctx, current.UserID, current.ProjectID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, ws := range statuses {
+		if ws.Category == task.StatusCategory {
+			return c.tasks.ChangeTaskStatus(ctx, current.ID, userID, string(ws.Name))
+		}
+	}
+
+	return nil
+}
+
+func (c CalDAV) DeleteObject(ctx context.Context, userID int64, calendarID string, name string, pre models.Preconditions) error {
+	const op = "services.caldav.DeleteObject"
+
+	cols, err := c.load(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	obj, err := cols.object(calendarID, name)
+	if err != nil {
+		return err
+	}
+	if err = pre.Check(obj.ETag); err != nil {
+		return err
+	}
+
+	if err = c.tasks.DeleteTask(ctx, obj.Task.ID, userID); err != nil {
+		return err
+	}
+
+	if err = c.names.DeleteCalendarObjectName(ctx, obj.Task.ID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// load returns all tasks the user can see grouped into calendars,
+// tasks of projects the user does not see are in the inbox
+func (c CalDAV) load(ctx context.Context, userID int64) (collections, error) {
+	projects, err := c.projects.SelectProjectsByUserID(ctx, userID)
+	if err != nil {
+		return collections{}, err
+	}
+
+	tasks, err := c.tasks.Tasks(ctx, userID, models.TaskFilter{Scope: models.ScopeAll})
+	if err != nil {
+		return collections{}, err
+	}
+
+	seenWorkspace := make(map[int64]struct{})
+	for _, p := range projects {
+		if p.WorkspaceID == nil {
+			continue
+		}
+		if _, ok := seenWorkspace[*p.WorkspaceID]; ok {
+			continue
+		}
+		seenWorkspace[*p.WorkspaceID] = struct{}{}
+
+		wsTasks, err := c.tasks.Tasks(ctx, userID, models.TaskFilter{WorkspaceID: p.WorkspaceID})
+		if err != nil {
+			return collections{}, err
+		}
+		tasks = append(tasks, wsTasks...)
+	}
+
+	ids := make([]int64, 0, len(tasks))
+	seenTask := make(map[int64]struct{}, len(tasks))
+	unique := tasks[:0]
+	for _, t := range tasks {
+		if _, ok := seenTask[t.ID]; ok {
+			continue
+		}
+		seenTask[t.ID] = struct{}{}
+		ids = append(ids, t.ID)
+		unique = append(unique, t)
+	}
+
+	names, err := c.names.SelectCalendarObjectNames(ctx, ids)
+	if err != nil {
+		return collections{}, err
+	}
+
+	cols := collections{
+		calendars: []models.Calendar{{ID: models.InboxCalendar, Name: "Inbox"}},
+		objects:   make(map[string][]models.CalendarObject),
+	}
+	for _, p := range projects {
+		cols.calendars = append(cols.calendars, models.Calendar{
+			ID:        strconv.FormatInt(p.ID, 10),
+			Name:      p.Name,
+			ProjectID: &p.ID,
+		})
+	}
+
+	for _, t := range unique {
+		calendarID := models.InboxCalendar
+		if t.ProjectID != nil {
+			if id := strconv.FormatInt(*t.ProjectID, 10); cols.has(id) {
+				calendarID = id
+			}
+		}
+
+		obj, err := toObject(t, names[t.ID])
+		if err != nil {
+			return collections{}, err
+		}
+		cols.objects[calendarID] = append(cols.objects[calendarID], obj)
+	}
+
+	for i, cal := range cols.calendars {
+		cols.calendars[i].CTag = ctag(cols.objects[cal.ID])
+	}
+
+	return cols, nil
+}
+
+func (cols collections) has(calendarID string) bool {
+	_, err := cols.calendar(calendarID)
+	return err == nil
+}
+
+func (cols collections) calendar(calendarID string) (models.Calendar, error) {
+	for _, cal := range cols.calendars {
+		if cal.ID == calendarID {
+			return cal, nil
+		}
+	}
+	return models.Calendar{}, models.ErrCalendarNotFound
+}
+
+func (cols collections) object(calendarID string, name string) (models.CalendarObject, error) {
+	if _, err := cols.calendar(calendarID); err != nil {
+		return models.CalendarObject{}, err
+	}
+	for _, obj := range cols.objects[calendarID] {
+		if obj.Name == name {
+			return obj, nil
+		}
+	}
+	return models.CalendarObject{}, models.ErrCalendarObjectNotFound
+}
+
+// toObject renders the task as VCALENDAR with a single VTODO keeping the name and UID of the client which created it
+func toObject(t models.Task, n models.CalendarObjectName) (models.CalendarObject, error) {
+	name := "task-" + strconv.FormatInt(t.ID, 10) + ".ics"
+	if n.Name != "" {
+		name = n.Name
+	}
+
+	todo := calendar.TaskToTodo(t)
+	if n.UID != "" {
+		for i, p := range todo.Props {
+			if p.Name == "UID" {
+				todo.Props[i].Value = n.UID
+			}
+		}
+	}
+
+	cal := ical.NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", prodID)
+	cal.Components = append(cal.Components, todo)
+
+	buf := &bytes.Buffer{}
+	if err := ical.Encode(buf, cal); err != nil {
+		return models.CalendarObject{}, err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	return models.CalendarObject{
+		Name: name,
+		Task: t,
+		Data: buf.Bytes(),
+		ETag: `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}, nil
+}
+
+// ctag changes when any object of the calendar is added, removed or changed
+func ctag(objects []models.CalendarObject) string {
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Name + obj.ETag
+	}
+	sort.Strings(keys)
+
+	sum := sha256.Sum256([]byte(strings.Join(keys, "\n")))
+	return hex.EncodeToString(sum[:16])
+}
+
+func parseTodo(data []byte) (*ical.Component, error) {
+	cals, err := ical.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", models.ErrInvalidCalendarObject, err.Error())
+	}
+
+	for _, cal := range cals {
+		if todos := cal.Children("VTODO"); len(todos) > 0 {
+			return todos[0], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: VTODO is required", models.ErrInvalidCalendarObject)
+}
+
+func validName(name string) bool {
+	return strings.HasSuffix(name, ".ics") && len(name) > len(".ics") && !strings.Contains(name, "/")
+}
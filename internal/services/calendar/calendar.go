@@ -0,0 +1,297 @@
+package calendar
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/lib/ical"
+	"TaskList/internal/models"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	prodID = "-//TaskList//TaskList API//EN"
+
+	feedTokenBytes = 32
+)
+
+var (
+	ErrNoTodos = errors.New("calendar does not contain VTODO components")
+)
+
+type TaskProvider interface {
+	SelectAllTasksByUserID(ctx context.Context, userID int64) ([]models.Task, error)
+}
+
+type TaskSaver interface {
+	CreateTask(ctx context.Context, task models.Task) (int64, error)
+}
+
+type FeedTokens interface {
+	UserByFeedToken(ctx context.Context, token string) (*models.User, error)
+	UpdateFeedToken(ctx context.Context, userID int64, token string) error
+}
+
+type Calendar struct {
+	provider TaskProvider
+	saver    TaskSaver
+	tokens   FeedTokens
+	cfg      *config.Config
+	log      *slog.Logger
+}
+
+func NewServices(p TaskProvider, s TaskSaver, t FeedTokens, cfg *config.Config, log *slog.Logger) *Calendar {
+	return &Calendar{provider: p, saver: s, tokens: t, cfg: cfg, log: log}
+}
+
+// Export returns all user tasks as iCalendar data
+func (c Calendar) Export(ctx context.Context, userID int64) ([]byte, error) {
+	const op = "services.calendar.Export"
+
+	t, err := c.provider.SelectAllTasksByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := ical.Encode(buf, TasksToCalendar(t)); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Feed returns calendar of the user who owns the secret feed token
+func (c Calendar) Feed(ctx context.Context, token string) ([]byte, error) {
+	const op = "services.calendar.Feed"
+
+	if token == "" {
+		return nil, models.ErrUserNotFound
+	}
+
+	user, err := c.tokens.UserByFeedToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if user.Disabled() {
+		return nil, models.ErrUserNotFound
+	}
+
+	return c.Export(ctx, user.ID)
+}
+
+// RegenerateFeedToken creates new feed token, the previous one stops working
+func (c Calendar) RegenerateFeedToken(ctx context.Context, userID int64) (string, error) {
+	const op = "services.calendar.RegenerateFeedToken"
+
+	b := make([]byte, feedTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	token := hex.EncodeToString(b)
+
+	if err := c.tokens.UpdateFeedToken(ctx, userID, token); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, nil
+}
+
+// Import creates a task for every VTODO component in the calendar
+func (c Calendar) Import(ctx context.Context, userID int64, r io.Reader) ([]int64, error) {
+	const op = "services.calendar.Import"
+
+	cals, err := ical.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var todos []*ical.Component
+	for _, cal := range cals {
+		todos = append(todos, cal.Children("VTODO")...)
+	}
+	if len(todos) == 0 {
+		return nil, ErrNoTodos
+	}
+
+	ids := make([]int64, 0, len(todos))
+	for _, todo := range todos {
+		task, err := TodoToTask(todo)
+		if err != nil {
+			return ids, fmt.Errorf("%s: %w", op, err)
+		}
+		task.UserID = userID
+
+		id, err := c.saver.CreateTask(ctx, task)
+		if err != nil {
+			return ids, fmt.Errorf("%s: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+
+	c.log.Info(
+		"tasks imported from calendar",
+		slog.String("op", op),
+		slog.Int64("user_id", userID),
+		slog.Int("count", len(ids)),
+	)
+
+	return ids, nil
+}
+
+// TasksToCalendar builds VCALENDAR with VTODO for every task
+// and VEVENT for tasks with due date, because many calendar clients ignore VTODO in subscriptions
+func TasksToCalendar(tasks []models.Task) *ical.Component {
+	cal := ical.NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", prodID)
+	cal.Add("CALSCALE", "GREGORIAN")
+	cal.AddText("X-WR-CALNAME", "TaskList")
+
+	for _, t := range tasks {
+		cal.Components = append(cal.Components, TaskToTodo(t))
+		if t.DueAt != nil {
+			cal.Components = append(cal.Components, taskToEvent(t))
+		}
+	}
+
+	return cal
+}
+
+func TaskToTodo(t models.Task) *ical.Component {
+	todo := ical.NewComponent("VTODO")
+	todo.Add("UID", taskUID(t.ID))
+	todo.AddTime("DTSTAMP", t.UpdatedAt)
+	todo.AddTime("CREATED", t.CreatedAt)
+	todo.AddTime("LAST-MODIFIED", t.UpdatedAt)
+	todo.AddText("SUMMARY", t.Title)
+	if t.Description != "" {
+		todo.AddText("DESCRIPTION", t.Description)
+	}
+	if t.DueAt != nil {
+		todo.AddTime("DUE", *t.DueAt)
+	}
+	if p := priorityToICal(t.Priority); p != "" {
+		todo.Add("PRIORITY", p)
+	}
+	if len(t.Tags) > 0 {
+		todo.AddTextList("CATEGORIES", t.Tags)
+	}
+	if t.Recurrence != "" {
+		todo.Add("RRULE", t.Recurrence)
+	}
+	todo.Add("STATUS", statusToICal(t.StatusCategory))
+	if t.StatusCategory == models.CategoryDone {
+		todo.AddTime("COMPLETED", t.UpdatedAt)
+		todo.Add("PERCENT-COMPLETE", "100")
+	}
+	return todo
+}
+
+func taskToEvent(t models.Task) *ical.Component {
+	event := ical.NewComponent("VEVENT")
+	event.Add("UID", "event-"+taskUID(t.ID))
+	event.AddTime("DTSTAMP", t.UpdatedAt)
+	event.AddTime("DTSTART", *t.DueAt)
+	event.AddText("SUMMARY", t.Title)
+	if t.Description != "" {
+		event.AddText("DESCRIPTION", t.Description)
+	}
+	if t.StatusCategory == models.CategoryDone {
+		event.Add("STATUS", "CANCELLED")
+	} else {
+		event.Add("STATUS", "CONFIRMED")
+	}
+	return event
+}
+
+// TodoToTask maps VTODO properties to a new task without owner,
+// STATUS is mapped to the category so the task gets the first status of the category in user workflow
+func TodoToTask(todo *ical.Component) (models.Task, error) {
+	task := models.Task{
+		Title:          strings.TrimSpace(todo.Text("SUMMARY")),
+		Description:    todo.Text("DESCRIPTION"),
+		StatusCategory: categoryFromICal(todo.Text("STATUS")),
+		Priority:       priorityFromICal(todo.Text("PRIORITY")),
+		Tags:           todo.TextList("CATEGORIES"),
+	}
+	if rrule, ok := todo.Prop("RRULE"); ok {
+		task.Recurrence = rrule.Value
+	}
+	if task.Title == "" {
+		task.Title = "Untitled"
+	}
+
+	due, ok, err := todo.Time("DUE", time.UTC)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("invalid DUE: %w", err)
+	}
+	if ok {
+		due = due.UTC()
+		task.DueAt = &due
+	}
+
+	return task, nil
+}
+
+func taskUID(id int64) string {
+	return "task-" + strconv.FormatInt(id, 10) + "@tasklist"
+}
+
+func statusToICal(c models.StatusCategory) string {
+	switch c {
+	case models.CategoryDone:
+		return "COMPLETED"
+	case models.CategoryInProgress:
+		return "IN-PROCESS"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+func categoryFromICal(s string) models.StatusCategory {
+	switch strings.ToUpper(s) {
+	case "COMPLETED":
+		return models.CategoryDone
+	case "IN-PROCESS":
+		return models.CategoryInProgress
+	default:
+		return models.CategoryTodo
+	}
+}
+
+// priorityToICal maps priority to RFC 5545 PRIORITY where 1 is the highest and 9 the lowest
+func priorityToICal(p models.Priority) string {
+	switch p {
+	case models.PriorityHigh:
+		return "1"
+	case models.PriorityMedium:
+		return "5"
+	case models.PriorityLow:
+		return "9"
+	default:
+		return ""
+	}
+}
+
+func priorityFromICal(s string) models.Priority {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	switch {
+	case err != nil || n <= 0:
+		return models.PriorityNone
+	case n < 5:
+		return models.PriorityHigh
+	case n == 5:
+		return models.PriorityMedium
+	default:
+		return models.PriorityLow
+	}
+}
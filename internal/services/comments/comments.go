@@ -0,0 +1,108 @@
+package comments
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/models"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const maxCommentLength = 10000
+
+type Saver interface {
+	InsertComment(ctx context.Context, comment models.Comment) (int64, error)
+}
+
+type Provider interface {
+	SelectComments(ctx context.Context, taskID int64) ([]models.Comment, error)
+}
+
+type UserProvider interface {
+	UserByID(ctx context.Context, userID int64) (*models.User, error)
+}
+
+// Tasks checks access to the task, implemented by tasks service
+type Tasks interface {
+	TasksByID(ctx context.Context, taskID int64, userID int64) (models.Task, error)
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, e models.TaskEvent)
+}
+
+type Comments struct {
+	saver    Saver
+	provider Provider
+	users    UserProvider
+	tasks    Tasks
+	events   Publisher
+	cfg      *config.Config
+	log      *slog.Logger
+}
+
+func NewServices(s Saver, p Provider, users UserProvider, t Tasks, events Publisher, cfg *config.Config, log *slog.Logger) *Comments {
+	return &Comments{saver: s, provider: p, users: users, tasks: t, events: events, cfg: cfg, log: log}
+}
+
+// AddComment adds comment to the task, everyone who can view the task can comment it
+func (c Comments) AddComment(ctx context.Context, taskID int64, userID int64, body string) (models.Comment, error) {
+	const op = "services.comments.AddComment"
+
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return models.Comment{}, models.ErrEmptyComment
+	}
+	if len(body) > maxCommentLength {
+		return models.Comment{}, fmt.Errorf("%w: comment is longer than %d bytes", models.ErrInvalidComment, maxCommentLength)
+	}
+
+	task, err := c.tasks.TasksByID(ctx, taskID, userID)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := c.users.UserByID(ctx, userID)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	comment := models.Comment{
+		TaskID:    taskID,
+		UserID:    userID,
+		Email:     user.Email,
+		Body:      body,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	comment.ID, err = c.saver.InsertComment(ctx, comment)
+	if err != nil {
+		return models.Comment{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	c.events.Publish(ctx, models.TaskEvent{
+		Type:    models.EventTaskCommented,
+		Task:    task,
+		ActorID: userID,
+		Comment: &comment,
+	})
+
+	return comment, nil
+}
+
+func (c Comments) Comments(ctx context.Context, taskID int64, userID int64) ([]models.Comment, error) {
+	const op = "services.comments.Comments"
+
+	if _, err := c.tasks.TasksByID(ctx, taskID, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	comments, err := c.provider.SelectComments(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return comments, nil
+}
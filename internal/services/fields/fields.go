@@ -0,0 +1,239 @@
+package fields
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxFieldNameLength = 64
+	dateLayout         = "2006-01-02"
+)
+
+var (
+	ErrTaskWithoutProject = errors.New("custom fields are available only for tasks in a project")
+)
+
+type Saver interface {
+	InsertCustomField(ctx context.Context, field models.CustomField) (int64, error)
+}
+
+type Provider interface {
+	SelectCustomFields(ctx context.Context, projectID int64, userID int64) ([]models.CustomField, error)
+	SelectCustomFieldByID(ctx context.Context, fieldID int64, userID int64) (models.CustomField, error)
+}
+
+type Deleter interface {
+	DeleteCustomField(ctx context.Context, fieldID int64, userID int64) error
+}
+
+type ProjectProvider interface {
+	SelectProjectByID(ctx context.Context, projectID int64, userID int64) (models.Project, error)
+}
+
+type UserProvider interface {
+	UserByID(ctx context.Context, userID int64) (*models.User, error)
+}
+
+type Fields struct {
+	saver    Saver
+	provider Provider
+	deleter  Deleter
+	projects ProjectProvider
+	users    UserProvider
+	cfg      *config.Config
+	log      *slog.Logger
+}
+
+func NewServices(
+	s Saver,
+	p Provider,
+	d Deleter,
+	projects ProjectProvider,
+	users UserProvider,
+	cfg *config.Config,
+	log *slog.Logger,
+) *Fields {
+	return &Fields{saver: s, provider: p, deleter: d, projects: projects, users: users, cfg: cfg, log: log}
+}
+
+func (f Fields) Fields(ctx context.Context, userID int64, projectID int64) ([]models.CustomField, error) {
+	const op = "services.fields.Fields"
+
+	if _, err := f.projects.SelectProjectByID(ctx, projectID, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	fields, err := f.provider.SelectCustomFields(ctx, projectID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return fields, nil
+}
+
+func (f Fields) CreateField(ctx context.Context, field models.CustomField) (int64, error) {
+	const op = "services.fields.CreateField"
+
+	field.Name = strings.TrimSpace(field.Name)
+	if field.Name == "" || len(field.Name) > maxFieldNameLength {
+		return 0, fmt.Errorf("field name must be from 1 to %d characters: %w", maxFieldNameLength, models.ErrInvalidFieldValue)
+	}
+	if !field.Type.Valid() {
+		return 0, models.ErrInvalidFieldType
+	}
+	if field.Type == models.FieldSelect && len(field.Options) == 0 {
+		return 0, fmt.Errorf("select field requires options: %w", models.ErrInvalidFieldValue)
+	}
+	if field.Type != models.FieldSelect {
+		field.Options = nil
+	}
+
+	if _, err := f.projects.SelectProjectByID(ctx, field.ProjectID, field.UserID); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := f.saver.InsertCustomField(ctx, field)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	f.log.Info(
+		"custom field created",
+		slog.String("op", op),
+		slog.Int64("project_id", field.ProjectID),
+		slog.Int64("field_id", id),
+	)
+
+	return id, nil
+}
+
+// DeleteField deletes field of the project with its values
+func (f Fields) DeleteField(ctx context.Context, userID int64, projectID int64, fieldID int64) error {
+	const op = "services.fields.DeleteField"
+
+	field, err := f.provider.SelectCustomFieldByID(ctx, fieldID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if field.ProjectID != projectID {
+		return models.ErrFieldNotFound
+	}
+
+	return f.deleter.DeleteCustomField(ctx, fieldID, userID)
+}
+
+// ResolveValues validates values by field names of the project,
+// nil value means the field should be cleared
+func (f Fields) ResolveValues(
+	ctx context.Context,
+	userID int64,
+	projectID *int64,
+	values map[string]*string,
+) ([]models.FieldValue, []int64, error) {
+	const op = "services.fields.ResolveValues"
+
+	if len(values) == 0 {
+		return nil, nil, nil
+	}
+	if projectID == nil {
+		return nil, nil, ErrTaskWithoutProject
+	}
+
+	defs, err := f.provider.SelectCustomFields(ctx, *projectID, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	byName := make(map[string]models.CustomField, len(defs))
+	for _, d := range defs {
+		byName[strings.ToLower(d.Name)] = d
+	}
+
+	var (
+		set   []models.FieldValue
+		clear []int64
+	)
+	for name, raw := range values {
+		def, ok := byName[strings.ToLower(name)]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %s", models.ErrFieldNotFound, name)
+		}
+
+		if raw == nil {
+			clear = append(clear, def.ID)
+			continue
+		}
+
+		v, err := Canonical(def, *raw)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if def.Type == models.FieldUser {
+			uid, _ := strconv.ParseInt(v, 10, 64)
+			if _, err = f.users.UserByID(ctx, uid); err != nil {
+				if errors.Is(err, models.ErrUserNotFound) {
+					return nil, nil, fmt.Errorf("%w: %s: user %d not found", models.ErrInvalidFieldValue, def.Name, uid)
+				}
+				return nil, nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+
+		set = append(set, models.FieldValue{FieldID: def.ID, Name: def.Name, Type: def.Type, Value: v})
+	}
+
+	return set, clear, nil
+}
+
+// Canonical validates raw value against field type and returns its stored form
+func Canonical(def models.CustomField, raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	invalid := fmt.Errorf("%w: %s expects %s", models.ErrInvalidFieldValue, def.Name, def.Type)
+
+	switch def.Type {
+	case models.FieldText:
+		return raw, nil
+	case models.FieldNumber:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return "", invalid
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case models.FieldDate:
+		if t, err := time.Parse(dateLayout, raw); err == nil {
+			return t.Format(dateLayout), nil
+		}
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return t.Format(dateLayout), nil
+		}
+		return "", invalid
+	case models.FieldSelect:
+		for _, o := range def.Options {
+			if strings.EqualFold(o, raw) {
+				return o, nil
+			}
+		}
+		return "", fmt.Errorf("%w: %s must be one of %s", models.ErrInvalidFieldValue, def.Name, strings.Join(def.Options, ", "))
+	case models.FieldCheckbox:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", invalid
+		}
+		return strconv.FormatBool(b), nil
+	case models.FieldUser:
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			return "", invalid
+		}
+		return strconv.FormatInt(id, 10), nil
+	default:
+		return "", models.ErrInvalidFieldType
+	}
+}
@@ -0,0 +1,176 @@
+package notifications
+
+import (
+	"TaskList/internal/lib/mention"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"unicode/utf8"
+)
+
+const commentPreviewLength = 80
+
+// HandleEvent turns the task event into notifications of watchers and mentioned users.
+// Owner and creator start watching created tasks, assignees and commenters the task they take part in.
+// The actor is never notified and users who lost access to the task are skipped.
+// Watchers of deleted tasks are notified and removed
+func (n Notifications) HandleEvent(ctx context.Context, e models.TaskEvent) {
+	const op = "services.notifications.HandleEvent"
+	log := n.log.With(slog.String("op", op), slog.String("event", string(e.Type)), slog.Int64("task_id", e.Task.ID))
+
+	if e.Type == models.EventTaskDeleted {
+		defer func() {
+			if err := n.updater.DeleteWatchers(ctx, e.Task.ID); err != nil {
+				log.Error("failed delete watchers", slog.String("err", err.Error()))
+			}
+		}()
+	}
+
+	for _, id := range autoWatchers(e) {
+		if err := n.saver.InsertWatcher(ctx, e.Task.ID, id); err != nil {
+			log.Error("failed add watcher", slog.String("err", err.Error()))
+		}
+	}
+
+	recipients := make(map[int64]models.EventType)
+	if e.Type != models.EventTaskCreated {
+		watchers, err := n.provider.SelectWatchers(ctx, e.Task.ID)
+		if err != nil {
+			log.Error("failed select watchers", slog.String("err", err.Error()))
+			return
+		}
+		for _, w := range watchers {
+			recipients[w.UserID] = e.Type
+		}
+	}
+
+	for _, id := range n.mentioned(ctx, log, e) {
+		recipients[id] = models.EventTaskMentioned
+	}
+	delete(recipients, e.ActorID)
+
+	if len(recipients) == 0 {
+		return
+	}
+
+	actor := "someone"
+	if user, err := n.users.UserByID(ctx, e.ActorID); err == nil {
+		actor = user.Email
+	}
+
+	for id, event := range recipients {
+		if e.Type != models.EventTaskDeleted {
+			if _, err := n.tasks.TasksByID(ctx, e.Task.ID, id); err != nil {
+				if !errors.Is(err, models.ErrTaskNotFound) {
+					log.Error("failed check access", slog.Int64("user_id", id), slog.String("err", err.Error()))
+				}
+				continue
+			}
+		}
+
+		if err := n.notify(ctx, id, e, event, actor); err != nil {
+			log.Error("failed create notification", slog.Int64("user_id", id), slog.String("err", err.Error()))
+		}
+	}
+}
+
+// mentioned returns users mentioned in the created task, the edited description or the comment
+// who can view the task, they start watching it. An edit notifies only mentions it added
+func (n Notifications) mentioned(ctx context.Context, log *slog.Logger, e models.TaskEvent) []int64 {
+	var text, previous string
+	switch {
+	case e.Type == models.EventTaskCreated:
+		text = e.Task.Title + "\n" + e.Task.Description
+	case e.Type == models.EventTaskUpdated && e.PreviousDescription != nil:
+		text = e.Task.Description
+		previous = *e.PreviousDescription
+	case e.Type == models.EventTaskCommented && e.Comment != nil:
+		text = e.Comment.Body
+	default:
+		return nil
+	}
+
+	known := mention.Parse(previous)
+
+	var res []int64
+	for _, email := range mention.Parse(text) {
+		if slices.Contains(known, email) {
+			continue
+		}
+		user, err := n.users.UserByEmail(ctx, email)
+		if err != nil {
+			if !errors.Is(err, models.ErrUserNotFound) {
+				log.Error("failed find mentioned user", slog.String("err", err.Error()))
+			}
+			continue
+		}
+		if _, err = n.tasks.TasksByID(ctx, e.Task.ID, user.ID); err != nil {
+			continue
+		}
+		if err = n.saver.InsertWatcher(ctx, e.Task.ID, user.ID); err != nil {
+			log.Error("failed add watcher", slog.String("err", err.Error()))
+		}
+		res = append(res, user.ID)
+	}
+	return res
+}
+
+func autoWatchers(e models.TaskEvent) []int64 {
+	switch e.Type {
+	case models.EventTaskCreated:
+		if e.Task.CreatedBy != 0 && e.Task.CreatedBy != e.Task.UserID {
+			return []int64{e.Task.UserID, e.Task.CreatedBy}
+		}
+		return []int64{e.Task.UserID}
+	case models.EventTaskAssigned:
+		if e.Task.AssigneeID != nil {
+			return []int64{*e.Task.AssigneeID}
+		}
+	case models.EventTaskCommented:
+		return []int64{e.ActorID}
+	}
+	return nil
+}
+
+func message(e models.TaskEvent, event models.EventType, actor string, recipient int64) string {
+	title := e.Task.Title
+	switch event {
+	case models.EventTaskMentioned:
+		if e.Type == models.EventTaskCommented {
+			return fmt.Sprintf("%s mentioned you in a comment on %q", actor, title)
+		}
+		return fmt.Sprintf("%s mentioned you in %q", actor, title)
+	case models.EventTaskCreated:
+		return fmt.Sprintf("%s created %q", actor, title)
+	case models.EventTaskStatusChanged:
+		return fmt.Sprintf("%s moved %q from %s to %s", actor, title, e.PreviousStatus, e.Task.Status)
+	case models.EventTaskAssigned:
+		switch {
+		case e.Task.AssigneeID == nil:
+			return fmt.Sprintf("%s unassigned %q", actor, title)
+		case *e.Task.AssigneeID == recipient:
+			return fmt.Sprintf("%s assigned %q to you", actor, title)
+		}
+		return fmt.Sprintf("%s reassigned %q", actor, title)
+	case models.EventTaskCommented:
+		if e.Comment == nil {
+			return fmt.Sprintf("%s commented on %q", actor, title)
+		}
+		return fmt.Sprintf("%s commented on %q: %s", actor, title, preview(e.Comment.Body))
+	case models.EventTaskDeleted:
+		return fmt.Sprintf("%s deleted %q", actor, title)
+	default:
+		return fmt.Sprintf("%s updated %q", actor, title)
+	}
+}
+
+func preview(s string) string {
+	if utf8.RuneCountInString(s) <= commentPreviewLength {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:commentPreviewLength]) + "…"
+}
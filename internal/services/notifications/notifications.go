@@ -0,0 +1,211 @@
+package notifications
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type Saver interface {
+	InsertNotification(ctx context.Context, n models.Notification) (int64, error)
+	InsertWatcher(ctx context.Context, taskID int64, userID int64) error
+	UpsertNotificationPreferences(ctx context.Context, userID int64, prefs models.NotificationPreferences) error
+}
+
+type Provider interface {
+	SelectNotifications(ctx context.Context, userID int64, unreadOnly bool, limit int) ([]models.Notification, error)
+	CountUnreadNotifications(ctx context.Context, userID int64) (int, error)
+	SelectWatchers(ctx context.Context, taskID int64) ([]models.Watcher, error)
+	SelectNotificationPreferences(ctx context.Context, userID int64) (models.NotificationPreferences, error)
+}
+
+type Updater interface {
+	UpdateNotificationRead(ctx context.Context, id int64, userID int64, read bool) error
+	MarkAllNotificationsRead(ctx context.Context, userID int64) (int64, error)
+	DeleteWatcher(ctx context.Context, taskID int64, userID int64) error
+	DeleteWatchers(ctx context.Context, taskID int64) error
+}
+
+type UserProvider interface {
+	UserByID(ctx context.Context, userID int64) (*models.User, error)
+	UserByEmail(ctx context.Context, email string) (*models.User, error)
+}
+
+// Tasks checks access to the task, implemented by tasks service
+type Tasks interface {
+	TasksByID(ctx context.Context, taskID int64, userID int64) (models.Task, error)
+}
+
+type Notifications struct {
+	saver    Saver
+	provider Provider
+	updater  Updater
+	users    UserProvider
+	tasks    Tasks
+	cfg      *config.Config
+	log      *slog.Logger
+}
+
+func NewServices(
+	s Saver,
+	p Provider,
+	u Updater,
+	users UserProvider,
+	t Tasks,
+	cfg *config.Config,
+	log *slog.Logger,
+) *Notifications {
+	return &Notifications{
+		saver:    s,
+		provider: p,
+		updater:  u,
+		users:    users,
+		tasks:    t,
+		cfg:      cfg,
+		log:      log,
+	}
+}
+
+// Notifications returns the newest notifications of the user and the number of unread ones
+func (n Notifications) Notifications(ctx context.Context, userID int64, unreadOnly bool, limit int) ([]models.Notification, int, error) {
+	const op = "services.notifications.Notifications"
+
+	list, err := n.provider.SelectNotifications(ctx, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	unread, err := n.provider.CountUnreadNotifications(ctx, userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return list, unread, nil
+}
+
+func (n Notifications) MarkRead(ctx context.Context, userID int64, id int64, read bool) error {
+	const op = "services.notifications.MarkRead"
+
+	if err := n.updater.UpdateNotificationRead(ctx, id, userID, read); err != nil {
+		if errors.Is(err, models.ErrNotificationNotFound) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkAllRead marks every unread notification of the user read and returns their number
+func (n Notifications) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	const op = "services.notifications.MarkAllRead"
+
+	count, err := n.updater.MarkAllNotificationsRead(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count, nil
+}
+
+// Preferences returns the setting of every event type, events without stored setting are enabled
+func (n Notifications) Preferences(ctx context.Context, userID int64) (models.NotificationPreferences, error) {
+	const op = "services.notifications.Preferences"
+
+	stored, err := n.provider.SelectNotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	prefs := make(models.NotificationPreferences, len(models.EventTypes()))
+	for _, t := range models.EventTypes() {
+		prefs[t] = stored.Enabled(t)
+	}
+
+	return prefs, nil
+}
+
+// SetPreferences changes settings of the given event types, other settings are kept
+func (n Notifications) SetPreferences(ctx context.Context, userID int64, prefs models.NotificationPreferences) (models.NotificationPreferences, error) {
+	const op = "services.notifications.SetPreferences"
+
+	for t := range prefs {
+		if !t.Valid() {
+			return nil, fmt.Errorf("%w: %s", models.ErrInvalidEventType, t)
+		}
+	}
+
+	if err := n.saver.UpsertNotificationPreferences(ctx, userID, prefs); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n.Preferences(ctx, userID)
+}
+
+// Watch subscribes the user to notifications about the task, the task must be visible to the user
+func (n Notifications) Watch(ctx context.Context, taskID int64, userID int64) error {
+	const op = "services.notifications.Watch"
+
+	if _, err := n.tasks.TasksByID(ctx, taskID, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := n.saver.InsertWatcher(ctx, taskID, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (n Notifications) Unwatch(ctx context.Context, taskID int64, userID int64) error {
+	const op = "services.notifications.Unwatch"
+
+	if _, err := n.tasks.TasksByID(ctx, taskID, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := n.updater.DeleteWatcher(ctx, taskID, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (n Notifications) Watchers(ctx context.Context, taskID int64, userID int64) ([]models.Watcher, error) {
+	const op = "services.notifications.Watchers"
+
+	if _, err := n.tasks.TasksByID(ctx, taskID, userID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	watchers, err := n.provider.SelectWatchers(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return watchers, nil
+}
+
+func (n Notifications) notify(ctx context.Context, userID int64, e models.TaskEvent, event models.EventType, actor string) error {
+	prefs, err := n.provider.SelectNotificationPreferences(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !prefs.Enabled(event) {
+		return nil
+	}
+
+	_, err = n.saver.InsertNotification(ctx, models.Notification{
+		UserID:    userID,
+		TaskID:    e.Task.ID,
+		ActorID:   e.ActorID,
+		Event:     event,
+		Message:   message(e, event, actor, userID),
+		CreatedAt: time.Now().UTC(),
+	})
+	return err
+}
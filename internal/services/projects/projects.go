@@ -0,0 +1,75 @@
+package projects
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+var (
+	ErrEmptyName = errors.New("project name is empty")
+)
+
+type Saver interface {
+	InsertProject(ctx context.Context, project models.Project) (int64, error)
+}
+
+type Provider interface {
+	SelectProjectsByUserID(ctx context.Context, userID int64) ([]models.Project, error)
+	SelectProjectByID(ctx context.Context, projectID int64, userID int64) (models.Project, error)
+}
+
+type Updater interface {
+	UpdateProject(ctx context.Context, project models.Project) error
+	DeleteProject(ctx context.Context, projectID int64, userID int64) error
+}
+
+type Projects struct {
+	saver    Saver
+	provider Provider
+	updater  Updater
+	cfg      *config.Config
+	log      *slog.Logger
+}
+
+func NewServices(s Saver, p Provider, u Updater, cfg *config.Config, log *slog.Logger) *Projects {
+	return &Projects{saver: s, provider: p, updater: u, cfg: cfg, log: log}
+}
+
+func (p Projects) CreateProject(ctx context.Context, project models.Project) (int64, error) {
+	project.Name = strings.TrimSpace(project.Name)
+	if project.Name == "" {
+		return 0, ErrEmptyName
+	}
+
+	id, err := p.saver.InsertProject(ctx, project)
+	if err != nil {
+		return 0, fmt.Errorf("services.projects.CreateProject: %w", err)
+	}
+	return id, nil
+}
+
+func (p Projects) Projects(ctx context.Context, userID int64) ([]models.Project, error) {
+	return p.provider.SelectProjectsByUserID(ctx, userID)
+}
+
+func (p Projects) Project(ctx context.Context, projectID int64, userID int64) (models.Project, error) {
+	return p.provider.SelectProjectByID(ctx, projectID, userID)
+}
+
+func (p Projects) RenameProject(ctx context.Context, project models.Project) error {
+	project.Name = strings.TrimSpace(project.Name)
+	if project.Name == "" {
+		return ErrEmptyName
+	}
+	return p.updater.UpdateProject(ctx, project)
+}
+
+// DeleteProject deletes the project and its workflow, tasks stay without project
+func (p Projects) DeleteProject(ctx context.Context, projectID int64, userID int64) error {
+	return p.updater.DeleteProject(ctx, projectID, userID)
+}
@@ -0,0 +1,167 @@
+package realtime
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/models"
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// sessionBuffer is the number of undelivered events after which the session is dropped
+const sessionBuffer = 256
+
+// Tasks checks the user can see the task, implemented by tasks service
+type Tasks interface {
+	CanView(ctx context.Context, task models.Task, userID int64) (bool, error)
+}
+
+// Session is one realtime connection of the user, it receives events of subscribed projects and tasks
+type Session struct {
+	UserID int64
+
+	events   chan models.TaskEvent
+	dropped  chan struct{}
+	once     sync.Once
+	mu       sync.RWMutex
+	projects map[int64]struct{}
+	tasks    map[int64]struct{}
+}
+
+// Events are task events for the session in publish order
+func (s *Session) Events() <-chan models.TaskEvent {
+	return s.events
+}
+
+// Dropped is closed when the session could not keep up with events
+func (s *Session) Dropped() <-chan struct{} {
+	return s.dropped
+}
+
+func (s *Session) SubscribeProject(projectID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projects[projectID] = struct{}{}
+}
+
+func (s *Session) UnsubscribeProject(projectID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.projects, projectID)
+}
+
+func (s *Session) SubscribeTask(taskID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks[taskID] = struct{}{}
+}
+
+func (s *Session) UnsubscribeTask(taskID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tasks, taskID)
+}
+
+func (s *Session) subscribed(task models.Task) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.tasks[task.ID]; ok {
+		return true
+	}
+	if task.ProjectID != nil {
+		_, ok := s.projects[*task.ProjectID]
+		return ok
+	}
+	return false
+}
+
+func (s *Session) drop() {
+	s.once.Do(func() { close(s.dropped) })
+}
+
+// Realtime keeps connected sessions and fans task events out to every session of a user
+type Realtime struct {
+	mu       sync.Mutex
+	sessions map[int64]map[*Session]struct{}
+	tasks    Tasks
+	cfg      *config.Config
+	log      *slog.Logger
+}
+
+func NewServices(t Tasks, cfg *config.Config, log *slog.Logger) *Realtime {
+	return &Realtime{
+		sessions: make(map[int64]map[*Session]struct{}),
+		tasks:    t,
+		cfg:      cfg,
+		log:      log,
+	}
+}
+
+func (rt *Realtime) Register(userID int64) *Session {
+	s := &Session{
+		UserID:   userID,
+		events:   make(chan models.TaskEvent, sessionBuffer),
+		dropped:  make(chan struct{}),
+		projects: make(map[int64]struct{}),
+		tasks:    make(map[int64]struct{}),
+	}
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if rt.sessions[userID] == nil {
+		rt.sessions[userID] = make(map[*Session]struct{})
+	}
+	rt.sessions[userID][s] = struct{}{}
+
+	return s
+}
+
+func (rt *Realtime) Unregister(s *Session) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	delete(rt.sessions[s.UserID], s)
+	if len(rt.sessions[s.UserID]) == 0 {
+		delete(rt.sessions, s.UserID)
+	}
+	s.drop()
+}
+
+// HandleEvent delivers the event to subscribed sessions of users who can see the task.
+// A session whose buffer is full is dropped instead of blocking the publisher
+func (rt *Realtime) HandleEvent(ctx context.Context, e models.TaskEvent) {
+	const op = "services.realtime.HandleEvent"
+
+	rt.mu.Lock()
+	targets := make(map[int64][]*Session, len(rt.sessions))
+	for userID, sessions := range rt.sessions {
+		for s := range sessions {
+			if s.subscribed(e.Task) {
+				targets[userID] = append(targets[userID], s)
+			}
+		}
+	}
+	rt.mu.Unlock()
+
+	for userID, sessions := range targets {
+		ok, err := rt.tasks.CanView(ctx, e.Task, userID)
+		if err != nil {
+			rt.log.Error("failed check access", slog.String("op", op), slog.Int64("user_id", userID), slog.String("err", err.Error()))
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		for _, s := range sessions {
+			select {
+			case s.events <- e:
+			default:
+				rt.log.Warn("slow realtime session dropped", slog.String("op", op), slog.Int64("user_id", userID))
+				rt.Unregister(s)
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package smartlists
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrEmptyName = errors.New("smart list name is empty")
+)
+
+type Saver interface {
+	InsertSmartList(ctx context.Context, list models.SmartList) (int64, error)
+}
+
+type Provider interface {
+	SelectSmartLists(ctx context.Context, userID int64) ([]models.SmartList, error)
+	SelectSmartListByID(ctx context.Context, listID int64, userID int64) (models.SmartList, error)
+}
+
+type Updater interface {
+	UpdateSmartList(ctx context.Context, list models.SmartList) error
+	DeleteSmartList(ctx context.Context, listID int64, userID int64) error
+}
+
+// Tasks evaluates filters, implemented by tasks service
+type Tasks interface {
+	Tasks(ctx context.Context, userID int64, filter models.TaskFilter) ([]models.Task, error)
+}
+
+type SmartLists struct {
+	saver    Saver
+	provider Provider
+	updater  Updater
+	tasks    Tasks
+	cfg      *config.Config
+	log      *slog.Logger
+}
+
+func NewServices(s Saver, p Provider, u Updater, t Tasks, cfg *config.Config, log *slog.Logger) *SmartLists {
+	return &SmartLists{saver: s, provider: p, updater: u, tasks: t, cfg: cfg, log: log}
+}
+
+// SmartLists returns built-in lists followed by user lists
+func (s SmartLists) SmartLists(ctx context.Context, userID int64) ([]models.SmartList, error) {
+	const op = "services.smartlists.SmartLists"
+
+	lists, err := s.provider.SelectSmartLists(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return append(builtIn(userID), lists...), nil
+}
+
+// SmartList finds list by built-in key (today, upcoming, overdue) or by numeric id
+func (s SmartLists) SmartList(ctx context.Context, userID int64, id string) (models.SmartList, error) {
+	for _, l := range builtIn(userID) {
+		if strings.EqualFold(l.Key, id) {
+			return l, nil
+		}
+	}
+
+	listID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return models.SmartList{}, models.ErrSmartListNotFound
+	}
+
+	return s.provider.SelectSmartListByID(ctx, listID, userID)
+}
+
+func (s SmartLists) CreateSmartList(ctx context.Context, list models.SmartList) (int64, error) {
+	const op = "services.smartlists.CreateSmartList"
+
+	if err := validate(&list); err != nil {
+		return 0, err
+	}
+
+	id, err := s.saver.InsertSmartList(ctx, list)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return id, nil
+}
+
+func (s SmartLists) UpdateSmartList(ctx context.Context, id string, list models.SmartList) error {
+	listID, err := s.storedID(id)
+	if err != nil {
+		return err
+	}
+	list.ID = listID
+
+	if err := validate(&list); err != nil {
+		return err
+	}
+
+	return s.updater.UpdateSmartList(ctx, list)
+}
+
+func (s SmartLists) DeleteSmartList(ctx context.Context, userID int64, id string) error {
+	listID, err := s.storedID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.updater.DeleteSmartList(ctx, listID, userID)
+}
+
+// Tasks runs the stored query of the list
+func (s SmartLists) Tasks(ctx context.Context, userID int64, id string) (models.SmartList, []models.Task, error) {
+	const op = "services.smartlists.Tasks"
+
+	list, err := s.SmartList(ctx, userID, id)
+	if err != nil {
+		return models.SmartList{}, nil, err
+	}
+
+	tasks, err := s.tasks.Tasks(ctx, userID, list.Filter)
+	if err != nil {
+		return models.SmartList{}, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return list, tasks, nil
+}
+
+func (s SmartLists) storedID(id string) (int64, error) {
+	for _, l := range models.BuiltInSmartLists() {
+		if strings.EqualFold(l.Key, id) {
+			return 0, models.ErrSmartListReadOnly
+		}
+	}
+
+	listID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, models.ErrSmartListNotFound
+	}
+	return listID, nil
+}
+
+func validate(list *models.SmartList) error {
+	list.Name = strings.TrimSpace(list.Name)
+	if list.Name == "" {
+		return ErrEmptyName
+	}
+	return list.Filter.Validate()
+}
+
+func builtIn(userID int64) []models.SmartList {
+	lists := models.BuiltInSmartLists()
+	for i := range lists {
+		lists[i].UserID = userID
+	}
+	return lists
+}
@@ -0,0 +1,270 @@
+package tasks
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// permission returns access level of the user to the task: owner, the highest of
+// the share permission and the workspace role permission, editor for assignee, none otherwise
+func (t Tasks) permission(ctx context.Context, task models.Task, userID int64) (models.Permission, error) {
+	if task.UserID == userID {
+		return models.PermissionOwner, nil
+	}
+
+	p := models.PermissionNone
+
+	share, err := t.provider.SelectTaskShare(ctx, task.ID, userID)
+	switch {
+	case err == nil:
+		p = share.Permission
+	case !errors.Is(err, models.ErrShareNotFound):
+		return models.PermissionNone, err
+	}
+
+	if task.WorkspaceID != nil {
+		wp, err := t.access.TaskPermission(ctx, userID, *task.WorkspaceID)
+		if err != nil {
+			return models.PermissionNone, err
+		}
+		if wp.Allows(p) {
+			p = wp
+		}
+	}
+
+	if p == models.PermissionNone && task.AssigneeID != nil && *task.AssigneeID == userID {
+		return models.PermissionEditor, nil
+	}
+
+	return p, nil
+}
+
+// CanView tells whether the user can see the task, the task may be already deleted
+func (t Tasks) CanView(ctx context.Context, task models.Task, userID int64) (bool, error) {
+	p, err := t.permission(ctx, task, userID)
+	if err != nil {
+		return false, err
+	}
+	return p != models.PermissionNone, nil
+}
+
+// Viewers returns users who can see the task, the same users CanView allows.
+// Shares of a deleted task are gone, so its viewers are the owner, the assignee and workspace members
+func (t Tasks) Viewers(ctx context.Context, task models.Task) ([]int64, error) {
+	const op = "services.tasks.Viewers"
+
+	ids := []int64{task.UserID}
+	if task.AssigneeID != nil {
+		ids = append(ids, *task.AssigneeID)
+	}
+
+	shares, err := t.provider.SelectTaskShares(ctx, task.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for _, s := range shares {
+		if s.Permission != models.PermissionNone {
+			ids = append(ids, s.UserID)
+		}
+	}
+
+	if task.WorkspaceID != nil {
+		members, err := t.access.TaskViewers(ctx, *task.WorkspaceID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ids = append(ids, members...)
+	}
+
+	slices.Sort(ids)
+	return slices.Compact(ids), nil
+}
+
+// authorizedTask loads the task and checks the user has required permission,
+// tasks without any access are reported as not found to hide their existence
+func (t Tasks) authorizedTask(ctx context.Context, taskID int64, userID int64, required models.Permission) (models.Task, error) {
+	task, err := t.provider.SelectTaskByID(ctx, taskID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	p, err := t.permission(ctx, task, userID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	if p == models.PermissionNone {
+		return models.Task{}, models.ErrTaskNotFound
+	}
+	if !p.Allows(required) {
+		return models.Task{}, fmt.Errorf("%w: %s permission required", models.ErrForbidden, required)
+	}
+
+	return task, nil
+}
+
+func (t Tasks) scopeTasks(ctx context.Context, userID int64, filter models.TaskFilter) ([]models.Task, error) {
+	if filter.WorkspaceID != nil {
+		p, err := t.access.TaskPermission(ctx, userID, *filter.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if p == models.PermissionNone {
+			return nil, models.ErrWorkspaceNotFound
+		}
+		return t.provider.SelectTasksByWorkspace(ctx, *filter.WorkspaceID)
+	}
+
+	switch filter.Scope {
+	case models.ScopeAssigned:
+		return t.provider.SelectTasksAssignedTo(ctx, userID)
+	case models.ScopeShared:
+		return t.provider.SelectTasksSharedWith(ctx, userID)
+	case models.ScopeAll:
+		var res []models.Task
+		seen := make(map[int64]struct{})
+		for _, load := range []func(context.Context, int64) ([]models.Task, error){
+			t.provider.SelectAllTasksByUserID,
+			t.provider.SelectTasksSharedWith,
+			t.provider.SelectTasksAssignedTo,
+		} {
+			tasks, err := load(ctx, userID)
+			if err != nil {
+				return nil, err
+			}
+			for _, task := range tasks {
+				if _, ok := seen[task.ID]; ok {
+					continue
+				}
+				seen[task.ID] = struct{}{}
+				res = append(res, task)
+			}
+		}
+		sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+		return res, nil
+	default:
+		return t.provider.SelectAllTasksByUserID(ctx, userID)
+	}
+}
+
+// AssignTask assigns the task to the user with the email, empty email removes assignee.
+// Assignee must have access to the task: be its owner, a user it is shared with or a workspace member
+func (t Tasks) AssignTask(ctx context.Context, taskID int64, userID int64, email string) error {
+	const op = "services.tasks.AssignTask"
+
+	task, err := t.authorizedTask(ctx, taskID, userID, models.PermissionEditor)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var assigneeID *int64
+	if email = strings.TrimSpace(email); email != "" {
+		assignee, err := t.users.UserByEmail(ctx, email)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		task.AssigneeID = nil
+		p, err := t.permission(ctx, task, assignee.ID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if p == models.PermissionNone {
+			return models.ErrAssigneeNoAccess
+		}
+		assigneeID = &assignee.ID
+	}
+
+	if err = t.updater.UpdateTaskAssignee(ctx, taskID, assigneeID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	t.log.Info("task assignee changed", slog.String("op", op), slog.Int64("task_id", taskID), slog.Int64("user_id", userID))
+
+	t.publish(ctx, models.TaskEvent{Type: models.EventTaskAssigned, Task: task, ActorID: userID})
+
+	return nil
+}
+
+// ShareTask gives the user with the email viewer or editor access, only the owner and workspace admins can share
+func (t Tasks) ShareTask(ctx context.Context, taskID int64, userID int64, email string, permission models.Permission) (models.TaskShare, error) {
+	const op = "services.tasks.ShareTask"
+
+	if !permission.Valid() {
+		return models.TaskShare{}, fmt.Errorf("%w: %s", models.ErrInvalidPermission, permission)
+	}
+
+	task, err := t.authorizedTask(ctx, taskID, userID, models.PermissionOwner)
+	if err != nil {
+		return models.TaskShare{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := t.users.UserByEmail(ctx, strings.TrimSpace(email))
+	if err != nil {
+		return models.TaskShare{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if user.ID == task.UserID {
+		return models.TaskShare{}, models.ErrShareWithOwner
+	}
+
+	share := models.TaskShare{TaskID: taskID, UserID: user.ID, Permission: permission}
+	if err = t.updater.UpsertTaskShare(ctx, share); err != nil {
+		return models.TaskShare{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if share, err = t.provider.SelectTaskShare(ctx, taskID, user.ID); err != nil {
+		return models.TaskShare{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	t.log.Info(
+		"task shared",
+		slog.String("op", op),
+		slog.Int64("task_id", taskID),
+		slog.Int64("shared_with", user.ID),
+		slog.String("permission", string(permission)),
+	)
+
+	return share, nil
+}
+
+// TaskShares lists users the task is shared with, available to everyone who can view the task
+func (t Tasks) TaskShares(ctx context.Context, taskID int64, userID int64) ([]models.TaskShare, error) {
+	const op = "services.tasks.TaskShares"
+
+	if _, err := t.authorizedTask(ctx, taskID, userID, models.PermissionViewer); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	shares, err := t.provider.SelectTaskShares(ctx, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return shares, nil
+}
+
+// UnshareTask revokes access of the user, owner can revoke anyone and users can leave shared tasks
+func (t Tasks) UnshareTask(ctx context.Context, taskID int64, userID int64, sharedWith int64) error {
+	const op = "services.tasks.UnshareTask"
+
+	required := models.PermissionOwner
+	if sharedWith == userID {
+		required = models.PermissionViewer
+	}
+
+	if _, err := t.authorizedTask(ctx, taskID, userID, required); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := t.updater.DeleteTaskShare(ctx, taskID, sharedWith); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package tasks
+
+import (
+	"TaskList/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const customFieldPrefix = "cf."
+
+var priorityRank = map[models.Priority]int{
+	models.PriorityHigh:   3,
+	models.PriorityMedium: 2,
+	models.PriorityLow:    1,
+	models.PriorityNone:   0,
+}
+
+// applyFilter filters and sorts tasks, now is used for relative due windows and carries user location
+func applyFilter(tasks []models.Task, f models.TaskFilter, now time.Time) []models.Task {
+	res := make([]models.Task, 0, len(tasks))
+	for _, t := range tasks {
+		if matchFilter(t, f, now) {
+			res = append(res, t)
+		}
+	}
+
+	sortTasks(res, f.Sort, f.Desc)
+
+	return res
+}
+
+func matchFilter(t models.Task, f models.TaskFilter, now time.Time) bool {
+	if f.ProjectID != nil && (t.ProjectID == nil || *t.ProjectID != *f.ProjectID) {
+		return false
+	}
+
+	if len(f.Statuses) > 0 && !containsFold(f.Statuses, t.Status) {
+		return false
+	}
+
+	if len(f.Categories) > 0 && !contains(f.Categories, t.StatusCategory) {
+		return false
+	}
+
+	for _, tag := range f.Tags {
+		if !contains(t.Tags, strings.ToLower(strings.TrimPrefix(tag, "#"))) {
+			return false
+		}
+	}
+
+	if f.Text != "" {
+		text := strings.ToLower(f.Text)
+		if !strings.Contains(strings.ToLower(t.Title), text) && !strings.Contains(strings.ToLower(t.Description), text) {
+			return false
+		}
+	}
+
+	if !matchDue(t.DueAt, f, now) {
+		return false
+	}
+
+	for _, c := range f.Fields {
+		v, ok := fieldValue(t, c.Name)
+		if !matchCondition(v, ok, c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchDue(due *time.Time, f models.TaskFilter, now time.Time) bool {
+	if f.Due == models.DueAny {
+		return true
+	}
+	if f.Due == models.DueNone {
+		return due == nil
+	}
+	if due == nil {
+		return false
+	}
+
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	d := due.In(now.Location())
+
+	switch f.Due {
+	case models.DueOverdue:
+		return d.Before(now)
+	case models.DueToday:
+		return !d.Before(startOfToday) && d.Before(startOfToday.AddDate(0, 0, 1))
+	case models.DueUpcoming:
+		days := f.DueDays
+		if days <= 0 {
+			days = models.DefaultUpcomingDays
+		}
+		return !d.Before(now) && d.Before(startOfToday.AddDate(0, 0, days+1))
+	case models.DueRange:
+		if f.DueFrom != nil && d.Before(*f.DueFrom) {
+			return false
+		}
+		if f.DueTo != nil && d.After(*f.DueTo) {
+			return false
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+func contains[T comparable](s []T, v T) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(s []models.Status, v models.Status) bool {
+	for _, e := range s {
+		if strings.EqualFold(string(e), string(v)) {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldValue(t models.Task, name string) (models.FieldValue, bool) {
+	for _, v := range t.CustomFields {
+		if strings.EqualFold(v.Name, name) {
+			return v, true
+		}
+	}
+	return models.FieldValue{}, false
+}
+
+func matchCondition(v models.FieldValue, ok bool, c models.FieldCondition) bool {
+	if !ok {
+		return c.Op == "ne"
+	}
+
+	if c.Op == "contains" {
+		return strings.Contains(strings.ToLower(v.Value), strings.ToLower(c.Value))
+	}
+
+	cmp, comparable := compareFieldValue(v, c.Value)
+	if !comparable {
+		return c.Op == "ne"
+	}
+
+	switch c.Op {
+	case "ne":
+		return cmp != 0
+	case "gt":
+		return cmp > 0
+	case "gte":
+		return cmp >= 0
+	case "lt":
+		return cmp < 0
+	case "lte":
+		return cmp <= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// compareFieldValue compares stored value with the raw value according to the field type
+func compareFieldValue(v models.FieldValue, raw string) (int, bool) {
+	raw = strings.TrimSpace(raw)
+
+	switch v.Type {
+	case models.FieldNumber, models.FieldUser:
+		a, err1 := strconv.ParseFloat(v.Value, 64)
+		b, err2 := strconv.ParseFloat(raw, 64)
+		if err1 != nil || err2 != nil {
+			return 0, false
+		}
+		return compareFloat(a, b), true
+	case models.FieldCheckbox:
+		a, err1 := strconv.ParseBool(v.Value)
+		b, err2 := strconv.ParseBool(raw)
+		if err1 != nil || err2 != nil {
+			return 0, false
+		}
+		if a == b {
+			return 0, true
+		}
+		if !a {
+			return -1, true
+		}
+		return 1, true
+	default:
+		// dates are stored as 2006-01-02 so they are ordered lexicographically
+		return strings.Compare(strings.ToLower(v.Value), strings.ToLower(raw)), true
+	}
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// sortTasks orders tasks by the key, tasks without value are always at the end
+func sortTasks(tasks []models.Task, key string, desc bool) {
+	if key == "" {
+		key = "created"
+	}
+
+	compare := func(a, b models.Task) (int, bool, bool) {
+		switch {
+		case key == "updated":
+			return a.UpdatedAt.Compare(b.UpdatedAt), true, true
+		case key == "due":
+			if a.DueAt == nil || b.DueAt == nil {
+				return 0, a.DueAt != nil, b.DueAt != nil
+			}
+			return a.DueAt.Compare(*b.DueAt), true, true
+		case key == "title":
+			return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)), true, true
+		case key == "priority":
+			return priorityRank[a.Priority] - priorityRank[b.Priority], true, true
+		case key == "status":
+			return strings.Compare(string(a.Status), string(b.Status)), true, true
+		case strings.HasPrefix(key, customFieldPrefix):
+			name := strings.TrimPrefix(key, customFieldPrefix)
+			av, aok := fieldValue(a, name)
+			bv, bok := fieldValue(b, name)
+			if !aok || !bok {
+				return 0, aok, bok
+			}
+			cmp, _ := compareFieldValue(av, bv.Value)
+			return cmp, true, true
+		default:
+			if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+				return c, true, true
+			}
+			return compareFloat(float64(a.ID), float64(b.ID)), true, true
+		}
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		cmp, iok, jok := compare(tasks[i], tasks[j])
+		if !iok || !jok {
+			return iok && !jok
+		}
+		if desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+}
@@ -2,9 +2,12 @@ package tasks
 
 import (
 	"TaskList/internal/config"
+	"TaskList/internal/lib/quickadd"
 	"TaskList/internal/models"
 	"context"
+	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 )
 
@@ -14,45 +17,456 @@ type Saver interface {
 
 type Provider interface {
 	SelectAllTasksByUserID(ctx context.Context, userID int64) ([]models.Task, error)
-	SelectTaskByID(ctx context.Context, taskID int64, userID int64) (models.Task, error)
+	SelectTasksAssignedTo(ctx context.Context, userID int64) ([]models.Task, error)
+	SelectTasksSharedWith(ctx context.Context, userID int64) ([]models.Task, error)
+	SelectTasksByWorkspace(ctx context.Context, workspaceID int64) ([]models.Task, error)
+	SelectTaskByID(ctx context.Context, taskID int64) (models.Task, error)
+	CountTasksByStatus(ctx context.Context, userID int64, projectID *int64, status models.Status) (int, error)
+	SelectTaskShare(ctx context.Context, taskID int64, userID int64) (models.TaskShare, error)
+	SelectTaskShares(ctx context.Context, taskID int64) ([]models.TaskShare, error)
 }
 
 type Updater interface {
-	UpdateStatusTask(ctx context.Context, taskID int64, userID int64) error
+	UpdateStatusTask(ctx context.Context, taskID int64, userID int64, status models.Status) error
+	SetTaskFieldValues(ctx context.Context, taskID int64, values []models.FieldValue, clear []int64) error
+	UpdateTaskAssignee(ctx context.Context, taskID int64, assigneeID *int64) error
+	UpsertTaskShare(ctx context.Context, share models.TaskShare) error
+	DeleteTaskShare(ctx context.Context, taskID int64, userID int64) error
+	UpdateTask(ctx context.Context, task models.Task) error
+	DeleteTask(ctx context.Context, taskID int64) error
+}
+
+type UserProvider interface {
+	UserByID(ctx context.Context, userID int64) (*models.User, error)
+	UserByEmail(ctx context.Context, email string) (*models.User, error)
+}
+
+type Workflow interface {
+	Workflow(ctx context.Context, userID int64, projectID *int64) ([]models.WorkflowStatus, error)
+}
+
+type Fields interface {
+	ResolveValues(
+		ctx context.Context,
+		userID int64,
+		projectID *int64,
+		values map[string]*string,
+	) ([]models.FieldValue, []int64, error)
+}
+
+// Access resolves permissions given by workspace roles, implemented by authz service
+type Access interface {
+	TaskPermission(ctx context.Context, userID int64, workspaceID int64) (models.Permission, error)
+	TaskViewers(ctx context.Context, workspaceID int64) ([]int64, error)
+}
+
+// Transactor runs fn in one transaction, storage calls made with the ctx given to fn join it.
+// AfterCommit defers fn until the transaction of the ctx is committed
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	AfterCommit(ctx context.Context, fn func(ctx context.Context))
+}
+
+// Publisher delivers task events to subscribers such as notifications
+type Publisher interface {
+	Publish(ctx context.Context, e models.TaskEvent)
 }
 
 type Tasks struct {
 	saver    Saver
 	provider Provider
 	updater  Updater
+	users    UserProvider
+	workflow Workflow
+	fields   Fields
+	access   Access
+	tx       Transactor
+	events   Publisher
 	cfg      *config.Config
 	log      *slog.Logger
 }
 
-func NewServices(s Saver, p Provider, u Updater, cfg *config.Config, log *slog.Logger) *Tasks {
-	return &Tasks{saver: s, provider: p, updater: u, cfg: cfg, log: log}
+func NewServices(
+	s Saver,
+	p Provider,
+	u Updater,
+	users UserProvider,
+	workflow Workflow,
+	fields Fields,
+	access Access,
+	tx Transactor,
+	events Publisher,
+	cfg *config.Config,
+	log *slog.Logger,
+) *Tasks {
+	return &Tasks{
+		saver:    s,
+		provider: p,
+		updater:  u,
+		users:    users,
+		workflow: workflow,
+		fields:   fields,
+		access:   access,
+		tx:       tx,
+		events:   events,
+		cfg:      cfg,
+		log:      log,
+	}
 }
 
+// CreateTask creates task in the first column of the workflow if status is not set,
+// if only StatusCategory is set the first status of the category is used.
+// CustomFields are matched by name and validated against field definitions of the project
 func (t Tasks) CreateTask(ctx context.Context, task models.Task) (int64, error) {
-	return t.saver.InsertTask(ctx, task)
+	const op = "services.tasks.CreateTask"
+
+	statuses, err := t.workflow.Workflow(ctx, task.UserID, task.ProjectID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	ws, err := resolveStatus(statuses, task.Status, task.StatusCategory)
+	if err != nil {
+		return 0, err
+	}
+	task.Status = ws.Name
+	task.StatusCategory = ws.Category
+
+	if len(task.CustomFields) > 0 {
+		raw := make(map[string]*string, len(task.CustomFields))
+		for _, v := range task.CustomFields {
+			raw[v.Name] = &v.Value
+		}
+		if task.CustomFields, _, err = t.fields.ResolveValues(ctx, task.UserID, task.ProjectID, raw); err != nil {
+			return 0, err
+		}
+	}
+
+	task.Tags = normalizeTags(task.Tags)
+	if task.CreatedBy == 0 {
+		task.CreatedBy = task.UserID
+	}
+
+	var id int64
+	err = t.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := t.checkWIPLimit(ctx, task.UserID, task.ProjectID, ws); err != nil {
+			return err
+		}
+		id, err = t.saver.InsertTask(ctx, task)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	t.publish(ctx, models.TaskEvent{Type: models.EventTaskCreated, Task: models.Task{ID: id}, ActorID: task.CreatedBy})
+
+	return id, nil
 }
 
-func (t Tasks) Tasks(ctx context.Context, userID int64) ([]models.Task, error) {
-	return t.provider.SelectAllTasksByUserID(ctx, userID)
+// SetTaskFields sets custom field values by field name, nil value clears the field.
+// Fields are defined by the task owner, so editors use the owner's definitions
+func (t Tasks) SetTaskFields(ctx context.Context, taskID int64, userID int64, values map[string]*string) error {
+	const op = "services.tasks.SetTaskFields"
+
+	task, err := t.authorizedTask(ctx, taskID, userID, models.PermissionEditor)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	set, clear, err := t.fields.ResolveValues(ctx, task.UserID, task.ProjectID, values)
+	if err != nil {
+		return err
+	}
+
+	if err = t.updater.SetTaskFieldValues(ctx, taskID, set, clear); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	t.publish(ctx, models.TaskEvent{Type: models.EventTaskUpdated, Task: task, ActorID: userID})
+
+	return nil
 }
 
+// QuickAdd parses single line relative to the user's timezone and creates the task,
+// with dryRun only the interpretation is returned
+func (t Tasks) QuickAdd(ctx context.Context, userID int64, line string, dryRun bool) (models.Task, quickadd.Result, error) {
+	const op = "services.tasks.QuickAdd"
+
+	user, err := t.users.UserByID(ctx, userID)
+	if err != nil {
+		return models.Task{}, quickadd.Result{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	parsed, err := quickadd.Parse(line, time.Now().In(user.Location()))
+	if err != nil {
+		return models.Task{}, quickadd.Result{}, err
+	}
+
+	task := models.Task{
+		UserID:     userID,
+		CreatedBy:  userID,
+		Title:      parsed.Title,
+		Priority:   models.Priority(parsed.Priority),
+		Tags:       parsed.Tags,
+		Recurrence: parsed.Recurrence,
+	}
+	if parsed.Due != nil {
+		due := parsed.Due.UTC()
+		task.DueAt = &due
+	}
+
+	if dryRun {
+		return task, parsed, nil
+	}
+
+	task.ID, err = t.CreateTask(ctx, task)
+	if err != nil {
+		return models.Task{}, parsed, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return task, parsed, nil
+}
+
+// Tasks returns tasks of the filter scope (owned by default) matching the filter,
+// relative due windows use the user timezone
+func (t Tasks) Tasks(ctx context.Context, userID int64, filter models.TaskFilter) ([]models.Task, error) {
+	const op = "services.tasks.Tasks"
+
+	now := time.Now().UTC()
+	if filter.Due != models.DueAny && filter.Due != models.DueNone {
+		user, err := t.users.UserByID(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		now = now.In(user.Location())
+	}
+
+	tasks, err := t.scopeTasks(ctx, userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return applyFilter(tasks, filter, now), nil
+}
+
+// TasksByID returns the task if the user can view it
 func (t Tasks) TasksByID(ctx context.Context, taskID int64, userID int64) (models.Task, error) {
-	return models.Task{
-		ID:          taskID,
-		UserID:      userID,
-		Title:       "Title 1",
-		Description: "",
-		Status:      "Done",
-		CreatedAt:   time.Now().UTC(),
-		UpdatedAt:   time.Now().UTC(),
-	}, nil
+	return t.authorizedTask(ctx, taskID, userID, models.PermissionViewer)
 }
 
+// ChangeTaskStatus moves task to another column of its owner workflow, respecting the column WIP limit
 func (t Tasks) ChangeTaskStatus(ctx context.Context, taskID int64, userID int64, newStatus string) error {
+	const op = "services.tasks.ChangeTaskStatus"
+
+	var task models.Task
+	var ws models.WorkflowStatus
+
+	// the permission and the WIP limit are checked in the transaction of the write
+	err := t.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		task, err = t.authorizedTask(ctx, taskID, userID, models.PermissionEditor)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		statuses, err := t.workflow.Workflow(ctx, task.UserID, task.ProjectID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		ws, err = resolveStatus(statuses, models.Status(newStatus), "")
+		if err != nil {
+			return err
+		}
+
+		if ws.Name == task.Status {
+			return nil
+		}
+
+		if err = t.checkWIPLimit(ctx, task.UserID, task.ProjectID, ws); err != nil {
+			return err
+		}
+		if err = t.updater.UpdateStatusTask(ctx, taskID, task.UserID, ws.Name); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if ws.Name == task.Status {
+		return nil
+	}
+
+	t.log.Info(
+		"task status changed",
+		slog.String("op", op),
+		slog.Int64("task_id", taskID),
+		slog.Int64("user_id", userID),
+		slog.String("from", string(task.Status)),
+		slog.String("to", string(ws.Name)),
+	)
+
+	t.publish(ctx, models.TaskEvent{
+		Type:           models.EventTaskStatusChanged,
+		Task:           task,
+		ActorID:        userID,
+		PreviousStatus: task.Status,
+	})
+
 	return nil
 }
+
+// publish reloads the task so subscribers get its stored state, failure to load only skips the event.
+// Inside a transaction the event is published after the commit, a rolled back change is not announced
+func (t Tasks) publish(ctx context.Context, e models.TaskEvent) {
+	t.tx.AfterCommit(ctx, func(ctx context.Context) {
+		task, err := t.provider.SelectTaskByID(ctx, e.Task.ID)
+		if err != nil {
+			t.log.Warn(
+				"failed load task for event",
+				slog.String("event", string(e.Type)),
+				slog.Int64("task_id", e.Task.ID),
+				slog.String("err", err.Error()),
+			)
+			return
+		}
+
+		e.Task = task
+		t.events.Publish(ctx, e)
+	})
+}
+
+// UpdateTask applies the patch to the task, editor permission is required
+func (t Tasks) UpdateTask(ctx context.Context, taskID int64, userID int64, patch models.TaskPatch) (models.Task, error) {
+	const op = "services.tasks.UpdateTask"
+
+	if patch.Empty() {
+		return models.Task{}, models.ErrNothingToApply
+	}
+
+	// the permission is checked in the transaction of the write, a revoked share can not slip in between
+	err := t.tx.WithinTx(ctx, func(ctx context.Context) error {
+		task, err := t.authorizedTask(ctx, taskID, userID, models.PermissionEditor)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		previous := task.Description
+		task, err = patch.Apply(task)
+		if err != nil {
+			return err
+		}
+		task.Tags = normalizeTags(task.Tags)
+
+		if err = t.updater.UpdateTask(ctx, task); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		e := models.TaskEvent{Type: models.EventTaskUpdated, Task: task, ActorID: userID}
+		if task.Description != previous {
+			e.PreviousDescription = &previous
+		}
+		t.publish(ctx, e)
+
+		return nil
+	})
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	return t.provider.SelectTaskByID(ctx, taskID)
+}
+
+// DeleteTask deletes the task, only its owner or a workspace admin can do it.
+// Subscribers get the last state of the task with the event
+func (t Tasks) DeleteTask(ctx context.Context, taskID int64, userID int64) error {
+	const op = "services.tasks.DeleteTask"
+
+	var task models.Task
+	err := t.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		task, err = t.authorizedTask(ctx, taskID, userID, models.PermissionOwner)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		if err = t.updater.DeleteTask(ctx, taskID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	t.log.Info("task deleted", slog.String("op", op), slog.Int64("task_id", taskID), slog.Int64("user_id", userID))
+
+	t.tx.AfterCommit(ctx, func(ctx context.Context) {
+		t.events.Publish(ctx, models.TaskEvent{Type: models.EventTaskDeleted, Task: task, ActorID: userID})
+	})
+
+	return nil
+}
+
+// checkWIPLimit is called in the transaction of the write, so concurrent requests can not both take the last place.
+// The storage isolates the transaction for it, postgres runs it serializable and sqlite has one writer
+func (t Tasks) checkWIPLimit(ctx context.Context, userID int64, projectID *int64, ws models.WorkflowStatus) error {
+	if ws.WIPLimit <= 0 {
+		return nil
+	}
+
+	count, err := t.provider.CountTasksByStatus(ctx, userID, projectID, ws.Name)
+	if err != nil {
+		return err
+	}
+	if count >= ws.WIPLimit {
+		return fmt.Errorf("%w: %s allows %d tasks", models.ErrWIPLimitExceeded, ws.Name, ws.WIPLimit)
+	}
+	return nil
+}
+
+// resolveStatus looks up the status in the workflow case-insensitively,
+// empty status means the first status of the category or of the workflow
+func resolveStatus(statuses []models.WorkflowStatus, status models.Status, category models.StatusCategory) (models.WorkflowStatus, error) {
+	if len(statuses) == 0 {
+		return models.WorkflowStatus{}, models.ErrUnknownStatus
+	}
+
+	if status == "" {
+		if category != "" {
+			for _, ws := range statuses {
+				if ws.Category == category {
+					return ws, nil
+				}
+			}
+		}
+		return statuses[0], nil
+	}
+
+	for _, ws := range statuses {
+		if strings.EqualFold(string(ws.Name), string(status)) {
+			return ws, nil
+		}
+	}
+
+	return models.WorkflowStatus{}, fmt.Errorf("%w: %s", models.ErrUnknownStatus, status)
+}
+
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	res := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(tag, "#")))
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		res = append(res, tag)
+	}
+	return res
+}
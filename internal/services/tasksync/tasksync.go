@@ -0,0 +1,412 @@
+package tasksync
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type Provider interface {
+	SelectTaskChanges(ctx context.Context, userID int64, after int64, limit int) ([]models.TaskChange, error)
+	SelectTaskVersions(ctx context.Context, taskID int64) (models.TaskVersions, error)
+	SelectTombstone(ctx context.Context, taskID int64) (models.Task, error)
+	LastTaskChange(ctx context.Context) (int64, error)
+	SelectSyncClientTask(ctx context.Context, userID int64, clientID string) (int64, error)
+}
+
+type Saver interface {
+	InsertSyncClientTask(ctx context.Context, userID int64, clientID string, taskID int64) error
+}
+
+// Tasks applies client changes with the usual permission checks, implemented by tasks service
+type Tasks interface {
+	CreateTask(ctx context.Context, task models.Task) (int64, error)
+	TasksByID(ctx context.Context, taskID int64, userID int64) (models.Task, error)
+	UpdateTask(ctx context.Context, taskID int64, userID int64, patch models.TaskPatch) (models.Task, error)
+	ChangeTaskStatus(ctx context.Context, taskID int64, userID int64, newStatus string) error
+	DeleteTask(ctx context.Context, taskID int64, userID int64) error
+	CanView(ctx context.Context, task models.Task, userID int64) (bool, error)
+}
+
+// Transactor runs fn in one transaction, storage calls made with the ctx given to fn join it
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Authz resolves the scope new tasks are stored in, implemented by authz service
+type Authz interface {
+	ProjectScope(ctx context.Context, userID int64, projectID *int64, action models.Action) (int64, error)
+}
+
+type Sync struct {
+	provider Provider
+	saver    Saver
+	tasks    Tasks
+	authz    Authz
+	tx       Transactor
+	cfg      *config.Config
+	log      *slog.Logger
+}
+
+func NewServices(p Provider, s Saver, t Tasks, authz Authz, tx Transactor, cfg *config.Config, log *slog.Logger) *Sync {
+	return &Sync{provider: p, saver: s, tasks: t, authz: authz, tx: tx, cfg: cfg, log: log}
+}
+
+// Sync applies the client changes in order and returns server changes after the token
+// including the client's own, empty token returns all tasks visible to the user.
+// Tombstones are returned for deleted tasks and tasks unshared from the user
+func (s Sync) Sync(ctx context.Context, userID int64, token string, changes []models.SyncChange) (models.Sync, error) {
+	const op = "services.tasksync.Sync"
+
+	after, err := s.parseToken(ctx, token)
+	if err != nil {
+		return models.Sync{}, err
+	}
+
+	if len(changes) > s.cfg.Sync.MaxChanges {
+		return models.Sync{}, fmt.Errorf("%w: at most %d changes per sync", models.ErrInvalidSyncOp, s.cfg.Sync.MaxChanges)
+	}
+
+	results := make([]models.SyncResult, 0, len(changes))
+	for _, c := range changes {
+		results = append(results, s.apply(ctx, userID, c))
+	}
+
+	res, err := s.changesAfter(ctx, userID, after)
+	if err != nil {
+		return models.Sync{}, fmt.Errorf("%s: %w", op, err)
+	}
+	res.Results = results
+
+	return res, nil
+}
+
+// parseToken returns the version of the token, tokens ahead of the change log
+// come from another database and the client must sync from scratch
+func (s Sync) parseToken(ctx context.Context, token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+
+	after, err := strconv.ParseInt(token, 10, 64)
+	if err != nil || after < 0 {
+		return 0, models.ErrInvalidSyncToken
+	}
+
+	last, err := s.provider.LastTaskChange(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if after > last {
+		return 0, models.ErrInvalidSyncToken
+	}
+
+	return after, nil
+}
+
+func (s Sync) changesAfter(ctx context.Context, userID int64, after int64) (models.Sync, error) {
+	// the storage skips tasks the user can't see, pages are not spent on changes of other users
+	changes, err := s.provider.SelectTaskChanges(ctx, userID, after, s.cfg.Sync.PageSize+1)
+	if err != nil {
+		return models.Sync{}, err
+	}
+
+	res := models.Sync{Token: strconv.FormatInt(after, 10)}
+	if len(changes) > s.cfg.Sync.PageSize {
+		changes = changes[:s.cfg.Sync.PageSize]
+		res.HasMore = true
+	}
+	if len(changes) > 0 {
+		res.Token = strconv.FormatInt(changes[len(changes)-1].Version, 10)
+	}
+
+	for _, c := range changes {
+		// users who got or lost the share were able to see the task before the change
+		accessChanged := slices.Contains(c.AccessChanged, userID)
+
+		if c.Deleted {
+			// the first sync has nothing to delete
+			if after == 0 {
+				continue
+			}
+			visible := accessChanged
+			if !visible {
+				if visible, err = s.tombstoneVisible(ctx, c.TaskID, userID); err != nil {
+					return models.Sync{}, err
+				}
+			}
+			if visible {
+				res.Tombstones = append(res.Tombstones, models.Tombstone{TaskID: c.TaskID, Version: c.Version})
+			}
+			continue
+		}
+
+		task, err := s.tasks.TasksByID(ctx, c.TaskID, userID)
+		if err != nil {
+			if !errors.Is(err, models.ErrTaskNotFound) {
+				return models.Sync{}, err
+			}
+			if after > 0 && accessChanged {
+				res.Tombstones = append(res.Tombstones, models.Tombstone{TaskID: c.TaskID, Version: c.Version})
+			}
+			continue
+		}
+
+		versions, err := s.provider.SelectTaskVersions(ctx, c.TaskID)
+		if err != nil {
+			return models.Sync{}, err
+		}
+
+		res.Tasks = append(res.Tasks, models.SyncTask{Task: task, Versions: versions})
+	}
+
+	return res, nil
+}
+
+func (s Sync) tombstoneVisible(ctx context.Context, taskID int64, userID int64) (bool, error) {
+	task, err := s.provider.SelectTombstone(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, models.ErrTaskNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return s.tasks.CanView(ctx, task, userID)
+}
+
+func (s Sync) apply(ctx context.Context, userID int64, c models.SyncChange) models.SyncResult {
+	res := models.SyncResult{ClientID: c.ClientID, TaskID: c.TaskID}
+
+	switch c.Op {
+	case models.SyncOpCreate:
+		res.TaskID, res.Err = s.create(ctx, userID, c)
+	case models.SyncOpUpdate:
+		res.Conflicts, res.Err = s.update(ctx, userID, c)
+	case models.SyncOpDelete:
+		res.Conflicts, res.Err = s.delete(ctx, userID, c)
+	default:
+		res.Err = fmt.Errorf("%w: unknown operation %s", models.ErrInvalidSyncOp, c.Op)
+	}
+
+	switch {
+	case res.Err != nil:
+		res.Status = models.SyncRejected
+	case len(res.Conflicts) > 0:
+		res.Status = models.SyncConflict
+	default:
+		res.Status = models.SyncApplied
+	}
+
+	return res
+}
+
+// create creates the task once for the client id, repeated sync after a lost response returns the same task.
+// The lookup, the task and the mapping are written in one transaction, a sync racing for the same
+// client id loses on the mapping, its task is rolled back and the task of the winner is returned
+func (s Sync) create(ctx context.Context, userID int64, c models.SyncChange) (int64, error) {
+	const op = "services.tasksync.create"
+
+	if c.ClientID == "" {
+		return 0, fmt.Errorf("%w: client_id is required to create task", models.ErrInvalidSyncOp)
+	}
+
+	var id int64
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		id, err = s.applyCreate(ctx, userID, c)
+		return err
+	})
+	if errors.Is(err, models.ErrSyncClientExists) {
+		if id, err = s.provider.SelectSyncClientTask(ctx, userID, c.ClientID); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+		return id, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s Sync) applyCreate(ctx context.Context, userID int64, c models.SyncChange) (int64, error) {
+	const op = "services.tasksync.create"
+
+	id, err := s.provider.SelectSyncClientTask(ctx, userID, c.ClientID)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, models.ErrTaskNotFound) {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	task := c.Task
+	task.UserID, err = s.authz.ProjectScope(ctx, userID, task.ProjectID, models.ActionEdit)
+	if err != nil {
+		return 0, err
+	}
+	task.CreatedBy = userID
+
+	id, err = s.tasks.CreateTask(ctx, task)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = s.saver.InsertSyncClientTask(ctx, userID, c.ClientID, id); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// update applies the fields which did not change on the server since the client versions,
+// other fields keep the server value and are reported as conflicts unless the values are equal.
+// Versions are checked and the fields written in one transaction, so a concurrent change can't slip between
+func (s Sync) update(ctx context.Context, userID int64, c models.SyncChange) ([]models.TaskField, error) {
+	if c.Patch.Empty() && c.Status == nil {
+		return nil, models.ErrNothingToApply
+	}
+
+	var conflicts []models.TaskField
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		conflicts, err = s.applyUpdate(ctx, userID, c)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return conflicts, nil
+}
+
+func (s Sync) applyUpdate(ctx context.Context, userID int64, c models.SyncChange) ([]models.TaskField, error) {
+	const op = "services.tasksync.update"
+
+	// versions go first, the storage locks the task with them
+	versions, err := s.provider.SelectTaskVersions(ctx, c.TaskID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	task, err := s.tasks.TasksByID(ctx, c.TaskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []models.TaskField
+	patch := c.Patch
+	for _, f := range c.Patch.Fields() {
+		if versions[f] <= c.Versions[f] {
+			continue
+		}
+		if !sameValue(task, c.Patch, f) {
+			conflicts = append(conflicts, f)
+		}
+		patch = patch.Without(f)
+	}
+
+	if !patch.Empty() {
+		if _, err = s.tasks.UpdateTask(ctx, c.TaskID, userID, patch); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Status != nil {
+		switch {
+		case versions[models.TaskFieldStatus] <= c.Versions[models.TaskFieldStatus]:
+			if err = s.tasks.ChangeTaskStatus(ctx, c.TaskID, userID, string(*c.Status)); err != nil {
+				return nil, err
+			}
+		case !strings.EqualFold(string(task.Status), string(*c.Status)):
+			conflicts = append(conflicts, models.TaskFieldStatus)
+		}
+	}
+
+	return conflicts, nil
+}
+
+// delete deletes the task if it did not change after the version the client saw,
+// otherwise the changed fields are reported and the client gets the task back.
+// The check and the delete are done in one transaction like in update
+func (s Sync) delete(ctx context.Context, userID int64, c models.SyncChange) ([]models.TaskField, error) {
+	var conflicts []models.TaskField
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		conflicts, err = s.applyDelete(ctx, userID, c)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return conflicts, nil
+}
+
+func (s Sync) applyDelete(ctx context.Context, userID int64, c models.SyncChange) ([]models.TaskField, error) {
+	const op = "services.tasksync.delete"
+
+	versions, err := s.provider.SelectTaskVersions(ctx, c.TaskID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := s.tasks.TasksByID(ctx, c.TaskID, userID); err != nil {
+		if errors.Is(err, models.ErrTaskNotFound) {
+			// deleted by another client or by the previous sync
+			if visible, verr := s.tombstoneVisible(ctx, c.TaskID, userID); verr == nil && visible {
+				return nil, nil
+			}
+		}
+		return nil, err
+	}
+
+	var conflicts []models.TaskField
+	for _, f := range models.TaskFields() {
+		if versions[f] > c.BaseVersion {
+			conflicts = append(conflicts, f)
+		}
+	}
+	if len(conflicts) > 0 {
+		return conflicts, nil
+	}
+
+	if err = s.tasks.DeleteTask(ctx, c.TaskID, userID); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// sameValue reports whether the patch sets the field to the value the task already has
+func sameValue(task models.Task, patch models.TaskPatch, f models.TaskField) bool {
+	patched, err := patch.Apply(task)
+	if err != nil {
+		return false
+	}
+
+	switch f {
+	case models.TaskFieldTitle:
+		return patched.Title == task.Title
+	case models.TaskFieldDescription:
+		return patched.Description == task.Description
+	case models.TaskFieldPriority:
+		return patched.Priority == task.Priority
+	case models.TaskFieldRecurrence:
+		return patched.Recurrence == task.Recurrence
+	case models.TaskFieldTags:
+		return slices.EqualFunc(patched.Tags, task.Tags, strings.EqualFold)
+	case models.TaskFieldDue:
+		if patched.DueAt == nil || task.DueAt == nil {
+			return patched.DueAt == task.DueAt
+		}
+		return patched.DueAt.Equal(*task.DueAt)
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package tasksync
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/models"
+	"TaskList/internal/storage/memory"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+// tasks creates tasks in the storage without the checks of the tasks service
+type tasks struct {
+	Tasks
+	s *memory.Storage
+}
+
+func (t tasks) CreateTask(ctx context.Context, task models.Task) (int64, error) {
+	return t.s.InsertTask(ctx, task)
+}
+
+// TasksByID shows only own tasks
+func (t tasks) TasksByID(ctx context.Context, taskID int64, userID int64) (models.Task, error) {
+	task, err := t.s.SelectTaskByID(ctx, taskID)
+	if err != nil || task.UserID != userID {
+		return models.Task{}, models.ErrTaskNotFound
+	}
+	return task, nil
+}
+
+type ownScope struct{}
+
+func (ownScope) ProjectScope(ctx context.Context, userID int64, projectID *int64, action models.Action) (int64, error) {
+	return userID, nil
+}
+
+// saver calls before ahead of every mapping insert
+type saver struct {
+	s      *memory.Storage
+	before func(ctx context.Context, userID int64, clientID string) error
+}
+
+func (s saver) InsertSyncClientTask(ctx context.Context, userID int64, clientID string, taskID int64) error {
+	if s.before != nil {
+		if err := s.before(ctx, userID, clientID); err != nil {
+			return err
+		}
+	}
+	return s.s.InsertSyncClientTask(ctx, userID, clientID, taskID)
+}
+
+func newTestSync(t *testing.T, sv *saver) (*Sync, *memory.Storage, int64) {
+	t.Helper()
+
+	s := memory.New()
+	userID, err := s.CreateUser(context.Background(), "a@example.com", []byte("hash"))
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	sv.s = s
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewServices(s, sv, tasks{s: s}, ownScope{}, s, &config.Config{}, log), s, userID
+}
+
+func userTasks(t *testing.T, s *memory.Storage, userID int64) []models.Task {
+	t.Helper()
+
+	list, err := s.SelectAllTasksByUserID(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("SelectAllTasksByUserID() error = %v", err)
+	}
+	return list
+}
+
+// a failed mapping rolls the task back, the retry of the client creates the task once
+func TestCreateRetryAfterFailedMapping(t *testing.T) {
+	failures := 1
+	sv := &saver{before: func(ctx context.Context, userID int64, clientID string) error {
+		if failures > 0 {
+			failures--
+			return errors.New("disk I/O error")
+		}
+		return nil
+	}}
+	s, st, userID := newTestSync(t, sv)
+	ctx := context.Background()
+	change := models.SyncChange{Op: models.SyncOpCreate, ClientID: "c1", Task: models.Task{Title: "offline"}}
+
+	if res := s.apply(ctx, userID, change); res.Status != models.SyncRejected {
+		t.Fatalf("first apply = %+v, want rejected", res)
+	}
+	if list := userTasks(t, st, userID); len(list) != 0 {
+		t.Fatalf("tasks after failed mapping = %+v, want none", list)
+	}
+
+	first := s.apply(ctx, userID, change)
+	if first.Status != models.SyncApplied || first.TaskID == 0 {
+		t.Fatalf("retry = %+v, want applied", first)
+	}
+	again := s.apply(ctx, userID, change)
+	if again.Status != models.SyncApplied || again.TaskID != first.TaskID {
+		t.Errorf("repeated retry = %+v, want task %d", again, first.TaskID)
+	}
+
+	if list := userTasks(t, st, userID); len(list) != 1 {
+		t.Errorf("tasks = %+v, want one task", list)
+	}
+}
+
+// staleProvider misses the mapping on the first lookup like a transaction begun before
+// a concurrent sync with the same client id committed
+type staleProvider struct {
+	*memory.Storage
+	lookups *int
+}
+
+func (p staleProvider) SelectSyncClientTask(ctx context.Context, userID int64, clientID string) (int64, error) {
+	*p.lookups++
+	if *p.lookups == 1 {
+		return 0, models.ErrTaskNotFound
+	}
+	return p.Storage.SelectSyncClientTask(ctx, userID, clientID)
+}
+
+// a sync which loses the race for the client id returns the task of the winner and keeps no task of its own
+func TestCreateLosesClientIDRace(t *testing.T) {
+	s, st, userID := newTestSync(t, &saver{})
+	ctx := context.Background()
+
+	winner, err := st.InsertTask(ctx, models.Task{UserID: userID, Title: "offline"})
+	if err != nil {
+		t.Fatalf("InsertTask() error = %v", err)
+	}
+	if err = st.InsertSyncClientTask(ctx, userID, "c1", winner); err != nil {
+		t.Fatalf("InsertSyncClientTask() error = %v", err)
+	}
+	s.provider = staleProvider{Storage: st, lookups: new(int)}
+
+	res := s.apply(ctx, userID, models.SyncChange{Op: models.SyncOpCreate, ClientID: "c1", Task: models.Task{Title: "offline"}})
+	if res.Status != models.SyncApplied || res.TaskID != winner {
+		t.Fatalf("apply = %+v, want task %d of the winner", res, winner)
+	}
+	if list := userTasks(t, st, userID); len(list) != 1 || list[0].ID != winner {
+		t.Errorf("tasks = %+v, want only task %d", list, winner)
+	}
+}
+
+// changes of other users do not take the page of the user
+func TestSyncSkipsOtherUsers(t *testing.T) {
+	s, st, userID := newTestSync(t, &saver{})
+	s.cfg.Sync.PageSize = 2
+	ctx := context.Background()
+
+	other, err := st.CreateUser(ctx, "b@example.com", []byte("hash"))
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	for range 5 {
+		if _, err = st.InsertTask(ctx, models.Task{UserID: other, Title: "foreign"}); err != nil {
+			t.Fatalf("InsertTask() error = %v", err)
+		}
+	}
+	own, err := st.InsertTask(ctx, models.Task{UserID: userID, Title: "own"})
+	if err != nil {
+		t.Fatalf("InsertTask() error = %v", err)
+	}
+
+	res, err := s.Sync(ctx, userID, "", nil)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if res.HasMore || len(res.Tasks) != 1 || res.Tasks[0].Task.ID != own {
+		t.Errorf("Sync() = %+v, want only task %d on one page", res, own)
+	}
+}
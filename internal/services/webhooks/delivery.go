@@ -0,0 +1,250 @@
+package webhooks
+
+import (
+	"TaskList/internal/models"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	pollInterval   = time.Second
+	deliveryBatch  = 20
+	parallelSends  = 4
+	maxBackoff     = 6 * time.Hour
+	maxErrorLength = 512
+)
+
+// HandleEvent queues deliveries of the event to active webhooks subscribed to it
+// whose owners can see the task
+func (w Webhooks) HandleEvent(ctx context.Context, e models.TaskEvent) {
+	const op = "services.webhooks.HandleEvent"
+	log := w.log.With(slog.String("op", op), slog.String("event", string(e.Type)), slog.Int64("task_id", e.Task.ID))
+
+	if !e.Type.Webhook() {
+		return
+	}
+
+	hooks, err := w.provider.SelectWebhooksByEvent(ctx, e.Type)
+	if err != nil {
+		log.Error("failed select webhooks", slog.String("err", err.Error()))
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	body, err := newPayload(e)
+	if err != nil {
+		log.Error("failed build payload", slog.String("err", err.Error()))
+		return
+	}
+
+	for _, hook := range hooks {
+		ok, err := w.tasks.CanView(ctx, e.Task, hook.UserID)
+		if err != nil {
+			log.Error("failed check access", slog.Int64("webhook_id", hook.ID), slog.String("err", err.Error()))
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if _, err = w.enqueue(ctx, hook.ID, e.Type, body, nil); err != nil {
+			log.Error("failed queue delivery", slog.Int64("webhook_id", hook.ID), slog.String("err", err.Error()))
+		}
+	}
+}
+
+func (w Webhooks) enqueue(ctx context.Context, webhookID int64, event models.EventType, payload []byte, redeliveryOf *int64) (models.WebhookDelivery, error) {
+	now := time.Now().UTC()
+	d := models.WebhookDelivery{
+		WebhookID:     webhookID,
+		Event:         event,
+		Payload:       payload,
+		Status:        models.DeliveryPending,
+		RedeliveryOf:  redeliveryOf,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+	}
+
+	id, err := w.saver.InsertDelivery(ctx, d)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	d.ID = id
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+
+	return d, nil
+}
+
+// Run delivers due deliveries until ctx is done, failed deliveries are retried with exponential backoff
+func (w Webhooks) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+		w.deliverDue(ctx)
+	}
+}
+
+func (w Webhooks) deliverDue(ctx context.Context) {
+	const op = "services.webhooks.deliverDue"
+
+	deliveries, err := w.provider.SelectDueDeliveries(ctx, time.Now().UTC(), deliveryBatch)
+	if err != nil {
+		w.log.Error("failed select deliveries", slog.String("op", op), slog.String("err", err.Error()))
+		return
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallelSends)
+	for _, d := range deliveries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(d models.WebhookDelivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			w.attempt(ctx, d)
+		}(d)
+	}
+	wg.Wait()
+}
+
+func (w Webhooks) attempt(ctx context.Context, d models.WebhookDelivery) {
+	const op = "services.webhooks.attempt"
+	log := w.log.With(slog.String("op", op), slog.Int64("delivery_id", d.ID), slog.Int64("webhook_id", d.WebhookID))
+
+	hook, err := w.provider.SelectWebhook(ctx, d.WebhookID)
+	if err != nil {
+		if !errors.Is(err, models.ErrWebhookNotFound) {
+			log.Error("failed select webhook", slog.String("err", err.Error()))
+		}
+		return
+	}
+
+	now := time.Now().UTC()
+	d.Attempts++
+	d.LastAttemptAt = &now
+	d.ResponseCode = nil
+	d.Error = ""
+
+	if hook.Active {
+		code, err := w.send(ctx, hook, d)
+		if err != nil {
+			d.Error = truncate(err.Error(), maxErrorLength)
+		}
+		if code != 0 {
+			d.ResponseCode = &code
+		}
+	} else {
+		d.Error = "webhook is disabled"
+	}
+
+	switch {
+	case d.Error == "":
+		d.Status = models.DeliverySucceeded
+		d.NextAttemptAt = nil
+	case !hook.Active || d.Attempts >= w.cfg.Webhooks.MaxAttempts:
+		d.Status = models.DeliveryFailed
+		d.NextAttemptAt = nil
+	default:
+		next := now.Add(backoff(w.cfg.Webhooks.Backoff, d.Attempts))
+		d.NextAttemptAt = &next
+	}
+
+	if err = w.updater.UpdateDeliveryAttempt(ctx, d); err != nil {
+		log.Error("failed store delivery attempt", slog.String("err", err.Error()))
+		return
+	}
+
+	log.Info(
+		"webhook delivery attempted",
+		slog.String("status", string(d.Status)),
+		slog.Int("attempt", d.Attempts),
+		slog.String("err", d.Error),
+	)
+}
+
+// send posts the payload, non 2xx response is returned as error with its code.
+// The receiver verifies X-TaskList-Signature: sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+// where timestamp is X-TaskList-Timestamp in unix seconds
+func (w Webhooks) send(ctx context.Context, hook models.Webhook, d models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TaskList-Webhooks/1")
+	req.Header.Set("X-TaskList-Event", string(d.Event))
+	req.Header.Set("X-TaskList-Delivery", strconv.FormatInt(d.ID, 10))
+	req.Header.Set("X-TaskList-Timestamp", timestamp)
+	req.Header.Set("X-TaskList-Signature", "sha256="+Sign(hook.Secret, timestamp, d.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns hex encoded HMAC-SHA256 of the timestamp and the body joined with a dot
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff doubles the base delay with every failed attempt
+func backoff(base time.Duration, attempts int) time.Duration {
+	d := base
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,91 @@
+package webhooks
+
+import (
+	"TaskList/internal/models"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+type payload struct {
+	// ID is the same for deliveries of one event to different webhooks and for redeliveries
+	ID             string           `json:"id"`
+	Event          models.EventType `json:"event"`
+	OccurredAt     time.Time        `json:"occurred_at"`
+	ActorID        int64            `json:"actor_id"`
+	Task           taskPayload      `json:"task"`
+	PreviousStatus models.Status    `json:"previous_status,omitempty"`
+	Comment        *commentPayload  `json:"comment,omitempty"`
+}
+
+type taskPayload struct {
+	ID             int64                 `json:"id"`
+	UserID         int64                 `json:"user_id"`
+	AssigneeID     *int64                `json:"assignee_id,omitempty"`
+	ProjectID      *int64                `json:"project_id,omitempty"`
+	WorkspaceID    *int64                `json:"workspace_id,omitempty"`
+	Title          string                `json:"title"`
+	Description    string                `json:"description"`
+	Status         models.Status         `json:"status"`
+	StatusCategory models.StatusCategory `json:"status_category"`
+	Priority       models.Priority       `json:"priority"`
+	Tags           []string              `json:"tags,omitempty"`
+	CustomFields   map[string]string     `json:"custom_fields,omitempty"`
+	Recurrence     string                `json:"recurrence,omitempty"`
+	DueAt          *time.Time            `json:"due,omitempty"`
+	CreatedAt      time.Time             `json:"created"`
+	UpdatedAt      time.Time             `json:"updated"`
+}
+
+type commentPayload struct {
+	ID     int64  `json:"id"`
+	UserID int64  `json:"user_id"`
+	Body   string `json:"body"`
+}
+
+func newPayload(e models.TaskEvent) ([]byte, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	t := e.Task
+	p := payload{
+		ID:         hex.EncodeToString(b),
+		Event:      e.Type,
+		OccurredAt: e.OccurredAt,
+		ActorID:    e.ActorID,
+		Task: taskPayload{
+			ID:             t.ID,
+			UserID:         t.UserID,
+			AssigneeID:     t.AssigneeID,
+			ProjectID:      t.ProjectID,
+			WorkspaceID:    t.WorkspaceID,
+			Title:          t.Title,
+			Description:    t.Description,
+			Status:         t.Status,
+			StatusCategory: t.StatusCategory,
+			Priority:       t.Priority,
+			Tags:           t.Tags,
+			Recurrence:     t.Recurrence,
+			DueAt:          t.DueAt,
+			CreatedAt:      t.CreatedAt,
+			UpdatedAt:      t.UpdatedAt,
+		},
+		PreviousStatus: e.PreviousStatus,
+	}
+
+	if len(t.CustomFields) > 0 {
+		p.Task.CustomFields = make(map[string]string, len(t.CustomFields))
+		for _, v := range t.CustomFields {
+			p.Task.CustomFields[v.Name] = v.Value
+		}
+	}
+
+	if e.Comment != nil {
+		p.Comment = &commentPayload{ID: e.Comment.ID, UserID: e.Comment.UserID, Body: e.Comment.Body}
+	}
+
+	return json.Marshal(p)
+}
@@ -0,0 +1,217 @@
+package webhooks
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/models"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Saver interface {
+	InsertWebhook(ctx context.Context, hook models.Webhook) (int64, error)
+	InsertDelivery(ctx context.Context, d models.WebhookDelivery) (int64, error)
+}
+
+type Provider interface {
+	SelectWebhooks(ctx context.Context, userID int64) ([]models.Webhook, error)
+	SelectWebhooksByEvent(ctx context.Context, event models.EventType) ([]models.Webhook, error)
+	SelectWebhookByID(ctx context.Context, id int64, userID int64) (models.Webhook, error)
+	SelectWebhook(ctx context.Context, id int64) (models.Webhook, error)
+	SelectDeliveries(ctx context.Context, webhookID int64, limit int) ([]models.WebhookDelivery, error)
+	SelectDueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error)
+	SelectDelivery(ctx context.Context, id int64, webhookID int64) (models.WebhookDelivery, error)
+}
+
+type Updater interface {
+	UpdateWebhook(ctx context.Context, hook models.Webhook) error
+	DeleteWebhook(ctx context.Context, id int64, userID int64) error
+	UpdateDeliveryAttempt(ctx context.Context, d models.WebhookDelivery) error
+}
+
+// Tasks checks the webhook owner can see the task, implemented by tasks service
+type Tasks interface {
+	CanView(ctx context.Context, task models.Task, userID int64) (bool, error)
+}
+
+type Webhooks struct {
+	saver    Saver
+	provider Provider
+	updater  Updater
+	tasks    Tasks
+	client   *http.Client
+	// wake starts delivery without waiting for the next poll
+	wake chan struct{}
+	cfg  *config.Config
+	log  *slog.Logger
+}
+
+func NewServices(s Saver, p Provider, u Updater, t Tasks, cfg *config.Config, log *slog.Logger) *Webhooks {
+	return &Webhooks{
+		saver:    s,
+		provider: p,
+		updater:  u,
+		tasks:    t,
+		client:   newClient(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivate),
+		wake:     make(chan struct{}, 1),
+		cfg:      cfg,
+		log:      log,
+	}
+}
+
+// CreateWebhook registers the endpoint, the returned webhook has the generated signing secret
+func (w Webhooks) CreateWebhook(ctx context.Context, hook models.Webhook) (models.Webhook, error) {
+	const op = "services.webhooks.CreateWebhook"
+
+	if err := w.validate(hook); err != nil {
+		return models.Webhook{}, err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+	hook.Secret = secret
+	hook.Active = true
+
+	hook.ID, err = w.saver.InsertWebhook(ctx, hook)
+	if err != nil {
+		return models.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	w.log.Info("webhook created", slog.String("op", op), slog.Int64("webhook_id", hook.ID), slog.Int64("user_id", hook.UserID))
+
+	return w.Webhook(ctx, hook.UserID, hook.ID)
+}
+
+func (w Webhooks) Webhooks(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	const op = "services.webhooks.Webhooks"
+
+	hooks, err := w.provider.SelectWebhooks(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return hooks, nil
+}
+
+func (w Webhooks) Webhook(ctx context.Context, userID int64, id int64) (models.Webhook, error) {
+	const op = "services.webhooks.Webhook"
+
+	hook, err := w.provider.SelectWebhookByID(ctx, id, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrWebhookNotFound) {
+			return models.Webhook{}, err
+		}
+		return models.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return hook, nil
+}
+
+// UpdateWebhook replaces url, events and active flag, the secret is kept
+func (w Webhooks) UpdateWebhook(ctx context.Context, hook models.Webhook) (models.Webhook, error) {
+	const op = "services.webhooks.UpdateWebhook"
+
+	if err := w.validate(hook); err != nil {
+		return models.Webhook{}, err
+	}
+
+	if err := w.updater.UpdateWebhook(ctx, hook); err != nil {
+		if errors.Is(err, models.ErrWebhookNotFound) {
+			return models.Webhook{}, err
+		}
+		return models.Webhook{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return w.Webhook(ctx, hook.UserID, hook.ID)
+}
+
+func (w Webhooks) DeleteWebhook(ctx context.Context, userID int64, id int64) error {
+	const op = "services.webhooks.DeleteWebhook"
+
+	if err := w.updater.DeleteWebhook(ctx, id, userID); err != nil {
+		if errors.Is(err, models.ErrWebhookNotFound) {
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Deliveries returns the delivery log of the webhook, the newest first
+func (w Webhooks) Deliveries(ctx context.Context, userID int64, id int64, limit int) ([]models.WebhookDelivery, error) {
+	const op = "services.webhooks.Deliveries"
+
+	if _, err := w.Webhook(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := w.provider.SelectDeliveries(ctx, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver queues a new delivery of the same payload, the original delivery stays in the log
+func (w Webhooks) Redeliver(ctx context.Context, userID int64, id int64, deliveryID int64) (models.WebhookDelivery, error) {
+	const op = "services.webhooks.Redeliver"
+
+	if _, err := w.Webhook(ctx, userID, id); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	original, err := w.provider.SelectDelivery(ctx, deliveryID, id)
+	if err != nil {
+		if errors.Is(err, models.ErrDeliveryNotFound) {
+			return models.WebhookDelivery{}, err
+		}
+		return models.WebhookDelivery{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	d, err := w.enqueue(ctx, id, original.Event, original.Payload, &original.ID)
+	if err != nil {
+		return models.WebhookDelivery{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return d, nil
+}
+
+func (w Webhooks) validate(hook models.Webhook) error {
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return models.ErrInvalidWebhookURL
+	}
+	// names are checked again when the delivery connects, they may resolve to another address by then
+	if !w.cfg.Webhooks.AllowPrivate && !publicHost(u.Hostname()) {
+		return models.ErrPrivateWebhookURL
+	}
+
+	if len(hook.Events) == 0 {
+		return models.ErrNoWebhookEvents
+	}
+	for _, e := range hook.Events {
+		if !e.Webhook() {
+			return fmt.Errorf("%w: %s", models.ErrInvalidEventType, e)
+		}
+	}
+
+	return nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
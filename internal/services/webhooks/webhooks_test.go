@@ -0,0 +1,301 @@
+package webhooks
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/models"
+	"TaskList/internal/storage/memory"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const userID = 1
+
+type allowAll struct{}
+
+func (allowAll) CanView(ctx context.Context, task models.Task, userID int64) (bool, error) {
+	return true, nil
+}
+
+// receiver records requests and answers with the queued status codes, then with 200
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	codes    []int
+}
+
+func newReceiver(t *testing.T, codes ...int) *receiver {
+	r := &receiver{codes: codes}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		code := http.StatusOK
+		if len(r.codes) > 0 {
+			code, r.codes = r.codes[0], r.codes[1:]
+		}
+		r.mu.Unlock()
+
+		if code == http.StatusFound {
+			w.Header().Set("Location", "http://169.254.169.254/latest/meta-data")
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func newTestService(t *testing.T, allowPrivate bool) (*Webhooks, *memory.Storage) {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.Webhooks.Timeout = 5 * time.Second
+	cfg.Webhooks.MaxAttempts = 3
+	cfg.Webhooks.Backoff = time.Millisecond
+	cfg.Webhooks.AllowPrivate = allowPrivate
+
+	s := memory.New()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewServices(s, s, s, allowAll{}, cfg, log), s
+}
+
+func createdEvent() models.TaskEvent {
+	return models.TaskEvent{
+		Type:       models.EventTaskCreated,
+		Task:       models.Task{ID: 7, UserID: userID, Title: "write tests", Status: models.Pending},
+		ActorID:    userID,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+// deliverAll runs delivery rounds until nothing is pending
+func deliverAll(t *testing.T, w *Webhooks, hookID int64) []models.WebhookDelivery {
+	t.Helper()
+	ctx := context.Background()
+
+	for range 10 {
+		w.deliverDue(ctx)
+
+		deliveries, err := w.Deliveries(ctx, userID, hookID, 100)
+		if err != nil {
+			t.Fatalf("Deliveries() error = %v", err)
+		}
+		pending := false
+		for _, d := range deliveries {
+			pending = pending || d.Status == models.DeliveryPending
+		}
+		if !pending {
+			return deliveries
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("deliveries are still pending")
+	return nil
+}
+
+func TestDeliverySigned(t *testing.T) {
+	ctx := context.Background()
+	w, _ := newTestService(t, true)
+	recv := newReceiver(t)
+
+	hook, err := w.CreateWebhook(ctx, models.Webhook{UserID: userID, URL: recv.URL, Events: []models.EventType{models.EventTaskCreated}})
+	if err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+
+	w.HandleEvent(ctx, createdEvent())
+	deliveries := deliverAll(t, w, hook.ID)
+
+	if len(deliveries) != 1 || deliveries[0].Status != models.DeliverySucceeded {
+		t.Fatalf("deliveries = %+v, want one succeeded", deliveries)
+	}
+	if code := deliveries[0].ResponseCode; code == nil || *code != http.StatusOK {
+		t.Errorf("ResponseCode = %v, want 200", code)
+	}
+	if recv.count() != 1 {
+		t.Fatalf("receiver got %d requests, want 1", recv.count())
+	}
+
+	req, body := recv.requests[0], recv.bodies[0]
+	if got := req.Header.Get("X-TaskList-Event"); got != string(models.EventTaskCreated) {
+		t.Errorf("X-TaskList-Event = %q", got)
+	}
+	want := "sha256=" + Sign(hook.Secret, req.Header.Get("X-TaskList-Timestamp"), body)
+	if got := req.Header.Get("X-TaskList-Signature"); got != want {
+		t.Errorf("X-TaskList-Signature = %q, want %q", got, want)
+	}
+
+	var p payload
+	if err = json.Unmarshal(body, &p); err != nil {
+		t.Fatalf("payload is not json: %v", err)
+	}
+	if p.Event != models.EventTaskCreated || p.Task.ID != 7 || p.Task.Title != "write tests" {
+		t.Errorf("payload = %+v", p)
+	}
+}
+
+func TestDeliveryRetries(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		codes    []int
+		status   models.DeliveryStatus
+		attempts int
+	}{
+		{"succeeds after failures", []int{http.StatusInternalServerError, http.StatusBadGateway}, models.DeliverySucceeded, 3},
+		{"fails after max attempts", []int{500, 500, 500, 500}, models.DeliveryFailed, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, _ := newTestService(t, true)
+			recv := newReceiver(t, tt.codes...)
+
+			hook, err := w.CreateWebhook(ctx, models.Webhook{UserID: userID, URL: recv.URL, Events: []models.EventType{models.EventTaskCreated}})
+			if err != nil {
+				t.Fatalf("CreateWebhook() error = %v", err)
+			}
+
+			w.HandleEvent(ctx, createdEvent())
+			d := deliverAll(t, w, hook.ID)[0]
+
+			if d.Status != tt.status || d.Attempts != tt.attempts {
+				t.Errorf("delivery status = %s after %d attempts, want %s after %d", d.Status, d.Attempts, tt.status, tt.attempts)
+			}
+			if recv.count() != tt.attempts {
+				t.Errorf("receiver got %d requests, want %d", recv.count(), tt.attempts)
+			}
+		})
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	ctx := context.Background()
+	w, _ := newTestService(t, true)
+	recv := newReceiver(t)
+
+	hook, err := w.CreateWebhook(ctx, models.Webhook{UserID: userID, URL: recv.URL, Events: []models.EventType{models.EventTaskCreated}})
+	if err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+	w.HandleEvent(ctx, createdEvent())
+	original := deliverAll(t, w, hook.ID)[0]
+
+	d, err := w.Redeliver(ctx, userID, hook.ID, original.ID)
+	if err != nil {
+		t.Fatalf("Redeliver() error = %v", err)
+	}
+	if d.RedeliveryOf == nil || *d.RedeliveryOf != original.ID {
+		t.Errorf("RedeliveryOf = %v, want %d", d.RedeliveryOf, original.ID)
+	}
+	deliverAll(t, w, hook.ID)
+
+	if recv.count() != 2 || string(recv.bodies[0]) != string(recv.bodies[1]) {
+		t.Errorf("redelivery must send the same payload again, got %d requests", recv.count())
+	}
+}
+
+func TestRedirectNotFollowed(t *testing.T) {
+	ctx := context.Background()
+	w, _ := newTestService(t, true)
+	recv := newReceiver(t, http.StatusFound)
+
+	hook, err := w.CreateWebhook(ctx, models.Webhook{UserID: userID, URL: recv.URL, Events: []models.EventType{models.EventTaskCreated}})
+	if err != nil {
+		t.Fatalf("CreateWebhook() error = %v", err)
+	}
+	w.HandleEvent(ctx, createdEvent())
+	w.deliverDue(ctx)
+
+	deliveries, err := w.Deliveries(ctx, userID, hook.ID, 10)
+	if err != nil {
+		t.Fatalf("Deliveries() error = %v", err)
+	}
+	d := deliveries[0]
+	if d.Status != models.DeliveryPending || d.ResponseCode == nil || *d.ResponseCode != http.StatusFound {
+		t.Errorf("delivery = %+v, want failed attempt with 302", d)
+	}
+	if recv.count() != 1 {
+		t.Errorf("receiver got %d requests, want 1", recv.count())
+	}
+}
+
+func TestPrivateURLRejected(t *testing.T) {
+	ctx := context.Background()
+	w, _ := newTestService(t, false)
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://api.localhost/hook",
+		"http://10.1.2.3/hook",
+		"http://192.168.0.10/hook",
+		"http://172.16.5.4/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://100.64.0.1/hook",
+		"http://0.0.0.0/hook",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+		"http://[fd00::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+	} {
+		_, err := w.CreateWebhook(ctx, models.Webhook{UserID: userID, URL: url, Events: []models.EventType{models.EventTaskCreated}})
+		if !errors.Is(err, models.ErrPrivateWebhookURL) {
+			t.Errorf("CreateWebhook(%s) error = %v, want %v", url, err, models.ErrPrivateWebhookURL)
+		}
+	}
+
+	for _, url := range []string{"https://example.com/hook", "http://8.8.8.8/hook", "https://[2001:4860:4860::8888]/hook"} {
+		if _, err := w.CreateWebhook(ctx, models.Webhook{UserID: userID, URL: url, Events: []models.EventType{models.EventTaskCreated}}); err != nil {
+			t.Errorf("CreateWebhook(%s) error = %v", url, err)
+		}
+	}
+}
+
+// a public name may resolve to an internal address, so the address is checked when connecting
+func TestPrivateAddressRefusedOnDial(t *testing.T) {
+	ctx := context.Background()
+	w, s := newTestService(t, false)
+	recv := newReceiver(t)
+
+	// stored directly, as if the name resolved to a public address when the webhook was created
+	url := strings.Replace(recv.URL, "127.0.0.1", "localhost", 1)
+	id, err := s.InsertWebhook(ctx, models.Webhook{UserID: userID, URL: url, Secret: "s", Active: true, Events: []models.EventType{models.EventTaskCreated}})
+	if err != nil {
+		t.Fatalf("InsertWebhook() error = %v", err)
+	}
+
+	w.HandleEvent(ctx, createdEvent())
+	w.deliverDue(ctx)
+
+	deliveries, err := w.Deliveries(ctx, userID, id, 10)
+	if err != nil {
+		t.Fatalf("Deliveries() error = %v", err)
+	}
+	if d := deliveries[0]; d.ResponseCode != nil || !strings.Contains(d.Error, errPrivateAddress.Error()) {
+		t.Errorf("delivery = %+v, want refused connection", d)
+	}
+	if recv.count() != 0 {
+		t.Errorf("receiver got %d requests, want none", recv.count())
+	}
+}
@@ -0,0 +1,288 @@
+package workflow
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/models"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+const maxStatusNameLength = 64
+
+type StatusSaver interface {
+	InsertWorkflowStatus(ctx context.Context, ws models.WorkflowStatus) (int64, error)
+}
+
+type StatusProvider interface {
+	SelectWorkflowStatuses(ctx context.Context, userID int64, projectID *int64) ([]models.WorkflowStatus, error)
+	SelectWorkflowStatusByID(ctx context.Context, statusID int64, userID int64) (models.WorkflowStatus, error)
+}
+
+type StatusUpdater interface {
+	UpdateWorkflowStatus(ctx context.Context, ws models.WorkflowStatus, oldName models.Status) error
+	DeleteWorkflowStatus(ctx context.Context, ws models.WorkflowStatus, fallback models.Status) error
+}
+
+type ProjectProvider interface {
+	SelectProjectByID(ctx context.Context, projectID int64, userID int64) (models.Project, error)
+}
+
+type TaskProvider interface {
+	SelectAllTasksByUserID(ctx context.Context, userID int64) ([]models.Task, error)
+}
+
+type Workflow struct {
+	saver    StatusSaver
+	provider StatusProvider
+	updater  StatusUpdater
+	projects ProjectProvider
+	tasks    TaskProvider
+	cfg      *config.Config
+	log      *slog.Logger
+}
+
+func NewServices(
+	s StatusSaver,
+	p StatusProvider,
+	u StatusUpdater,
+	projects ProjectProvider,
+	tasks TaskProvider,
+	cfg *config.Config,
+	log *slog.Logger,
+) *Workflow {
+	return &Workflow{saver: s, provider: p, updater: u, projects: projects, tasks: tasks, cfg: cfg, log: log}
+}
+
+// Workflow returns ordered statuses for the project:
+// statuses of the project, otherwise statuses for all user projects, otherwise the default workflow
+func (w Workflow) Workflow(ctx context.Context, userID int64, projectID *int64) ([]models.WorkflowStatus, error) {
+	const op = "services.workflow.Workflow"
+
+	if projectID != nil {
+		if _, err := w.projects.SelectProjectByID(ctx, *projectID, userID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		statuses, err := w.provider.SelectWorkflowStatuses(ctx, userID, projectID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if len(statuses) > 0 {
+			return statuses, nil
+		}
+	}
+
+	statuses, err := w.provider.SelectWorkflowStatuses(ctx, userID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(statuses) > 0 {
+		return statuses, nil
+	}
+
+	statuses = models.DefaultWorkflow()
+	for i := range statuses {
+		statuses[i].UserID = userID
+	}
+	return statuses, nil
+}
+
+// CreateStatus adds column to the workflow of the scope, on the first change of the scope
+// the currently used workflow is copied into it so existing tasks keep their statuses
+func (w Workflow) CreateStatus(ctx context.Context, ws models.WorkflowStatus) (int64, error) {
+	const op = "services.workflow.CreateStatus"
+
+	if err := validateStatus(&ws); err != nil {
+		return 0, err
+	}
+
+	statuses, err := w.ensureScope(ctx, ws.UserID, ws.ProjectID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if ws.Position <= 0 {
+		ws.Position = len(statuses) + 1
+		if len(statuses) > 0 && statuses[len(statuses)-1].Position >= ws.Position {
+			ws.Position = statuses[len(statuses)-1].Position + 1
+		}
+	}
+
+	id, err := w.saver.InsertWorkflowStatus(ctx, ws)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	w.log.Info(
+		"workflow status created",
+		slog.String("op", op),
+		slog.Int64("user_id", ws.UserID),
+		slog.Int64("status_id", id),
+	)
+
+	return id, nil
+}
+
+// UpdateStatus changes the column, if ws.ProjectID is set the status must belong to that project
+func (w Workflow) UpdateStatus(ctx context.Context, ws models.WorkflowStatus) error {
+	const op = "services.workflow.UpdateStatus"
+
+	current, err := w.provider.SelectWorkflowStatusByID(ctx, ws.ID, ws.UserID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if ws.ProjectID != nil && !sameProject(current.ProjectID, ws.ProjectID) {
+		return models.ErrStatusNotFound
+	}
+
+	if ws.Name == "" {
+		ws.Name = current.Name
+	}
+	if ws.Category == "" {
+		ws.Category = current.Category
+	}
+	if ws.Position <= 0 {
+		ws.Position = current.Position
+	}
+	if ws.WIPLimit < 0 {
+		ws.WIPLimit = current.WIPLimit
+	}
+	ws.ProjectID = current.ProjectID
+
+	if err = validateStatus(&ws); err != nil {
+		return err
+	}
+
+	if err = w.updater.UpdateWorkflowStatus(ctx, ws, current.Name); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteStatus removes the column, its tasks are moved to the first remaining column.
+// If projectID is set the status must belong to that project
+func (w Workflow) DeleteStatus(ctx context.Context, userID int64, projectID *int64, statusID int64) error {
+	const op = "services.workflow.DeleteStatus"
+
+	ws, err := w.provider.SelectWorkflowStatusByID(ctx, statusID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if projectID != nil && !sameProject(ws.ProjectID, projectID) {
+		return models.ErrStatusNotFound
+	}
+
+	statuses, err := w.provider.SelectWorkflowStatuses(ctx, userID, ws.ProjectID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var fallback *models.WorkflowStatus
+	for i := range statuses {
+		if statuses[i].ID != ws.ID {
+			fallback = &statuses[i]
+			break
+		}
+	}
+	if fallback == nil {
+		return fmt.Errorf("%s: workflow must contain at least one status: %w", op, models.ErrUnknownStatus)
+	}
+
+	if err = w.updater.DeleteWorkflowStatus(ctx, ws, fallback.Name); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Board returns tasks of the project grouped by workflow columns,
+// nil project means tasks without project. Tasks with status missing in the workflow
+// are shown in the first column
+func (w Workflow) Board(ctx context.Context, userID int64, projectID *int64) ([]models.BoardColumn, error) {
+	const op = "services.workflow.Board"
+
+	statuses, err := w.Workflow(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := w.tasks.SelectAllTasksByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	columns := make([]models.BoardColumn, len(statuses))
+	index := make(map[models.Status]int, len(statuses))
+	for i, s := range statuses {
+		columns[i] = models.BoardColumn{Status: s, Tasks: []models.Task{}}
+		index[s.Name] = i
+	}
+
+	for _, t := range tasks {
+		if !sameProject(t.ProjectID, projectID) {
+			continue
+		}
+		i, ok := index[t.Status]
+		if !ok {
+			i = 0
+		}
+		columns[i].Tasks = append(columns[i].Tasks, t)
+	}
+
+	return columns, nil
+}
+
+// ensureScope copies effective workflow into the scope if the scope does not have own statuses yet
+func (w Workflow) ensureScope(ctx context.Context, userID int64, projectID *int64) ([]models.WorkflowStatus, error) {
+	effective, err := w.Workflow(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	own, err := w.provider.SelectWorkflowStatuses(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if len(own) > 0 {
+		return own, nil
+	}
+
+	for i, s := range effective {
+		s.ID = 0
+		s.UserID = userID
+		s.ProjectID = projectID
+		if s.Position <= 0 {
+			s.Position = i + 1
+		}
+		if s.ID, err = w.saver.InsertWorkflowStatus(ctx, s); err != nil {
+			return nil, err
+		}
+		effective[i] = s
+	}
+
+	return effective, nil
+}
+
+func validateStatus(ws *models.WorkflowStatus) error {
+	ws.Name = models.Status(strings.TrimSpace(string(ws.Name)))
+	if ws.Name == "" || len(ws.Name) > maxStatusNameLength {
+		return fmt.Errorf("status name must be from 1 to %d characters: %w", maxStatusNameLength, models.ErrUnknownStatus)
+	}
+	if !ws.Category.Valid() {
+		return models.ErrInvalidCategory
+	}
+	if ws.WIPLimit < 0 {
+		ws.WIPLimit = 0
+	}
+	return nil
+}
+
+func sameProject(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,259 @@
+package workspaces
+
+import (
+	"TaskList/internal/config"
+	"TaskList/internal/models"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	invitationTokenBytes = 20
+	invitationTTL        = 7 * 24 * time.Hour
+)
+
+var (
+	ErrEmptyName = errors.New("workspace name is empty")
+)
+
+type Saver interface {
+	InsertWorkspace(ctx context.Context, ws models.Workspace) (int64, error)
+	InsertInvitation(ctx context.Context, inv models.Invitation) (int64, error)
+}
+
+type Provider interface {
+	SelectWorkspacesByUserID(ctx context.Context, userID int64) ([]models.Workspace, error)
+	SelectWorkspaceByID(ctx context.Context, workspaceID int64, userID int64) (models.Workspace, error)
+	SelectWorkspaceMembers(ctx context.Context, workspaceID int64) ([]models.WorkspaceMember, error)
+	SelectWorkspaceMember(ctx context.Context, workspaceID int64, userID int64) (models.WorkspaceMember, error)
+	SelectInvitations(ctx context.Context, workspaceID int64) ([]models.Invitation, error)
+	SelectInvitationByToken(ctx context.Context, token string) (models.Invitation, error)
+	SelectProjectsByWorkspace(ctx context.Context, workspaceID int64) ([]models.Project, error)
+}
+
+type Updater interface {
+	UpdateWorkspace(ctx context.Context, ws models.Workspace) error
+	DeleteWorkspace(ctx context.Context, workspaceID int64) error
+	UpdateWorkspaceMemberRole(ctx context.Context, workspaceID int64, userID int64, role models.Role) error
+	DeleteWorkspaceMember(ctx context.Context, workspaceID int64, userID int64) error
+	AcceptInvitation(ctx context.Context, inv models.Invitation, userID int64) error
+	DeleteInvitation(ctx context.Context, workspaceID int64, invitationID int64) error
+}
+
+type UserProvider interface {
+	UserByID(ctx context.Context, userID int64) (*models.User, error)
+}
+
+// Workspaces manages workspaces, members and invitations.
+// Access to the workspace is checked by the authz layer before calling the service,
+// methods changing members take the acting member to compare roles
+type Workspaces struct {
+	saver    Saver
+	provider Provider
+	updater  Updater
+	users    UserProvider
+	cfg      *config.Config
+	log      *slog.Logger
+}
+
+func NewServices(s Saver, p Provider, u Updater, users UserProvider, cfg *config.Config, log *slog.Logger) *Workspaces {
+	return &Workspaces{saver: s, provider: p, updater: u, users: users, cfg: cfg, log: log}
+}
+
+// CreateWorkspace creates workspace owned by the user
+func (w Workspaces) CreateWorkspace(ctx context.Context, userID int64, name string) (int64, error) {
+	const op = "services.workspaces.CreateWorkspace"
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, ErrEmptyName
+	}
+
+	id, err := w.saver.InsertWorkspace(ctx, models.Workspace{OwnerID: userID, Name: name})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	w.log.Info("workspace created", slog.String("op", op), slog.Int64("workspace_id", id), slog.Int64("user_id", userID))
+
+	return id, nil
+}
+
+func (w Workspaces) Workspaces(ctx context.Context, userID int64) ([]models.Workspace, error) {
+	return w.provider.SelectWorkspacesByUserID(ctx, userID)
+}
+
+func (w Workspaces) Workspace(ctx context.Context, workspaceID int64, userID int64) (models.Workspace, error) {
+	return w.provider.SelectWorkspaceByID(ctx, workspaceID, userID)
+}
+
+func (w Workspaces) RenameWorkspace(ctx context.Context, workspaceID int64, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrEmptyName
+	}
+	return w.updater.UpdateWorkspace(ctx, models.Workspace{ID: workspaceID, Name: name})
+}
+
+// DeleteWorkspace deletes the workspace, its projects become personal projects of the owner
+func (w Workspaces) DeleteWorkspace(ctx context.Context, workspaceID int64) error {
+	return w.updater.DeleteWorkspace(ctx, workspaceID)
+}
+
+func (w Workspaces) Members(ctx context.Context, workspaceID int64) ([]models.WorkspaceMember, error) {
+	return w.provider.SelectWorkspaceMembers(ctx, workspaceID)
+}
+
+func (w Workspaces) Projects(ctx context.Context, workspaceID int64) ([]models.Project, error) {
+	return w.provider.SelectProjectsByWorkspace(ctx, workspaceID)
+}
+
+// ChangeRole changes role of the member, actor must outrank both the current and the new role
+func (w Workspaces) ChangeRole(ctx context.Context, actor models.WorkspaceMember, userID int64, role models.Role) error {
+	const op = "services.workspaces.ChangeRole"
+
+	if !role.Valid() {
+		return fmt.Errorf("%w: %s", models.ErrInvalidRole, role)
+	}
+
+	target, err := w.provider.SelectWorkspaceMember(ctx, actor.WorkspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if !actor.Role.Outranks(target.Role) || !actor.Role.Outranks(role) {
+		return fmt.Errorf("%w: %s can not change %s to %s", models.ErrForbidden, actor.Role, target.Role, role)
+	}
+
+	if err = w.updater.UpdateWorkspaceMemberRole(ctx, actor.WorkspaceID, userID, role); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	w.log.Info(
+		"member role changed",
+		slog.String("op", op),
+		slog.Int64("workspace_id", actor.WorkspaceID),
+		slog.Int64("user_id", userID),
+		slog.String("role", string(role)),
+	)
+
+	return nil
+}
+
+// RemoveMember removes member from the workspace, members can leave by removing themselves,
+// others require manage members permission and a higher role
+func (w Workspaces) RemoveMember(ctx context.Context, actor models.WorkspaceMember, userID int64) error {
+	const op = "services.workspaces.RemoveMember"
+
+	target, err := w.provider.SelectWorkspaceMember(ctx, actor.WorkspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	switch {
+	case target.Role == models.RoleOwner:
+		return fmt.Errorf("%w: owner can not leave the workspace", models.ErrForbidden)
+	case target.UserID == actor.UserID:
+	case !actor.Role.Can(models.ActionManageMembers) || !actor.Role.Outranks(target.Role):
+		return fmt.Errorf("%w: %s can not remove %s", models.ErrForbidden, actor.Role, target.Role)
+	}
+
+	if err = w.updater.DeleteWorkspaceMember(ctx, actor.WorkspaceID, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Invite creates invitation with a secret token, the token is delivered to the invitee by the inviter
+func (w Workspaces) Invite(ctx context.Context, actor models.WorkspaceMember, email string, role models.Role) (models.Invitation, error) {
+	const op = "services.workspaces.Invite"
+
+	if !role.Valid() {
+		return models.Invitation{}, fmt.Errorf("%w: %s", models.ErrInvalidRole, role)
+	}
+	if !actor.Role.Outranks(role) {
+		return models.Invitation{}, fmt.Errorf("%w: %s can not invite %s", models.ErrForbidden, actor.Role, role)
+	}
+
+	b := make([]byte, invitationTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return models.Invitation{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	inv := models.Invitation{
+		WorkspaceID: actor.WorkspaceID,
+		Email:       strings.ToLower(strings.TrimSpace(email)),
+		Role:        role,
+		Token:       hex.EncodeToString(b),
+		InvitedBy:   actor.UserID,
+		CreatedAt:   time.Now().UTC(),
+		ExpiresAt:   time.Now().UTC().Add(invitationTTL),
+	}
+
+	id, err := w.saver.InsertInvitation(ctx, inv)
+	if err != nil {
+		return models.Invitation{}, fmt.Errorf("%s: %w", op, err)
+	}
+	inv.ID = id
+
+	w.log.Info(
+		"invitation created",
+		slog.String("op", op),
+		slog.Int64("workspace_id", actor.WorkspaceID),
+		slog.Int64("invitation_id", id),
+	)
+
+	return inv, nil
+}
+
+func (w Workspaces) Invitations(ctx context.Context, workspaceID int64) ([]models.Invitation, error) {
+	return w.provider.SelectInvitations(ctx, workspaceID)
+}
+
+func (w Workspaces) RevokeInvitation(ctx context.Context, workspaceID int64, invitationID int64) error {
+	return w.updater.DeleteInvitation(ctx, workspaceID, invitationID)
+}
+
+// AcceptInvitation adds the user to the workspace, the invitation must be sent to the user email
+func (w Workspaces) AcceptInvitation(ctx context.Context, userID int64, token string) (int64, error) {
+	const op = "services.workspaces.AcceptInvitation"
+
+	inv, err := w.provider.SelectInvitationByToken(ctx, token)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if inv.AcceptedAt != nil {
+		return 0, models.ErrInvitationNotFound
+	}
+	if time.Now().After(inv.ExpiresAt) {
+		return 0, models.ErrInvitationExpired
+	}
+
+	user, err := w.users.UserByID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if !strings.EqualFold(user.Email, inv.Email) {
+		return 0, models.ErrInvitationEmail
+	}
+
+	if err = w.updater.AcceptInvitation(ctx, inv, userID); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	w.log.Info(
+		"invitation accepted",
+		slog.String("op", op),
+		slog.Int64("workspace_id", inv.WorkspaceID),
+		slog.Int64("user_id", userID),
+	)
+
+	return inv.WorkspaceID, nil
+}
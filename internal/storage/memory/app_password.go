@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"time"
+)
+
+func (s *Storage) InsertAppPassword(ctx context.Context, p models.AppPassword, tokenHash string) (int64, error) {
+	defer s.lock(ctx)()
+
+	p.ID = s.nextID("app_passwords")
+	p.LastUsedAt = nil
+	s.appPasswords[p.ID] = appPassword{AppPassword: p, tokenHash: tokenHash}
+
+	return p.ID, nil
+}
+
+func (s *Storage) SelectAppPasswords(ctx context.Context, userID int64) ([]models.AppPassword, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var res []models.AppPassword
+	for _, id := range sortedKeys(s.appPasswords) {
+		if p := s.appPasswords[id]; p.UserID == userID {
+			p.LastUsedAt = copyTime(p.LastUsedAt)
+			res = append(res, p.AppPassword)
+		}
+	}
+
+	return res, nil
+}
+
+func (s *Storage) DeleteAppPassword(ctx context.Context, userID int64, id int64) error {
+	defer s.lock(ctx)()
+
+	if p, ok := s.appPasswords[id]; !ok || p.UserID != userID {
+		return models.ErrAppPasswordNotFound
+	}
+	delete(s.appPasswords, id)
+
+	return nil
+}
+
+// UserByAppPassword returns the user with the email who has the app password and marks the password used,
+// the mark is updated at most once a minute like in the sql storages
+func (s *Storage) UserByAppPassword(ctx context.Context, email string, tokenHash string) (*models.User, error) {
+	defer s.lock(ctx)()
+
+	user, ok := s.userByEmail(email)
+	if !ok {
+		return nil, models.ErrInvalidCredentials
+	}
+
+	for id, p := range s.appPasswords {
+		if p.UserID != user.ID || p.tokenHash != tokenHash {
+			continue
+		}
+
+		now := time.Now().UTC()
+		if p.LastUsedAt == nil || p.LastUsedAt.Before(now.Add(-time.Minute)) {
+			p.LastUsedAt = &now
+			s.appPasswords[id] = p
+		}
+		return copyUser(user), nil
+	}
+
+	return nil, models.ErrInvalidCredentials
+}
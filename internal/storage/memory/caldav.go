@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"context"
+)
+
+// SelectCalendarObjectNames returns names chosen by CalDAV clients for the tasks which have them
+func (s *Storage) SelectCalendarObjectNames(ctx context.Context, taskIDs []int64) (map[int64]models.CalendarObjectName, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	res := make(map[int64]models.CalendarObjectName, len(taskIDs))
+	for _, id := range taskIDs {
+		if n, ok := s.caldav[id]; ok {
+			res[id] = n
+		}
+	}
+
+	return res, nil
+}
+
+func (s *Storage) UpsertCalendarObjectName(ctx context.Context, n models.CalendarObjectName) error {
+	defer s.lock(ctx)()
+
+	s.caldav[n.TaskID] = n
+
+	return nil
+}
+
+func (s *Storage) DeleteCalendarObjectName(ctx context.Context, taskID int64) error {
+	defer s.lock(ctx)()
+
+	delete(s.caldav, taskID)
+
+	return nil
+}
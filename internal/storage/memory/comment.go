@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"cmp"
+	"context"
+	"slices"
+)
+
+func (s *Storage) InsertComment(ctx context.Context, comment models.Comment) (int64, error) {
+	defer s.lock(ctx)()
+
+	comment.ID = s.nextID("task_comments")
+	comment.Email = ""
+	s.comments[comment.ID] = comment
+
+	return comment.ID, nil
+}
+
+func (s *Storage) SelectComments(ctx context.Context, taskID int64) ([]models.Comment, error) {
+	return s.selectComments([]int64{taskID}), nil
+}
+
+// SelectCommentsByTaskIDs returns comments of all the tasks at once
+func (s *Storage) SelectCommentsByTaskIDs(ctx context.Context, taskIDs []int64) ([]models.Comment, error) {
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+	return s.selectComments(taskIDs), nil
+}
+
+func (s *Storage) selectComments(taskIDs []int64) []models.Comment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var comments []models.Comment
+	for _, c := range s.comments {
+		user, ok := s.users[c.UserID]
+		if !ok || !slices.Contains(taskIDs, c.TaskID) {
+			continue
+		}
+		c.Email = user.Email
+		comments = append(comments, c)
+	}
+	slices.SortFunc(comments, func(a, b models.Comment) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return comments
+}
@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+func (s *Storage) InsertCustomField(ctx context.Context, field models.CustomField) (int64, error) {
+	defer s.lock(ctx)()
+
+	for _, f := range s.fields {
+		if f.ProjectID == field.ProjectID && f.Name == field.Name {
+			return 0, models.ErrFieldAlreadyExists
+		}
+	}
+
+	field.ID = s.nextID("custom_fields")
+	field.Options = slices.Clone(field.Options)
+	if field.Options == nil {
+		field.Options = []string{}
+	}
+	field.CreatedAt = time.Now().UTC()
+	s.fields[field.ID] = field
+
+	return field.ID, nil
+}
+
+func (s *Storage) SelectCustomFields(ctx context.Context, projectID int64, userID int64) ([]models.CustomField, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var fields []models.CustomField
+	for _, f := range s.fields {
+		if f.ProjectID == projectID && f.UserID == userID {
+			f.Options = slices.Clone(f.Options)
+			fields = append(fields, f)
+		}
+	}
+	slices.SortFunc(fields, compareFields)
+
+	return fields, nil
+}
+
+func (s *Storage) SelectCustomFieldByID(ctx context.Context, fieldID int64, userID int64) (models.CustomField, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, ok := s.fields[fieldID]
+	if !ok || f.UserID != userID {
+		return models.CustomField{}, models.ErrFieldNotFound
+	}
+	f.Options = slices.Clone(f.Options)
+
+	return f, nil
+}
+
+func (s *Storage) DeleteCustomField(ctx context.Context, fieldID int64, userID int64) error {
+	defer s.lock(ctx)()
+
+	if f, ok := s.fields[fieldID]; !ok || f.UserID != userID {
+		return models.ErrFieldNotFound
+	}
+	s.deleteField(fieldID)
+
+	return nil
+}
+
+// deleteField deletes the field with its values
+func (s *Storage) deleteField(fieldID int64) {
+	delete(s.fields, fieldID)
+	for taskID, values := range s.fieldValues {
+		if _, ok := values[fieldID]; ok {
+			s.clearFieldValue(taskID, fieldID)
+		}
+	}
+}
+
+// SetTaskFieldValues upserts values and deletes values of the fields from clear
+func (s *Storage) SetTaskFieldValues(ctx context.Context, taskID int64, values []models.FieldValue, clear []int64) error {
+	defer s.lock(ctx)()
+
+	s.upsertFieldValues(taskID, values)
+	for _, fieldID := range clear {
+		s.clearFieldValue(taskID, fieldID)
+	}
+
+	if task, ok := s.tasks[taskID]; ok {
+		task.UpdatedAt = time.Now().UTC()
+		s.tasks[taskID] = task
+	}
+
+	return nil
+}
+
+func (s *Storage) upsertFieldValues(taskID int64, values []models.FieldValue) {
+	for _, v := range values {
+		stored := s.fieldValues[taskID]
+		if stored == nil {
+			stored = make(map[int64]string)
+			s.fieldValues[taskID] = stored
+		}
+		if old, ok := stored[v.FieldID]; ok && old == v.Value {
+			continue
+		}
+		stored[v.FieldID] = v.Value
+		s.logChange(taskID, string(models.TaskFieldCustomFields), 0)
+	}
+}
+
+func (s *Storage) clearFieldValue(taskID int64, fieldID int64) {
+	stored := s.fieldValues[taskID]
+	if _, ok := stored[fieldID]; !ok {
+		return
+	}
+
+	delete(stored, fieldID)
+	if len(stored) == 0 {
+		delete(s.fieldValues, taskID)
+	}
+	if _, ok := s.tasks[taskID]; ok {
+		s.logChange(taskID, string(models.TaskFieldCustomFields), 0)
+	}
+}
+
+// taskFieldValues returns values of the task in the order of the fields
+func (s *Storage) taskFieldValues(taskID int64) []models.FieldValue {
+	stored := s.fieldValues[taskID]
+	if len(stored) == 0 {
+		return nil
+	}
+
+	fields := make([]models.CustomField, 0, len(stored))
+	for fieldID := range stored {
+		if f, ok := s.fields[fieldID]; ok {
+			fields = append(fields, f)
+		}
+	}
+	slices.SortFunc(fields, compareFields)
+
+	var values []models.FieldValue
+	for _, f := range fields {
+		values = append(values, models.FieldValue{FieldID: f.ID, Name: f.Name, Type: f.Type, Value: stored[f.ID]})
+	}
+	return values
+}
+
+func compareFields(a, b models.CustomField) int {
+	return cmp.Or(cmp.Compare(a.Position, b.Position), cmp.Compare(a.ID, b.ID))
+}
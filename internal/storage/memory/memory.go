@@ -0,0 +1,292 @@
+// Package memory keeps all the data in process memory, it is meant for development and tests,
+// the data is lost when the process exits
+package memory
+
+import (
+	"TaskList/internal/models"
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+type pairKey struct {
+	a, b int64
+}
+
+type clientKey struct {
+	userID   int64
+	clientID string
+}
+
+type appPassword struct {
+	models.AppPassword
+	tokenHash string
+}
+
+type member struct {
+	role      models.Role
+	createdAt time.Time
+}
+
+type change struct {
+	seq    int64
+	taskID int64
+	field  string
+	userID int64
+}
+
+// Storage is safe for concurrent use, every method holds the lock for its whole work
+// so the method is atomic like a transaction of the sql storages
+type Storage struct {
+	mu sync.RWMutex
+	// tx serializes WithinTx and the writes made outside of it, a rollback restores the data
+	// taken when the transaction began and must not undo writes of others
+	tx sync.Mutex
+
+	data
+}
+
+// data are the tables, the storage copies them to roll back a transaction
+type data struct {
+	// ids holds the last id given in every table
+	ids map[string]int64
+
+	users        map[int64]models.User
+	appPasswords map[int64]appPassword
+
+	tasks       map[int64]models.Task
+	tags        map[int64]map[string]struct{}
+	fieldValues map[int64]map[int64]string
+	shares      map[pairKey]models.TaskShare
+	comments    map[int64]models.Comment
+	caldav      map[int64]models.CalendarObjectName
+
+	projects map[int64]models.Project
+	statuses map[int64]models.WorkflowStatus
+	fields   map[int64]models.CustomField
+
+	watchers      map[pairKey]time.Time
+	notifications map[int64]models.Notification
+	preferences   map[int64]models.NotificationPreferences
+
+	webhooks   map[int64]models.Webhook
+	deliveries map[int64]models.WebhookDelivery
+
+	smartLists map[int64]models.SmartList
+
+	workspaces  map[int64]models.Workspace
+	members     map[pairKey]member
+	invitations map[int64]models.Invitation
+
+	changes     []change
+	tombstones  map[int64]models.Task
+	syncClients map[clientKey]int64
+}
+
+func New() *Storage {
+	return &Storage{data: data{
+		ids:           make(map[string]int64),
+		users:         make(map[int64]models.User),
+		appPasswords:  make(map[int64]appPassword),
+		tasks:         make(map[int64]models.Task),
+		tags:          make(map[int64]map[string]struct{}),
+		fieldValues:   make(map[int64]map[int64]string),
+		shares:        make(map[pairKey]models.TaskShare),
+		comments:      make(map[int64]models.Comment),
+		caldav:        make(map[int64]models.CalendarObjectName),
+		projects:      make(map[int64]models.Project),
+		statuses:      make(map[int64]models.WorkflowStatus),
+		fields:        make(map[int64]models.CustomField),
+		watchers:      make(map[pairKey]time.Time),
+		notifications: make(map[int64]models.Notification),
+		preferences:   make(map[int64]models.NotificationPreferences),
+		webhooks:      make(map[int64]models.Webhook),
+		deliveries:    make(map[int64]models.WebhookDelivery),
+		smartLists:    make(map[int64]models.SmartList),
+		workspaces:    make(map[int64]models.Workspace),
+		members:       make(map[pairKey]member),
+		invitations:   make(map[int64]models.Invitation),
+		tombstones:    make(map[int64]models.Task),
+		syncClients:   make(map[clientKey]int64),
+	}}
+}
+
+// clone copies the tables, values are replaced on update and only the nested maps are copied deeply
+func (d data) clone() data {
+	c := data{
+		ids:           maps.Clone(d.ids),
+		users:         maps.Clone(d.users),
+		appPasswords:  maps.Clone(d.appPasswords),
+		tasks:         maps.Clone(d.tasks),
+		tags:          make(map[int64]map[string]struct{}, len(d.tags)),
+		fieldValues:   make(map[int64]map[int64]string, len(d.fieldValues)),
+		shares:        maps.Clone(d.shares),
+		comments:      maps.Clone(d.comments),
+		caldav:        maps.Clone(d.caldav),
+		projects:      maps.Clone(d.projects),
+		statuses:      maps.Clone(d.statuses),
+		fields:        maps.Clone(d.fields),
+		watchers:      maps.Clone(d.watchers),
+		notifications: maps.Clone(d.notifications),
+		preferences:   maps.Clone(d.preferences),
+		webhooks:      maps.Clone(d.webhooks),
+		deliveries:    maps.Clone(d.deliveries),
+		smartLists:    maps.Clone(d.smartLists),
+		workspaces:    maps.Clone(d.workspaces),
+		members:       maps.Clone(d.members),
+		invitations:   maps.Clone(d.invitations),
+		changes:       slices.Clone(d.changes),
+		tombstones:    maps.Clone(d.tombstones),
+		syncClients:   maps.Clone(d.syncClients),
+	}
+	for id, tags := range d.tags {
+		c.tags[id] = maps.Clone(tags)
+	}
+	for id, values := range d.fieldValues {
+		c.fieldValues[id] = maps.Clone(values)
+	}
+	return c
+}
+
+func (s *Storage) Ping() error {
+	return nil
+}
+
+func (s *Storage) Close() error {
+	return nil
+}
+
+func (s *Storage) nextID(table string) int64 {
+	s.ids[table]++
+	return s.ids[table]
+}
+
+// sortedKeys returns the keys in ascending order, rows of the sql storages come in the order of their ids
+func sortedKeys[K cmp.Ordered, V any](m map[K]V) []K {
+	return slices.Sorted(maps.Keys(m))
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := t.UTC()
+	return &v
+}
+
+func copyInt64(v *int64) *int64 {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	return &c
+}
+
+func equalInt64(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+type txKey struct{}
+
+// txState collects the hooks of AfterCommit until the outermost WithinTx returns
+type txState struct {
+	afterCommit []func(ctx context.Context)
+}
+
+// WithinTx runs fn holding the transaction lock, so a check and the write made on it are atomic
+// against other transactions. The data is restored when fn fails, a failed nested call
+// is rolled back alone like a savepoint
+func (s *Storage) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		snapshot, hooks := s.snapshot(), len(state.afterCommit)
+		if err := fn(ctx); err != nil {
+			s.restore(snapshot)
+			state.afterCommit = state.afterCommit[:hooks]
+			return err
+		}
+		return nil
+	}
+
+	state := &txState{}
+	if err := s.runTx(context.WithValue(ctx, txKey{}, state), fn); err != nil {
+		return err
+	}
+
+	// hooks run without the lock, they may start transactions of their own
+	for _, hook := range state.afterCommit {
+		hook(ctx)
+	}
+	return nil
+}
+
+// runTx runs fn under the transaction lock, the data is restored when fn fails or panics
+func (s *Storage) runTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	s.tx.Lock()
+	defer s.tx.Unlock()
+
+	snapshot := s.snapshot()
+	committed := false
+	defer func() {
+		if !committed {
+			s.restore(snapshot)
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func (s *Storage) snapshot() data {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.data.clone()
+}
+
+func (s *Storage) restore(snapshot data) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data = snapshot
+}
+
+// lock takes the write lock, outside of a transaction it waits for the running one
+// so the rollback of the transaction can't undo the write
+func (s *Storage) lock(ctx context.Context) func() {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		s.mu.Lock()
+		return s.mu.Unlock
+	}
+
+	s.tx.Lock()
+	s.mu.Lock()
+	return func() {
+		s.mu.Unlock()
+		s.tx.Unlock()
+	}
+}
+
+// AfterCommit runs fn once the outermost WithinTx of the ctx succeeds, at once outside of a transaction
+func (s *Storage) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+	fn(ctx)
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"TaskList/internal/storage/storagetest"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestContract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return New()
+	})
+}
+
+// a panic in fn rolls the transaction back and releases the lock for the next one
+func TestWithinTxPanic(t *testing.T) {
+	s := New()
+	ctx := context.Background()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic is not propagated")
+			}
+		}()
+		_ = s.WithinTx(ctx, func(ctx context.Context) error {
+			if _, err := s.CreateUser(ctx, "a@example.com", []byte("hash")); err != nil {
+				return err
+			}
+			panic("failure")
+		})
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := s.CreateUser(ctx, "a@example.com", []byte("hash"))
+			return err
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("WithinTx() after panic error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("transaction lock is held after panic")
+	}
+}
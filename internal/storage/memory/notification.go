@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"time"
+)
+
+// InsertWatcher subscribes the user to the task, watching twice is not an error
+func (s *Storage) InsertWatcher(ctx context.Context, taskID int64, userID int64) error {
+	defer s.lock(ctx)()
+
+	key := pairKey{taskID, userID}
+	if _, ok := s.watchers[key]; !ok {
+		s.watchers[key] = time.Now().UTC()
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteWatcher(ctx context.Context, taskID int64, userID int64) error {
+	defer s.lock(ctx)()
+
+	delete(s.watchers, pairKey{taskID, userID})
+
+	return nil
+}
+
+func (s *Storage) DeleteWatchers(ctx context.Context, taskID int64) error {
+	defer s.lock(ctx)()
+
+	for key := range s.watchers {
+		if key.a == taskID {
+			delete(s.watchers, key)
+		}
+	}
+
+	return nil
+}
+
+func (s *Storage) SelectWatchers(ctx context.Context, taskID int64) ([]models.Watcher, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var watchers []models.Watcher
+	for key, createdAt := range s.watchers {
+		user, ok := s.users[key.b]
+		if key.a != taskID || !ok {
+			continue
+		}
+		watchers = append(watchers, models.Watcher{TaskID: key.a, UserID: key.b, Email: user.Email, CreatedAt: createdAt})
+	}
+	slices.SortFunc(watchers, func(a, b models.Watcher) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.UserID, b.UserID))
+	})
+
+	return watchers, nil
+}
+
+func (s *Storage) InsertNotification(ctx context.Context, n models.Notification) (int64, error) {
+	defer s.lock(ctx)()
+
+	n.ID = s.nextID("notifications")
+	n.ReadAt = nil
+	s.notifications[n.ID] = n
+
+	return n.ID, nil
+}
+
+// SelectNotifications returns the newest notifications of the user first
+func (s *Storage) SelectNotifications(ctx context.Context, userID int64, unreadOnly bool, limit int) ([]models.Notification, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var res []models.Notification
+	for _, n := range s.notifications {
+		if n.UserID != userID || (unreadOnly && n.ReadAt != nil) {
+			continue
+		}
+		n.ReadAt = copyTime(n.ReadAt)
+		res = append(res, n)
+	}
+	slices.SortFunc(res, func(a, b models.Notification) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+	if limit >= 0 && len(res) > limit {
+		res = res[:limit]
+	}
+
+	return res, nil
+}
+
+func (s *Storage) CountUnreadNotifications(ctx context.Context, userID int64) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int
+	for _, n := range s.notifications {
+		if n.UserID == userID && n.ReadAt == nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// UpdateNotificationRead marks the notification read or unread, read time of already read notification is kept
+func (s *Storage) UpdateNotificationRead(ctx context.Context, id int64, userID int64, read bool) error {
+	defer s.lock(ctx)()
+
+	n, ok := s.notifications[id]
+	if !ok || n.UserID != userID {
+		return models.ErrNotificationNotFound
+	}
+
+	switch {
+	case !read:
+		n.ReadAt = nil
+	case n.ReadAt == nil:
+		now := time.Now().UTC()
+		n.ReadAt = &now
+	}
+	s.notifications[id] = n
+
+	return nil
+}
+
+func (s *Storage) MarkAllNotificationsRead(ctx context.Context, userID int64) (int64, error) {
+	defer s.lock(ctx)()
+
+	var count int64
+	now := time.Now().UTC()
+	for id, n := range s.notifications {
+		if n.UserID == userID && n.ReadAt == nil {
+			n.ReadAt = &now
+			s.notifications[id] = n
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (s *Storage) SelectNotificationPreferences(ctx context.Context, userID int64) (models.NotificationPreferences, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	prefs := make(models.NotificationPreferences)
+	maps.Copy(prefs, s.preferences[userID])
+
+	return prefs, nil
+}
+
+func (s *Storage) UpsertNotificationPreferences(ctx context.Context, userID int64, prefs models.NotificationPreferences) error {
+	defer s.lock(ctx)()
+
+	stored := s.preferences[userID]
+	if stored == nil {
+		stored = make(models.NotificationPreferences)
+		s.preferences[userID] = stored
+	}
+	maps.Copy(stored, prefs)
+
+	return nil
+}
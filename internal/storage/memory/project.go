@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+func (s *Storage) InsertProject(ctx context.Context, project models.Project) (int64, error) {
+	defer s.lock(ctx)()
+
+	now := time.Now().UTC()
+	id := s.nextID("projects")
+	s.projects[id] = models.Project{
+		ID:          id,
+		UserID:      project.UserID,
+		WorkspaceID: copyInt64(project.WorkspaceID),
+		Name:        project.Name,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	return id, nil
+}
+
+// SelectProjectsByUserID selects own projects and projects of workspaces the user is member of
+func (s *Storage) SelectProjectsByUserID(ctx context.Context, userID int64) ([]models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterProjects(func(p models.Project) bool {
+		if p.UserID == userID {
+			return true
+		}
+		if p.WorkspaceID == nil {
+			return false
+		}
+		_, ok := s.members[pairKey{*p.WorkspaceID, userID}]
+		return ok
+	}), nil
+}
+
+func (s *Storage) SelectProjectsByWorkspace(ctx context.Context, workspaceID int64) ([]models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterProjects(func(p models.Project) bool {
+		return p.WorkspaceID != nil && *p.WorkspaceID == workspaceID
+	}), nil
+}
+
+func (s *Storage) filterProjects(match func(p models.Project) bool) []models.Project {
+	var projects []models.Project
+	for _, id := range sortedKeys(s.projects) {
+		if p := s.projects[id]; match(p) {
+			p.WorkspaceID = copyInt64(p.WorkspaceID)
+			projects = append(projects, p)
+		}
+	}
+	slices.SortStableFunc(projects, func(a, b models.Project) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return projects
+}
+
+func (s *Storage) SelectProjectByID(ctx context.Context, projectID int64, userID int64) (models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.projects[projectID]
+	if !ok || p.UserID != userID {
+		return models.Project{}, models.ErrProjectNotFound
+	}
+	p.WorkspaceID = copyInt64(p.WorkspaceID)
+
+	return p, nil
+}
+
+// SelectProject selects project regardless of the owner, access is checked by the caller
+func (s *Storage) SelectProject(ctx context.Context, projectID int64) (models.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.projects[projectID]
+	if !ok {
+		return models.Project{}, models.ErrProjectNotFound
+	}
+	p.WorkspaceID = copyInt64(p.WorkspaceID)
+
+	return p, nil
+}
+
+func (s *Storage) UpdateProject(ctx context.Context, project models.Project) error {
+	defer s.lock(ctx)()
+
+	p, ok := s.projects[project.ID]
+	if !ok || p.UserID != project.UserID {
+		return models.ErrProjectNotFound
+	}
+	p.Name = project.Name
+	p.UpdatedAt = time.Now().UTC()
+	s.projects[project.ID] = p
+
+	return nil
+}
+
+// DeleteProject deletes the project with its statuses and fields, tasks are kept without project
+func (s *Storage) DeleteProject(ctx context.Context, projectID int64, userID int64) error {
+	defer s.lock(ctx)()
+
+	if p, ok := s.projects[projectID]; !ok || p.UserID != userID {
+		return models.ErrProjectNotFound
+	}
+	delete(s.projects, projectID)
+
+	for _, id := range sortedKeys(s.tasks) {
+		task := s.tasks[id]
+		if task.ProjectID != nil && *task.ProjectID == projectID {
+			task.ProjectID = nil
+			s.tasks[id] = task
+			s.logChange(id, string(models.TaskFieldProject), 0)
+		}
+	}
+	for id, ws := range s.statuses {
+		if ws.ProjectID != nil && *ws.ProjectID == projectID {
+			delete(s.statuses, id)
+		}
+	}
+	for _, id := range sortedKeys(s.fields) {
+		if s.fields[id].ProjectID == projectID {
+			s.deleteField(id)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+// UpsertTaskShare shares the task with the user or changes permission of existing share
+func (s *Storage) UpsertTaskShare(ctx context.Context, share models.TaskShare) error {
+	defer s.lock(ctx)()
+
+	key := pairKey{share.TaskID, share.UserID}
+	if stored, ok := s.shares[key]; ok {
+		stored.Permission = share.Permission
+		s.shares[key] = stored
+		return nil
+	}
+
+	s.shares[key] = models.TaskShare{
+		TaskID:     share.TaskID,
+		UserID:     share.UserID,
+		Permission: share.Permission,
+		CreatedAt:  time.Now().UTC(),
+	}
+	s.logChange(share.TaskID, "access", share.UserID)
+
+	return nil
+}
+
+func (s *Storage) SelectTaskShares(ctx context.Context, taskID int64) ([]models.TaskShare, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var shares []models.TaskShare
+	for key, share := range s.shares {
+		user, ok := s.users[key.b]
+		if key.a != taskID || !ok {
+			continue
+		}
+		share.Email = user.Email
+		shares = append(shares, share)
+	}
+	slices.SortFunc(shares, func(a, b models.TaskShare) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.UserID, b.UserID))
+	})
+
+	return shares, nil
+}
+
+func (s *Storage) SelectTaskShare(ctx context.Context, taskID int64, userID int64) (models.TaskShare, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	share, ok := s.shares[pairKey{taskID, userID}]
+	user, found := s.users[userID]
+	if !ok || !found {
+		return models.TaskShare{}, models.ErrShareNotFound
+	}
+	share.Email = user.Email
+
+	return share, nil
+}
+
+// DeleteTaskShare revokes access, the user stops being assignee of the task
+func (s *Storage) DeleteTaskShare(ctx context.Context, taskID int64, userID int64) error {
+	defer s.lock(ctx)()
+
+	key := pairKey{taskID, userID}
+	if _, ok := s.shares[key]; !ok {
+		return models.ErrShareNotFound
+	}
+	delete(s.shares, key)
+	s.logChange(taskID, "access", userID)
+
+	if task, ok := s.tasks[taskID]; ok && task.AssigneeID != nil && *task.AssigneeID == userID {
+		s.setAssignee(taskID, nil)
+	}
+
+	return nil
+}
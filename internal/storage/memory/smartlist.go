@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"slices"
+	"time"
+)
+
+func (s *Storage) InsertSmartList(ctx context.Context, list models.SmartList) (int64, error) {
+	defer s.lock(ctx)()
+
+	now := time.Now().UTC()
+	id := s.nextID("smart_lists")
+	s.smartLists[id] = models.SmartList{
+		ID:        id,
+		UserID:    list.UserID,
+		Name:      list.Name,
+		Filter:    cloneFilter(list.Filter),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return id, nil
+}
+
+func (s *Storage) SelectSmartLists(ctx context.Context, userID int64) ([]models.SmartList, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var lists []models.SmartList
+	for _, id := range sortedKeys(s.smartLists) {
+		if sl := s.smartLists[id]; sl.UserID == userID {
+			sl.Filter = cloneFilter(sl.Filter)
+			lists = append(lists, sl)
+		}
+	}
+
+	return lists, nil
+}
+
+func (s *Storage) SelectSmartListByID(ctx context.Context, listID int64, userID int64) (models.SmartList, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sl, ok := s.smartLists[listID]
+	if !ok || sl.UserID != userID {
+		return models.SmartList{}, models.ErrSmartListNotFound
+	}
+	sl.Filter = cloneFilter(sl.Filter)
+
+	return sl, nil
+}
+
+func (s *Storage) UpdateSmartList(ctx context.Context, list models.SmartList) error {
+	defer s.lock(ctx)()
+
+	sl, ok := s.smartLists[list.ID]
+	if !ok || sl.UserID != list.UserID {
+		return models.ErrSmartListNotFound
+	}
+	sl.Name = list.Name
+	sl.Filter = cloneFilter(list.Filter)
+	sl.UpdatedAt = time.Now().UTC()
+	s.smartLists[list.ID] = sl
+
+	return nil
+}
+
+func (s *Storage) DeleteSmartList(ctx context.Context, listID int64, userID int64) error {
+	defer s.lock(ctx)()
+
+	if sl, ok := s.smartLists[listID]; !ok || sl.UserID != userID {
+		return models.ErrSmartListNotFound
+	}
+	delete(s.smartLists, listID)
+
+	return nil
+}
+
+// cloneFilter copies slices and pointers of the filter so the stored list is not changed by the caller
+func cloneFilter(f models.TaskFilter) models.TaskFilter {
+	f.WorkspaceID = copyInt64(f.WorkspaceID)
+	f.ProjectID = copyInt64(f.ProjectID)
+	f.Statuses = slices.Clone(f.Statuses)
+	f.Categories = slices.Clone(f.Categories)
+	f.Tags = slices.Clone(f.Tags)
+	f.DueFrom = copyTime(f.DueFrom)
+	f.DueTo = copyTime(f.DueTo)
+	f.Fields = slices.Clone(f.Fields)
+	return f
+}
@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"cmp"
+	"context"
+	"slices"
+)
+
+// logChange appends the change to the log used by sync, it stands for the triggers of the sql storages
+func (s *Storage) logChange(taskID int64, field string, userID int64) {
+	s.changes = append(s.changes, change{
+		seq:    s.nextID("task_changes"),
+		taskID: taskID,
+		field:  field,
+		userID: userID,
+	})
+}
+
+// SelectTaskChanges returns tasks changed after the version ordered by their latest change.
+// Only tasks the user may see are returned like in the sql storages
+func (s *Storage) SelectTaskChanges(ctx context.Context, userID int64, after int64, limit int) ([]models.TaskChange, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// tasks shared or unshared with the user after the version
+	accessed := make(map[int64]bool)
+	for _, c := range s.changes {
+		if c.seq > after && c.field == "access" && c.userID == userID {
+			accessed[c.taskID] = true
+		}
+	}
+
+	byTask := make(map[int64]*models.TaskChange)
+	for _, c := range s.changes {
+		if c.seq <= after || !(accessed[c.taskID] || s.taskVisible(c.taskID, userID)) {
+			continue
+		}
+		tc, ok := byTask[c.taskID]
+		if !ok {
+			tc = &models.TaskChange{TaskID: c.taskID}
+			byTask[c.taskID] = tc
+		}
+		tc.Version = max(tc.Version, c.seq)
+		switch c.field {
+		case "deleted":
+			tc.Deleted = true
+		case "access":
+			tc.AccessChanged = append(tc.AccessChanged, c.userID)
+		}
+	}
+
+	changes := make([]models.TaskChange, 0, len(byTask))
+	for _, tc := range byTask {
+		changes = append(changes, *tc)
+	}
+	slices.SortFunc(changes, func(a, b models.TaskChange) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	if len(changes) > limit {
+		changes = changes[:limit]
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	return changes, nil
+}
+
+// taskVisible reports whether the task or its tombstone is owned by, assigned or shared to the user
+// or is in a workspace of the user
+func (s *Storage) taskVisible(taskID int64, userID int64) bool {
+	task, ok := s.tasks[taskID]
+	if !ok {
+		task, ok = s.tombstones[taskID]
+	}
+	if ok {
+		if task.UserID == userID || (task.AssigneeID != nil && *task.AssigneeID == userID) {
+			return true
+		}
+		if ws := s.taskWorkspace(task); ws != nil {
+			if _, member := s.members[pairKey{*ws, userID}]; member {
+				return true
+			}
+		}
+	}
+	_, shared := s.shares[pairKey{taskID, userID}]
+	return shared
+}
+
+// SelectTaskVersions returns version of every field of the task,
+// fields which did not change since creation have the version of creation
+func (s *Storage) SelectTaskVersions(ctx context.Context, taskID int64) (models.TaskVersions, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var created int64
+	changed := make(map[string]int64)
+	for _, c := range s.changes {
+		if c.taskID != taskID {
+			continue
+		}
+		switch c.field {
+		case "access", "deleted":
+		case "created":
+			created = c.seq
+		default:
+			changed[c.field] = c.seq
+		}
+	}
+
+	versions := make(models.TaskVersions)
+	for _, f := range models.TaskFields() {
+		versions[f] = max(created, changed[string(f)])
+	}
+
+	return versions, nil
+}
+
+// SelectTombstone returns the deleted task with the owner, project and assignee it had
+func (s *Storage) SelectTombstone(ctx context.Context, taskID int64) (models.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, ok := s.tombstones[taskID]
+	if !ok {
+		return models.Task{}, models.ErrTaskNotFound
+	}
+	task.ProjectID = copyInt64(task.ProjectID)
+	task.AssigneeID = copyInt64(task.AssigneeID)
+	task.WorkspaceID = s.taskWorkspace(task)
+
+	return task, nil
+}
+
+// LastTaskChange returns the version of the latest change, 0 when there were no changes
+func (s *Storage) LastTaskChange(ctx context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.changes) == 0 {
+		return 0, nil
+	}
+
+	return s.changes[len(s.changes)-1].seq, nil
+}
+
+// SelectSyncClientTask returns the task created by the user from the client id
+func (s *Storage) SelectSyncClientTask(ctx context.Context, userID int64, clientID string) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	taskID, ok := s.syncClients[clientKey{userID, clientID}]
+	if !ok {
+		return 0, models.ErrTaskNotFound
+	}
+
+	return taskID, nil
+}
+
+// InsertSyncClientTask maps the client id to the task, ErrSyncClientExists if the id is mapped already
+func (s *Storage) InsertSyncClientTask(ctx context.Context, userID int64, clientID string, taskID int64) error {
+	defer s.lock(ctx)()
+
+	key := clientKey{userID, clientID}
+	if _, ok := s.syncClients[key]; ok {
+		return models.ErrSyncClientExists
+	}
+	s.syncClients[key] = taskID
+
+	return nil
+}
@@ -0,0 +1,321 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"time"
+)
+
+func (s *Storage) InsertTask(ctx context.Context, task models.Task) (int64, error) {
+	defer s.lock(ctx)()
+
+	status := task.Status
+	if status == "" {
+		status = models.Pending
+	}
+
+	createdBy := task.CreatedBy
+	if createdBy == 0 {
+		createdBy = task.UserID
+	}
+
+	now := time.Now().UTC()
+	id := s.nextID("tasks")
+	s.tasks[id] = models.Task{
+		ID:          id,
+		UserID:      task.UserID,
+		CreatedBy:   createdBy,
+		AssigneeID:  copyInt64(task.AssigneeID),
+		ProjectID:   copyInt64(task.ProjectID),
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      status,
+		Priority:    task.Priority,
+		Recurrence:  task.Recurrence,
+		DueAt:       copyTime(task.DueAt),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.logChange(id, "created", 0)
+
+	s.setTags(id, task.Tags)
+	s.upsertFieldValues(id, task.CustomFields)
+
+	return id, nil
+}
+
+func (s *Storage) UpdateStatusTask(ctx context.Context, taskID int64, userID int64, status models.Status) error {
+	defer s.lock(ctx)()
+
+	task, ok := s.tasks[taskID]
+	if !ok || task.UserID != userID {
+		return models.ErrTaskNotFound
+	}
+
+	if task.Status != status {
+		s.logChange(taskID, string(models.TaskFieldStatus), 0)
+	}
+	task.Status = status
+	task.UpdatedAt = time.Now().UTC()
+	s.tasks[taskID] = task
+
+	return nil
+}
+
+// UpdateTask stores title, description, priority, recurrence, due date and tags of the task
+func (s *Storage) UpdateTask(ctx context.Context, task models.Task) error {
+	defer s.lock(ctx)()
+
+	stored, ok := s.tasks[task.ID]
+	if !ok {
+		return models.ErrTaskNotFound
+	}
+
+	if stored.Title != task.Title {
+		s.logChange(task.ID, string(models.TaskFieldTitle), 0)
+	}
+	if stored.Description != task.Description {
+		s.logChange(task.ID, string(models.TaskFieldDescription), 0)
+	}
+	if stored.Priority != task.Priority {
+		s.logChange(task.ID, string(models.TaskFieldPriority), 0)
+	}
+	if stored.Recurrence != task.Recurrence {
+		s.logChange(task.ID, string(models.TaskFieldRecurrence), 0)
+	}
+	if !equalTime(stored.DueAt, task.DueAt) {
+		s.logChange(task.ID, string(models.TaskFieldDue), 0)
+	}
+
+	stored.Title = task.Title
+	stored.Description = task.Description
+	stored.Priority = task.Priority
+	stored.Recurrence = task.Recurrence
+	stored.DueAt = copyTime(task.DueAt)
+	stored.UpdatedAt = time.Now().UTC()
+	s.tasks[task.ID] = stored
+
+	s.setTags(task.ID, task.Tags)
+
+	return nil
+}
+
+// DeleteTask deletes the task with its tags, field values, shares and comments,
+// watchers are removed by notifications when they handle the deletion
+func (s *Storage) DeleteTask(ctx context.Context, taskID int64) error {
+	defer s.lock(ctx)()
+
+	task, ok := s.tasks[taskID]
+	if !ok {
+		return models.ErrTaskNotFound
+	}
+
+	delete(s.tasks, taskID)
+	s.logChange(taskID, "deleted", 0)
+	s.tombstones[taskID] = models.Task{
+		ID:         taskID,
+		UserID:     task.UserID,
+		ProjectID:  task.ProjectID,
+		AssigneeID: task.AssigneeID,
+	}
+
+	delete(s.tags, taskID)
+	delete(s.fieldValues, taskID)
+	for key := range s.shares {
+		if key.a == taskID {
+			delete(s.shares, key)
+			s.logChange(taskID, "access", key.b)
+		}
+	}
+	for id, c := range s.comments {
+		if c.TaskID == taskID {
+			delete(s.comments, id)
+		}
+	}
+
+	return nil
+}
+
+// CountTasksByStatus counts tasks in the board column of the project, nil project means tasks without project
+func (s *Storage) CountTasksByStatus(ctx context.Context, userID int64, projectID *int64, status models.Status) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int
+	for _, task := range s.tasks {
+		if task.UserID == userID && task.Status == status && equalInt64(task.ProjectID, projectID) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (s *Storage) SelectAllTasksByUserID(ctx context.Context, userID int64) ([]models.Task, error) {
+	return s.selectTasks(func(t models.Task) bool {
+		return t.UserID == userID
+	}), nil
+}
+
+// SelectTaskByID selects task regardless of the owner, access is checked by the caller
+func (s *Storage) SelectTaskByID(ctx context.Context, taskID int64) (models.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, ok := s.tasks[taskID]
+	if !ok {
+		return models.Task{}, models.ErrTaskNotFound
+	}
+
+	return s.taskModel(task), nil
+}
+
+func (s *Storage) SelectTasksAssignedTo(ctx context.Context, userID int64) ([]models.Task, error) {
+	return s.selectTasks(func(t models.Task) bool {
+		return t.AssigneeID != nil && *t.AssigneeID == userID
+	}), nil
+}
+
+func (s *Storage) SelectTasksSharedWith(ctx context.Context, userID int64) ([]models.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterTasks(func(t models.Task) bool {
+		_, ok := s.shares[pairKey{t.ID, userID}]
+		return ok
+	}), nil
+}
+
+func (s *Storage) SelectTasksByWorkspace(ctx context.Context, workspaceID int64) ([]models.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterTasks(func(t models.Task) bool {
+		ws := s.taskWorkspace(t)
+		return ws != nil && *ws == workspaceID
+	}), nil
+}
+
+// UpdateTaskAssignee sets assignee of the task, nil removes assignee
+func (s *Storage) UpdateTaskAssignee(ctx context.Context, taskID int64, assigneeID *int64) error {
+	defer s.lock(ctx)()
+
+	if _, ok := s.tasks[taskID]; !ok {
+		return models.ErrTaskNotFound
+	}
+	s.setAssignee(taskID, assigneeID)
+
+	return nil
+}
+
+func (s *Storage) setAssignee(taskID int64, assigneeID *int64) {
+	task := s.tasks[taskID]
+	if !equalInt64(task.AssigneeID, assigneeID) {
+		s.logChange(taskID, string(models.TaskFieldAssignee), 0)
+	}
+	task.AssigneeID = copyInt64(assigneeID)
+	task.UpdatedAt = time.Now().UTC()
+	s.tasks[taskID] = task
+}
+
+func (s *Storage) selectTasks(match func(t models.Task) bool) []models.Task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterTasks(match)
+}
+
+// filterTasks returns matching tasks with tags and custom fields, the caller holds the lock
+func (s *Storage) filterTasks(match func(t models.Task) bool) []models.Task {
+	var tasks []models.Task
+	for _, id := range sortedKeys(s.tasks) {
+		if task := s.tasks[id]; match(task) {
+			tasks = append(tasks, s.taskModel(task))
+		}
+	}
+	return tasks
+}
+
+// taskModel resolves workspace, status category, tags and custom fields of the stored task
+func (s *Storage) taskModel(task models.Task) models.Task {
+	task.AssigneeID = copyInt64(task.AssigneeID)
+	task.ProjectID = copyInt64(task.ProjectID)
+	task.WorkspaceID = s.taskWorkspace(task)
+	task.StatusCategory = s.statusCategory(task)
+	task.DueAt = copyTime(task.DueAt)
+	task.Tags = s.taskTags(task.ID)
+	task.CustomFields = s.taskFieldValues(task.ID)
+	return task
+}
+
+func (s *Storage) taskWorkspace(task models.Task) *int64 {
+	if task.ProjectID == nil {
+		return nil
+	}
+	return copyInt64(s.projects[*task.ProjectID].WorkspaceID)
+}
+
+// statusCategory returns category found in user workflow, status defined for the project has priority
+// over the status defined for all user projects, statuses of the default workflow are resolved without stored workflow
+func (s *Storage) statusCategory(task models.Task) models.StatusCategory {
+	var (
+		category models.StatusCategory
+		found    bool
+	)
+	for _, ws := range s.statuses {
+		if ws.UserID != task.UserID || ws.Name != task.Status {
+			continue
+		}
+		if ws.ProjectID != nil && equalInt64(ws.ProjectID, task.ProjectID) {
+			return ws.Category
+		}
+		if ws.ProjectID == nil {
+			category, found = ws.Category, true
+		}
+	}
+	if found {
+		return category
+	}
+
+	for _, ws := range models.DefaultWorkflow() {
+		if ws.Name == task.Status {
+			return ws.Category
+		}
+	}
+
+	return models.CategoryTodo
+}
+
+// setTags replaces tags of the task, only an actual difference gets into the change log
+func (s *Storage) setTags(taskID int64, tags []string) {
+	stored := s.tags[taskID]
+	next := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		next[tag] = struct{}{}
+	}
+
+	changed := len(stored) != len(next)
+	for tag := range next {
+		if _, ok := stored[tag]; !ok {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	if len(next) == 0 {
+		delete(s.tags, taskID)
+	} else {
+		s.tags[taskID] = next
+	}
+	s.logChange(taskID, string(models.TaskFieldTags), 0)
+}
+
+func (s *Storage) taskTags(taskID int64) []string {
+	tags := s.tags[taskID]
+	if len(tags) == 0 {
+		return nil
+	}
+	return sortedKeys(tags)
+}
@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"slices"
+	"time"
+)
+
+func (s *Storage) CreateUser(ctx context.Context, email string, passHash []byte) (int64, error) {
+	defer s.lock(ctx)()
+
+	if _, ok := s.userByEmail(email); ok {
+		return 0, models.ErrUserAlreadyExists
+	}
+
+	id := s.nextID("users")
+	s.users[id] = models.User{
+		ID:           id,
+		Email:        email,
+		PasswordHash: slices.Clone(passHash),
+		Timezone:     "UTC",
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	return id, nil
+}
+
+func (s *Storage) UserByEmail(ctx context.Context, email string) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.userByEmail(email)
+	if !ok {
+		return nil, models.ErrUserNotFound
+	}
+
+	return copyUser(user), nil
+}
+
+func (s *Storage) UserByFeedToken(ctx context.Context, token string) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, id := range sortedKeys(s.users) {
+		if user := s.users[id]; user.FeedToken != "" && user.FeedToken == token {
+			return copyUser(user), nil
+		}
+	}
+
+	return nil, models.ErrUserNotFound
+}
+
+func (s *Storage) UserByID(ctx context.Context, userID int64) (*models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return nil, models.ErrUserNotFound
+	}
+
+	return copyUser(user), nil
+}
+
+// SelectUsersByIDs returns users with the ids, unknown ids are skipped
+func (s *Storage) SelectUsersByIDs(ctx context.Context, ids []int64) ([]models.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var users []models.User
+	for _, id := range sortedKeys(s.users) {
+		if slices.Contains(ids, id) {
+			users = append(users, *copyUser(s.users[id]))
+		}
+	}
+
+	return users, nil
+}
+
+func (s *Storage) UpdateTimezone(ctx context.Context, userID int64, timezone string) error {
+	return s.updateUser(ctx, userID, func(u *models.User) {
+		u.Timezone = timezone
+	})
+}
+
+func (s *Storage) UpdateFeedToken(ctx context.Context, userID int64, token string) error {
+	return s.updateUser(ctx, userID, func(u *models.User) {
+		u.FeedToken = token
+	})
+}
+
+func (s *Storage) UpdatePasswordHash(ctx context.Context, userID int64, passHash []byte) error {
+	return s.updateUser(ctx, userID, func(u *models.User) {
+		u.PasswordHash = slices.Clone(passHash)
+	})
+}
+
+// SetUserDisabled disables the user at the time, nil enables the user
+func (s *Storage) SetUserDisabled(ctx context.Context, userID int64, disabledAt *time.Time) error {
+	return s.updateUser(ctx, userID, func(u *models.User) {
+		u.DisabledAt = copyTime(disabledAt)
+	})
+}
+
+func (s *Storage) updateUser(ctx context.Context, userID int64, update func(u *models.User)) error {
+	defer s.lock(ctx)()
+
+	user, ok := s.users[userID]
+	if !ok {
+		return models.ErrUserNotFound
+	}
+	update(&user)
+	s.users[userID] = user
+
+	return nil
+}
+
+func (s *Storage) userByEmail(email string) (models.User, bool) {
+	for _, user := range s.users {
+		if user.Email == email {
+			return user, true
+		}
+	}
+	return models.User{}, false
+}
+
+func copyUser(u models.User) *models.User {
+	u.PasswordHash = slices.Clone(u.PasswordHash)
+	u.DisabledAt = copyTime(u.DisabledAt)
+	return &u
+}
@@ -0,0 +1,232 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+func (s *Storage) InsertWebhook(ctx context.Context, hook models.Webhook) (int64, error) {
+	defer s.lock(ctx)()
+
+	now := time.Now().UTC()
+	id := s.nextID("webhooks")
+	s.webhooks[id] = models.Webhook{
+		ID:        id,
+		UserID:    hook.UserID,
+		URL:       hook.URL,
+		Secret:    hook.Secret,
+		Events:    slices.Clone(hook.Events),
+		Active:    hook.Active,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return id, nil
+}
+
+func (s *Storage) SelectWebhooks(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	return s.selectWebhooks(func(hook models.Webhook) bool {
+		return hook.UserID == userID
+	}), nil
+}
+
+// SelectWebhooksByEvent returns active webhooks of all users subscribed to the event
+func (s *Storage) SelectWebhooksByEvent(ctx context.Context, event models.EventType) ([]models.Webhook, error) {
+	return s.selectWebhooks(func(hook models.Webhook) bool {
+		return hook.Active && slices.Contains(hook.Events, event)
+	}), nil
+}
+
+func (s *Storage) selectWebhooks(match func(hook models.Webhook) bool) []models.Webhook {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var hooks []models.Webhook
+	for _, id := range sortedKeys(s.webhooks) {
+		if hook := s.webhooks[id]; match(hook) {
+			hook.Events = slices.Clone(hook.Events)
+			hooks = append(hooks, hook)
+		}
+	}
+	return hooks
+}
+
+func (s *Storage) SelectWebhookByID(ctx context.Context, id int64, userID int64) (models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hook, ok := s.webhooks[id]
+	if !ok || hook.UserID != userID {
+		return models.Webhook{}, models.ErrWebhookNotFound
+	}
+	hook.Events = slices.Clone(hook.Events)
+
+	return hook, nil
+}
+
+// SelectWebhook returns the webhook of any user, used for delivery
+func (s *Storage) SelectWebhook(ctx context.Context, id int64) (models.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hook, ok := s.webhooks[id]
+	if !ok {
+		return models.Webhook{}, models.ErrWebhookNotFound
+	}
+	hook.Events = slices.Clone(hook.Events)
+
+	return hook, nil
+}
+
+func (s *Storage) UpdateWebhook(ctx context.Context, hook models.Webhook) error {
+	defer s.lock(ctx)()
+
+	stored, ok := s.webhooks[hook.ID]
+	if !ok || stored.UserID != hook.UserID {
+		return models.ErrWebhookNotFound
+	}
+	stored.URL = hook.URL
+	stored.Events = slices.Clone(hook.Events)
+	stored.Active = hook.Active
+	stored.UpdatedAt = time.Now().UTC()
+	s.webhooks[hook.ID] = stored
+
+	return nil
+}
+
+// DeleteWebhook deletes the webhook with its delivery log
+func (s *Storage) DeleteWebhook(ctx context.Context, id int64, userID int64) error {
+	defer s.lock(ctx)()
+
+	if hook, ok := s.webhooks[id]; !ok || hook.UserID != userID {
+		return models.ErrWebhookNotFound
+	}
+	delete(s.webhooks, id)
+
+	for deliveryID, d := range s.deliveries {
+		if d.WebhookID == id {
+			delete(s.deliveries, deliveryID)
+		}
+	}
+
+	return nil
+}
+
+func (s *Storage) InsertDelivery(ctx context.Context, d models.WebhookDelivery) (int64, error) {
+	defer s.lock(ctx)()
+
+	id := s.nextID("webhook_deliveries")
+	s.deliveries[id] = models.WebhookDelivery{
+		ID:            id,
+		WebhookID:     d.WebhookID,
+		Event:         d.Event,
+		Payload:       slices.Clone(d.Payload),
+		Status:        d.Status,
+		RedeliveryOf:  copyInt64(d.RedeliveryOf),
+		NextAttemptAt: copyTime(d.NextAttemptAt),
+		CreatedAt:     d.CreatedAt,
+	}
+
+	return id, nil
+}
+
+// SelectDeliveries returns the newest deliveries of the webhook first
+func (s *Storage) SelectDeliveries(ctx context.Context, webhookID int64, limit int) ([]models.WebhookDelivery, error) {
+	deliveries := s.selectDeliveries(
+		func(d models.WebhookDelivery) bool {
+			return d.WebhookID == webhookID
+		},
+		func(a, b models.WebhookDelivery) int {
+			return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+		},
+		limit,
+	)
+
+	return deliveries, nil
+}
+
+// SelectDueDeliveries returns pending deliveries whose next attempt is due, the oldest first
+func (s *Storage) SelectDueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	deliveries := s.selectDeliveries(
+		func(d models.WebhookDelivery) bool {
+			return d.Status == models.DeliveryPending && d.NextAttemptAt != nil && !d.NextAttemptAt.After(now)
+		},
+		func(a, b models.WebhookDelivery) int {
+			return cmp.Or(a.NextAttemptAt.Compare(*b.NextAttemptAt), cmp.Compare(a.ID, b.ID))
+		},
+		limit,
+	)
+
+	return deliveries, nil
+}
+
+func (s *Storage) selectDeliveries(
+	match func(d models.WebhookDelivery) bool,
+	compare func(a, b models.WebhookDelivery) int,
+	limit int,
+) []models.WebhookDelivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var deliveries []models.WebhookDelivery
+	for _, d := range s.deliveries {
+		if match(d) {
+			deliveries = append(deliveries, copyDelivery(d))
+		}
+	}
+	slices.SortFunc(deliveries, compare)
+	if limit >= 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries
+}
+
+func (s *Storage) SelectDelivery(ctx context.Context, id int64, webhookID int64) (models.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	d, ok := s.deliveries[id]
+	if !ok || d.WebhookID != webhookID {
+		return models.WebhookDelivery{}, models.ErrDeliveryNotFound
+	}
+
+	return copyDelivery(d), nil
+}
+
+// UpdateDeliveryAttempt stores the result of the delivery attempt
+func (s *Storage) UpdateDeliveryAttempt(ctx context.Context, d models.WebhookDelivery) error {
+	defer s.lock(ctx)()
+
+	stored, ok := s.deliveries[d.ID]
+	if !ok {
+		return models.ErrDeliveryNotFound
+	}
+	stored.Status = d.Status
+	stored.Attempts = d.Attempts
+	stored.ResponseCode = nil
+	if d.ResponseCode != nil {
+		code := *d.ResponseCode
+		stored.ResponseCode = &code
+	}
+	stored.Error = d.Error
+	stored.NextAttemptAt = copyTime(d.NextAttemptAt)
+	stored.LastAttemptAt = copyTime(d.LastAttemptAt)
+	s.deliveries[d.ID] = stored
+
+	return nil
+}
+
+func copyDelivery(d models.WebhookDelivery) models.WebhookDelivery {
+	d.Payload = slices.Clone(d.Payload)
+	if d.ResponseCode != nil {
+		code := *d.ResponseCode
+		d.ResponseCode = &code
+	}
+	d.RedeliveryOf = copyInt64(d.RedeliveryOf)
+	d.NextAttemptAt = copyTime(d.NextAttemptAt)
+	d.LastAttemptAt = copyTime(d.LastAttemptAt)
+	return d
+}
@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+func (s *Storage) InsertWorkflowStatus(ctx context.Context, ws models.WorkflowStatus) (int64, error) {
+	defer s.lock(ctx)()
+
+	if s.statusExists(ws.UserID, ws.ProjectID, ws.Name, 0) {
+		return 0, models.ErrStatusAlreadyExists
+	}
+	s.shiftPositions(ws.UserID, ws.ProjectID, ws.Position, 0)
+
+	ws.ID = s.nextID("workflow_statuses")
+	ws.ProjectID = copyInt64(ws.ProjectID)
+	ws.CreatedAt = time.Now().UTC()
+	s.statuses[ws.ID] = ws
+
+	return ws.ID, nil
+}
+
+// statusExists reports whether the scope already has the status name, the status exceptID is not counted
+func (s *Storage) statusExists(userID int64, projectID *int64, name models.Status, exceptID int64) bool {
+	for id, ws := range s.statuses {
+		if id != exceptID && ws.UserID == userID && equalInt64(ws.ProjectID, projectID) && ws.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// shiftPositions moves columns starting from the position one step right to free the place
+func (s *Storage) shiftPositions(userID int64, projectID *int64, position int, exceptID int64) {
+	inScope := func(id int64, ws models.WorkflowStatus) bool {
+		return id != exceptID && ws.UserID == userID && equalInt64(ws.ProjectID, projectID)
+	}
+
+	taken := false
+	for id, ws := range s.statuses {
+		if inScope(id, ws) && ws.Position == position {
+			taken = true
+			break
+		}
+	}
+	if !taken {
+		return
+	}
+
+	for id, ws := range s.statuses {
+		if inScope(id, ws) && ws.Position >= position {
+			ws.Position++
+			s.statuses[id] = ws
+		}
+	}
+}
+
+// SelectWorkflowStatuses returns statuses defined exactly for the scope, nil project means statuses for all projects
+func (s *Storage) SelectWorkflowStatuses(ctx context.Context, userID int64, projectID *int64) ([]models.WorkflowStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var statuses []models.WorkflowStatus
+	for _, ws := range s.statuses {
+		if ws.UserID == userID && equalInt64(ws.ProjectID, projectID) {
+			ws.ProjectID = copyInt64(ws.ProjectID)
+			statuses = append(statuses, ws)
+		}
+	}
+	slices.SortFunc(statuses, func(a, b models.WorkflowStatus) int {
+		return cmp.Or(cmp.Compare(a.Position, b.Position), cmp.Compare(a.ID, b.ID))
+	})
+
+	return statuses, nil
+}
+
+func (s *Storage) SelectWorkflowStatusByID(ctx context.Context, statusID int64, userID int64) (models.WorkflowStatus, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ws, ok := s.statuses[statusID]
+	if !ok || ws.UserID != userID {
+		return models.WorkflowStatus{}, models.ErrStatusNotFound
+	}
+	ws.ProjectID = copyInt64(ws.ProjectID)
+
+	return ws, nil
+}
+
+// UpdateWorkflowStatus updates the status, tasks are moved to the new name at the same time
+func (s *Storage) UpdateWorkflowStatus(ctx context.Context, ws models.WorkflowStatus, oldName models.Status) error {
+	defer s.lock(ctx)()
+
+	stored, ok := s.statuses[ws.ID]
+	if !ok || stored.UserID != ws.UserID {
+		return models.ErrStatusNotFound
+	}
+	if s.statusExists(stored.UserID, stored.ProjectID, ws.Name, ws.ID) {
+		return models.ErrStatusAlreadyExists
+	}
+	s.shiftPositions(ws.UserID, ws.ProjectID, ws.Position, ws.ID)
+
+	stored.Name = ws.Name
+	stored.Category = ws.Category
+	stored.Position = ws.Position
+	stored.WIPLimit = ws.WIPLimit
+	s.statuses[ws.ID] = stored
+
+	if oldName != ws.Name {
+		s.renameTasksStatus(ws.UserID, ws.ProjectID, oldName, ws.Name)
+	}
+
+	return nil
+}
+
+// DeleteWorkflowStatus deletes the status and moves its tasks to the fallback status
+func (s *Storage) DeleteWorkflowStatus(ctx context.Context, ws models.WorkflowStatus, fallback models.Status) error {
+	defer s.lock(ctx)()
+
+	if stored, ok := s.statuses[ws.ID]; !ok || stored.UserID != ws.UserID {
+		return models.ErrStatusNotFound
+	}
+	delete(s.statuses, ws.ID)
+
+	s.renameTasksStatus(ws.UserID, ws.ProjectID, ws.Name, fallback)
+
+	return nil
+}
+
+// renameTasksStatus changes status of tasks in the scope, nil project affects tasks of all projects
+// which do not define own statuses
+func (s *Storage) renameTasksStatus(userID int64, projectID *int64, from, to models.Status) {
+	ownWorkflow := make(map[int64]bool)
+	for _, ws := range s.statuses {
+		if ws.ProjectID != nil {
+			ownWorkflow[*ws.ProjectID] = true
+		}
+	}
+
+	now := time.Now().UTC()
+	for _, id := range sortedKeys(s.tasks) {
+		task := s.tasks[id]
+		if task.UserID != userID || task.Status != from {
+			continue
+		}
+		inScope := equalInt64(task.ProjectID, projectID) ||
+			(projectID == nil && (task.ProjectID == nil || !ownWorkflow[*task.ProjectID]))
+		if !inScope {
+			continue
+		}
+
+		if from != to {
+			s.logChange(id, string(models.TaskFieldStatus), 0)
+		}
+		task.Status = to
+		task.UpdatedAt = now
+		s.tasks[id] = task
+	}
+}
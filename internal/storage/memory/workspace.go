@@ -0,0 +1,273 @@
+package memory
+
+import (
+	"TaskList/internal/models"
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+// InsertWorkspace creates workspace with its owner as the first member
+func (s *Storage) InsertWorkspace(ctx context.Context, ws models.Workspace) (int64, error) {
+	defer s.lock(ctx)()
+
+	now := time.Now().UTC()
+	id := s.nextID("workspaces")
+	s.workspaces[id] = models.Workspace{
+		ID:        id,
+		OwnerID:   ws.OwnerID,
+		Name:      ws.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.members[pairKey{id, ws.OwnerID}] = member{role: models.RoleOwner, createdAt: now}
+
+	return id, nil
+}
+
+// SelectWorkspacesByUserID selects workspaces the user is member of with the user role
+func (s *Storage) SelectWorkspacesByUserID(ctx context.Context, userID int64) ([]models.Workspace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var workspaces []models.Workspace
+	for _, id := range sortedKeys(s.workspaces) {
+		if m, ok := s.members[pairKey{id, userID}]; ok {
+			ws := s.workspaces[id]
+			ws.Role = m.role
+			workspaces = append(workspaces, ws)
+		}
+	}
+	slices.SortStableFunc(workspaces, func(a, b models.Workspace) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return workspaces, nil
+}
+
+func (s *Storage) SelectWorkspaceByID(ctx context.Context, workspaceID int64, userID int64) (models.Workspace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ws, ok := s.workspaces[workspaceID]
+	m, found := s.members[pairKey{workspaceID, userID}]
+	if !ok || !found {
+		return models.Workspace{}, models.ErrWorkspaceNotFound
+	}
+	ws.Role = m.role
+
+	return ws, nil
+}
+
+func (s *Storage) UpdateWorkspace(ctx context.Context, ws models.Workspace) error {
+	defer s.lock(ctx)()
+
+	stored, ok := s.workspaces[ws.ID]
+	if !ok {
+		return models.ErrWorkspaceNotFound
+	}
+	stored.Name = ws.Name
+	stored.UpdatedAt = time.Now().UTC()
+	s.workspaces[ws.ID] = stored
+
+	return nil
+}
+
+// DeleteWorkspace deletes workspace with members and invitations,
+// its projects stay with the owner as personal projects
+func (s *Storage) DeleteWorkspace(ctx context.Context, workspaceID int64) error {
+	defer s.lock(ctx)()
+
+	if _, ok := s.workspaces[workspaceID]; !ok {
+		return models.ErrWorkspaceNotFound
+	}
+	delete(s.workspaces, workspaceID)
+
+	for id, p := range s.projects {
+		if p.WorkspaceID != nil && *p.WorkspaceID == workspaceID {
+			p.WorkspaceID = nil
+			s.projects[id] = p
+		}
+	}
+	for key := range s.members {
+		if key.a == workspaceID {
+			delete(s.members, key)
+		}
+	}
+	for id, inv := range s.invitations {
+		if inv.WorkspaceID == workspaceID {
+			delete(s.invitations, id)
+		}
+	}
+
+	return nil
+}
+
+func (s *Storage) SelectWorkspaceMembers(ctx context.Context, workspaceID int64) ([]models.WorkspaceMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var members []models.WorkspaceMember
+	for key, m := range s.members {
+		user, ok := s.users[key.b]
+		if key.a != workspaceID || !ok {
+			continue
+		}
+		members = append(members, models.WorkspaceMember{
+			WorkspaceID: key.a,
+			UserID:      key.b,
+			Email:       user.Email,
+			Role:        m.role,
+			CreatedAt:   m.createdAt,
+		})
+	}
+	slices.SortFunc(members, func(a, b models.WorkspaceMember) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.UserID, b.UserID))
+	})
+
+	return members, nil
+}
+
+func (s *Storage) SelectWorkspaceMember(ctx context.Context, workspaceID int64, userID int64) (models.WorkspaceMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m, ok := s.members[pairKey{workspaceID, userID}]
+	user, found := s.users[userID]
+	if !ok || !found {
+		return models.WorkspaceMember{}, models.ErrMemberNotFound
+	}
+
+	return models.WorkspaceMember{
+		WorkspaceID: workspaceID,
+		UserID:      userID,
+		Email:       user.Email,
+		Role:        m.role,
+		CreatedAt:   m.createdAt,
+	}, nil
+}
+
+func (s *Storage) UpdateWorkspaceMemberRole(ctx context.Context, workspaceID int64, userID int64, role models.Role) error {
+	defer s.lock(ctx)()
+
+	key := pairKey{workspaceID, userID}
+	m, ok := s.members[key]
+	if !ok {
+		return models.ErrMemberNotFound
+	}
+	m.role = role
+	s.members[key] = m
+
+	return nil
+}
+
+// DeleteWorkspaceMember removes member, the user stops being assignee of workspace tasks not shared with them
+func (s *Storage) DeleteWorkspaceMember(ctx context.Context, workspaceID int64, userID int64) error {
+	defer s.lock(ctx)()
+
+	key := pairKey{workspaceID, userID}
+	if _, ok := s.members[key]; !ok {
+		return models.ErrMemberNotFound
+	}
+	delete(s.members, key)
+
+	for _, id := range sortedKeys(s.tasks) {
+		task := s.tasks[id]
+		if task.AssigneeID == nil || *task.AssigneeID != userID {
+			continue
+		}
+		if ws := s.taskWorkspace(task); ws == nil || *ws != workspaceID {
+			continue
+		}
+		if _, shared := s.shares[pairKey{id, userID}]; shared {
+			continue
+		}
+		s.setAssignee(id, nil)
+	}
+
+	return nil
+}
+
+func (s *Storage) InsertInvitation(ctx context.Context, inv models.Invitation) (int64, error) {
+	defer s.lock(ctx)()
+
+	id := s.nextID("workspace_invitations")
+	s.invitations[id] = models.Invitation{
+		ID:          id,
+		WorkspaceID: inv.WorkspaceID,
+		Email:       inv.Email,
+		Role:        inv.Role,
+		Token:       inv.Token,
+		InvitedBy:   inv.InvitedBy,
+		CreatedAt:   time.Now().UTC(),
+		ExpiresAt:   inv.ExpiresAt.UTC(),
+	}
+
+	return id, nil
+}
+
+// SelectInvitations selects pending invitations of the workspace
+func (s *Storage) SelectInvitations(ctx context.Context, workspaceID int64) ([]models.Invitation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var invitations []models.Invitation
+	for _, inv := range s.invitations {
+		if inv.WorkspaceID == workspaceID && inv.AcceptedAt == nil {
+			invitations = append(invitations, inv)
+		}
+	}
+	slices.SortFunc(invitations, func(a, b models.Invitation) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return invitations, nil
+}
+
+func (s *Storage) SelectInvitationByToken(ctx context.Context, token string) (models.Invitation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, inv := range s.invitations {
+		if inv.Token == token {
+			inv.AcceptedAt = copyTime(inv.AcceptedAt)
+			return inv, nil
+		}
+	}
+
+	return models.Invitation{}, models.ErrInvitationNotFound
+}
+
+// AcceptInvitation marks invitation accepted and adds the user to the workspace
+func (s *Storage) AcceptInvitation(ctx context.Context, inv models.Invitation, userID int64) error {
+	defer s.lock(ctx)()
+
+	stored, ok := s.invitations[inv.ID]
+	if !ok || stored.AcceptedAt != nil {
+		return models.ErrInvitationNotFound
+	}
+	key := pairKey{inv.WorkspaceID, userID}
+	if _, ok = s.members[key]; ok {
+		return models.ErrAlreadyMember
+	}
+
+	now := time.Now().UTC()
+	stored.AcceptedAt = &now
+	s.invitations[inv.ID] = stored
+	s.members[key] = member{role: inv.Role, createdAt: now}
+
+	return nil
+}
+
+func (s *Storage) DeleteInvitation(ctx context.Context, workspaceID int64, invitationID int64) error {
+	defer s.lock(ctx)()
+
+	inv, ok := s.invitations[invitationID]
+	if !ok || inv.WorkspaceID != workspaceID || inv.AcceptedAt != nil {
+		return models.ErrInvitationNotFound
+	}
+	delete(s.invitations, invitationID)
+
+	return nil
+}
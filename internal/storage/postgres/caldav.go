@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"fmt"
+)
+
+// SelectCalendarObjectNames returns names chosen by CalDAV clients for the tasks which have them
+func (s Storage) SelectCalendarObjectNames(ctx context.Context, taskIDs []int64) (map[int64]models.CalendarObjectName, error) {
+	const op = "storage.postgres.SelectCalendarObjectNames"
+
+	res := make(map[int64]models.CalendarObjectName, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return res, nil
+	}
+
+	query := `SELECT task_id, name, uid FROM caldav_objects WHERE task_id IN ` + placeholders(1, len(taskIDs))
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, int64Args(taskIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed select object names %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		var n models.CalendarObjectName
+		if err = rows.Scan(&n.TaskID, &n.Name, &n.UID); err != nil {
+			return nil, fmt.Errorf("failed scan object name %s:%w", op, err)
+		}
+		res[n.TaskID] = n
+	}
+
+	return res, rows.Err()
+}
+
+func (s Storage) UpsertCalendarObjectName(ctx context.Context, n models.CalendarObjectName) error {
+	const op = "storage.postgres.UpsertCalendarObjectName"
+
+	query := `INSERT INTO caldav_objects (task_id, name, uid) VALUES ($1, $2, $3)
+		ON CONFLICT (task_id) DO UPDATE SET name = excluded.name, uid = excluded.uid`
+
+	if _, err := s.conn(ctx).ExecContext(ctx, query, n.TaskID, n.Name, n.UID); err != nil {
+		return fmt.Errorf("failed upsert object name %s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s Storage) DeleteCalendarObjectName(ctx context.Context, taskID int64) error {
+	const op = "storage.postgres.DeleteCalendarObjectName"
+
+	if _, err := s.conn(ctx).ExecContext(ctx, `DELETE FROM caldav_objects WHERE task_id = $1`, taskID); err != nil {
+		return fmt.Errorf("failed delete object name %s:%w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"fmt"
+)
+
+const commentSelect = `SELECT c.id, c.task_id, c.user_id, u.email, c.body, c.created_at
+	FROM task_comments c
+	JOIN users u ON u.id = c.user_id`
+
+func (s Storage) InsertComment(ctx context.Context, comment models.Comment) (int64, error) {
+	const op = "storage.postgres.InsertComment"
+	var id int64
+
+	query := `INSERT INTO task_comments (task_id, user_id, body, created_at) VALUES ($1, $2, $3, $4) RETURNING id`
+
+	err := s.conn(ctx).QueryRowContext(ctx, query, comment.TaskID, comment.UserID, comment.Body, comment.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed insert comment %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s Storage) SelectComments(ctx context.Context, taskID int64) ([]models.Comment, error) {
+	const op = "storage.postgres.SelectComments"
+
+	comments, err := s.selectComments(ctx, `c.task_id = $1`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select comments %s:%w", op, err)
+	}
+
+	return comments, nil
+}
+
+// SelectCommentsByTaskIDs returns comments of all the tasks in one query
+func (s Storage) SelectCommentsByTaskIDs(ctx context.Context, taskIDs []int64) ([]models.Comment, error) {
+	const op = "storage.postgres.SelectCommentsByTaskIDs"
+
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+
+	comments, err := s.selectComments(ctx, `c.task_id IN `+placeholders(1, len(taskIDs)), int64Args(taskIDs)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed select comments %s:%w", op, err)
+	}
+
+	return comments, nil
+}
+
+func (s Storage) selectComments(ctx context.Context, where string, args ...any) ([]models.Comment, error) {
+	rows, err := s.conn(ctx).QueryContext(ctx, commentSelect+` WHERE `+where+` ORDER BY c.created_at, c.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var comments []models.Comment
+	for rows.Next() {
+		var c models.Comment
+		if err = rows.Scan(&c.ID, &c.TaskID, &c.UserID, &c.Email, &c.Body, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+
+	return comments, rows.Err()
+}
@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"TaskList/internal/lib/txmanager"
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type CustomField struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	ProjectID int64     `db:"project_id"`
+	Name      string    `db:"name"`
+	Type      string    `db:"type"`
+	Options   string    `db:"options"`
+	Position  int       `db:"position"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+const customFieldColumns = `id, user_id, project_id, name, type, options, position, created_at`
+
+func scanCustomField(row scanner) (models.CustomField, error) {
+	var f CustomField
+	err := row.Scan(&f.ID, &f.UserID, &f.ProjectID, &f.Name, &f.Type, &f.Options, &f.Position, &f.CreatedAt)
+	if err != nil {
+		return models.CustomField{}, err
+	}
+
+	var options []string
+	if err = json.Unmarshal([]byte(f.Options), &options); err != nil {
+		return models.CustomField{}, err
+	}
+
+	return models.CustomField{
+		ID:        f.ID,
+		UserID:    f.UserID,
+		ProjectID: f.ProjectID,
+		Name:      f.Name,
+		Type:      models.FieldType(f.Type),
+		Options:   options,
+		Position:  f.Position,
+		CreatedAt: f.CreatedAt,
+	}, nil
+}
+
+func (s Storage) InsertCustomField(ctx context.Context, field models.CustomField) (int64, error) {
+	const op = "storage.postgres.InsertCustomField"
+	var id int64
+
+	options := field.Options
+	if options == nil {
+		options = []string{}
+	}
+	opts, err := json.Marshal(options)
+	if err != nil {
+		return 0, fmt.Errorf("failed marshal options %s:%w", op, err)
+	}
+
+	query := `INSERT INTO custom_fields (user_id, project_id, name, type, options, position, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+
+	err = s.conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		field.UserID,
+		field.ProjectID,
+		field.Name,
+		string(field.Type),
+		string(opts),
+		field.Position,
+		time.Now().UTC(),
+	).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, models.ErrFieldAlreadyExists
+		}
+		return 0, fmt.Errorf("failed insert field %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s Storage) SelectCustomFields(ctx context.Context, projectID int64, userID int64) ([]models.CustomField, error) {
+	const op = "storage.postgres.SelectCustomFields"
+
+	query := `SELECT ` + customFieldColumns + ` FROM custom_fields
+		WHERE project_id = $1 AND user_id = $2
+		ORDER BY position, id`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, projectID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select fields %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var fields []models.CustomField
+	for rows.Next() {
+		f, err := scanCustomField(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan field %s:%w", op, err)
+		}
+		fields = append(fields, f)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select fields %s:%w", op, err)
+	}
+
+	return fields, nil
+}
+
+func (s Storage) SelectCustomFieldByID(ctx context.Context, fieldID int64, userID int64) (models.CustomField, error) {
+	const op = "storage.postgres.SelectCustomFieldByID"
+
+	query := `SELECT ` + customFieldColumns + ` FROM custom_fields WHERE id = $1 AND user_id = $2`
+
+	f, err := scanCustomField(s.conn(ctx).QueryRowContext(ctx, query, fieldID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.CustomField{}, models.ErrFieldNotFound
+		}
+		return models.CustomField{}, fmt.Errorf("failed select field %s:%w", op, err)
+	}
+
+	return f, nil
+}
+
+// DeleteCustomField deletes the field, its values are deleted by the foreign key
+func (s Storage) DeleteCustomField(ctx context.Context, fieldID int64, userID int64) error {
+	const op = "storage.postgres.DeleteCustomField"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `DELETE FROM custom_fields WHERE id = $1 AND user_id = $2`, fieldID, userID)
+	if err != nil {
+		return fmt.Errorf("failed delete field %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrFieldNotFound, op)
+}
+
+// SetTaskFieldValues upserts values and deletes values of the fields from clear
+func (s Storage) SetTaskFieldValues(ctx context.Context, taskID int64, values []models.FieldValue, clear []int64) error {
+	const op = "storage.postgres.SetTaskFieldValues"
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err = upsertFieldValues(ctx, tx, taskID, values); err != nil {
+		return fmt.Errorf("failed set values %s:%w", op, err)
+	}
+
+	for _, fieldID := range clear {
+		_, err = tx.ExecContext(ctx, `DELETE FROM task_field_values WHERE task_id = $1 AND field_id = $2`, taskID, fieldID)
+		if err != nil {
+			return fmt.Errorf("failed clear value %s:%w", op, err)
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE tasks SET updated_at = $1 WHERE id = $2`, time.Now().UTC(), taskID); err != nil {
+		return fmt.Errorf("failed touch task %s:%w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
+}
+
+func upsertFieldValues(ctx context.Context, tx txmanager.Querier, taskID int64, values []models.FieldValue) error {
+	query := `INSERT INTO task_field_values (task_id, field_id, value) VALUES ($1, $2, $3)
+		ON CONFLICT (task_id, field_id) DO UPDATE SET value = excluded.value`
+
+	for _, v := range values {
+		if _, err := tx.ExecContext(ctx, query, taskID, v.FieldID, v.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// selectFieldValues returns custom field values grouped by task id
+func (s Storage) selectFieldValues(ctx context.Context, where string, args ...any) (map[int64][]models.FieldValue, error) {
+	query := `SELECT v.task_id, f.id, f.name, f.type, v.value
+	FROM task_field_values v
+	JOIN custom_fields f ON f.id = v.field_id
+	JOIN tasks t ON t.id = v.task_id
+	WHERE ` + where + `
+	ORDER BY f.position, f.id`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	values := make(map[int64][]models.FieldValue)
+	for rows.Next() {
+		var (
+			taskID int64
+			v      models.FieldValue
+			typ    string
+		)
+		if err = rows.Scan(&taskID, &v.FieldID, &v.Name, &typ, &v.Value); err != nil {
+			return nil, err
+		}
+		v.Type = models.FieldType(typ)
+		values[taskID] = append(values[taskID], v)
+	}
+
+	return values, rows.Err()
+}
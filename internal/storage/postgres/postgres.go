@@ -0,0 +1,210 @@
+// Package postgres implements the storage of the services on PostgreSQL,
+// queries and errors mirror the sqlite storage
+package postgres
+
+import (
+	"TaskList/internal/lib/migrator"
+	"TaskList/internal/lib/txmanager"
+	"TaskList/migrations"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	uniqueViolation      = "23505"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+type Storage struct {
+	db  *sql.DB
+	txm *txmanager.Manager
+}
+
+type Options struct {
+	MaxOpenConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// New connects to the database by the dsn, timestamps are scanned in UTC like in the sqlite storage
+func New(dsn string, opts Options) (*Storage, error) {
+	const op = "storage.postgres.New"
+
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed parse dsn %s:%w", op, err)
+	}
+
+	db := stdlib.OpenDB(*cfg, stdlib.OptionAfterConnect(func(ctx context.Context, conn *pgx.Conn) error {
+		conn.TypeMap().RegisterType(&pgtype.Type{
+			Name:  "timestamptz",
+			OID:   pgtype.TimestamptzOID,
+			Codec: &pgtype.TimestamptzCodec{ScanLocation: time.UTC},
+		})
+		return nil
+	}))
+	if opts.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(opts.MaxOpenConns)
+		db.SetMaxIdleConns(opts.MaxOpenConns)
+	}
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+
+	// checks made in WithinTx, such as the WIP limit, must hold until the commit, READ COMMITTED
+	// lets two transactions count the same rows and both insert, SERIALIZABLE fails one of them
+	// and the manager runs it again
+	txm := txmanager.New(db, isRetryable, txmanager.WithIsolation(sql.LevelSerializable))
+
+	return &Storage{db: db, txm: txm}, nil
+}
+
+// WithinTx runs fn in one transaction, storage methods called with the ctx given to fn join it
+func (s Storage) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return s.txm.WithinTx(ctx, fn)
+}
+
+// AfterCommit runs fn after the transaction of the ctx is committed, at once outside of a transaction
+func (s Storage) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	s.txm.AfterCommit(ctx, fn)
+}
+
+// conn returns the transaction of the context or the database
+func (s Storage) conn(ctx context.Context) txmanager.Querier {
+	return s.txm.Conn(ctx)
+}
+
+func (s Storage) Ping() error {
+	return s.db.Ping()
+}
+
+func (s Storage) Close() error {
+	return s.db.Close()
+}
+
+// Migrator applies the migrations embedded from the migrations/postgres directory
+func (s Storage) Migrator() (*migrator.Migrator, error) {
+	return migrator.New(s.db, goose.DialectPostgres, migrations.Postgres())
+}
+
+// Check returns indexes left invalid by failed concurrent builds and constraints which were never validated,
+// postgres checks the data itself
+func (s Storage) Check(ctx context.Context) ([]string, error) {
+	const op = "storage.postgres.Check"
+
+	query := `SELECT 'invalid index: ' || i.indexrelid::regclass::text
+		FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indrelid
+		WHERE NOT i.indisvalid AND c.relnamespace = current_schema()::regnamespace
+		UNION ALL
+		SELECT 'not validated constraint: ' || con.conname || ' on ' || con.conrelid::regclass::text
+		FROM pg_constraint con
+		WHERE NOT con.convalidated AND con.connamespace = current_schema()::regnamespace`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed check %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var problems []string
+	for rows.Next() {
+		var p string
+		if err = rows.Scan(&p); err != nil {
+			return nil, fmt.Errorf("failed check %s:%w", op, err)
+		}
+		problems = append(problems, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed check %s:%w", op, err)
+	}
+
+	return problems, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// isRetryable reports errors of concurrent transactions, the transaction may succeed when it is run again
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected)
+}
+
+// placeholders returns "($n, $n+1, ...)" for count values starting from the parameter n, count must not be 0
+func placeholders(n int, count int) string {
+	var b strings.Builder
+	b.WriteString("(")
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("$" + strconv.Itoa(n+i))
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+func int64Args(ids []int64) []any {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func affectedOrNotFound(res sql.Result, notFound error, op string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed get affected rows %s:%w", op, err)
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func timeFromNull(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time.UTC()
+	return &v
+}
+
+func nullInt64(v *int64) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *v, Valid: true}
+}
+
+func int64FromNull(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int64
+}
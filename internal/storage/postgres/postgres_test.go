@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"TaskList/internal/models"
+	"TaskList/internal/storage/storagetest"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestContract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return newTestStorage(t)
+	})
+}
+
+// tasks moved into a column concurrently must not pass its limit, the count and the move run in one transaction
+func TestConcurrentStatusChangesKeepLimit(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	userID := newTestUser(t, s)
+
+	const limit, tasks = 2, 8
+
+	ids := make([]int64, 0, tasks)
+	for range tasks {
+		id, err := s.InsertTask(ctx, models.Task{UserID: userID, Title: "todo"})
+		if err != nil {
+			t.Fatalf("InsertTask() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.WithinTx(ctx, func(ctx context.Context) error {
+				n, err := s.CountTasksByStatus(ctx, userID, nil, models.Done)
+				if err != nil || n >= limit {
+					return err
+				}
+				// both transactions count before either moves its task
+				time.Sleep(10 * time.Millisecond)
+				return s.UpdateStatusTask(ctx, id, userID, models.Done)
+			})
+			if err != nil {
+				t.Errorf("WithinTx() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n, err := s.CountTasksByStatus(ctx, userID, nil, models.Done); err != nil || n != limit {
+		t.Errorf("CountTasksByStatus(Done) = %d, %v, want %d", n, err, limit)
+	}
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Project struct {
+	ID          int64         `db:"id"`
+	UserID      int64         `db:"user_id"`
+	WorkspaceID sql.NullInt64 `db:"workspace_id"`
+	Name        string        `db:"name"`
+	CreatedAt   time.Time     `db:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at"`
+}
+
+const projectColumns = `id, user_id, workspace_id, name, created_at, updated_at`
+
+func (p *Project) dest() []any {
+	return []any{&p.ID, &p.UserID, &p.WorkspaceID, &p.Name, &p.CreatedAt, &p.UpdatedAt}
+}
+
+func (p Project) toModel() models.Project {
+	return models.Project{
+		ID:          p.ID,
+		UserID:      p.UserID,
+		WorkspaceID: int64FromNull(p.WorkspaceID),
+		Name:        p.Name,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+func (s Storage) InsertProject(ctx context.Context, project models.Project) (int64, error) {
+	const op = "storage.postgres.InsertProject"
+	var id int64
+
+	query := `INSERT INTO projects (user_id, workspace_id, name, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+
+	now := time.Now().UTC()
+	err := s.conn(ctx).QueryRowContext(ctx, query, project.UserID, nullInt64(project.WorkspaceID), project.Name, now, now).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed insert project %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+// SelectProjectsByUserID selects own projects and projects of workspaces the user is member of
+func (s Storage) SelectProjectsByUserID(ctx context.Context, userID int64) ([]models.Project, error) {
+	const op = "storage.postgres.SelectProjectsByUserID"
+
+	projects, err := s.selectProjects(
+		ctx,
+		`user_id = $1 OR workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = $1)`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed select projects %s:%w", op, err)
+	}
+
+	return projects, nil
+}
+
+func (s Storage) SelectProjectsByWorkspace(ctx context.Context, workspaceID int64) ([]models.Project, error) {
+	const op = "storage.postgres.SelectProjectsByWorkspace"
+
+	projects, err := s.selectProjects(ctx, `workspace_id = $1`, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select projects %s:%w", op, err)
+	}
+
+	return projects, nil
+}
+
+func (s Storage) selectProjects(ctx context.Context, where string, args ...any) ([]models.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE ` + where + ` ORDER BY name`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var projects []models.Project
+	for rows.Next() {
+		var p Project
+		if err = rows.Scan(p.dest()...); err != nil {
+			return nil, err
+		}
+		projects = append(projects, p.toModel())
+	}
+
+	return projects, rows.Err()
+}
+
+func (s Storage) SelectProjectByID(ctx context.Context, projectID int64, userID int64) (models.Project, error) {
+	const op = "storage.postgres.SelectProjectByID"
+	var p Project
+
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE id = $1 AND user_id = $2`
+
+	err := s.conn(ctx).QueryRowContext(ctx, query, projectID, userID).Scan(p.dest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Project{}, models.ErrProjectNotFound
+		}
+		return models.Project{}, fmt.Errorf("failed select project %s:%w", op, err)
+	}
+
+	return p.toModel(), nil
+}
+
+// SelectProject selects project regardless of the owner, access is checked by the caller
+func (s Storage) SelectProject(ctx context.Context, projectID int64) (models.Project, error) {
+	const op = "storage.postgres.SelectProject"
+	var p Project
+
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE id = $1`
+
+	err := s.conn(ctx).QueryRowContext(ctx, query, projectID).Scan(p.dest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Project{}, models.ErrProjectNotFound
+		}
+		return models.Project{}, fmt.Errorf("failed select project %s:%w", op, err)
+	}
+
+	return p.toModel(), nil
+}
+
+func (s Storage) UpdateProject(ctx context.Context, project models.Project) error {
+	const op = "storage.postgres.UpdateProject"
+
+	query := `UPDATE projects SET name = $1, updated_at = $2 WHERE id = $3 AND user_id = $4`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, project.Name, time.Now().UTC(), project.ID, project.UserID)
+	if err != nil {
+		return fmt.Errorf("failed update project %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrProjectNotFound, op)
+}
+
+// DeleteProject deletes the project with its statuses and custom fields, tasks are kept without project,
+// all of it is done by the foreign keys
+func (s Storage) DeleteProject(ctx context.Context, projectID int64, userID int64) error {
+	const op = "storage.postgres.DeleteProject"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `DELETE FROM projects WHERE id = $1 AND user_id = $2`, projectID, userID)
+	if err != nil {
+		return fmt.Errorf("failed delete project %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrProjectNotFound, op)
+}
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const shareSelect = `SELECT ts.task_id, ts.user_id, u.email, ts.permission, ts.created_at
+	FROM task_shares ts
+	JOIN users u ON u.id = ts.user_id`
+
+// UpsertTaskShare shares the task with the user or changes permission of existing share
+func (s Storage) UpsertTaskShare(ctx context.Context, share models.TaskShare) error {
+	const op = "storage.postgres.UpsertTaskShare"
+
+	query := `INSERT INTO task_shares (task_id, user_id, permission, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (task_id, user_id) DO UPDATE SET permission = excluded.permission`
+
+	_, err := s.conn(ctx).ExecContext(ctx, query, share.TaskID, share.UserID, string(share.Permission), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed upsert share %s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s Storage) SelectTaskShares(ctx context.Context, taskID int64) ([]models.TaskShare, error) {
+	const op = "storage.postgres.SelectTaskShares"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, shareSelect+` WHERE ts.task_id = $1 ORDER BY ts.created_at`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select shares %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var shares []models.TaskShare
+	for rows.Next() {
+		share, err := scanShare(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan share %s:%w", op, err)
+		}
+		shares = append(shares, share)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select shares %s:%w", op, err)
+	}
+
+	return shares, nil
+}
+
+func (s Storage) SelectTaskShare(ctx context.Context, taskID int64, userID int64) (models.TaskShare, error) {
+	const op = "storage.postgres.SelectTaskShare"
+
+	share, err := scanShare(s.conn(ctx).QueryRowContext(ctx, shareSelect+` WHERE ts.task_id = $1 AND ts.user_id = $2`, taskID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TaskShare{}, models.ErrShareNotFound
+		}
+		return models.TaskShare{}, fmt.Errorf("failed select share %s:%w", op, err)
+	}
+
+	return share, nil
+}
+
+// DeleteTaskShare revokes access, the user stops being assignee of the task
+func (s Storage) DeleteTaskShare(ctx context.Context, taskID int64, userID int64) error {
+	const op = "storage.postgres.DeleteTaskShare"
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM task_shares WHERE task_id = $1 AND user_id = $2`, taskID, userID)
+	if err != nil {
+		return fmt.Errorf("failed delete share %s:%w", op, err)
+	}
+	if err = affectedOrNotFound(res, models.ErrShareNotFound, op); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE tasks SET assignee_id = NULL, updated_at = $1 WHERE id = $2 AND assignee_id = $3`,
+		time.Now().UTC(), taskID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed unassign task %s:%w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
+}
+
+func scanShare(row scanner) (models.TaskShare, error) {
+	var (
+		share      models.TaskShare
+		permission string
+	)
+
+	err := row.Scan(&share.TaskID, &share.UserID, &share.Email, &permission, &share.CreatedAt)
+	share.Permission = models.Permission(permission)
+
+	return share, err
+}
@@ -0,0 +1,213 @@
+package postgres
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type SmartList struct {
+	ID        int64     `db:"id"`
+	UserID    int64     `db:"user_id"`
+	Name      string    `db:"name"`
+	Query     string    `db:"query"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// smartListQuery is stored form of models.TaskFilter
+type smartListQuery struct {
+	Scope       models.TaskScope        `json:"scope,omitempty"`
+	WorkspaceID *int64                  `json:"workspace_id,omitempty"`
+	ProjectID   *int64                  `json:"project_id,omitempty"`
+	Statuses    []models.Status         `json:"statuses,omitempty"`
+	Categories  []models.StatusCategory `json:"categories,omitempty"`
+	Tags        []string                `json:"tags,omitempty"`
+	Text        string                  `json:"text,omitempty"`
+	Due         models.DueWindow        `json:"due,omitempty"`
+	DueDays     int                     `json:"due_days,omitempty"`
+	DueFrom     *time.Time              `json:"due_from,omitempty"`
+	DueTo       *time.Time              `json:"due_to,omitempty"`
+	Fields      []fieldCondition        `json:"fields,omitempty"`
+	Sort        string                  `json:"sort,omitempty"`
+	Desc        bool                    `json:"desc,omitempty"`
+}
+
+type fieldCondition struct {
+	Name  string `json:"name"`
+	Op    string `json:"op"`
+	Value string `json:"value"`
+}
+
+func marshalFilter(f models.TaskFilter) (string, error) {
+	q := smartListQuery{
+		Scope:       f.Scope,
+		WorkspaceID: f.WorkspaceID,
+		ProjectID:   f.ProjectID,
+		Statuses:    f.Statuses,
+		Categories:  f.Categories,
+		Tags:        f.Tags,
+		Text:        f.Text,
+		Due:         f.Due,
+		DueDays:     f.DueDays,
+		DueFrom:     f.DueFrom,
+		DueTo:       f.DueTo,
+		Sort:        f.Sort,
+		Desc:        f.Desc,
+	}
+	for _, c := range f.Fields {
+		q.Fields = append(q.Fields, fieldCondition{Name: c.Name, Op: c.Op, Value: c.Value})
+	}
+
+	b, err := json.Marshal(q)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func unmarshalFilter(s string) (models.TaskFilter, error) {
+	var q smartListQuery
+	if err := json.Unmarshal([]byte(s), &q); err != nil {
+		return models.TaskFilter{}, err
+	}
+
+	f := models.TaskFilter{
+		Scope:       q.Scope,
+		WorkspaceID: q.WorkspaceID,
+		ProjectID:   q.ProjectID,
+		Statuses:    q.Statuses,
+		Categories:  q.Categories,
+		Tags:        q.Tags,
+		Text:        q.Text,
+		Due:         q.Due,
+		DueDays:     q.DueDays,
+		DueFrom:     q.DueFrom,
+		DueTo:       q.DueTo,
+		Sort:        q.Sort,
+		Desc:        q.Desc,
+	}
+	for _, c := range q.Fields {
+		f.Fields = append(f.Fields, models.FieldCondition{Name: c.Name, Op: c.Op, Value: c.Value})
+	}
+
+	return f, nil
+}
+
+func scanSmartList(row scanner) (models.SmartList, error) {
+	var sl SmartList
+	if err := row.Scan(&sl.ID, &sl.UserID, &sl.Name, &sl.Query, &sl.CreatedAt, &sl.UpdatedAt); err != nil {
+		return models.SmartList{}, err
+	}
+
+	filter, err := unmarshalFilter(sl.Query)
+	if err != nil {
+		return models.SmartList{}, err
+	}
+
+	return models.SmartList{
+		ID:        sl.ID,
+		UserID:    sl.UserID,
+		Name:      sl.Name,
+		Filter:    filter,
+		CreatedAt: sl.CreatedAt,
+		UpdatedAt: sl.UpdatedAt,
+	}, nil
+}
+
+func (s Storage) InsertSmartList(ctx context.Context, list models.SmartList) (int64, error) {
+	const op = "storage.postgres.InsertSmartList"
+
+	q, err := marshalFilter(list.Filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed marshal filter %s:%w", op, err)
+	}
+
+	query := `INSERT INTO smart_lists (user_id, name, query, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+
+	var id int64
+	now := time.Now().UTC()
+	if err = s.conn(ctx).QueryRowContext(ctx, query, list.UserID, list.Name, q, now, now).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed insert smart list %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s Storage) SelectSmartLists(ctx context.Context, userID int64) ([]models.SmartList, error) {
+	const op = "storage.postgres.SelectSmartLists"
+
+	query := `SELECT id, user_id, name, query, created_at, updated_at FROM smart_lists WHERE user_id = $1 ORDER BY id`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select smart lists %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var lists []models.SmartList
+	for rows.Next() {
+		sl, err := scanSmartList(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan smart list %s:%w", op, err)
+		}
+		lists = append(lists, sl)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select smart lists %s:%w", op, err)
+	}
+
+	return lists, nil
+}
+
+func (s Storage) SelectSmartListByID(ctx context.Context, listID int64, userID int64) (models.SmartList, error) {
+	const op = "storage.postgres.SelectSmartListByID"
+
+	query := `SELECT id, user_id, name, query, created_at, updated_at FROM smart_lists WHERE id = $1 AND user_id = $2`
+
+	sl, err := scanSmartList(s.conn(ctx).QueryRowContext(ctx, query, listID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.SmartList{}, models.ErrSmartListNotFound
+		}
+		return models.SmartList{}, fmt.Errorf("failed select smart list %s:%w", op, err)
+	}
+
+	return sl, nil
+}
+
+func (s Storage) UpdateSmartList(ctx context.Context, list models.SmartList) error {
+	const op = "storage.postgres.UpdateSmartList"
+
+	q, err := marshalFilter(list.Filter)
+	if err != nil {
+		return fmt.Errorf("failed marshal filter %s:%w", op, err)
+	}
+
+	query := `UPDATE smart_lists SET name = $1, query = $2, updated_at = $3 WHERE id = $4 AND user_id = $5`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, list.Name, q, time.Now().UTC(), list.ID, list.UserID)
+	if err != nil {
+		return fmt.Errorf("failed update smart list %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrSmartListNotFound, op)
+}
+
+func (s Storage) DeleteSmartList(ctx context.Context, listID int64, userID int64) error {
+	const op = "storage.postgres.DeleteSmartList"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `DELETE FROM smart_lists WHERE id = $1 AND user_id = $2`, listID, userID)
+	if err != nil {
+		return fmt.Errorf("failed delete smart list %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrSmartListNotFound, op)
+}
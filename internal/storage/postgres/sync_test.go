@@ -0,0 +1,213 @@
+package postgres
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// envTestDSN points to a database the tests may create schemas in, the tests are skipped without it
+const envTestDSN = "TASKLIST_TEST_POSTGRES_DSN"
+
+// newTestStorage migrates a new schema of the test database and drops it after the test
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	dsn := os.Getenv(envTestDSN)
+	if dsn == "" {
+		t.Skipf("%s is not set", envTestDSN)
+	}
+	ctx := context.Background()
+
+	admin, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open admin connection: %v", err)
+	}
+	t.Cleanup(func() { _ = admin.Close() })
+
+	schema := fmt.Sprintf("tasklist_test_%d", time.Now().UnixNano())
+	if _, err = admin.ExecContext(ctx, `CREATE SCHEMA `+schema); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() { _, _ = admin.ExecContext(ctx, `DROP SCHEMA `+schema+` CASCADE`) })
+
+	switch {
+	case !strings.Contains(dsn, "://"):
+		dsn += " search_path=" + schema
+	case strings.Contains(dsn, "?"):
+		dsn += "&search_path=" + schema
+	default:
+		dsn += "?search_path=" + schema
+	}
+
+	s, err := New(dsn, Options{MaxOpenConns: 20})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	m, err := s.Migrator()
+	if err != nil {
+		t.Fatalf("Migrator() error = %v", err)
+	}
+	if _, err = m.Up(ctx); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	return s
+}
+
+func newTestUser(t *testing.T, s *Storage) int64 {
+	t.Helper()
+
+	id, err := s.CreateUser(context.Background(), fmt.Sprintf("user%d@example.com", time.Now().UnixNano()), []byte("hash"))
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	return id
+}
+
+// a change committed while an earlier one is in flight must not get a version the next sync skips
+func TestTaskChangesCommitOrder(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	task := models.Task{UserID: newTestUser(t, s), Title: "in flight"}
+
+	token, err := s.LastTaskChange(ctx)
+	if err != nil {
+		t.Fatalf("LastTaskChange() error = %v", err)
+	}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	doneA := make(chan error, 1)
+	var idA int64
+	go func() {
+		doneA <- s.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			idA, err = s.InsertTask(ctx, task)
+			close(started)
+			<-release
+			return err
+		})
+	}()
+	<-started
+
+	doneB := make(chan error, 1)
+	var idB int64
+	go func() {
+		var err error
+		idB, err = s.InsertTask(ctx, task)
+		doneB <- err
+	}()
+
+	select {
+	case err = <-doneB:
+		close(release)
+		t.Fatalf("change committed while an earlier change is in flight, error %v", err)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	if last, err := s.LastTaskChange(ctx); err != nil || last != token {
+		t.Errorf("LastTaskChange() = %d, %v, want %d while the changes are in flight", last, err, token)
+	}
+
+	close(release)
+	if err = <-doneA; err != nil {
+		t.Fatalf("first transaction: %v", err)
+	}
+	if err = <-doneB; err != nil {
+		t.Fatalf("second insert: %v", err)
+	}
+
+	changes, err := s.SelectTaskChanges(ctx, task.UserID, token, 100)
+	if err != nil {
+		t.Fatalf("SelectTaskChanges() error = %v", err)
+	}
+	versions := make(map[int64]int64)
+	for _, c := range changes {
+		versions[c.TaskID] = c.Version
+	}
+	if versions[idA] == 0 || versions[idB] == 0 || versions[idA] >= versions[idB] {
+		t.Errorf("versions = %v, want task %d before task %d", versions, idA, idB)
+	}
+}
+
+// a client syncing while writers commit concurrently must see every task
+func TestTaskChangesNotSkipped(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	userID := newTestUser(t, s)
+
+	const writers, tasksPerWriter = 8, 20
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created = make(map[int64]struct{})
+	)
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range tasksPerWriter {
+				var id int64
+				err := s.WithinTx(ctx, func(ctx context.Context) error {
+					var err error
+					id, err = s.InsertTask(ctx, models.Task{UserID: userID, Title: "concurrent"})
+					// widens the window between taking the version and the commit
+					time.Sleep(time.Duration(rand.N(3)) * time.Millisecond)
+					return err
+				})
+				if err != nil {
+					t.Errorf("InsertTask() error = %v", err)
+					return
+				}
+				mu.Lock()
+				created[id] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	writing := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(writing)
+	}()
+
+	var token int64
+	seen := make(map[int64]struct{})
+	poll := func() {
+		changes, err := s.SelectTaskChanges(ctx, userID, token, 1000)
+		if err != nil {
+			t.Fatalf("SelectTaskChanges() error = %v", err)
+		}
+		for _, c := range changes {
+			seen[c.TaskID] = struct{}{}
+			token = max(token, c.Version)
+		}
+	}
+
+	for done := false; !done; {
+		select {
+		case <-writing:
+			done = true
+		default:
+		}
+		poll()
+	}
+	poll()
+
+	for id := range created {
+		if _, ok := seen[id]; !ok {
+			t.Errorf("task %d was skipped by sync", id)
+		}
+	}
+}
@@ -0,0 +1,414 @@
+package postgres
+
+import (
+	"TaskList/internal/lib/txmanager"
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type Task struct {
+	ID          int64          `db:"id"`
+	UserID      int64          `db:"user_id"`
+	CreatedBy   int64          `db:"created_by"`
+	AssigneeID  sql.NullInt64  `db:"assignee_id"`
+	ProjectID   sql.NullInt64  `db:"project_id"`
+	WorkspaceID sql.NullInt64  `db:"workspace_id"`
+	Title       string         `db:"task_name"`
+	Description string         `db:"description"`
+	Status      string         `db:"status"`
+	Category    sql.NullString `db:"category"`
+	Priority    string         `db:"priority"`
+	Recurrence  string         `db:"recurrence"`
+	DueAt       sql.NullTime   `db:"due_at"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+}
+
+// taskSelect selects tasks with category of their status,
+// status defined for the project has priority over the status defined for all user projects
+const taskSelect = `SELECT
+		t.id,
+		t.user_id,
+		COALESCE(t.created_by, t.user_id) AS created_by,
+		t.assignee_id,
+		t.project_id,
+		(SELECT p.workspace_id FROM projects p WHERE p.id = t.project_id) AS workspace_id,
+		t.task_name,
+		t.description,
+		t.status,
+		(SELECT w.category FROM workflow_statuses w
+			WHERE w.user_id = t.user_id
+			  AND w.name = t.status
+			  AND (w.project_id = t.project_id OR w.project_id IS NULL)
+			ORDER BY w.project_id IS NULL
+			LIMIT 1) AS category,
+		t.priority,
+		t.recurrence,
+		t.due_at,
+		t.created_at,
+		t.updated_at
+	FROM tasks t`
+
+func scanTask(row scanner) (Task, error) {
+	var task Task
+	err := row.Scan(
+		&task.ID,
+		&task.UserID,
+		&task.CreatedBy,
+		&task.AssigneeID,
+		&task.ProjectID,
+		&task.WorkspaceID,
+		&task.Title,
+		&task.Description,
+		&task.Status,
+		&task.Category,
+		&task.Priority,
+		&task.Recurrence,
+		&task.DueAt,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+	)
+	return task, err
+}
+
+func (t Task) toModel(tags []string, fields []models.FieldValue) models.Task {
+	return models.Task{
+		ID:             t.ID,
+		UserID:         t.UserID,
+		CreatedBy:      t.CreatedBy,
+		AssigneeID:     int64FromNull(t.AssigneeID),
+		ProjectID:      int64FromNull(t.ProjectID),
+		WorkspaceID:    int64FromNull(t.WorkspaceID),
+		Title:          t.Title,
+		Description:    t.Description,
+		Status:         models.Status(t.Status),
+		StatusCategory: statusCategory(models.Status(t.Status), t.Category),
+		Priority:       models.Priority(t.Priority),
+		Recurrence:     t.Recurrence,
+		Tags:           tags,
+		CustomFields:   fields,
+		DueAt:          timeFromNull(t.DueAt),
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
+	}
+}
+
+func (s Storage) UpdateStatusTask(ctx context.Context, taskID int64, userID int64, status models.Status) error {
+	const op = "storage.postgres.UpdateStatusTask"
+
+	query := `UPDATE tasks SET status = $1, updated_at = $2 WHERE id = $3 AND user_id = $4`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, string(status), time.Now().UTC(), taskID, userID)
+	if err != nil {
+		return fmt.Errorf("failed update status %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrTaskNotFound, op)
+}
+
+// UpdateTask stores title, description, priority, recurrence, due date and tags of the task
+func (s Storage) UpdateTask(ctx context.Context, task models.Task) error {
+	const op = "storage.postgres.UpdateTask"
+
+	query := `UPDATE tasks SET task_name = $1, description = $2, priority = $3, recurrence = $4, due_at = $5, updated_at = $6
+		WHERE id = $7`
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(
+		ctx,
+		query,
+		task.Title,
+		task.Description,
+		string(task.Priority),
+		task.Recurrence,
+		nullTime(task.DueAt),
+		time.Now().UTC(),
+		task.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed update task %s:%w", op, err)
+	}
+	if err = affectedOrNotFound(res, models.ErrTaskNotFound, op); err != nil {
+		return err
+	}
+
+	// only removed tags are deleted, so unchanged tags do not get into the change log
+	args := []any{task.ID}
+	query = `DELETE FROM task_tags WHERE task_id = $1`
+	if len(task.Tags) > 0 {
+		query += ` AND tag NOT IN ` + placeholders(2, len(task.Tags))
+		for _, tag := range task.Tags {
+			args = append(args, tag)
+		}
+	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed delete tags %s:%w", op, err)
+	}
+	if err = insertTags(ctx, tx, task.ID, task.Tags); err != nil {
+		return fmt.Errorf("failed insert tags %s:%w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteTask deletes the task, its tags, field values, shares and comments are deleted by the foreign keys,
+// watchers are removed by notifications when they handle the deletion
+func (s Storage) DeleteTask(ctx context.Context, taskID int64) error {
+	const op = "storage.postgres.DeleteTask"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `DELETE FROM tasks WHERE id = $1`, taskID)
+	if err != nil {
+		return fmt.Errorf("failed delete task %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrTaskNotFound, op)
+}
+
+// CountTasksByStatus counts tasks in the board column of the project, nil project means tasks without project
+func (s Storage) CountTasksByStatus(ctx context.Context, userID int64, projectID *int64, status models.Status) (int, error) {
+	const op = "storage.postgres.CountTasksByStatus"
+	var count int
+
+	query := `SELECT count(*) FROM tasks WHERE user_id = $1 AND status = $2 AND project_id IS NOT DISTINCT FROM $3`
+
+	err := s.conn(ctx).QueryRowContext(ctx, query, userID, string(status), nullInt64(projectID)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed count tasks %s:%w", op, err)
+	}
+
+	return count, nil
+}
+
+func (s Storage) InsertTask(ctx context.Context, task models.Task) (int64, error) {
+	const op = "storage.postgres.InsertTask"
+	var id int64
+
+	query := `INSERT INTO tasks (user_id, created_by, assignee_id, project_id, task_name, description, status, priority, recurrence, due_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id`
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	status := task.Status
+	if status == "" {
+		status = models.Pending
+	}
+
+	createdBy := task.CreatedBy
+	if createdBy == 0 {
+		createdBy = task.UserID
+	}
+
+	now := time.Now().UTC()
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		task.UserID,
+		createdBy,
+		nullInt64(task.AssigneeID),
+		nullInt64(task.ProjectID),
+		task.Title,
+		task.Description,
+		string(status),
+		string(task.Priority),
+		task.Recurrence,
+		nullTime(task.DueAt),
+		now,
+		now,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed exec query %s:%w", op, err)
+	}
+
+	if err = insertTags(ctx, tx, id, task.Tags); err != nil {
+		return 0, fmt.Errorf("failed insert tags %s:%w", op, err)
+	}
+
+	if err = upsertFieldValues(ctx, tx, id, task.CustomFields); err != nil {
+		return 0, fmt.Errorf("failed insert custom fields %s:%w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+func insertTags(ctx context.Context, tx txmanager.Querier, taskID int64, tags []string) error {
+	for _, tag := range tags {
+		_, err := tx.ExecContext(ctx, `INSERT INTO task_tags (task_id, tag) VALUES ($1, $2) ON CONFLICT DO NOTHING`, taskID, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s Storage) selectTags(ctx context.Context, where string, args ...any) (map[int64][]string, error) {
+	query := `SELECT tt.task_id, tt.tag
+	FROM task_tags tt
+	JOIN tasks t ON t.id = tt.task_id
+	WHERE ` + where + `
+	ORDER BY tt.tag`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	tags := make(map[int64][]string)
+	for rows.Next() {
+		var (
+			taskID int64
+			tag    string
+		)
+		if err = rows.Scan(&taskID, &tag); err != nil {
+			return nil, err
+		}
+		tags[taskID] = append(tags[taskID], tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (s Storage) SelectAllTasksByUserID(ctx context.Context, userID int64) ([]models.Task, error) {
+	const op = "storage.postgres.SelectAllTasksByUserID"
+
+	tasks, err := s.selectTasks(ctx, `t.user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select tasks %s:%w", op, err)
+	}
+	return tasks, nil
+}
+
+// SelectTaskByID selects task regardless of the owner, access is checked by the caller
+func (s Storage) SelectTaskByID(ctx context.Context, taskID int64) (models.Task, error) {
+	const op = "storage.postgres.SelectTaskByID"
+
+	tasks, err := s.selectTasks(ctx, `t.id = $1`, taskID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed select task %s:%w", op, err)
+	}
+	if len(tasks) == 0 {
+		return models.Task{}, models.ErrTaskNotFound
+	}
+
+	return tasks[0], nil
+}
+
+func (s Storage) SelectTasksAssignedTo(ctx context.Context, userID int64) ([]models.Task, error) {
+	const op = "storage.postgres.SelectTasksAssignedTo"
+
+	tasks, err := s.selectTasks(ctx, `t.assignee_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select assigned tasks %s:%w", op, err)
+	}
+	return tasks, nil
+}
+
+func (s Storage) SelectTasksSharedWith(ctx context.Context, userID int64) ([]models.Task, error) {
+	const op = "storage.postgres.SelectTasksSharedWith"
+
+	tasks, err := s.selectTasks(ctx, `t.id IN (SELECT ts.task_id FROM task_shares ts WHERE ts.user_id = $1)`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select shared tasks %s:%w", op, err)
+	}
+	return tasks, nil
+}
+
+func (s Storage) SelectTasksByWorkspace(ctx context.Context, workspaceID int64) ([]models.Task, error) {
+	const op = "storage.postgres.SelectTasksByWorkspace"
+
+	tasks, err := s.selectTasks(ctx, `t.project_id IN (SELECT p.id FROM projects p WHERE p.workspace_id = $1)`, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select workspace tasks %s:%w", op, err)
+	}
+	return tasks, nil
+}
+
+// selectTasks selects tasks with tags and custom fields, where is a condition on tasks aliased as t
+func (s Storage) selectTasks(ctx context.Context, where string, args ...any) ([]models.Task, error) {
+	tags, err := s.selectTags(ctx, where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := s.selectFieldValues(ctx, where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn(ctx).QueryContext(ctx, taskSelect+` WHERE `+where+` ORDER BY t.id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task.toModel(tags[task.ID], fields[task.ID]))
+	}
+
+	return tasks, rows.Err()
+}
+
+// UpdateTaskAssignee sets assignee of the task, nil removes assignee
+func (s Storage) UpdateTaskAssignee(ctx context.Context, taskID int64, assigneeID *int64) error {
+	const op = "storage.postgres.UpdateTaskAssignee"
+
+	query := `UPDATE tasks SET assignee_id = $1, updated_at = $2 WHERE id = $3`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, nullInt64(assigneeID), time.Now().UTC(), taskID)
+	if err != nil {
+		return fmt.Errorf("failed update assignee %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrTaskNotFound, op)
+}
+
+// statusCategory returns category found in user workflow,
+// statuses of the default workflow are resolved without stored workflow
+func statusCategory(status models.Status, category sql.NullString) models.StatusCategory {
+	if category.Valid {
+		return models.StatusCategory(category.String)
+	}
+
+	for _, ws := range models.DefaultWorkflow() {
+		if ws.Name == status {
+			return ws.Category
+		}
+	}
+
+	return models.CategoryTodo
+}
@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type User struct {
+	ID           int64
+	Email        string
+	PasswordHash string
+	FeedToken    sql.NullString
+	Timezone     string
+	CreatedAt    time.Time
+	DisabledAt   sql.NullTime
+}
+
+const userColumns = `id, email, password_hash, feed_token, timezone, created_at, disabled_at`
+
+func (s Storage) CreateUser(ctx context.Context, email string, passHash []byte) (int64, error) {
+	const op = "storage.postgres.CreateUser"
+	var id int64
+
+	q := `INSERT INTO users (email, password_hash, created_at) VALUES ($1, $2, $3) RETURNING id`
+	err := s.conn(ctx).QueryRowContext(ctx, q, email, string(passHash), time.Now().UTC()).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, models.ErrUserAlreadyExists
+		}
+		return 0, fmt.Errorf("failed insert user %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s Storage) UserByEmail(ctx context.Context, email string) (*models.User, error) {
+	const op = "storage.postgres.UserByEmail"
+
+	return s.selectUser(ctx, op, `email = $1`, email)
+}
+
+func (s Storage) UserByFeedToken(ctx context.Context, token string) (*models.User, error) {
+	const op = "storage.postgres.UserByFeedToken"
+
+	return s.selectUser(ctx, op, `feed_token = $1`, token)
+}
+
+func (s Storage) UserByID(ctx context.Context, userID int64) (*models.User, error) {
+	const op = "storage.postgres.UserByID"
+
+	return s.selectUser(ctx, op, `id = $1`, userID)
+}
+
+func (s Storage) selectUser(ctx context.Context, op string, where string, arg any) (*models.User, error) {
+	var user User
+
+	err := s.conn(ctx).QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE `+where, arg).Scan(user.dest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed select user %s:%w", op, err)
+	}
+
+	return user.toModel(), nil
+}
+
+// SelectUsersByIDs returns users with the ids, unknown ids are skipped
+func (s Storage) SelectUsersByIDs(ctx context.Context, ids []int64) ([]models.User, error) {
+	const op = "storage.postgres.SelectUsersByIDs"
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE id IN ` + placeholders(1, len(ids))
+	rows, err := s.conn(ctx).QueryContext(ctx, query, int64Args(ids)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed select users %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var users []models.User
+	for rows.Next() {
+		var user User
+		if err = rows.Scan(user.dest()...); err != nil {
+			return nil, fmt.Errorf("failed scan user %s:%w", op, err)
+		}
+		users = append(users, *user.toModel())
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select users %s:%w", op, err)
+	}
+
+	return users, nil
+}
+
+func (s Storage) UpdateTimezone(ctx context.Context, userID int64, timezone string) error {
+	const op = "storage.postgres.UpdateTimezone"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `UPDATE users SET timezone = $1 WHERE id = $2`, timezone, userID)
+	if err != nil {
+		return fmt.Errorf("failed update timezone %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrUserNotFound, op)
+}
+
+func (s Storage) UpdateFeedToken(ctx context.Context, userID int64, token string) error {
+	const op = "storage.postgres.UpdateFeedToken"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `UPDATE users SET feed_token = $1 WHERE id = $2`, token, userID)
+	if err != nil {
+		return fmt.Errorf("failed update feed token %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrUserNotFound, op)
+}
+
+func (s Storage) UpdatePasswordHash(ctx context.Context, userID int64, passHash []byte) error {
+	const op = "storage.postgres.UpdatePasswordHash"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `UPDATE users SET password_hash = $1 WHERE id = $2`, string(passHash), userID)
+	if err != nil {
+		return fmt.Errorf("failed update password %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrUserNotFound, op)
+}
+
+// SetUserDisabled disables the user at the time, nil enables the user
+func (s Storage) SetUserDisabled(ctx context.Context, userID int64, disabledAt *time.Time) error {
+	const op = "storage.postgres.SetUserDisabled"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `UPDATE users SET disabled_at = $1 WHERE id = $2`, nullTime(disabledAt), userID)
+	if err != nil {
+		return fmt.Errorf("failed update user %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrUserNotFound, op)
+}
+
+func (u *User) dest() []any {
+	return []any{&u.ID, &u.Email, &u.PasswordHash, &u.FeedToken, &u.Timezone, &u.CreatedAt, &u.DisabledAt}
+}
+
+func (u User) toModel() *models.User {
+	return &models.User{
+		ID:           u.ID,
+		Email:        u.Email,
+		PasswordHash: []byte(u.PasswordHash),
+		FeedToken:    u.FeedToken.String,
+		Timezone:     u.Timezone,
+		CreatedAt:    u.CreatedAt.UTC(),
+		DisabledAt:   timeFromNull(u.DisabledAt),
+	}
+}
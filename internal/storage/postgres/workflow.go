@@ -0,0 +1,252 @@
+package postgres
+
+import (
+	"TaskList/internal/lib/txmanager"
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type WorkflowStatus struct {
+	ID        int64         `db:"id"`
+	UserID    int64         `db:"user_id"`
+	ProjectID sql.NullInt64 `db:"project_id"`
+	Name      string        `db:"name"`
+	Category  string        `db:"category"`
+	Position  int           `db:"position"`
+	WIPLimit  int           `db:"wip_limit"`
+	CreatedAt time.Time     `db:"created_at"`
+}
+
+const workflowStatusColumns = `id, user_id, project_id, name, category, position, wip_limit, created_at`
+
+func scanWorkflowStatus(row scanner) (models.WorkflowStatus, error) {
+	var ws WorkflowStatus
+	err := row.Scan(
+		&ws.ID,
+		&ws.UserID,
+		&ws.ProjectID,
+		&ws.Name,
+		&ws.Category,
+		&ws.Position,
+		&ws.WIPLimit,
+		&ws.CreatedAt,
+	)
+	if err != nil {
+		return models.WorkflowStatus{}, err
+	}
+
+	return models.WorkflowStatus{
+		ID:        ws.ID,
+		UserID:    ws.UserID,
+		ProjectID: int64FromNull(ws.ProjectID),
+		Name:      models.Status(ws.Name),
+		Category:  models.StatusCategory(ws.Category),
+		Position:  ws.Position,
+		WIPLimit:  ws.WIPLimit,
+		CreatedAt: ws.CreatedAt,
+	}, nil
+}
+
+func (s Storage) InsertWorkflowStatus(ctx context.Context, ws models.WorkflowStatus) (int64, error) {
+	const op = "storage.postgres.InsertWorkflowStatus"
+	var id int64
+
+	query := `INSERT INTO workflow_statuses (user_id, project_id, name, category, position, wip_limit, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err = shiftPositions(ctx, tx, ws.UserID, ws.ProjectID, ws.Position, 0); err != nil {
+		return 0, fmt.Errorf("failed shift positions %s:%w", op, err)
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		ws.UserID,
+		nullInt64(ws.ProjectID),
+		string(ws.Name),
+		string(ws.Category),
+		ws.Position,
+		ws.WIPLimit,
+		time.Now().UTC(),
+	).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, models.ErrStatusAlreadyExists
+		}
+		return 0, fmt.Errorf("failed insert status %s:%w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+// shiftPositions moves columns starting from the position one step right to free the place
+func shiftPositions(ctx context.Context, tx txmanager.Querier, userID int64, projectID *int64, position int, exceptID int64) error {
+	query := `UPDATE workflow_statuses SET position = position + 1
+		WHERE user_id = $1 AND project_id IS NOT DISTINCT FROM $2 AND position >= $3 AND id != $4
+		  AND EXISTS (SELECT 1 FROM workflow_statuses w
+			WHERE w.user_id = $1 AND w.project_id IS NOT DISTINCT FROM $2 AND w.position = $3 AND w.id != $4)`
+
+	_, err := tx.ExecContext(ctx, query, userID, nullInt64(projectID), position, exceptID)
+	return err
+}
+
+// SelectWorkflowStatuses returns statuses defined exactly for the scope, nil project means statuses for all projects
+func (s Storage) SelectWorkflowStatuses(ctx context.Context, userID int64, projectID *int64) ([]models.WorkflowStatus, error) {
+	const op = "storage.postgres.SelectWorkflowStatuses"
+
+	query := `SELECT ` + workflowStatusColumns + ` FROM workflow_statuses
+		WHERE user_id = $1 AND project_id IS NOT DISTINCT FROM $2
+		ORDER BY position, id`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, userID, nullInt64(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed select statuses %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var statuses []models.WorkflowStatus
+	for rows.Next() {
+		ws, err := scanWorkflowStatus(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan status %s:%w", op, err)
+		}
+		statuses = append(statuses, ws)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select statuses %s:%w", op, err)
+	}
+
+	return statuses, nil
+}
+
+func (s Storage) SelectWorkflowStatusByID(ctx context.Context, statusID int64, userID int64) (models.WorkflowStatus, error) {
+	const op = "storage.postgres.SelectWorkflowStatusByID"
+
+	query := `SELECT ` + workflowStatusColumns + ` FROM workflow_statuses WHERE id = $1 AND user_id = $2`
+
+	ws, err := scanWorkflowStatus(s.conn(ctx).QueryRowContext(ctx, query, statusID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WorkflowStatus{}, models.ErrStatusNotFound
+		}
+		return models.WorkflowStatus{}, fmt.Errorf("failed select status %s:%w", op, err)
+	}
+
+	return ws, nil
+}
+
+// UpdateWorkflowStatus updates the status, tasks are moved to the new name in the same transaction
+func (s Storage) UpdateWorkflowStatus(ctx context.Context, ws models.WorkflowStatus, oldName models.Status) error {
+	const op = "storage.postgres.UpdateWorkflowStatus"
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `UPDATE workflow_statuses SET name = $1, category = $2, position = $3, wip_limit = $4
+		WHERE id = $5 AND user_id = $6`
+
+	if err = shiftPositions(ctx, tx, ws.UserID, ws.ProjectID, ws.Position, ws.ID); err != nil {
+		return fmt.Errorf("failed shift positions %s:%w", op, err)
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		query,
+		string(ws.Name),
+		string(ws.Category),
+		ws.Position,
+		ws.WIPLimit,
+		ws.ID,
+		ws.UserID,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.ErrStatusAlreadyExists
+		}
+		return fmt.Errorf("failed update status %s:%w", op, err)
+	}
+	if err = affectedOrNotFound(res, models.ErrStatusNotFound, op); err != nil {
+		return err
+	}
+
+	if oldName != ws.Name {
+		if err = renameTasksStatus(ctx, tx, ws.UserID, ws.ProjectID, oldName, ws.Name); err != nil {
+			return fmt.Errorf("failed rename tasks status %s:%w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteWorkflowStatus deletes the status and moves its tasks to the fallback status
+func (s Storage) DeleteWorkflowStatus(ctx context.Context, ws models.WorkflowStatus, fallback models.Status) error {
+	const op = "storage.postgres.DeleteWorkflowStatus"
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM workflow_statuses WHERE id = $1 AND user_id = $2`, ws.ID, ws.UserID)
+	if err != nil {
+		return fmt.Errorf("failed delete status %s:%w", op, err)
+	}
+	if err = affectedOrNotFound(res, models.ErrStatusNotFound, op); err != nil {
+		return err
+	}
+
+	if err = renameTasksStatus(ctx, tx, ws.UserID, ws.ProjectID, ws.Name, fallback); err != nil {
+		return fmt.Errorf("failed move tasks %s:%w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
+}
+
+// renameTasksStatus changes status of tasks in the scope, nil project affects tasks of all projects
+// which do not define own status with the same name
+func renameTasksStatus(ctx context.Context, tx txmanager.Querier, userID int64, projectID *int64, from, to models.Status) error {
+	query := `UPDATE tasks SET status = $1, updated_at = $2
+		WHERE user_id = $3 AND status = $4
+		  AND (project_id IS NOT DISTINCT FROM $5 OR ($5::bigint IS NULL AND NOT EXISTS (
+				SELECT 1 FROM workflow_statuses w WHERE w.project_id = tasks.project_id
+			)))`
+
+	_, err := tx.ExecContext(ctx, query, string(to), time.Now().UTC(), userID, string(from), nullInt64(projectID))
+	return err
+}
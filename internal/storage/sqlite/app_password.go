@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func (s Storage) InsertAppPassword(ctx context.Context, p models.AppPassword, tokenHash string) (int64, error) {
+	const op = "storage.sqlite.InsertAppPassword"
+
+	query := `INSERT INTO app_passwords (user_id, name, token_hash, created_at) VALUES (?, ?, ?, ?)`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, p.UserID, p.Name, tokenHash, p.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed insert app password %s:%w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed insert app password %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s Storage) SelectAppPasswords(ctx context.Context, userID int64) ([]models.AppPassword, error) {
+	const op = "storage.sqlite.SelectAppPasswords"
+
+	query := `SELECT id, user_id, name, created_at, last_used_at FROM app_passwords WHERE user_id = ? ORDER BY id`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select app passwords %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []models.AppPassword
+	for rows.Next() {
+		var (
+			p        models.AppPassword
+			lastUsed sql.NullTime
+		)
+		if err = rows.Scan(&p.ID, &p.UserID, &p.Name, &p.CreatedAt, &lastUsed); err != nil {
+			return nil, fmt.Errorf("failed scan app password %s:%w", op, err)
+		}
+		p.LastUsedAt = timeFromNull(lastUsed)
+		res = append(res, p)
+	}
+
+	return res, rows.Err()
+}
+
+func (s Storage) DeleteAppPassword(ctx context.Context, userID int64, id int64) error {
+	const op = "storage.sqlite.DeleteAppPassword"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `DELETE FROM app_passwords WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed delete app password %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrAppPasswordNotFound, op)
+}
+
+// UserByAppPassword returns the user with the email who has the app password and marks the password used,
+// the mark is updated at most once a minute because clients authenticate every request
+func (s Storage) UserByAppPassword(ctx context.Context, email string, tokenHash string) (*models.User, error) {
+	const op = "storage.sqlite.UserByAppPassword"
+	var (
+		user          User
+		appPasswordID int64
+	)
+
+	q := `SELECT u.id, u.email, u.password_hash, u.feed_token, u.timezone, u.created_at, u.disabled_at, ap.id
+		FROM users u
+		JOIN app_passwords ap ON ap.user_id = u.id
+		WHERE u.email = ? AND ap.token_hash = ?`
+
+	err := s.conn(ctx).QueryRowContext(ctx, q, email, tokenHash).Scan(append(user.dest(), &appPasswordID)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed select user %s:%w", op, err)
+	}
+
+	now := time.Now().UTC()
+	_, err = s.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE app_passwords SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)`,
+		now, appPasswordID, now.Add(-time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed update app password %s:%w", op, err)
+	}
+
+	return user.toModel(), nil
+}
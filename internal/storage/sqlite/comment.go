@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"fmt"
+)
+
+func (s Storage) InsertComment(ctx context.Context, comment models.Comment) (int64, error) {
+	const op = "storage.sqlite.InsertComment"
+
+	query := `INSERT INTO task_comments (task_id, user_id, body, created_at) VALUES (?, ?, ?, ?)`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, comment.TaskID, comment.UserID, comment.Body, comment.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed insert comment %s:%w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed get comment id %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s Storage) SelectComments(ctx context.Context, taskID int64) ([]models.Comment, error) {
+	const op = "storage.sqlite.SelectComments"
+
+	query := `SELECT c.id, c.task_id, c.user_id, u.email, c.body, c.created_at
+		FROM task_comments c
+		JOIN users u ON u.id = c.user_id
+		WHERE c.task_id = ?
+		ORDER BY c.created_at, c.id`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select comments %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var comments []models.Comment
+	for rows.Next() {
+		var c models.Comment
+		if err = rows.Scan(&c.ID, &c.TaskID, &c.UserID, &c.Email, &c.Body, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed scan comment %s:%w", op, err)
+		}
+		comments = append(comments, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select comments %s:%w", op, err)
+	}
+
+	return comments, nil
+}
+
+// SelectCommentsByTaskIDs returns comments of all the tasks in one query
+func (s Storage) SelectCommentsByTaskIDs(ctx context.Context, taskIDs []int64) ([]models.Comment, error) {
+	const op = "storage.sqlite.SelectCommentsByTaskIDs"
+
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+
+	in, args := inPlaceholders(taskIDs)
+	query := `SELECT c.id, c.task_id, c.user_id, u.email, c.body, c.created_at
+		FROM task_comments c
+		JOIN users u ON u.id = c.user_id
+		WHERE c.task_id IN ` + in + `
+		ORDER BY c.created_at, c.id`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed select comments %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var comments []models.Comment
+	for rows.Next() {
+		var c models.Comment
+		if err = rows.Scan(&c.ID, &c.TaskID, &c.UserID, &c.Email, &c.Body, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed scan comment %s:%w", op, err)
+		}
+		comments = append(comments, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select comments %s:%w", op, err)
+	}
+
+	return comments, nil
+}
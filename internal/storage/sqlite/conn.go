@@ -0,0 +1,151 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options configure connections of the sqlite storage, empty values keep the defaults of the driver
+type Options struct {
+	// JournalMode is DELETE, TRUNCATE, PERSIST, MEMORY, WAL or OFF
+	JournalMode string
+	// Synchronous is OFF, NORMAL, FULL or EXTRA
+	Synchronous string
+	ForeignKeys bool
+	// BusyTimeout is how long a connection waits for the lock of another process
+	BusyTimeout time.Duration
+	// MaxReadConns limits the reader pool, writes always go through one connection
+	MaxReadConns int
+}
+
+var synchronousLevels = map[string]int{"OFF": 0, "NORMAL": 1, "FULL": 2, "EXTRA": 3}
+
+// pools sends queries outside of transactions to the reader pool and changes to the writer
+type pools struct {
+	writer *sql.DB
+	reader *sql.DB
+}
+
+func (p pools) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return p.writer.ExecContext(ctx, query, args...)
+}
+
+func (p pools) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return p.reader.QueryContext(ctx, query, args...)
+}
+
+func (p pools) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return p.reader.QueryRowContext(ctx, query, args...)
+}
+
+// PrepareContext prepares on the writer, the statement may change data
+func (p pools) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.writer.PrepareContext(ctx, query)
+}
+
+// openPools opens the single connection writer first, so the file exists in the journal mode before readers open it
+func openPools(path string, opts Options) (pools, error) {
+	if _, ok := synchronousLevels[strings.ToUpper(opts.Synchronous)]; opts.Synchronous != "" && !ok {
+		return pools{}, fmt.Errorf("unknown synchronous level %q", opts.Synchronous)
+	}
+
+	writer, err := sql.Open("sqlite3", dsn(path, opts, false))
+	if err != nil {
+		return pools{}, err
+	}
+	// sqlite has one writer at a time, waiting in the pool is cheaper than waiting for the file lock
+	writer.SetMaxOpenConns(1)
+	writer.SetMaxIdleConns(1)
+	if err = writer.Ping(); err != nil {
+		_ = writer.Close()
+		return pools{}, err
+	}
+
+	reader, err := sql.Open("sqlite3", dsn(path, opts, true))
+	if err != nil {
+		_ = writer.Close()
+		return pools{}, err
+	}
+	if opts.MaxReadConns > 0 {
+		reader.SetMaxOpenConns(opts.MaxReadConns)
+		reader.SetMaxIdleConns(opts.MaxReadConns)
+	}
+
+	return pools{writer: writer, reader: reader}, nil
+}
+
+// dsn passes the pragmas to the driver so every new connection of the pool gets them,
+// the writer starts transactions with BEGIN IMMEDIATE to take the lock before it reads
+func dsn(path string, opts Options, readOnly bool) string {
+	params := url.Values{}
+	params.Set("_foreign_keys", strconv.FormatBool(opts.ForeignKeys))
+	if opts.BusyTimeout > 0 {
+		params.Set("_busy_timeout", strconv.FormatInt(opts.BusyTimeout.Milliseconds(), 10))
+	}
+	if opts.Synchronous != "" {
+		params.Set("_synchronous", strings.ToUpper(opts.Synchronous))
+	}
+	if readOnly {
+		params.Set("_query_only", "true")
+	} else {
+		if opts.JournalMode != "" {
+			params.Set("_journal_mode", strings.ToUpper(opts.JournalMode))
+		}
+		params.Set("_txlock", "immediate")
+	}
+
+	return "file:" + path + "?" + params.Encode()
+}
+
+// checkPragmas verifies that connections of both pools got the configured pragmas,
+// e.g. WAL is refused on network file systems and the database silently stays in another mode
+func (p pools) checkPragmas(ctx context.Context, opts Options) error {
+	for _, pool := range []struct {
+		name     string
+		db       *sql.DB
+		readOnly bool
+	}{
+		{"writer", p.writer, false},
+		{"reader", p.reader, true},
+	} {
+		want := map[string]string{
+			"foreign_keys": boolPragma(opts.ForeignKeys),
+			"query_only":   boolPragma(pool.readOnly),
+		}
+		if opts.JournalMode != "" {
+			want["journal_mode"] = strings.ToLower(opts.JournalMode)
+		}
+		if opts.Synchronous != "" {
+			want["synchronous"] = strconv.Itoa(synchronousLevels[strings.ToUpper(opts.Synchronous)])
+		}
+		if opts.BusyTimeout > 0 {
+			want["busy_timeout"] = strconv.FormatInt(opts.BusyTimeout.Milliseconds(), 10)
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(want)) {
+			var got string
+			if err := pool.db.QueryRowContext(ctx, `PRAGMA `+name).Scan(&got); err != nil {
+				return fmt.Errorf("failed read pragma %s: %w", name, err)
+			}
+			if !strings.EqualFold(got, want[name]) {
+				return fmt.Errorf("%s connection has %s = %s, want %s", pool.name, name, got, want[name])
+			}
+		}
+	}
+
+	return nil
+}
+
+func boolPragma(v bool) string {
+	if v {
+		return "1"
+	}
+	return "0"
+}
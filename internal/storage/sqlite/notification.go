@@ -0,0 +1,247 @@
+package sqlite
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// InsertWatcher subscribes the user to the task, watching twice is not an error
+func (s Storage) InsertWatcher(ctx context.Context, taskID int64, userID int64) error {
+	const op = "storage.sqlite.InsertWatcher"
+
+	query := `INSERT OR IGNORE INTO task_watchers (task_id, user_id, created_at) VALUES (?, ?, ?)`
+
+	if _, err := s.conn(ctx).ExecContext(ctx, query, taskID, userID, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed insert watcher %s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s Storage) DeleteWatcher(ctx context.Context, taskID int64, userID int64) error {
+	const op = "storage.sqlite.DeleteWatcher"
+
+	query := `DELETE FROM task_watchers WHERE task_id = ? AND user_id = ?`
+
+	if _, err := s.conn(ctx).ExecContext(ctx, query, taskID, userID); err != nil {
+		return fmt.Errorf("failed delete watcher %s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s Storage) DeleteWatchers(ctx context.Context, taskID int64) error {
+	const op = "storage.sqlite.DeleteWatchers"
+
+	if _, err := s.conn(ctx).ExecContext(ctx, `DELETE FROM task_watchers WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("failed delete watchers %s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s Storage) SelectWatchers(ctx context.Context, taskID int64) ([]models.Watcher, error) {
+	const op = "storage.sqlite.SelectWatchers"
+
+	query := `SELECT w.task_id, w.user_id, u.email, w.created_at
+		FROM task_watchers w
+		JOIN users u ON u.id = w.user_id
+		WHERE w.task_id = ?
+		ORDER BY w.created_at`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select watchers %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var watchers []models.Watcher
+	for rows.Next() {
+		var w models.Watcher
+		if err = rows.Scan(&w.TaskID, &w.UserID, &w.Email, &w.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed scan watcher %s:%w", op, err)
+		}
+		watchers = append(watchers, w)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select watchers %s:%w", op, err)
+	}
+
+	return watchers, nil
+}
+
+func (s Storage) InsertNotification(ctx context.Context, n models.Notification) (int64, error) {
+	const op = "storage.sqlite.InsertNotification"
+
+	query := `INSERT INTO notifications (user_id, task_id, actor_id, event, message, created_at) VALUES (?, ?, ?, ?, ?, ?)`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, n.UserID, n.TaskID, n.ActorID, string(n.Event), n.Message, n.CreatedAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed insert notification %s:%w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed get notification id %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+// SelectNotifications returns the newest notifications of the user first
+func (s Storage) SelectNotifications(ctx context.Context, userID int64, unreadOnly bool, limit int) ([]models.Notification, error) {
+	const op = "storage.sqlite.SelectNotifications"
+
+	query := `SELECT id, user_id, task_id, actor_id, event, message, read_at, created_at
+		FROM notifications
+		WHERE user_id = ? AND (? = 0 OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed select notifications %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var res []models.Notification
+	for rows.Next() {
+		var (
+			n      models.Notification
+			event  string
+			readAt sql.NullTime
+		)
+		if err = rows.Scan(&n.ID, &n.UserID, &n.TaskID, &n.ActorID, &event, &n.Message, &readAt, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed scan notification %s:%w", op, err)
+		}
+		n.Event = models.EventType(event)
+		n.ReadAt = timeFromNull(readAt)
+		res = append(res, n)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select notifications %s:%w", op, err)
+	}
+
+	return res, nil
+}
+
+func (s Storage) CountUnreadNotifications(ctx context.Context, userID int64) (int, error) {
+	const op = "storage.sqlite.CountUnreadNotifications"
+	var count int
+
+	query := `SELECT count(*) FROM notifications WHERE user_id = ? AND read_at IS NULL`
+
+	if err := s.conn(ctx).QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed count notifications %s:%w", op, err)
+	}
+
+	return count, nil
+}
+
+// UpdateNotificationRead marks the notification read or unread, read time of already read notification is kept
+func (s Storage) UpdateNotificationRead(ctx context.Context, id int64, userID int64, read bool) error {
+	const op = "storage.sqlite.UpdateNotificationRead"
+
+	query := `UPDATE notifications SET read_at = CASE WHEN ? THEN COALESCE(read_at, ?) END WHERE id = ? AND user_id = ?`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, read, time.Now().UTC(), id, userID)
+	if err != nil {
+		return fmt.Errorf("failed update notification %s:%w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed update notification %s:%w", op, err)
+	}
+	if n == 0 {
+		return models.ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+func (s Storage) MarkAllNotificationsRead(ctx context.Context, userID int64) (int64, error) {
+	const op = "storage.sqlite.MarkAllNotificationsRead"
+
+	query := `UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, time.Now().UTC(), userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed update notifications %s:%w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed update notifications %s:%w", op, err)
+	}
+
+	return n, nil
+}
+
+func (s Storage) SelectNotificationPreferences(ctx context.Context, userID int64) (models.NotificationPreferences, error) {
+	const op = "storage.sqlite.SelectNotificationPreferences"
+
+	query := `SELECT event, enabled FROM notification_preferences WHERE user_id = ?`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select preferences %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	prefs := make(models.NotificationPreferences)
+	for rows.Next() {
+		var (
+			event   string
+			enabled bool
+		)
+		if err = rows.Scan(&event, &enabled); err != nil {
+			return nil, fmt.Errorf("failed scan preference %s:%w", op, err)
+		}
+		prefs[models.EventType(event)] = enabled
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select preferences %s:%w", op, err)
+	}
+
+	return prefs, nil
+}
+
+func (s Storage) UpsertNotificationPreferences(ctx context.Context, userID int64, prefs models.NotificationPreferences) error {
+	const op = "storage.sqlite.UpsertNotificationPreferences"
+
+	query := `INSERT INTO notification_preferences (user_id, event, enabled) VALUES (?, ?, ?)
+		ON CONFLICT (user_id, event) DO UPDATE SET enabled = excluded.enabled`
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for event, enabled := range prefs {
+		if _, err = tx.ExecContext(ctx, query, userID, string(event), enabled); err != nil {
+			return fmt.Errorf("failed upsert preference %s:%w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
+}
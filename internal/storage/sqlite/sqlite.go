@@ -1,27 +1,146 @@
 package sqlite
 
 import (
+	"TaskList/internal/lib/migrator"
+	"TaskList/internal/lib/txmanager"
+	"TaskList/migrations"
+	"context"
 	"database/sql"
-	_ "github.com/mattn/go-sqlite3"
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+	"github.com/pressly/goose/v3"
+	"strings"
 )
 
 type Storage struct {
-	db *sql.DB
+	pools pools
+	txm   *txmanager.Manager
+	stmts *stmtCache
 }
 
-func New(storagePath string) (*Storage, error) {
-	db, err := sql.Open("sqlite3", storagePath)
+// New opens the writer and the reader pools and checks that the pragmas took effect
+func New(storagePath string, opts Options) (*Storage, error) {
+	const op = "storage.sqlite.New"
+
+	p, err := openPools(storagePath, opts)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed open %s:%w", op, err)
+	}
+
+	if err = p.checkPragmas(context.Background(), opts); err != nil {
+		_ = p.reader.Close()
+		_ = p.writer.Close()
+		return nil, fmt.Errorf("failed check pragmas %s:%w", op, err)
 	}
 
-	return &Storage{db: db}, nil
+	return &Storage{pools: p, txm: txmanager.New(p.writer, isBusy), stmts: &stmtCache{}}, nil
+}
+
+// WithinTx runs fn in one transaction, storage methods called with the ctx given to fn join it.
+// The statements are prepared before the transaction takes the writer connection
+func (s Storage) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "storage.sqlite.WithinTx"
+
+	if !s.txm.InTx(ctx) {
+		if _, err := s.stmts.get(ctx, s.pools); err != nil {
+			return fmt.Errorf("failed prepare statements %s:%w", op, err)
+		}
+	}
+	return s.txm.WithinTx(ctx, fn)
+}
+
+// AfterCommit runs fn after the transaction of the ctx is committed, at once outside of a transaction
+func (s Storage) AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	s.txm.AfterCommit(ctx, fn)
+}
+
+// conn returns the transaction of the context, outside of it reads go to the reader pool
+func (s Storage) conn(ctx context.Context) txmanager.Querier {
+	if s.txm.InTx(ctx) {
+		return s.txm.Conn(ctx)
+	}
+	return s.pools
+}
+
+// isBusy reports errors of concurrent writers, the transaction may succeed when it is run again
+func isBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked)
 }
 
 func (s Storage) Ping() error {
-	return s.db.Ping()
+	if err := s.pools.writer.Ping(); err != nil {
+		return err
+	}
+	return s.pools.reader.Ping()
 }
 
 func (s Storage) Close() error {
-	return s.db.Close()
+	// statements are closed before the pools they are prepared on
+	return errors.Join(s.stmts.close(), s.pools.reader.Close(), s.pools.writer.Close())
+}
+
+// Migrator applies the migrations embedded from the migrations directory
+func (s Storage) Migrator() (*migrator.Migrator, error) {
+	return migrator.New(s.pools.writer, goose.DialectSQLite3, migrations.FS)
+}
+
+// Check runs the integrity and foreign key checks of sqlite and returns the problems found
+func (s Storage) Check(ctx context.Context) ([]string, error) {
+	const op = "storage.sqlite.Check"
+
+	var problems []string
+
+	rows, err := s.pools.reader.QueryContext(ctx, `PRAGMA integrity_check`)
+	if err != nil {
+		return nil, fmt.Errorf("failed check integrity %s:%w", op, err)
+	}
+	for rows.Next() {
+		var msg string
+		if err = rows.Scan(&msg); err != nil {
+			_ = rows.Close()
+			return nil, fmt.Errorf("failed check integrity %s:%w", op, err)
+		}
+		if msg != "ok" {
+			problems = append(problems, "integrity: "+msg)
+		}
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed check integrity %s:%w", op, err)
+	}
+
+	rows, err = s.pools.reader.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return nil, fmt.Errorf("failed check foreign keys %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	for rows.Next() {
+		var (
+			table, parent string
+			rowID         sql.NullInt64
+			fk            int64
+		)
+		if err = rows.Scan(&table, &rowID, &parent, &fk); err != nil {
+			return nil, fmt.Errorf("failed check foreign keys %s:%w", op, err)
+		}
+		problems = append(problems, fmt.Sprintf("foreign key: %s row %d references missing %s", table, rowID.Int64, parent))
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed check foreign keys %s:%w", op, err)
+	}
+
+	return problems, nil
+}
+
+// inPlaceholders returns "(?, ?, ...)" and args for the ids, ids must not be empty
+func inPlaceholders(ids []int64) (string, []any) {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return `(?` + strings.Repeat(`, ?`, len(ids)-1) + `)`, args
 }
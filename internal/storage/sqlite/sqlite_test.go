@@ -0,0 +1,191 @@
+package sqlite
+
+import (
+	"TaskList/internal/models"
+	"TaskList/internal/storage/storagetest"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestStorage opens a migrated database in a temp dir with the options of the default config
+func newTestStorage(t testing.TB) *Storage {
+	t.Helper()
+
+	s, err := New(filepath.Join(t.TempDir(), "tasklist.db"), Options{
+		JournalMode: "WAL",
+		Synchronous: "NORMAL",
+		ForeignKeys: true,
+		BusyTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+
+	m, err := s.Migrator()
+	if err != nil {
+		t.Fatalf("Migrator() error = %v", err)
+	}
+	if _, err = m.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	return s
+}
+
+func TestContract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return newTestStorage(t)
+	})
+}
+
+// the first write of a fresh storage may come in a transaction, which holds the only writer connection
+func TestWithinTxPreparesStatements(t *testing.T) {
+	s := newTestStorage(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		id, err := s.CreateUser(ctx, "a@example.com", []byte("hash"))
+		if err != nil {
+			return err
+		}
+		_, err = s.InsertTask(ctx, models.Task{UserID: id, Title: "first"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithinTx() error = %v", err)
+	}
+}
+
+// a transaction begun past WithinTx must fail instead of waiting for the connection it holds
+func TestStatementsNotPreparedInTx(t *testing.T) {
+	s := newTestStorage(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := s.txm.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := s.UserByEmail(ctx, "a@example.com")
+		return err
+	})
+	if !errors.Is(err, errNotPrepared) {
+		t.Fatalf("WithinTx() error = %v, want %v", err, errNotPrepared)
+	}
+}
+
+// benchStorage is a storage with one user owning n tasks with tags
+func benchStorage(b *testing.B, n int) (*Storage, int64) {
+	s := newTestStorage(b)
+	ctx := context.Background()
+
+	userID, err := s.CreateUser(ctx, "a@example.com", []byte("hash"))
+	if err != nil {
+		b.Fatalf("CreateUser() error = %v", err)
+	}
+	for i := range n {
+		task := models.Task{UserID: userID, Title: fmt.Sprintf("task %d", i), Tags: []string{"home", "work"}}
+		if _, err = s.InsertTask(ctx, task); err != nil {
+			b.Fatalf("InsertTask() error = %v", err)
+		}
+	}
+
+	return s, userID
+}
+
+// BenchmarkQueries compares the statements prepared once with the preparation on every call
+func BenchmarkQueries(b *testing.B) {
+	s, userID := benchStorage(b, 50)
+	ctx := context.Background()
+
+	st, err := s.statements(ctx)
+	if err != nil {
+		b.Fatalf("statements() error = %v", err)
+	}
+
+	for _, q := range []struct {
+		name     string
+		query    string
+		prepared *sql.Stmt
+		arg      any
+	}{
+		{"UserByEmail", userByEmailQuery, st.userByEmail.reader, "a@example.com"},
+		{"UserByID", userByIDQuery, st.userByID.reader, userID},
+		{"SelectTasksByUserID", taskSelect + ` WHERE t.user_id = ?`, st.selectTasksByUserID.reader, userID},
+		{"SelectTagsByUserID", tagsQuery(`t.user_id = ?`), st.selectTagsByUserID.reader, userID},
+	} {
+		b.Run(q.name+"/prepared", func(b *testing.B) {
+			for range b.N {
+				rows, err := q.prepared.QueryContext(ctx, q.arg)
+				if err != nil {
+					b.Fatal(err)
+				}
+				drain(b, rows)
+			}
+		})
+		b.Run(q.name+"/per-call", func(b *testing.B) {
+			for range b.N {
+				prepared, err := s.pools.reader.PrepareContext(ctx, q.query)
+				if err != nil {
+					b.Fatal(err)
+				}
+				rows, err := prepared.QueryContext(ctx, q.arg)
+				if err != nil {
+					b.Fatal(err)
+				}
+				drain(b, rows)
+				_ = prepared.Close()
+			}
+		})
+	}
+}
+
+func drain(b *testing.B, rows *sql.Rows) {
+	for rows.Next() {
+	}
+	if err := rows.Err(); err != nil {
+		b.Fatal(err)
+	}
+	_ = rows.Close()
+}
+
+func BenchmarkSelectAllTasksByUserID(b *testing.B) {
+	s, userID := benchStorage(b, 50)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for range b.N {
+		if _, err := s.SelectAllTasksByUserID(ctx, userID); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInsertTask(b *testing.B) {
+	s, userID := benchStorage(b, 0)
+	ctx := context.Background()
+	task := models.Task{UserID: userID, Title: "benchmark", Tags: []string{"home", "work"}}
+
+	b.ResetTimer()
+	for range b.N {
+		if _, err := s.InsertTask(ctx, task); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUserByEmail(b *testing.B) {
+	s, _ := benchStorage(b, 0)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for range b.N {
+		if _, err := s.UserByEmail(ctx, "a@example.com"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// stmt is prepared once for the pool its query goes to. Reads are prepared on the writer too,
+// a transaction can only use statements of its own database
+type stmt struct {
+	reader *sql.Stmt
+	writer *sql.Stmt
+}
+
+// statements are the queries run on every request, the rest of the queries are prepared by the driver per call
+type statements struct {
+	createUser                stmt
+	userByEmail               stmt
+	userByID                  stmt
+	insertTask                stmt
+	insertTag                 stmt
+	upsertFieldValue          stmt
+	selectTasksByUserID       stmt
+	selectTagsByUserID        stmt
+	selectFieldValuesByUserID stmt
+}
+
+// errNotPrepared is returned in a transaction begun before the statements were prepared,
+// they can not be prepared on the writer pool while the transaction holds its only connection
+var errNotPrepared = errors.New("statements are not prepared")
+
+// stmtCache prepares the statements on the first use outside of a transaction, the tables may not exist
+// before the migrations are applied. A failed preparation is tried again by the next call
+type stmtCache struct {
+	mu     sync.Mutex
+	st     *statements
+	closed bool
+}
+
+func (c *stmtCache) get(ctx context.Context, p pools) (*statements, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.st != nil {
+		return c.st, nil
+	}
+	if c.closed {
+		return nil, sql.ErrConnDone
+	}
+
+	st, err := prepareStatements(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	c.st = st
+
+	return st, nil
+}
+
+// prepared returns the statements without preparing them
+func (c *stmtCache) prepared() (*statements, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.st == nil {
+		return nil, errNotPrepared
+	}
+	return c.st, nil
+}
+
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	if c.st == nil {
+		return nil
+	}
+	return c.st.close()
+}
+
+func prepareStatements(ctx context.Context, p pools) (*statements, error) {
+	var st statements
+
+	for _, q := range []struct {
+		dst   *stmt
+		query string
+		read  bool
+	}{
+		{&st.createUser, createUserQuery, false},
+		{&st.userByEmail, userByEmailQuery, true},
+		{&st.userByID, userByIDQuery, true},
+		{&st.insertTask, insertTaskQuery, false},
+		{&st.insertTag, insertTagQuery, false},
+		{&st.upsertFieldValue, upsertFieldValueQuery, false},
+		{&st.selectTasksByUserID, taskSelect + ` WHERE t.user_id = ?`, true},
+		{&st.selectTagsByUserID, tagsQuery(`t.user_id = ?`), true},
+		{&st.selectFieldValuesByUserID, fieldValuesQuery(`t.user_id = ?`), true},
+	} {
+		var err error
+		if q.dst.writer, err = p.writer.PrepareContext(ctx, q.query); err != nil {
+			_ = st.close()
+			return nil, fmt.Errorf("failed prepare %q: %w", q.query, err)
+		}
+		if !q.read {
+			continue
+		}
+		if q.dst.reader, err = p.reader.PrepareContext(ctx, q.query); err != nil {
+			_ = st.close()
+			return nil, fmt.Errorf("failed prepare %q: %w", q.query, err)
+		}
+	}
+
+	return &st, nil
+}
+
+func (st *statements) close() error {
+	var errs []error
+	for _, s := range []stmt{
+		st.createUser,
+		st.userByEmail,
+		st.userByID,
+		st.insertTask,
+		st.insertTag,
+		st.upsertFieldValue,
+		st.selectTasksByUserID,
+		st.selectTagsByUserID,
+		st.selectFieldValuesByUserID,
+	} {
+		for _, prepared := range []*sql.Stmt{s.reader, s.writer} {
+			if prepared != nil {
+				errs = append(errs, prepared.Close())
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Prepare prepares the statements run on every request, serve calls it at startup
+// so a query broken by the schema fails the start instead of requests
+func (s Storage) Prepare(ctx context.Context) error {
+	const op = "storage.sqlite.Prepare"
+
+	if _, err := s.stmts.get(ctx, s.pools); err != nil {
+		return fmt.Errorf("failed prepare statements %s:%w", op, err)
+	}
+
+	return nil
+}
+
+// statements returns the prepared statements, in a transaction only the ones prepared before it
+func (s Storage) statements(ctx context.Context) (*statements, error) {
+	if s.txm.InTx(ctx) {
+		return s.stmts.prepared()
+	}
+	return s.stmts.get(ctx, s.pools)
+}
+
+// bind returns the statement bound to the transaction of the context,
+// outside of it reads go to the reader pool like queries of conn
+func (s Storage) bind(ctx context.Context, st stmt) *sql.Stmt {
+	if s.txm.InTx(ctx) {
+		return s.txm.Stmt(ctx, st.writer)
+	}
+	if st.reader != nil {
+		return st.reader
+	}
+	return st.writer
+}
@@ -0,0 +1,191 @@
+package sqlite
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SelectTaskChanges returns tasks changed after the version ordered by their latest change.
+// Only tasks the user may see are returned: owned, assigned, shared, in a workspace of the user
+// or shared and unshared after the version, deleted tasks are matched by their tombstone
+func (s Storage) SelectTaskChanges(ctx context.Context, userID int64, after int64, limit int) ([]models.TaskChange, error) {
+	const op = "storage.sqlite.SelectTaskChanges"
+
+	query := `SELECT c.task_id,
+			MAX(c.seq),
+			MAX(c.field = 'deleted'),
+			COALESCE(GROUP_CONCAT(CASE WHEN c.field = 'access' THEN c.user_id END), '')
+		FROM task_changes c
+		LEFT JOIN tasks t ON t.id = c.task_id
+		LEFT JOIN task_tombstones tt ON tt.task_id = c.task_id
+		WHERE c.seq > ?1
+			AND (COALESCE(t.user_id, tt.user_id) = ?2
+				OR COALESCE(t.assignee_id, tt.assignee_id) = ?2
+				OR EXISTS (SELECT 1 FROM task_shares ts WHERE ts.task_id = c.task_id AND ts.user_id = ?2)
+				OR EXISTS (SELECT 1 FROM projects p
+					JOIN workspace_members wm ON wm.workspace_id = p.workspace_id
+					WHERE p.id = COALESCE(t.project_id, tt.project_id) AND wm.user_id = ?2)
+				OR EXISTS (SELECT 1 FROM task_changes a
+					WHERE a.task_id = c.task_id AND a.field = 'access' AND a.user_id = ?2 AND a.seq > ?1))
+		GROUP BY c.task_id
+		ORDER BY MAX(c.seq)
+		LIMIT ?3`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, after, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed select changes %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var changes []models.TaskChange
+	for rows.Next() {
+		var (
+			c      models.TaskChange
+			access string
+		)
+		if err = rows.Scan(&c.TaskID, &c.Version, &c.Deleted, &access); err != nil {
+			return nil, fmt.Errorf("failed scan change %s:%w", op, err)
+		}
+		for _, id := range strings.Split(access, ",") {
+			if id == "" {
+				continue
+			}
+			userID, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed parse user id %s:%w", op, err)
+			}
+			c.AccessChanged = append(c.AccessChanged, userID)
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
+
+// SelectTaskVersions returns version of every field of the task,
+// fields which did not change since creation have the version of creation
+func (s Storage) SelectTaskVersions(ctx context.Context, taskID int64) (models.TaskVersions, error) {
+	const op = "storage.sqlite.SelectTaskVersions"
+
+	query := `SELECT field, MAX(seq) FROM task_changes
+		WHERE task_id = ? AND field NOT IN ('access', 'deleted')
+		GROUP BY field`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select versions %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var created int64
+	changed := make(map[string]int64)
+	for rows.Next() {
+		var (
+			field   string
+			version int64
+		)
+		if err = rows.Scan(&field, &version); err != nil {
+			return nil, fmt.Errorf("failed scan version %s:%w", op, err)
+		}
+		if field == "created" {
+			created = version
+			continue
+		}
+		changed[field] = version
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select versions %s:%w", op, err)
+	}
+
+	versions := make(models.TaskVersions)
+	for _, f := range models.TaskFields() {
+		versions[f] = max(created, changed[string(f)])
+	}
+
+	return versions, nil
+}
+
+// SelectTombstone returns the deleted task with the owner, project and assignee it had
+func (s Storage) SelectTombstone(ctx context.Context, taskID int64) (models.Task, error) {
+	const op = "storage.sqlite.SelectTombstone"
+
+	query := `SELECT tt.task_id, tt.user_id, tt.project_id, tt.assignee_id,
+			(SELECT p.workspace_id FROM projects p WHERE p.id = tt.project_id)
+		FROM task_tombstones tt
+		WHERE tt.task_id = ?`
+
+	var (
+		task                               models.Task
+		projectID, assigneeID, workspaceID sql.NullInt64
+	)
+	err := s.conn(ctx).QueryRowContext(ctx, query, taskID).Scan(&task.ID, &task.UserID, &projectID, &assigneeID, &workspaceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Task{}, models.ErrTaskNotFound
+		}
+		return models.Task{}, fmt.Errorf("failed select tombstone %s:%w", op, err)
+	}
+
+	task.ProjectID = int64FromNull(projectID)
+	task.AssigneeID = int64FromNull(assigneeID)
+	task.WorkspaceID = int64FromNull(workspaceID)
+
+	return task, nil
+}
+
+// LastTaskChange returns the version of the latest change, 0 when there were no changes
+func (s Storage) LastTaskChange(ctx context.Context) (int64, error) {
+	const op = "storage.sqlite.LastTaskChange"
+	var seq int64
+
+	if err := s.conn(ctx).QueryRowContext(ctx, `SELECT COALESCE(MAX(seq), 0) FROM task_changes`).Scan(&seq); err != nil {
+		return 0, fmt.Errorf("failed select last change %s:%w", op, err)
+	}
+
+	return seq, nil
+}
+
+// SelectSyncClientTask returns the task created by the user from the client id
+func (s Storage) SelectSyncClientTask(ctx context.Context, userID int64, clientID string) (int64, error) {
+	const op = "storage.sqlite.SelectSyncClientTask"
+	var taskID int64
+
+	query := `SELECT task_id FROM sync_client_ids WHERE user_id = ? AND client_id = ?`
+
+	if err := s.conn(ctx).QueryRowContext(ctx, query, userID, clientID).Scan(&taskID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, models.ErrTaskNotFound
+		}
+		return 0, fmt.Errorf("failed select client task %s:%w", op, err)
+	}
+
+	return taskID, nil
+}
+
+// InsertSyncClientTask maps the client id to the task, ErrSyncClientExists if the id is mapped already
+func (s Storage) InsertSyncClientTask(ctx context.Context, userID int64, clientID string, taskID int64) error {
+	const op = "storage.sqlite.InsertSyncClientTask"
+
+	query := `INSERT INTO sync_client_ids (user_id, client_id, task_id, created_at) VALUES (?, ?, ?, ?)`
+
+	if _, err := s.conn(ctx).ExecContext(ctx, query, userID, clientID, taskID, time.Now().UTC()); err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && errors.Is(sqliteErr.ExtendedCode, sqlite3.ErrConstraintPrimaryKey) {
+			return models.ErrSyncClientExists
+		}
+		return fmt.Errorf("failed insert client task %s:%w", op, err)
+	}
+
+	return nil
+}
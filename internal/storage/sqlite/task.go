@@ -1,49 +1,285 @@
 package sqlite
 
 import (
+	"TaskList/internal/lib/txmanager"
 	"TaskList/internal/models"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 )
 
 type Task struct {
-	ID          int64     `db:"id"`
-	UserID      int64     `db:"user_id"`
-	Title       string    `db:"task_name"`
-	Description string    `db:"description"`
-	Status      string    `db:"status"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	ID          int64          `db:"id"`
+	UserID      int64          `db:"user_id"`
+	CreatedBy   int64          `db:"created_by"`
+	AssigneeID  sql.NullInt64  `db:"assignee_id"`
+	ProjectID   sql.NullInt64  `db:"project_id"`
+	WorkspaceID sql.NullInt64  `db:"workspace_id"`
+	Title       string         `db:"task_name"`
+	Description sql.NullString `db:"description"`
+	Status      string         `db:"status"`
+	Category    sql.NullString `db:"category"`
+	Priority    string         `db:"priority"`
+	Recurrence  string         `db:"recurrence"`
+	DueAt       sql.NullTime   `db:"due_at"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
 }
 
-func (s Storage) UpdateStatusTask(ctx context.Context, taskID int64, userID int64) error {
-	//TODO implement me
-	panic("implement me")
+// taskSelect selects tasks with category of their status,
+// status defined for the project has priority over the status defined for all user projects
+const taskSelect = `SELECT
+		t.id,
+		t.user_id,
+		COALESCE(t.created_by, t.user_id) AS created_by,
+		t.assignee_id,
+		t.project_id,
+		(SELECT p.workspace_id FROM projects p WHERE p.id = t.project_id) AS workspace_id,
+		t.task_name,
+		t.description,
+		t.status,
+		(SELECT w.category FROM workflow_statuses w
+			WHERE w.user_id = t.user_id
+			  AND w.name = t.status
+			  AND (w.project_id = t.project_id OR w.project_id IS NULL)
+			ORDER BY w.project_id IS NULL
+			LIMIT 1) AS category,
+		t.priority,
+		t.recurrence,
+		t.due_at,
+		t.created_at,
+		t.updated_at
+	FROM tasks t`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row scanner) (Task, error) {
+	var task Task
+	err := row.Scan(
+		&task.ID,
+		&task.UserID,
+		&task.CreatedBy,
+		&task.AssigneeID,
+		&task.ProjectID,
+		&task.WorkspaceID,
+		&task.Title,
+		&task.Description,
+		&task.Status,
+		&task.Category,
+		&task.Priority,
+		&task.Recurrence,
+		&task.DueAt,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+	)
+	return task, err
+}
+
+func (t Task) toModel(tags []string, fields []models.FieldValue) models.Task {
+	return models.Task{
+		ID:             t.ID,
+		UserID:         t.UserID,
+		CreatedBy:      t.CreatedBy,
+		AssigneeID:     int64FromNull(t.AssigneeID),
+		ProjectID:      int64FromNull(t.ProjectID),
+		WorkspaceID:    int64FromNull(t.WorkspaceID),
+		Title:          t.Title,
+		Description:    t.Description.String,
+		Status:         models.Status(t.Status),
+		StatusCategory: statusCategory(models.Status(t.Status), t.Category),
+		Priority:       models.Priority(t.Priority),
+		Recurrence:     t.Recurrence,
+		Tags:           tags,
+		CustomFields:   fields,
+		DueAt:          timeFromNull(t.DueAt),
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.UpdatedAt,
+	}
+}
+
+func (s Storage) UpdateStatusTask(ctx context.Context, taskID int64, userID int64, status models.Status) error {
+	const op = "storage.sqlite.UpdateStatusTask"
+
+	query := `UPDATE tasks SET status = ?, updated_at = ? WHERE id = ? AND user_id = ?`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, string(status), time.Now().UTC(), taskID, userID)
+	if err != nil {
+		return fmt.Errorf("failed update status %s:%w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed update status %s:%w", op, err)
+	}
+	if n == 0 {
+		return models.ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// UpdateTask stores title, description, priority, recurrence, due date and tags of the task
+func (s Storage) UpdateTask(ctx context.Context, task models.Task) error {
+	const op = "storage.sqlite.UpdateTask"
+
+	query := `UPDATE tasks SET task_name = ?, description = ?, priority = ?, recurrence = ?, due_at = ?, updated_at = ?
+		WHERE id = ?`
+
+	st, err := s.statements(ctx)
+	if err != nil {
+		return fmt.Errorf("failed prepare statements %s:%w", op, err)
+	}
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(
+		ctx,
+		query,
+		task.Title,
+		task.Description,
+		string(task.Priority),
+		task.Recurrence,
+		nullTime(task.DueAt),
+		time.Now().UTC(),
+		task.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed update task %s:%w", op, err)
+	}
+	if err = affectedOrNotFound(res, models.ErrTaskNotFound, op); err != nil {
+		return err
+	}
+
+	// only removed tags are deleted, so unchanged tags do not get into the change log
+	args := []any{task.ID}
+	query = `DELETE FROM task_tags WHERE task_id = ?`
+	if len(task.Tags) > 0 {
+		query += ` AND tag NOT IN (?` + strings.Repeat(`, ?`, len(task.Tags)-1) + `)`
+		for _, tag := range task.Tags {
+			args = append(args, tag)
+		}
+	}
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed delete tags %s:%w", op, err)
+	}
+	if err = insertTags(ctx, tx, st.insertTag, task.ID, task.Tags); err != nil {
+		return fmt.Errorf("failed insert tags %s:%w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
 }
 
+// DeleteTask deletes the task with its tags, field values, shares and comments,
+// watchers are removed by notifications when they handle the deletion
+func (s Storage) DeleteTask(ctx context.Context, taskID int64) error {
+	const op = "storage.sqlite.DeleteTask"
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM tasks WHERE id = ?`, taskID)
+	if err != nil {
+		return fmt.Errorf("failed delete task %s:%w", op, err)
+	}
+	if err = affectedOrNotFound(res, models.ErrTaskNotFound, op); err != nil {
+		return err
+	}
+
+	for _, query := range []string{
+		`DELETE FROM task_tags WHERE task_id = ?`,
+		`DELETE FROM task_field_values WHERE task_id = ?`,
+		`DELETE FROM task_shares WHERE task_id = ?`,
+		`DELETE FROM task_comments WHERE task_id = ?`,
+	} {
+		if _, err = tx.ExecContext(ctx, query, taskID); err != nil {
+			return fmt.Errorf("failed delete task data %s:%w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
+}
+
+// CountTasksByStatus counts tasks in the board column of the project, nil project means tasks without project
+func (s Storage) CountTasksByStatus(ctx context.Context, userID int64, projectID *int64, status models.Status) (int, error) {
+	const op = "storage.sqlite.CountTasksByStatus"
+	var count int
+
+	query := `SELECT count(*) FROM tasks WHERE user_id = ? AND status = ? AND project_id IS ?`
+
+	err := s.conn(ctx).QueryRowContext(ctx, query, userID, string(status), nullInt64(projectID)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed count tasks %s:%w", op, err)
+	}
+
+	return count, nil
+}
+
+const insertTaskQuery = `INSERT INTO tasks (user_id, created_by, assignee_id, project_id, task_name, description, status, priority, recurrence, due_at, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
 func (s Storage) InsertTask(ctx context.Context, task models.Task) (int64, error) {
 	const op = "storage.sqlite.InsertTask"
 	var id int64
 
-	query := `INSERT INTO tasks (user_id, task_name, description, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?)`
-
-	stmt, err := s.db.PrepareContext(ctx, query)
+	st, err := s.statements(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed prepare query %s:%w", op, err)
+		return 0, fmt.Errorf("failed prepare statements %s:%w", op, err)
 	}
 
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
 	defer func() {
-		//todo: возможно стоит залогировать, попробовать найти решение как обрабатывать такие ошибки
-		_ = stmt.Close()
+		_ = tx.Rollback()
 	}()
 
-	result, err := stmt.ExecContext(
+	status := task.Status
+	if status == "" {
+		status = models.Pending
+	}
+
+	createdBy := task.CreatedBy
+	if createdBy == 0 {
+		createdBy = task.UserID
+	}
+
+	result, err := tx.Stmt(ctx, st.insertTask.writer).ExecContext(
 		ctx,
 		task.UserID,
+		createdBy,
+		nullInt64(task.AssigneeID),
+		nullInt64(task.ProjectID),
 		task.Title,
 		task.Description,
+		string(status),
+		string(task.Priority),
+		task.Recurrence,
+		nullTime(task.DueAt),
 		time.Now().UTC(),
 		time.Now().UTC(),
 	)
@@ -56,77 +292,270 @@ func (s Storage) InsertTask(ctx context.Context, task models.Task) (int64, error
 		return 0, fmt.Errorf("failed create task %s:%w", op, err)
 	}
 
+	if err = insertTags(ctx, tx, st.insertTag, id, task.Tags); err != nil {
+		return 0, fmt.Errorf("failed insert tags %s:%w", op, err)
+	}
+
+	if err = upsertFieldValues(ctx, tx, st.upsertFieldValue, id, task.CustomFields); err != nil {
+		return 0, fmt.Errorf("failed insert custom fields %s:%w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
 	return id, nil
 }
 
+const insertTagQuery = `INSERT OR IGNORE INTO task_tags (task_id, tag) VALUES (?, ?)`
+
+// insertTags runs the prepared statement in the transaction, the bound statement is closed with it
+func insertTags(ctx context.Context, tx *txmanager.Tx, st stmt, taskID int64, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	insert := tx.Stmt(ctx, st.writer)
+	for _, tag := range tags {
+		if _, err := insert.ExecContext(ctx, taskID, tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// selectTagsByUserID returns tags of all user tasks grouped by task id
+func (s Storage) selectTagsByUserID(ctx context.Context, st *statements, userID int64) (map[int64][]string, error) {
+	rows, err := s.bind(ctx, st.selectTagsByUserID).QueryContext(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return scanTags(rows)
+}
+
+func (s Storage) selectTags(ctx context.Context, where string, args ...any) (map[int64][]string, error) {
+	rows, err := s.conn(ctx).QueryContext(ctx, tagsQuery(where), args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanTags(rows)
+}
+
+func tagsQuery(where string) string {
+	return `SELECT tt.task_id, tt.tag
+	FROM task_tags tt
+	JOIN tasks t ON t.id = tt.task_id
+	WHERE ` + where + `
+	ORDER BY tt.tag`
+}
+
+// scanTags reads all rows and closes them
+func scanTags(rows *sql.Rows) (map[int64][]string, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	tags := make(map[int64][]string)
+	for rows.Next() {
+		var (
+			taskID int64
+			tag    string
+		)
+		if err := rows.Scan(&taskID, &tag); err != nil {
+			return nil, err
+		}
+		tags[taskID] = append(tags[taskID], tag)
+	}
+
+	return tags, rows.Err()
+}
+
 func (s Storage) SelectAllTasksByUserID(ctx context.Context, userID int64) ([]models.Task, error) {
 	const op = "storage.sqlite.SelectAllTasksByUserID"
 
-	query := `SELECT
-		id,
-		user_id,
-		task_name,
-		description,
-		status,
-		created_at,
-		updated_at
-	FROM tasks
-	WHERE user_id = ?`
+	st, err := s.statements(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed prepare statements %s:%w", op, err)
+	}
 
-	var tasks []models.Task
-	var task Task
-	stmt, err := s.db.PrepareContext(ctx, query)
+	tags, err := s.selectTagsByUserID(ctx, st, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select tags %s:%w", op, err)
+	}
+
+	fields, err := s.selectFieldValuesByUserID(ctx, st, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed exec query %s:%w", op, err)
+		return nil, fmt.Errorf("failed select custom fields %s:%w", op, err)
 	}
-	//todo: не забыть обработать
-	defer stmt.Close()
 
-	rows, err := stmt.QueryContext(ctx, userID)
+	rows, err := s.bind(ctx, st.selectTasksByUserID).QueryContext(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed select tasks %s:%w", op, err)
 	}
+	defer func() {
+		_ = rows.Close()
+	}()
 
+	var tasks []models.Task
 	for rows.Next() {
-		err = rows.Scan(
-			&task.ID,
-			&task.UserID,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-		)
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed scan tasks %s:%w", op, err)
 		}
-		tasks = append(tasks, models.Task{
-			ID:          task.ID,
-			UserID:      task.UserID,
-			Title:       task.Title,
-			Description: task.Description,
-			Status:      statusInDBToStatusModel(task.Status),
-			CreatedAt:   task.CreatedAt,
-			UpdatedAt:   task.UpdatedAt,
-		})
+		tasks = append(tasks, task.toModel(tags[task.ID], fields[task.ID]))
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select tasks %s:%w", op, err)
+	}
+
+	return tasks, nil
+}
+
+// SelectTaskByID selects task regardless of the owner, access is checked by the caller
+func (s Storage) SelectTaskByID(ctx context.Context, taskID int64) (models.Task, error) {
+	const op = "storage.sqlite.SelectTaskByID"
+
+	query := taskSelect + ` WHERE t.id = ?`
+
+	task, err := scanTask(s.conn(ctx).QueryRowContext(ctx, query, taskID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Task{}, models.ErrTaskNotFound
+		}
+		return models.Task{}, fmt.Errorf("failed select task %s:%w", op, err)
+	}
+
+	tags, err := s.selectTags(ctx, `t.id = ?`, taskID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed select tags %s:%w", op, err)
+	}
+
+	fields, err := s.selectFieldValues(ctx, `t.id = ?`, taskID)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("failed select custom fields %s:%w", op, err)
+	}
+
+	return task.toModel(tags[task.ID], fields[task.ID]), nil
+}
+
+func (s Storage) SelectTasksAssignedTo(ctx context.Context, userID int64) ([]models.Task, error) {
+	const op = "storage.sqlite.SelectTasksAssignedTo"
+
+	tasks, err := s.selectTasks(ctx, `t.assignee_id = ?`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select assigned tasks %s:%w", op, err)
+	}
+	return tasks, nil
+}
+
+func (s Storage) SelectTasksSharedWith(ctx context.Context, userID int64) ([]models.Task, error) {
+	const op = "storage.sqlite.SelectTasksSharedWith"
+
+	tasks, err := s.selectTasks(ctx, `t.id IN (SELECT ts.task_id FROM task_shares ts WHERE ts.user_id = ?)`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select shared tasks %s:%w", op, err)
 	}
+	return tasks, nil
+}
 
+func (s Storage) SelectTasksByWorkspace(ctx context.Context, workspaceID int64) ([]models.Task, error) {
+	const op = "storage.sqlite.SelectTasksByWorkspace"
+
+	tasks, err := s.selectTasks(ctx, `t.project_id IN (SELECT p.id FROM projects p WHERE p.workspace_id = ?)`, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select workspace tasks %s:%w", op, err)
+	}
 	return tasks, nil
+}
 
+// selectTasks selects tasks with tags and custom fields, where is a condition on tasks aliased as t
+func (s Storage) selectTasks(ctx context.Context, where string, args ...any) ([]models.Task, error) {
+	tags, err := s.selectTags(ctx, where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := s.selectFieldValues(ctx, where, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.conn(ctx).QueryContext(ctx, taskSelect+` WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var tasks []models.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task.toModel(tags[task.ID], fields[task.ID]))
+	}
+
+	return tasks, rows.Err()
+}
+
+// UpdateTaskAssignee sets assignee of the task, nil removes assignee
+func (s Storage) UpdateTaskAssignee(ctx context.Context, taskID int64, assigneeID *int64) error {
+	const op = "storage.sqlite.UpdateTaskAssignee"
+
+	query := `UPDATE tasks SET assignee_id = ?, updated_at = ? WHERE id = ?`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, nullInt64(assigneeID), time.Now().UTC(), taskID)
+	if err != nil {
+		return fmt.Errorf("failed update assignee %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrTaskNotFound, op)
+}
+
+// statusCategory returns category found in user workflow,
+// statuses of the default workflow are resolved without stored workflow
+func statusCategory(status models.Status, category sql.NullString) models.StatusCategory {
+	if category.Valid {
+		return models.StatusCategory(category.String)
+	}
+
+	for _, ws := range models.DefaultWorkflow() {
+		if ws.Name == status {
+			return ws.Category
+		}
+	}
+
+	return models.CategoryTodo
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+func timeFromNull(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time.UTC()
+	return &v
 }
 
-func statusInDBToStatusModel(s string) models.Status {
-	switch s {
-	case "Pending":
-		return models.Pending
-	case "Done":
-		return models.Done
-	default:
-		return models.Pending
+func nullInt64(v *int64) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
 	}
+	return sql.NullInt64{Int64: *v, Valid: true}
 }
 
-func (s Storage) SelectTaskByID(ctx context.Context, taskID int64, userID int64) (models.Task, error) {
-	//TODO implement me
-	panic("implement me")
+func int64FromNull(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int64
 }
@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"github.com/mattn/go-sqlite3"
 	"time"
 )
@@ -13,51 +14,211 @@ type User struct {
 	ID           int64
 	Email        string
 	PasswordHash string
+	FeedToken    sql.NullString
+	Timezone     string
 	CreatedAt    time.Time
+	DisabledAt   sql.NullTime
 }
 
+const userColumns = `id, email, password_hash, feed_token, timezone, created_at, disabled_at`
+
+const (
+	createUserQuery  = `insert into users (email, password_hash, created_at) values (?,?,?)`
+	userByEmailQuery = `SELECT ` + userColumns + ` FROM users WHERE email = ?`
+	userByIDQuery    = `SELECT ` + userColumns + ` FROM users WHERE id = ?`
+)
+
 func (s Storage) CreateUser(ctx context.Context, email string, passHash []byte) (int64, error) {
-	q := `insert into users (email, password_hash, created_at) values (?,?,?)`
-	stmt, err := s.db.PrepareContext(ctx, q)
+	const op = "storage.sqlite.CreateUser"
+
+	st, err := s.statements(ctx)
 	if err != nil {
-		return 0, err
+		return 0, fmt.Errorf("failed prepare statements %s:%w", op, err)
 	}
 
-	exec, err := stmt.Exec(email, passHash, time.Now().UTC())
+	exec, err := s.bind(ctx, st.createUser).ExecContext(ctx, email, passHash, time.Now().UTC())
 	if err != nil {
 		var sqliteErr sqlite3.Error
 
 		if errors.As(err, &sqliteErr) && errors.Is(sqliteErr.ExtendedCode, sqlite3.ErrConstraintUnique) {
 			return 0, models.ErrUserAlreadyExists
 		}
-		return 0, err
+		return 0, fmt.Errorf("failed insert user %s:%w", op, err)
 	}
 
 	id, err := exec.LastInsertId()
 	if err != nil {
-		return 0, err
+		return 0, fmt.Errorf("failed insert user %s:%w", op, err)
 	}
 	return id, nil
 }
 
 func (s Storage) UserByEmail(ctx context.Context, email string) (*models.User, error) {
+	const op = "storage.sqlite.UserByEmail"
+	var user User
+
+	st, err := s.statements(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed prepare statements %s:%w", op, err)
+	}
+
+	if err = s.bind(ctx, st.userByEmail).QueryRowContext(ctx, email).Scan(user.dest()...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed select user %s:%w", op, err)
+	}
+
+	return user.toModel(), nil
+}
+
+func (s Storage) UserByFeedToken(ctx context.Context, token string) (*models.User, error) {
+	const op = "storage.sqlite.UserByFeedToken"
+	var user User
+
+	q := `SELECT ` + userColumns + ` FROM users WHERE feed_token = ?`
+	err := s.conn(ctx).QueryRowContext(ctx, q, token).Scan(user.dest()...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed select user %s:%w", op, err)
+	}
+
+	return user.toModel(), nil
+}
+
+func (s Storage) UserByID(ctx context.Context, userID int64) (*models.User, error) {
+	const op = "storage.sqlite.UserByID"
 	var user User
-	q := `SELECT * FROM users WHERE email = ?`
-	stmt, err := s.db.PrepareContext(ctx, q)
+
+	st, err := s.statements(ctx)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed prepare statements %s:%w", op, err)
 	}
-	if err := stmt.QueryRow(email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt); err != nil {
+
+	if err = s.bind(ctx, st.userByID).QueryRowContext(ctx, userID).Scan(user.dest()...); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, models.ErrUserNotFound
 		}
-		return nil, err
+		return nil, fmt.Errorf("failed select user %s:%w", op, err)
+	}
+
+	return user.toModel(), nil
+}
+
+// SelectUsersByIDs returns users with the ids, unknown ids are skipped
+func (s Storage) SelectUsersByIDs(ctx context.Context, ids []int64) ([]models.User, error) {
+	const op = "storage.sqlite.SelectUsersByIDs"
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	in, args := inPlaceholders(ids)
+	rows, err := s.conn(ctx).QueryContext(ctx, `SELECT `+userColumns+` FROM users WHERE id IN `+in, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed select users %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var users []models.User
+	for rows.Next() {
+		var user User
+		if err = rows.Scan(user.dest()...); err != nil {
+			return nil, fmt.Errorf("failed scan user %s:%w", op, err)
+		}
+		users = append(users, *user.toModel())
 	}
 
-	return &models.User{
-		ID:           user.ID,
-		Email:        user.Email,
-		PasswordHash: []byte(user.PasswordHash),
-		CreatedAt:    user.CreatedAt.UTC(),
-	}, nil
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select users %s:%w", op, err)
+	}
+
+	return users, nil
+}
+
+func (s Storage) UpdateTimezone(ctx context.Context, userID int64, timezone string) error {
+	const op = "storage.sqlite.UpdateTimezone"
+
+	q := `UPDATE users SET timezone = ? WHERE id = ?`
+	res, err := s.conn(ctx).ExecContext(ctx, q, timezone, userID)
+	if err != nil {
+		return fmt.Errorf("failed update timezone %s:%w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed update timezone %s:%w", op, err)
+	}
+	if n == 0 {
+		return models.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (s Storage) UpdateFeedToken(ctx context.Context, userID int64, token string) error {
+	const op = "storage.sqlite.UpdateFeedToken"
+
+	q := `UPDATE users SET feed_token = ? WHERE id = ?`
+	res, err := s.conn(ctx).ExecContext(ctx, q, token, userID)
+	if err != nil {
+		return fmt.Errorf("failed update feed token %s:%w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed update feed token %s:%w", op, err)
+	}
+	if n == 0 {
+		return models.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (s Storage) UpdatePasswordHash(ctx context.Context, userID int64, passHash []byte) error {
+	const op = "storage.sqlite.UpdatePasswordHash"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `UPDATE users SET password_hash = ? WHERE id = ?`, passHash, userID)
+	if err != nil {
+		return fmt.Errorf("failed update password %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrUserNotFound, op)
+}
+
+// SetUserDisabled disables the user at the time, nil enables the user
+func (s Storage) SetUserDisabled(ctx context.Context, userID int64, disabledAt *time.Time) error {
+	const op = "storage.sqlite.SetUserDisabled"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `UPDATE users SET disabled_at = ? WHERE id = ?`, disabledAt, userID)
+	if err != nil {
+		return fmt.Errorf("failed update user %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrUserNotFound, op)
+}
+
+func (u *User) dest() []any {
+	return []any{&u.ID, &u.Email, &u.PasswordHash, &u.FeedToken, &u.Timezone, &u.CreatedAt, &u.DisabledAt}
+}
+
+func (u User) toModel() *models.User {
+	user := &models.User{
+		ID:           u.ID,
+		Email:        u.Email,
+		PasswordHash: []byte(u.PasswordHash),
+		FeedToken:    u.FeedToken.String,
+		Timezone:     u.Timezone,
+		CreatedAt:    u.CreatedAt.UTC(),
+	}
+	if u.DisabledAt.Valid {
+		t := u.DisabledAt.Time.UTC()
+		user.DisabledAt = &t
+	}
+	return user
 }
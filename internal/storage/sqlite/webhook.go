@@ -0,0 +1,347 @@
+package sqlite
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const webhookColumns = `id, user_id, url, secret, events, active, created_at, updated_at`
+
+const deliveryColumns = `id, webhook_id, event, payload, status, attempts, response_code, error,
+	redelivery_of, next_attempt_at, last_attempt_at, created_at`
+
+func (s Storage) InsertWebhook(ctx context.Context, hook models.Webhook) (int64, error) {
+	const op = "storage.sqlite.InsertWebhook"
+
+	query := `INSERT INTO webhooks (user_id, url, secret, events, active, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	now := time.Now().UTC()
+	res, err := s.conn(ctx).ExecContext(ctx, query, hook.UserID, hook.URL, hook.Secret, joinEvents(hook.Events), hook.Active, now, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed insert webhook %s:%w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed get webhook id %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+func (s Storage) SelectWebhooks(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	const op = "storage.sqlite.SelectWebhooks"
+
+	hooks, err := s.selectWebhooks(ctx, `WHERE user_id = ? ORDER BY id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select webhooks %s:%w", op, err)
+	}
+
+	return hooks, nil
+}
+
+// SelectWebhooksByEvent returns active webhooks of all users subscribed to the event
+func (s Storage) SelectWebhooksByEvent(ctx context.Context, event models.EventType) ([]models.Webhook, error) {
+	const op = "storage.sqlite.SelectWebhooksByEvent"
+
+	hooks, err := s.selectWebhooks(ctx, `WHERE active = 1 AND instr(',' || events || ',', ?) > 0`, ","+string(event)+",")
+	if err != nil {
+		return nil, fmt.Errorf("failed select webhooks %s:%w", op, err)
+	}
+
+	return hooks, nil
+}
+
+func (s Storage) selectWebhooks(ctx context.Context, where string, args ...any) ([]models.Webhook, error) {
+	rows, err := s.conn(ctx).QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var hooks []models.Webhook
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+
+	return hooks, rows.Err()
+}
+
+func (s Storage) SelectWebhookByID(ctx context.Context, id int64, userID int64) (models.Webhook, error) {
+	const op = "storage.sqlite.SelectWebhookByID"
+
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ? AND user_id = ?`
+
+	hook, err := scanWebhook(s.conn(ctx).QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Webhook{}, models.ErrWebhookNotFound
+		}
+		return models.Webhook{}, fmt.Errorf("failed select webhook %s:%w", op, err)
+	}
+
+	return hook, nil
+}
+
+// SelectWebhook returns the webhook of any user, used for delivery
+func (s Storage) SelectWebhook(ctx context.Context, id int64) (models.Webhook, error) {
+	const op = "storage.sqlite.SelectWebhook"
+
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ?`
+
+	hook, err := scanWebhook(s.conn(ctx).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Webhook{}, models.ErrWebhookNotFound
+		}
+		return models.Webhook{}, fmt.Errorf("failed select webhook %s:%w", op, err)
+	}
+
+	return hook, nil
+}
+
+func (s Storage) UpdateWebhook(ctx context.Context, hook models.Webhook) error {
+	const op = "storage.sqlite.UpdateWebhook"
+
+	query := `UPDATE webhooks SET url = ?, events = ?, active = ?, updated_at = ? WHERE id = ? AND user_id = ?`
+
+	res, err := s.conn(ctx).ExecContext(ctx, query, hook.URL, joinEvents(hook.Events), hook.Active, time.Now().UTC(), hook.ID, hook.UserID)
+	if err != nil {
+		return fmt.Errorf("failed update webhook %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrWebhookNotFound, op)
+}
+
+// DeleteWebhook deletes the webhook with its delivery log
+func (s Storage) DeleteWebhook(ctx context.Context, id int64, userID int64) error {
+	const op = "storage.sqlite.DeleteWebhook"
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed delete webhook %s:%w", op, err)
+	}
+	if err = affectedOrNotFound(res, models.ErrWebhookNotFound, op); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+		return fmt.Errorf("failed delete deliveries %s:%w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s Storage) InsertDelivery(ctx context.Context, d models.WebhookDelivery) (int64, error) {
+	const op = "storage.sqlite.InsertDelivery"
+
+	query := `INSERT INTO webhook_deliveries (webhook_id, event, payload, status, redelivery_of, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := s.conn(ctx).ExecContext(
+		ctx,
+		query,
+		d.WebhookID,
+		string(d.Event),
+		d.Payload,
+		string(d.Status),
+		nullInt64(d.RedeliveryOf),
+		nullTime(d.NextAttemptAt),
+		d.CreatedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed insert delivery %s:%w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed get delivery id %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+// SelectDeliveries returns the newest deliveries of the webhook first
+func (s Storage) SelectDeliveries(ctx context.Context, webhookID int64, limit int) ([]models.WebhookDelivery, error) {
+	const op = "storage.sqlite.SelectDeliveries"
+
+	deliveries, err := s.selectDeliveries(ctx, `WHERE webhook_id = ? ORDER BY created_at DESC, id DESC LIMIT ?`, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed select deliveries %s:%w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+// SelectDueDeliveries returns pending deliveries whose next attempt is due, the oldest first
+func (s Storage) SelectDueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	const op = "storage.sqlite.SelectDueDeliveries"
+
+	deliveries, err := s.selectDeliveries(
+		ctx,
+		`WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?`,
+		string(models.DeliveryPending), now, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed select deliveries %s:%w", op, err)
+	}
+
+	return deliveries, nil
+}
+
+func (s Storage) selectDeliveries(ctx context.Context, where string, args ...any) ([]models.WebhookDelivery, error) {
+	rows, err := s.conn(ctx).QueryContext(ctx, `SELECT `+deliveryColumns+` FROM webhook_deliveries `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (s Storage) SelectDelivery(ctx context.Context, id int64, webhookID int64) (models.WebhookDelivery, error) {
+	const op = "storage.sqlite.SelectDelivery"
+
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = ? AND webhook_id = ?`
+
+	d, err := scanDelivery(s.conn(ctx).QueryRowContext(ctx, query, id, webhookID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebhookDelivery{}, models.ErrDeliveryNotFound
+		}
+		return models.WebhookDelivery{}, fmt.Errorf("failed select delivery %s:%w", op, err)
+	}
+
+	return d, nil
+}
+
+// UpdateDeliveryAttempt stores the result of the delivery attempt
+func (s Storage) UpdateDeliveryAttempt(ctx context.Context, d models.WebhookDelivery) error {
+	const op = "storage.sqlite.UpdateDeliveryAttempt"
+
+	query := `UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, response_code = ?, error = ?, next_attempt_at = ?, last_attempt_at = ?
+		WHERE id = ?`
+
+	var code sql.NullInt64
+	if d.ResponseCode != nil {
+		code = sql.NullInt64{Int64: int64(*d.ResponseCode), Valid: true}
+	}
+
+	res, err := s.conn(ctx).ExecContext(
+		ctx,
+		query,
+		string(d.Status),
+		d.Attempts,
+		code,
+		d.Error,
+		nullTime(d.NextAttemptAt),
+		nullTime(d.LastAttemptAt),
+		d.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed update delivery %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrDeliveryNotFound, op)
+}
+
+func scanWebhook(row scanner) (models.Webhook, error) {
+	var (
+		hook   models.Webhook
+		events string
+	)
+	err := row.Scan(&hook.ID, &hook.UserID, &hook.URL, &hook.Secret, &events, &hook.Active, &hook.CreatedAt, &hook.UpdatedAt)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	for _, e := range strings.Split(events, ",") {
+		if e != "" {
+			hook.Events = append(hook.Events, models.EventType(e))
+		}
+	}
+
+	return hook, nil
+}
+
+func scanDelivery(row scanner) (models.WebhookDelivery, error) {
+	var (
+		d             models.WebhookDelivery
+		event, status string
+		code          sql.NullInt64
+		redeliveryOf  sql.NullInt64
+		next, last    sql.NullTime
+	)
+	err := row.Scan(
+		&d.ID,
+		&d.WebhookID,
+		&event,
+		&d.Payload,
+		&status,
+		&d.Attempts,
+		&code,
+		&d.Error,
+		&redeliveryOf,
+		&next,
+		&last,
+		&d.CreatedAt,
+	)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	d.Event = models.EventType(event)
+	d.Status = models.DeliveryStatus(status)
+	if code.Valid {
+		c := int(code.Int64)
+		d.ResponseCode = &c
+	}
+	d.RedeliveryOf = int64FromNull(redeliveryOf)
+	d.NextAttemptAt = timeFromNull(next)
+	d.LastAttemptAt = timeFromNull(last)
+
+	return d, nil
+}
+
+func joinEvents(events []models.EventType) string {
+	s := make([]string, len(events))
+	for i, e := range events {
+		s[i] = string(e)
+	}
+	return strings.Join(s, ",")
+}
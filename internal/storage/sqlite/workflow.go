@@ -0,0 +1,260 @@
+package sqlite
+
+import (
+	"TaskList/internal/lib/txmanager"
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+	"time"
+)
+
+type WorkflowStatus struct {
+	ID        int64         `db:"id"`
+	UserID    int64         `db:"user_id"`
+	ProjectID sql.NullInt64 `db:"project_id"`
+	Name      string        `db:"name"`
+	Category  string        `db:"category"`
+	Position  int           `db:"position"`
+	WIPLimit  int           `db:"wip_limit"`
+	CreatedAt time.Time     `db:"created_at"`
+}
+
+const workflowStatusColumns = `id, user_id, project_id, name, category, position, wip_limit, created_at`
+
+func scanWorkflowStatus(row scanner) (models.WorkflowStatus, error) {
+	var ws WorkflowStatus
+	err := row.Scan(
+		&ws.ID,
+		&ws.UserID,
+		&ws.ProjectID,
+		&ws.Name,
+		&ws.Category,
+		&ws.Position,
+		&ws.WIPLimit,
+		&ws.CreatedAt,
+	)
+	if err != nil {
+		return models.WorkflowStatus{}, err
+	}
+
+	return models.WorkflowStatus{
+		ID:        ws.ID,
+		UserID:    ws.UserID,
+		ProjectID: int64FromNull(ws.ProjectID),
+		Name:      models.Status(ws.Name),
+		Category:  models.StatusCategory(ws.Category),
+		Position:  ws.Position,
+		WIPLimit:  ws.WIPLimit,
+		CreatedAt: ws.CreatedAt,
+	}, nil
+}
+
+func (s Storage) InsertWorkflowStatus(ctx context.Context, ws models.WorkflowStatus) (int64, error) {
+	const op = "storage.sqlite.InsertWorkflowStatus"
+
+	query := `INSERT INTO workflow_statuses (user_id, project_id, name, category, position, wip_limit, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err = shiftPositions(ctx, tx, ws.UserID, ws.ProjectID, ws.Position, 0); err != nil {
+		return 0, fmt.Errorf("failed shift positions %s:%w", op, err)
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		query,
+		ws.UserID,
+		nullInt64(ws.ProjectID),
+		string(ws.Name),
+		string(ws.Category),
+		ws.Position,
+		ws.WIPLimit,
+		time.Now().UTC(),
+	)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && errors.Is(sqliteErr.ExtendedCode, sqlite3.ErrConstraintUnique) {
+			return 0, models.ErrStatusAlreadyExists
+		}
+		return 0, fmt.Errorf("failed insert status %s:%w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed insert status %s:%w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+// shiftPositions moves columns starting from the position one step right to free the place
+func shiftPositions(ctx context.Context, tx txmanager.Querier, userID int64, projectID *int64, position int, exceptID int64) error {
+	query := `UPDATE workflow_statuses SET position = position + 1
+		WHERE user_id = ? AND project_id IS ? AND position >= ? AND id != ?
+		  AND EXISTS (SELECT 1 FROM workflow_statuses w
+			WHERE w.user_id = ? AND w.project_id IS ? AND w.position = ? AND w.id != ?)`
+
+	pid := nullInt64(projectID)
+	_, err := tx.ExecContext(ctx, query, userID, pid, position, exceptID, userID, pid, position, exceptID)
+	return err
+}
+
+// SelectWorkflowStatuses returns statuses defined exactly for the scope, nil project means statuses for all projects
+func (s Storage) SelectWorkflowStatuses(ctx context.Context, userID int64, projectID *int64) ([]models.WorkflowStatus, error) {
+	const op = "storage.sqlite.SelectWorkflowStatuses"
+
+	query := `SELECT ` + workflowStatusColumns + ` FROM workflow_statuses
+		WHERE user_id = ? AND project_id IS ?
+		ORDER BY position, id`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, userID, nullInt64(projectID))
+	if err != nil {
+		return nil, fmt.Errorf("failed select statuses %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var statuses []models.WorkflowStatus
+	for rows.Next() {
+		ws, err := scanWorkflowStatus(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan status %s:%w", op, err)
+		}
+		statuses = append(statuses, ws)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select statuses %s:%w", op, err)
+	}
+
+	return statuses, nil
+}
+
+func (s Storage) SelectWorkflowStatusByID(ctx context.Context, statusID int64, userID int64) (models.WorkflowStatus, error) {
+	const op = "storage.sqlite.SelectWorkflowStatusByID"
+
+	query := `SELECT ` + workflowStatusColumns + ` FROM workflow_statuses WHERE id = ? AND user_id = ?`
+
+	ws, err := scanWorkflowStatus(s.conn(ctx).QueryRowContext(ctx, query, statusID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WorkflowStatus{}, models.ErrStatusNotFound
+		}
+		return models.WorkflowStatus{}, fmt.Errorf("failed select status %s:%w", op, err)
+	}
+
+	return ws, nil
+}
+
+// UpdateWorkflowStatus updates the status, tasks are moved to the new name in the same transaction
+func (s Storage) UpdateWorkflowStatus(ctx context.Context, ws models.WorkflowStatus, oldName models.Status) error {
+	const op = "storage.sqlite.UpdateWorkflowStatus"
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `UPDATE workflow_statuses SET name = ?, category = ?, position = ?, wip_limit = ?
+		WHERE id = ? AND user_id = ?`
+
+	if err = shiftPositions(ctx, tx, ws.UserID, ws.ProjectID, ws.Position, ws.ID); err != nil {
+		return fmt.Errorf("failed shift positions %s:%w", op, err)
+	}
+
+	res, err := tx.ExecContext(
+		ctx,
+		query,
+		string(ws.Name),
+		string(ws.Category),
+		ws.Position,
+		ws.WIPLimit,
+		ws.ID,
+		ws.UserID,
+	)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && errors.Is(sqliteErr.ExtendedCode, sqlite3.ErrConstraintUnique) {
+			return models.ErrStatusAlreadyExists
+		}
+		return fmt.Errorf("failed update status %s:%w", op, err)
+	}
+	if err = affectedOrNotFound(res, models.ErrStatusNotFound, op); err != nil {
+		return err
+	}
+
+	if oldName != ws.Name {
+		if err = renameTasksStatus(ctx, tx, ws.UserID, ws.ProjectID, oldName, ws.Name); err != nil {
+			return fmt.Errorf("failed rename tasks status %s:%w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteWorkflowStatus deletes the status and moves its tasks to the fallback status
+func (s Storage) DeleteWorkflowStatus(ctx context.Context, ws models.WorkflowStatus, fallback models.Status) error {
+	const op = "storage.sqlite.DeleteWorkflowStatus"
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM workflow_statuses WHERE id = ? AND user_id = ?`, ws.ID, ws.UserID)
+	if err != nil {
+		return fmt.Errorf("failed delete status %s:%w", op, err)
+	}
+	if err = affectedOrNotFound(res, models.ErrStatusNotFound, op); err != nil {
+		return err
+	}
+
+	if err = renameTasksStatus(ctx, tx, ws.UserID, ws.ProjectID, ws.Name, fallback); err != nil {
+		return fmt.Errorf("failed move tasks %s:%w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
+}
+
+// renameTasksStatus changes status of tasks in the scope, nil project affects tasks of all projects
+// which do not define own status with the same name
+func renameTasksStatus(ctx context.Context, tx txmanager.Querier, userID int64, projectID *int64, from, to models.Status) error {
+	query := `UPDATE tasks SET status = ?, updated_at = ?
+		WHERE user_id = ? AND status = ?
+		  AND (project_id IS ? OR (? IS NULL AND NOT EXISTS (
+				SELECT 1 FROM workflow_statuses w WHERE w.project_id = tasks.project_id
+			)))`
+
+	pid := nullInt64(projectID)
+	_, err := tx.ExecContext(ctx, query, string(to), time.Now().UTC(), userID, string(from), pid, pid)
+	return err
+}
@@ -0,0 +1,448 @@
+package sqlite
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// InsertWorkspace creates workspace with its owner as the first member
+func (s Storage) InsertWorkspace(ctx context.Context, ws models.Workspace) (int64, error) {
+	const op = "storage.sqlite.InsertWorkspace"
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO workspaces (owner_id, name, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+		ws.OwnerID, ws.Name, now, now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed insert workspace %s:%w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed insert workspace %s:%w", op, err)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT INTO workspace_members (workspace_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`,
+		id, ws.OwnerID, string(models.RoleOwner), now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed insert owner %s:%w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+// SelectWorkspacesByUserID selects workspaces the user is member of with the user role
+func (s Storage) SelectWorkspacesByUserID(ctx context.Context, userID int64) ([]models.Workspace, error) {
+	const op = "storage.sqlite.SelectWorkspacesByUserID"
+
+	query := `SELECT w.id, w.owner_id, w.name, m.role, w.created_at, w.updated_at
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id = ?
+		ORDER BY w.name`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select workspaces %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var workspaces []models.Workspace
+	for rows.Next() {
+		ws, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan workspace %s:%w", op, err)
+		}
+		workspaces = append(workspaces, ws)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select workspaces %s:%w", op, err)
+	}
+
+	return workspaces, nil
+}
+
+func (s Storage) SelectWorkspaceByID(ctx context.Context, workspaceID int64, userID int64) (models.Workspace, error) {
+	const op = "storage.sqlite.SelectWorkspaceByID"
+
+	query := `SELECT w.id, w.owner_id, w.name, m.role, w.created_at, w.updated_at
+		FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE w.id = ? AND m.user_id = ?`
+
+	ws, err := scanWorkspace(s.conn(ctx).QueryRowContext(ctx, query, workspaceID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Workspace{}, models.ErrWorkspaceNotFound
+		}
+		return models.Workspace{}, fmt.Errorf("failed select workspace %s:%w", op, err)
+	}
+
+	return ws, nil
+}
+
+func (s Storage) UpdateWorkspace(ctx context.Context, ws models.Workspace) error {
+	const op = "storage.sqlite.UpdateWorkspace"
+
+	res, err := s.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE workspaces SET name = ?, updated_at = ? WHERE id = ?`,
+		ws.Name, time.Now().UTC(), ws.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed update workspace %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrWorkspaceNotFound, op)
+}
+
+// DeleteWorkspace deletes workspace with members and invitations,
+// its projects stay with the owner as personal projects
+func (s Storage) DeleteWorkspace(ctx context.Context, workspaceID int64) error {
+	const op = "storage.sqlite.DeleteWorkspace"
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM workspaces WHERE id = ?`, workspaceID)
+	if err != nil {
+		return fmt.Errorf("failed delete workspace %s:%w", op, err)
+	}
+	if err = affectedOrNotFound(res, models.ErrWorkspaceNotFound, op); err != nil {
+		return err
+	}
+
+	for _, q := range []string{
+		`UPDATE projects SET workspace_id = NULL WHERE workspace_id = ?`,
+		`DELETE FROM workspace_members WHERE workspace_id = ?`,
+		`DELETE FROM workspace_invitations WHERE workspace_id = ?`,
+	} {
+		if _, err = tx.ExecContext(ctx, q, workspaceID); err != nil {
+			return fmt.Errorf("failed delete workspace %s:%w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s Storage) SelectWorkspaceMembers(ctx context.Context, workspaceID int64) ([]models.WorkspaceMember, error) {
+	const op = "storage.sqlite.SelectWorkspaceMembers"
+
+	query := `SELECT m.workspace_id, m.user_id, u.email, m.role, m.created_at
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = ?
+		ORDER BY m.created_at`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select members %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var members []models.WorkspaceMember
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan member %s:%w", op, err)
+		}
+		members = append(members, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select members %s:%w", op, err)
+	}
+
+	return members, nil
+}
+
+func (s Storage) SelectWorkspaceMember(ctx context.Context, workspaceID int64, userID int64) (models.WorkspaceMember, error) {
+	const op = "storage.sqlite.SelectWorkspaceMember"
+
+	query := `SELECT m.workspace_id, m.user_id, u.email, m.role, m.created_at
+		FROM workspace_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.workspace_id = ? AND m.user_id = ?`
+
+	m, err := scanMember(s.conn(ctx).QueryRowContext(ctx, query, workspaceID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WorkspaceMember{}, models.ErrMemberNotFound
+		}
+		return models.WorkspaceMember{}, fmt.Errorf("failed select member %s:%w", op, err)
+	}
+
+	return m, nil
+}
+
+func (s Storage) UpdateWorkspaceMemberRole(ctx context.Context, workspaceID int64, userID int64, role models.Role) error {
+	const op = "storage.sqlite.UpdateWorkspaceMemberRole"
+
+	res, err := s.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE workspace_members SET role = ? WHERE workspace_id = ? AND user_id = ?`,
+		string(role), workspaceID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed update role %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrMemberNotFound, op)
+}
+
+// DeleteWorkspaceMember removes member, the user stops being assignee of workspace tasks not shared with them
+func (s Storage) DeleteWorkspaceMember(ctx context.Context, workspaceID int64, userID int64) error {
+	const op = "storage.sqlite.DeleteWorkspaceMember"
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	res, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM workspace_members WHERE workspace_id = ? AND user_id = ?`,
+		workspaceID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed delete member %s:%w", op, err)
+	}
+	if err = affectedOrNotFound(res, models.ErrMemberNotFound, op); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE tasks SET assignee_id = NULL, updated_at = ?
+		WHERE assignee_id = ?
+		  AND project_id IN (SELECT id FROM projects WHERE workspace_id = ?)
+		  AND id NOT IN (SELECT task_id FROM task_shares WHERE user_id = ?)`,
+		time.Now().UTC(), userID, workspaceID, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed unassign tasks %s:%w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s Storage) InsertInvitation(ctx context.Context, inv models.Invitation) (int64, error) {
+	const op = "storage.sqlite.InsertInvitation"
+
+	query := `INSERT INTO workspace_invitations (workspace_id, email, role, token, invited_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	res, err := s.conn(ctx).ExecContext(
+		ctx, query,
+		inv.WorkspaceID, inv.Email, string(inv.Role), inv.Token, inv.InvitedBy, time.Now().UTC(), inv.ExpiresAt.UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed insert invitation %s:%w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed insert invitation %s:%w", op, err)
+	}
+
+	return id, nil
+}
+
+const invitationColumns = `id, workspace_id, email, role, token, invited_by, created_at, expires_at, accepted_at`
+
+// SelectInvitations selects pending invitations of the workspace
+func (s Storage) SelectInvitations(ctx context.Context, workspaceID int64) ([]models.Invitation, error) {
+	const op = "storage.sqlite.SelectInvitations"
+
+	query := `SELECT ` + invitationColumns + ` FROM workspace_invitations
+		WHERE workspace_id = ? AND accepted_at IS NULL
+		ORDER BY created_at`
+
+	rows, err := s.conn(ctx).QueryContext(ctx, query, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed select invitations %s:%w", op, err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var invitations []models.Invitation
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed scan invitation %s:%w", op, err)
+		}
+		invitations = append(invitations, inv)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed select invitations %s:%w", op, err)
+	}
+
+	return invitations, nil
+}
+
+func (s Storage) SelectInvitationByToken(ctx context.Context, token string) (models.Invitation, error) {
+	const op = "storage.sqlite.SelectInvitationByToken"
+
+	query := `SELECT ` + invitationColumns + ` FROM workspace_invitations WHERE token = ?`
+
+	inv, err := scanInvitation(s.conn(ctx).QueryRowContext(ctx, query, token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Invitation{}, models.ErrInvitationNotFound
+		}
+		return models.Invitation{}, fmt.Errorf("failed select invitation %s:%w", op, err)
+	}
+
+	return inv, nil
+}
+
+// AcceptInvitation marks invitation accepted and adds the user to the workspace
+func (s Storage) AcceptInvitation(ctx context.Context, inv models.Invitation, userID int64) error {
+	const op = "storage.sqlite.AcceptInvitation"
+
+	tx, err := s.txm.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed begin tx %s:%w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now().UTC()
+	res, err := tx.ExecContext(
+		ctx,
+		`UPDATE workspace_invitations SET accepted_at = ? WHERE id = ? AND accepted_at IS NULL`,
+		now, inv.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed accept invitation %s:%w", op, err)
+	}
+	if err = affectedOrNotFound(res, models.ErrInvitationNotFound, op); err != nil {
+		return err
+	}
+
+	res, err = tx.ExecContext(
+		ctx,
+		`INSERT OR IGNORE INTO workspace_members (workspace_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`,
+		inv.WorkspaceID, userID, string(inv.Role), now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed insert member %s:%w", op, err)
+	}
+	if err = affectedOrNotFound(res, models.ErrAlreadyMember, op); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed commit tx %s:%w", op, err)
+	}
+
+	return nil
+}
+
+func (s Storage) DeleteInvitation(ctx context.Context, workspaceID int64, invitationID int64) error {
+	const op = "storage.sqlite.DeleteInvitation"
+
+	res, err := s.conn(ctx).ExecContext(
+		ctx,
+		`DELETE FROM workspace_invitations WHERE id = ? AND workspace_id = ? AND accepted_at IS NULL`,
+		invitationID, workspaceID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed delete invitation %s:%w", op, err)
+	}
+
+	return affectedOrNotFound(res, models.ErrInvitationNotFound, op)
+}
+
+func scanWorkspace(row scanner) (models.Workspace, error) {
+	var (
+		ws   models.Workspace
+		role string
+	)
+
+	err := row.Scan(&ws.ID, &ws.OwnerID, &ws.Name, &role, &ws.CreatedAt, &ws.UpdatedAt)
+	ws.Role = models.Role(role)
+
+	return ws, err
+}
+
+func scanMember(row scanner) (models.WorkspaceMember, error) {
+	var (
+		m    models.WorkspaceMember
+		role string
+	)
+
+	err := row.Scan(&m.WorkspaceID, &m.UserID, &m.Email, &role, &m.CreatedAt)
+	m.Role = models.Role(role)
+
+	return m, err
+}
+
+func scanInvitation(row scanner) (models.Invitation, error) {
+	var (
+		inv        models.Invitation
+		role       string
+		acceptedAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&inv.ID,
+		&inv.WorkspaceID,
+		&inv.Email,
+		&role,
+		&inv.Token,
+		&inv.InvitedBy,
+		&inv.CreatedAt,
+		&inv.ExpiresAt,
+		&acceptedAt,
+	)
+	inv.Role = models.Role(role)
+	inv.AcceptedAt = timeFromNull(acceptedAt)
+
+	return inv, err
+}
@@ -0,0 +1,567 @@
+// Package storagetest is the contract every storage backend must pass, the backends run it from their tests
+package storagetest
+
+import (
+	"TaskList/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Storage is the part of a backend used by the auth and tasks services
+type Storage interface {
+	CreateUser(ctx context.Context, email string, passHash []byte) (int64, error)
+	UserByEmail(ctx context.Context, email string) (*models.User, error)
+	UserByID(ctx context.Context, userID int64) (*models.User, error)
+	UpdateTimezone(ctx context.Context, userID int64, timezone string) error
+	UpdatePasswordHash(ctx context.Context, userID int64, passHash []byte) error
+	SetUserDisabled(ctx context.Context, userID int64, disabledAt *time.Time) error
+
+	InsertAppPassword(ctx context.Context, p models.AppPassword, tokenHash string) (int64, error)
+	SelectAppPasswords(ctx context.Context, userID int64) ([]models.AppPassword, error)
+	DeleteAppPassword(ctx context.Context, userID int64, id int64) error
+	UserByAppPassword(ctx context.Context, email string, tokenHash string) (*models.User, error)
+
+	InsertTask(ctx context.Context, task models.Task) (int64, error)
+	SelectAllTasksByUserID(ctx context.Context, userID int64) ([]models.Task, error)
+	SelectTaskByID(ctx context.Context, taskID int64) (models.Task, error)
+	CountTasksByStatus(ctx context.Context, userID int64, projectID *int64, status models.Status) (int, error)
+	UpdateStatusTask(ctx context.Context, taskID int64, userID int64, status models.Status) error
+	UpdateTask(ctx context.Context, task models.Task) error
+	DeleteTask(ctx context.Context, taskID int64) error
+	UpdateTaskAssignee(ctx context.Context, taskID int64, assigneeID *int64) error
+
+	UpsertTaskShare(ctx context.Context, share models.TaskShare) error
+	SelectTaskShare(ctx context.Context, taskID int64, userID int64) (models.TaskShare, error)
+	SelectTaskShares(ctx context.Context, taskID int64) ([]models.TaskShare, error)
+	DeleteTaskShare(ctx context.Context, taskID int64, userID int64) error
+	SelectTasksSharedWith(ctx context.Context, userID int64) ([]models.Task, error)
+	SelectTasksAssignedTo(ctx context.Context, userID int64) ([]models.Task, error)
+
+	SelectTaskChanges(ctx context.Context, userID int64, after int64, limit int) ([]models.TaskChange, error)
+	LastTaskChange(ctx context.Context) (int64, error)
+	InsertSyncClientTask(ctx context.Context, userID int64, clientID string, taskID int64) error
+	SelectSyncClientTask(ctx context.Context, userID int64, clientID string) (int64, error)
+
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	AfterCommit(ctx context.Context, fn func(ctx context.Context))
+}
+
+// Run runs the contract against storages made by newStorage, every test gets an empty storage
+func Run(t *testing.T, newStorage func(t *testing.T) Storage) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s Storage)
+	}{
+		{"Users", testUsers},
+		{"AppPasswords", testAppPasswords},
+		{"Tasks", testTasks},
+		{"Shares", testShares},
+		{"TaskChanges", testTaskChanges},
+		{"TaskChangesOfUser", testTaskChangesOfUser},
+		{"SyncClientTasks", testSyncClientTasks},
+		{"WithinTxRollsBack", testWithinTxRollsBack},
+		{"AfterCommit", testAfterCommit},
+		{"WithinTxSerializesChecks", testWithinTxSerializesChecks},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStorage(t))
+		})
+	}
+}
+
+func createUser(t *testing.T, s Storage, email string) int64 {
+	t.Helper()
+
+	id, err := s.CreateUser(context.Background(), email, []byte("hash"))
+	if err != nil {
+		t.Fatalf("CreateUser(%s) error = %v", email, err)
+	}
+	return id
+}
+
+func insertTask(t *testing.T, s Storage, task models.Task) int64 {
+	t.Helper()
+
+	id, err := s.InsertTask(context.Background(), task)
+	if err != nil {
+		t.Fatalf("InsertTask() error = %v", err)
+	}
+	return id
+}
+
+func taskIDs(tasks []models.Task) []int64 {
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func testUsers(t *testing.T, s Storage) {
+	ctx := context.Background()
+	id := createUser(t, s, "a@example.com")
+
+	if _, err := s.CreateUser(ctx, "a@example.com", []byte("other")); !errors.Is(err, models.ErrUserAlreadyExists) {
+		t.Errorf("CreateUser() of taken email error = %v, want %v", err, models.ErrUserAlreadyExists)
+	}
+
+	user, err := s.UserByEmail(ctx, "a@example.com")
+	if err != nil {
+		t.Fatalf("UserByEmail() error = %v", err)
+	}
+	if user.ID != id || string(user.PasswordHash) != "hash" || user.Timezone != "UTC" || user.DisabledAt != nil {
+		t.Errorf("UserByEmail() = %+v", user)
+	}
+
+	if _, err = s.UserByEmail(ctx, "missing@example.com"); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("UserByEmail() of missing user error = %v, want %v", err, models.ErrUserNotFound)
+	}
+	if _, err = s.UserByID(ctx, id+100); !errors.Is(err, models.ErrUserNotFound) {
+		t.Errorf("UserByID() of missing user error = %v, want %v", err, models.ErrUserNotFound)
+	}
+
+	disabledAt := time.Now().UTC().Truncate(time.Second)
+	if err = s.UpdateTimezone(ctx, id, "Europe/Moscow"); err != nil {
+		t.Fatalf("UpdateTimezone() error = %v", err)
+	}
+	if err = s.UpdatePasswordHash(ctx, id, []byte("new")); err != nil {
+		t.Fatalf("UpdatePasswordHash() error = %v", err)
+	}
+	if err = s.SetUserDisabled(ctx, id, &disabledAt); err != nil {
+		t.Fatalf("SetUserDisabled() error = %v", err)
+	}
+
+	user, err = s.UserByID(ctx, id)
+	if err != nil {
+		t.Fatalf("UserByID() error = %v", err)
+	}
+	if user.Timezone != "Europe/Moscow" || string(user.PasswordHash) != "new" || user.DisabledAt == nil || !user.DisabledAt.Equal(disabledAt) {
+		t.Errorf("UserByID() after updates = %+v", user)
+	}
+
+	if err = s.SetUserDisabled(ctx, id, nil); err != nil {
+		t.Fatalf("SetUserDisabled(nil) error = %v", err)
+	}
+	if user, err = s.UserByID(ctx, id); err != nil || user.DisabledAt != nil {
+		t.Errorf("UserByID() after enable = %+v, %v", user, err)
+	}
+}
+
+func testAppPasswords(t *testing.T, s Storage) {
+	ctx := context.Background()
+	owner := createUser(t, s, "a@example.com")
+	other := createUser(t, s, "b@example.com")
+
+	id, err := s.InsertAppPassword(ctx, models.AppPassword{UserID: owner, Name: "phone", CreatedAt: time.Now().UTC()}, "token")
+	if err != nil {
+		t.Fatalf("InsertAppPassword() error = %v", err)
+	}
+
+	user, err := s.UserByAppPassword(ctx, "a@example.com", "token")
+	if err != nil || user.ID != owner {
+		t.Fatalf("UserByAppPassword() = %+v, %v, want user %d", user, err, owner)
+	}
+	for _, email := range []string{"b@example.com", "missing@example.com"} {
+		if _, err = s.UserByAppPassword(ctx, email, "token"); !errors.Is(err, models.ErrInvalidCredentials) {
+			t.Errorf("UserByAppPassword(%s) error = %v, want %v", email, err, models.ErrInvalidCredentials)
+		}
+	}
+
+	list, err := s.SelectAppPasswords(ctx, owner)
+	if err != nil {
+		t.Fatalf("SelectAppPasswords() error = %v", err)
+	}
+	if len(list) != 1 || list[0].ID != id || list[0].Name != "phone" || list[0].LastUsedAt == nil {
+		t.Errorf("SelectAppPasswords() = %+v, want the used password %d", list, id)
+	}
+
+	if err = s.DeleteAppPassword(ctx, other, id); !errors.Is(err, models.ErrAppPasswordNotFound) {
+		t.Errorf("DeleteAppPassword() by another user error = %v, want %v", err, models.ErrAppPasswordNotFound)
+	}
+	if err = s.DeleteAppPassword(ctx, owner, id); err != nil {
+		t.Fatalf("DeleteAppPassword() error = %v", err)
+	}
+	if _, err = s.UserByAppPassword(ctx, "a@example.com", "token"); !errors.Is(err, models.ErrInvalidCredentials) {
+		t.Errorf("UserByAppPassword() of deleted password error = %v, want %v", err, models.ErrInvalidCredentials)
+	}
+}
+
+func testTasks(t *testing.T, s Storage) {
+	ctx := context.Background()
+	owner := createUser(t, s, "a@example.com")
+	other := createUser(t, s, "b@example.com")
+	due := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+
+	id := insertTask(t, s, models.Task{UserID: owner, Title: "Pay rent", Priority: "high", DueAt: &due, Tags: []string{"home", "money"}})
+	second := insertTask(t, s, models.Task{UserID: owner, Title: "Write report", Status: models.Done})
+	insertTask(t, s, models.Task{UserID: other, Title: "Foreign"})
+
+	task, err := s.SelectTaskByID(ctx, id)
+	if err != nil {
+		t.Fatalf("SelectTaskByID() error = %v", err)
+	}
+	if task.UserID != owner || task.CreatedBy != owner || task.Title != "Pay rent" || task.Status != models.Pending ||
+		task.Priority != "high" || task.DueAt == nil || !task.DueAt.Equal(due) || task.CreatedAt.IsZero() {
+		t.Errorf("SelectTaskByID() = %+v", task)
+	}
+	if tags := slices.Sorted(slices.Values(task.Tags)); !slices.Equal(tags, []string{"home", "money"}) {
+		t.Errorf("tags = %v, want [home money]", task.Tags)
+	}
+	if _, err = s.SelectTaskByID(ctx, id+100); !errors.Is(err, models.ErrTaskNotFound) {
+		t.Errorf("SelectTaskByID() of missing task error = %v, want %v", err, models.ErrTaskNotFound)
+	}
+
+	tasks, err := s.SelectAllTasksByUserID(ctx, owner)
+	if err != nil {
+		t.Fatalf("SelectAllTasksByUserID() error = %v", err)
+	}
+	if ids := taskIDs(tasks); !slices.Equal(ids, []int64{id, second}) {
+		t.Errorf("SelectAllTasksByUserID() ids = %v, want %v", ids, []int64{id, second})
+	}
+
+	if n, err := s.CountTasksByStatus(ctx, owner, nil, models.Pending); err != nil || n != 1 {
+		t.Errorf("CountTasksByStatus(Pending) = %d, %v, want 1", n, err)
+	}
+	if err = s.UpdateStatusTask(ctx, id, other, models.Done); !errors.Is(err, models.ErrTaskNotFound) {
+		t.Errorf("UpdateStatusTask() by another user error = %v, want %v", err, models.ErrTaskNotFound)
+	}
+	if err = s.UpdateStatusTask(ctx, id, owner, models.Done); err != nil {
+		t.Fatalf("UpdateStatusTask() error = %v", err)
+	}
+	if n, err := s.CountTasksByStatus(ctx, owner, nil, models.Done); err != nil || n != 2 {
+		t.Errorf("CountTasksByStatus(Done) = %d, %v, want 2", n, err)
+	}
+
+	task.Title = "Pay rent twice"
+	task.DueAt = nil
+	task.Tags = []string{"money"}
+	if err = s.UpdateTask(ctx, task); err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	task, err = s.SelectTaskByID(ctx, id)
+	if err != nil {
+		t.Fatalf("SelectTaskByID() error = %v", err)
+	}
+	if task.Title != "Pay rent twice" || task.DueAt != nil || !slices.Equal(task.Tags, []string{"money"}) || task.Status != models.Done {
+		t.Errorf("SelectTaskByID() after update = %+v", task)
+	}
+	if err = s.UpdateTask(ctx, models.Task{ID: id + 100, Title: "missing"}); !errors.Is(err, models.ErrTaskNotFound) {
+		t.Errorf("UpdateTask() of missing task error = %v, want %v", err, models.ErrTaskNotFound)
+	}
+
+	if err = s.DeleteTask(ctx, id); err != nil {
+		t.Fatalf("DeleteTask() error = %v", err)
+	}
+	if err = s.DeleteTask(ctx, id); !errors.Is(err, models.ErrTaskNotFound) {
+		t.Errorf("DeleteTask() twice error = %v, want %v", err, models.ErrTaskNotFound)
+	}
+	if _, err = s.SelectTaskByID(ctx, id); !errors.Is(err, models.ErrTaskNotFound) {
+		t.Errorf("SelectTaskByID() of deleted task error = %v, want %v", err, models.ErrTaskNotFound)
+	}
+}
+
+func testShares(t *testing.T, s Storage) {
+	ctx := context.Background()
+	owner := createUser(t, s, "a@example.com")
+	guest := createUser(t, s, "b@example.com")
+	id := insertTask(t, s, models.Task{UserID: owner, Title: "Shared"})
+
+	if _, err := s.SelectTaskShare(ctx, id, guest); !errors.Is(err, models.ErrShareNotFound) {
+		t.Errorf("SelectTaskShare() before share error = %v, want %v", err, models.ErrShareNotFound)
+	}
+
+	for _, p := range []models.Permission{models.PermissionViewer, models.PermissionEditor} {
+		if err := s.UpsertTaskShare(ctx, models.TaskShare{TaskID: id, UserID: guest, Permission: p}); err != nil {
+			t.Fatalf("UpsertTaskShare(%s) error = %v", p, err)
+		}
+	}
+
+	share, err := s.SelectTaskShare(ctx, id, guest)
+	if err != nil {
+		t.Fatalf("SelectTaskShare() error = %v", err)
+	}
+	if share.Email != "b@example.com" || share.Permission != models.PermissionEditor {
+		t.Errorf("SelectTaskShare() = %+v, want editor b@example.com", share)
+	}
+	if shares, err := s.SelectTaskShares(ctx, id); err != nil || len(shares) != 1 {
+		t.Errorf("SelectTaskShares() = %+v, %v, want one share", shares, err)
+	}
+	if tasks, err := s.SelectTasksSharedWith(ctx, guest); err != nil || !slices.Equal(taskIDs(tasks), []int64{id}) {
+		t.Errorf("SelectTasksSharedWith() = %v, %v, want [%d]", taskIDs(tasks), err, id)
+	}
+
+	if err = s.UpdateTaskAssignee(ctx, id, &guest); err != nil {
+		t.Fatalf("UpdateTaskAssignee() error = %v", err)
+	}
+	if tasks, err := s.SelectTasksAssignedTo(ctx, guest); err != nil || !slices.Equal(taskIDs(tasks), []int64{id}) {
+		t.Errorf("SelectTasksAssignedTo() = %v, %v, want [%d]", taskIDs(tasks), err, id)
+	}
+
+	// revoking access unassigns the task
+	if err = s.DeleteTaskShare(ctx, id, guest); err != nil {
+		t.Fatalf("DeleteTaskShare() error = %v", err)
+	}
+	if err = s.DeleteTaskShare(ctx, id, guest); !errors.Is(err, models.ErrShareNotFound) {
+		t.Errorf("DeleteTaskShare() twice error = %v, want %v", err, models.ErrShareNotFound)
+	}
+	task, err := s.SelectTaskByID(ctx, id)
+	if err != nil || task.AssigneeID != nil {
+		t.Errorf("SelectTaskByID() after unshare = %+v, %v, want no assignee", task, err)
+	}
+}
+
+func testTaskChanges(t *testing.T, s Storage) {
+	ctx := context.Background()
+	owner := createUser(t, s, "a@example.com")
+
+	token, err := s.LastTaskChange(ctx)
+	if err != nil {
+		t.Fatalf("LastTaskChange() error = %v", err)
+	}
+
+	created := insertTask(t, s, models.Task{UserID: owner, Title: "Created"})
+	deleted := insertTask(t, s, models.Task{UserID: owner, Title: "Deleted"})
+	if err = s.DeleteTask(ctx, deleted); err != nil {
+		t.Fatalf("DeleteTask() error = %v", err)
+	}
+
+	changes, err := s.SelectTaskChanges(ctx, owner, token, 100)
+	if err != nil {
+		t.Fatalf("SelectTaskChanges() error = %v", err)
+	}
+	got := make(map[int64]models.TaskChange)
+	var last int64
+	for _, c := range changes {
+		if c.Version <= last {
+			t.Errorf("versions are not ascending: %+v", changes)
+		}
+		last = c.Version
+		got[c.TaskID] = c
+	}
+	if c, ok := got[created]; !ok || c.Deleted {
+		t.Errorf("change of task %d = %+v, want created", created, c)
+	}
+	if c, ok := got[deleted]; !ok || !c.Deleted {
+		t.Errorf("change of task %d = %+v, want deleted", deleted, c)
+	}
+
+	if head, err := s.LastTaskChange(ctx); err != nil || head != last {
+		t.Errorf("LastTaskChange() = %d, %v, want %d", head, err, last)
+	}
+	if changes, err = s.SelectTaskChanges(ctx, owner, last, 100); err != nil || len(changes) != 0 {
+		t.Errorf("SelectTaskChanges() after the last version = %+v, %v, want none", changes, err)
+	}
+}
+
+// changes of tasks the user can't see are skipped, a task unshared from the user stays in the log for them
+func testTaskChangesOfUser(t *testing.T, s Storage) {
+	ctx := context.Background()
+	owner := createUser(t, s, "a@example.com")
+	guest := createUser(t, s, "b@example.com")
+
+	own := insertTask(t, s, models.Task{UserID: owner, Title: "Own"})
+	shared := insertTask(t, s, models.Task{UserID: owner, Title: "Shared"})
+	unshared := insertTask(t, s, models.Task{UserID: owner, Title: "Unshared"})
+	guestTask := insertTask(t, s, models.Task{UserID: guest, Title: "Guest"})
+
+	token, err := s.LastTaskChange(ctx)
+	if err != nil {
+		t.Fatalf("LastTaskChange() error = %v", err)
+	}
+	for _, id := range []int64{shared, unshared} {
+		if err = s.UpsertTaskShare(ctx, models.TaskShare{TaskID: id, UserID: guest, Permission: models.PermissionViewer}); err != nil {
+			t.Fatalf("UpsertTaskShare() error = %v", err)
+		}
+	}
+	if err = s.DeleteTaskShare(ctx, unshared, guest); err != nil {
+		t.Fatalf("DeleteTaskShare() error = %v", err)
+	}
+	if err = s.UpdateStatusTask(ctx, own, owner, models.Done); err != nil {
+		t.Fatalf("UpdateStatusTask() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		userID int64
+		after  int64
+		want   []int64
+	}{
+		{"owner from scratch", owner, 0, []int64{own, shared, unshared}},
+		{"guest from scratch", guest, 0, []int64{guestTask, shared, unshared}},
+		{"guest after token", guest, token, []int64{shared, unshared}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := s.SelectTaskChanges(ctx, tt.userID, tt.after, 100)
+			if err != nil {
+				t.Fatalf("SelectTaskChanges() error = %v", err)
+			}
+			var got []int64
+			for _, c := range changes {
+				got = append(got, c.TaskID)
+			}
+			slices.Sort(got)
+			if want := slices.Sorted(slices.Values(tt.want)); !slices.Equal(got, want) {
+				t.Errorf("tasks = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func testSyncClientTasks(t *testing.T, s Storage) {
+	ctx := context.Background()
+	owner := createUser(t, s, "a@example.com")
+	first := insertTask(t, s, models.Task{UserID: owner, Title: "First"})
+	second := insertTask(t, s, models.Task{UserID: owner, Title: "Second"})
+
+	if _, err := s.SelectSyncClientTask(ctx, owner, "c1"); !errors.Is(err, models.ErrTaskNotFound) {
+		t.Errorf("SelectSyncClientTask() before insert error = %v, want %v", err, models.ErrTaskNotFound)
+	}
+	if err := s.InsertSyncClientTask(ctx, owner, "c1", first); err != nil {
+		t.Fatalf("InsertSyncClientTask() error = %v", err)
+	}
+	if err := s.InsertSyncClientTask(ctx, owner, "c1", second); !errors.Is(err, models.ErrSyncClientExists) {
+		t.Errorf("InsertSyncClientTask() of mapped id error = %v, want %v", err, models.ErrSyncClientExists)
+	}
+	if id, err := s.SelectSyncClientTask(ctx, owner, "c1"); err != nil || id != first {
+		t.Errorf("SelectSyncClientTask() = %d, %v, want %d", id, err, first)
+	}
+}
+
+func testWithinTxRollsBack(t *testing.T, s Storage) {
+	ctx := context.Background()
+	owner := createUser(t, s, "a@example.com")
+	kept := insertTask(t, s, models.Task{UserID: owner, Title: "Kept"})
+
+	fail := errors.New("failure")
+	var rolledBack int64
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if rolledBack, err = s.InsertTask(ctx, models.Task{UserID: owner, Title: "Rolled back"}); err != nil {
+			return err
+		}
+		if err = s.UpdateStatusTask(ctx, kept, owner, models.Done); err != nil {
+			return err
+		}
+		return fail
+	})
+	if !errors.Is(err, fail) {
+		t.Fatalf("WithinTx() error = %v, want %v", err, fail)
+	}
+
+	if _, err = s.SelectTaskByID(ctx, rolledBack); !errors.Is(err, models.ErrTaskNotFound) {
+		t.Errorf("SelectTaskByID() of task inserted by failed tx error = %v, want %v", err, models.ErrTaskNotFound)
+	}
+	if task, err := s.SelectTaskByID(ctx, kept); err != nil || task.Status != models.Pending {
+		t.Errorf("SelectTaskByID() of task updated by failed tx = %+v, %v, want Pending", task, err)
+	}
+
+	// a failed nested call is rolled back alone, the outer transaction commits its own writes
+	var outer, nested int64
+	err = s.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if outer, err = s.InsertTask(ctx, models.Task{UserID: owner, Title: "Outer"}); err != nil {
+			return err
+		}
+		if err = s.WithinTx(ctx, func(ctx context.Context) error {
+			if nested, err = s.InsertTask(ctx, models.Task{UserID: owner, Title: "Nested"}); err != nil {
+				return err
+			}
+			return fail
+		}); !errors.Is(err, fail) {
+			t.Errorf("nested WithinTx() error = %v, want %v", err, fail)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTx() error = %v", err)
+	}
+	if _, err = s.SelectTaskByID(ctx, outer); err != nil {
+		t.Errorf("SelectTaskByID() of task of the outer tx error = %v", err)
+	}
+	if _, err = s.SelectTaskByID(ctx, nested); !errors.Is(err, models.ErrTaskNotFound) {
+		t.Errorf("SelectTaskByID() of task of the failed nested tx error = %v, want %v", err, models.ErrTaskNotFound)
+	}
+}
+
+func testAfterCommit(t *testing.T, s Storage) {
+	ctx := context.Background()
+	owner := createUser(t, s, "a@example.com")
+
+	var calls []string
+	record := func(name string) func(context.Context) {
+		return func(context.Context) { calls = append(calls, name) }
+	}
+
+	s.AfterCommit(ctx, record("outside"))
+
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		s.AfterCommit(ctx, record("committed"))
+		if len(calls) != 1 {
+			t.Errorf("hook ran before commit, calls %v", calls)
+		}
+		_ = s.WithinTx(ctx, func(ctx context.Context) error {
+			s.AfterCommit(ctx, record("failed nested"))
+			return errors.New("nested failure")
+		})
+		_, err := s.InsertTask(ctx, models.Task{UserID: owner, Title: "In tx"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithinTx() error = %v", err)
+	}
+
+	fail := errors.New("failure")
+	if err = s.WithinTx(ctx, func(ctx context.Context) error {
+		s.AfterCommit(ctx, record("failed"))
+		return fail
+	}); !errors.Is(err, fail) {
+		t.Errorf("WithinTx() error = %v, want %v", err, fail)
+	}
+
+	if want := []string{"outside", "committed"}; !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+// a check made in WithinTx holds until the write, concurrent transactions can not both take the last place
+func testWithinTxSerializesChecks(t *testing.T, s Storage) {
+	ctx := context.Background()
+	owner := createUser(t, s, "a@example.com")
+
+	const limit, writers = 3, 10
+
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.WithinTx(ctx, func(ctx context.Context) error {
+				n, err := s.CountTasksByStatus(ctx, owner, nil, models.Pending)
+				if err != nil || n >= limit {
+					return err
+				}
+				// gives the other writers the time to count the same number
+				time.Sleep(5 * time.Millisecond)
+				_, err = s.InsertTask(ctx, models.Task{UserID: owner, Title: fmt.Sprintf("task %d", i)})
+				return err
+			})
+			if err != nil {
+				t.Errorf("WithinTx() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n, err := s.CountTasksByStatus(ctx, owner, nil, models.Pending); err != nil || n != limit {
+		t.Errorf("CountTasksByStatus() = %d, %v, want %d", n, err, limit)
+	}
+}
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"TaskList/pkg/client"
+	"context"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"net/http"
+	"time"
+)
+
+func newClient(server string, token string, httpClient *http.Client) *client.Client {
+	return client.New(server, client.WithToken(token), client.WithHTTPClient(httpClient))
+}
+
+func (m *model) filter() client.TaskFilter {
+	f := client.TaskFilter{Scope: scopes[m.scope], Text: m.search, Sort: "due"}
+	if c := categories[m.category]; c != "" {
+		f.Categories = []string{c}
+	}
+	return f
+}
+
+func (m *model) load() tea.Cmd {
+	m.seq++
+	m.loading = true
+	seq, filter, c := m.seq, m.filter(), m.client
+
+	return m.request(func(ctx context.Context) tea.Msg {
+		tasks, err := c.Tasks(ctx, filter)
+		return tasksMsg{seq: seq, tasks: tasks, err: err}
+	})
+}
+
+// startTicker starts reloading the list every refresh interval, once per program
+func (m *model) startTicker() tea.Cmd {
+	if m.ticking {
+		return nil
+	}
+	m.ticking = true
+	return m.tick()
+}
+
+func (m *model) tick() tea.Cmd {
+	if m.refresh <= 0 {
+		return nil
+	}
+	return tea.Tick(m.refresh, func(time.Time) tea.Msg {
+		return tickMsg{}
+	})
+}
+
+// login uses a separate client so requests in flight keep the old one
+func (m *model) login(email string, password string) tea.Cmd {
+	c := newClient(m.config.Server, "", m.httpClient)
+
+	return m.request(func(ctx context.Context) tea.Msg {
+		token, err := c.Login(ctx, email, password)
+		return loginMsg{token: token, err: err}
+	})
+}
+
+// toggle moves the task to the first done status of its workflow, done tasks go back to the first todo status
+func (m *model) toggle(task client.Task) tea.Cmd {
+	category := "done"
+	if task.StatusCategory == "done" {
+		category = "todo"
+	}
+	c := m.client
+
+	return m.request(func(ctx context.Context) tea.Msg {
+		statuses, err := c.Statuses(ctx, task.ProjectID)
+		if err != nil {
+			return doneMsg{err: err}
+		}
+		for _, s := range statuses {
+			if s.Category != category {
+				continue
+			}
+			if _, err = c.UpdateTask(ctx, task.ID, client.UpdateTaskRequest{Status: &s.Name}); err != nil {
+				return doneMsg{err: err}
+			}
+			return doneMsg{message: fmt.Sprintf("%q moved to %s", task.Title, s.Name)}
+		}
+		return doneMsg{err: fmt.Errorf("workflow has no %s status", category)}
+	})
+}
+
+func (m *model) update(task client.Task, patch client.UpdateTaskRequest, message string) tea.Cmd {
+	c := m.client
+
+	return m.request(func(ctx context.Context) tea.Msg {
+		_, err := c.UpdateTask(ctx, task.ID, patch)
+		return doneMsg{message: message, err: err}
+	})
+}
+
+func (m *model) quickAdd(text string) tea.Cmd {
+	c := m.client
+
+	return m.request(func(ctx context.Context) tea.Msg {
+		id, err := c.QuickAdd(ctx, text)
+		return doneMsg{message: fmt.Sprintf("task %d created", id), err: err}
+	})
+}
+
+func (m *model) delete(task client.Task) tea.Cmd {
+	c := m.client
+
+	return m.request(func(ctx context.Context) tea.Msg {
+		err := c.DeleteTask(ctx, task.ID)
+		return doneMsg{message: fmt.Sprintf("%q deleted", task.Title), err: err}
+	})
+}
+
+// request runs the call outside of the update loop with the request timeout
+func (m *model) request(call func(ctx context.Context) tea.Msg) tea.Cmd {
+	parent := m.ctx
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(parent, requestTimeout)
+		defer cancel()
+		return call(ctx)
+	}
+}
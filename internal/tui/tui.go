@@ -0,0 +1,478 @@
+// Package tui is the interactive task board of cmd/tasklist-tui. It uses the REST API
+// through pkg/client and shares the config file and the token with tasklist-cli
+package tui
+
+import (
+	"TaskList/internal/cli"
+	"TaskList/pkg/client"
+	"context"
+	"errors"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"net/http"
+	"time"
+)
+
+const requestTimeout = 15 * time.Second
+
+type Options struct {
+	// ConfigPath and Server are resolved like in tasklist-cli when empty
+	ConfigPath string
+	Server     string
+	// Refresh is the interval of reloading the list, 0 disables it
+	Refresh    time.Duration
+	HTTPClient *http.Client
+}
+
+// Run shows the board until the user quits or ctx is done
+func Run(ctx context.Context, opts Options) error {
+	configPath, cfg, err := cli.ResolveConfig(opts.ConfigPath, opts.Server)
+	if err != nil {
+		return err
+	}
+
+	m := newModel(ctx, opts, configPath, cfg)
+	_, err = tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	if errors.Is(err, tea.ErrProgramKilled) && ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+type mode int
+
+const (
+	modeLogin mode = iota
+	modeList
+	modeTitle
+	modeDescription
+	modeSearch
+	modeNew
+	modeDelete
+)
+
+var (
+	scopes     = []string{"owned", "assigned", "shared", "all"}
+	categories = []string{"", "todo", "in_progress", "done"}
+)
+
+type model struct {
+	ctx        context.Context
+	httpClient *http.Client
+	client     *client.Client
+	configPath string
+	config     cli.Config
+	refresh    time.Duration
+
+	mode   mode
+	tasks  []client.Task
+	cursor int
+	offset int
+	width  int
+	height int
+
+	scope    int
+	category int
+	search   string
+
+	// seq is the number of the latest load, responses of older loads are dropped
+	seq     int
+	loading bool
+	ticking bool
+	message string
+	err     error
+
+	email    textinput.Model
+	password textinput.Model
+	input    textinput.Model
+	editor   textarea.Model
+}
+
+type (
+	tasksMsg struct {
+		seq   int
+		tasks []client.Task
+		err   error
+	}
+	// doneMsg is the result of a change, the list is reloaded after it
+	doneMsg struct {
+		message string
+		err     error
+	}
+	loginMsg struct {
+		token string
+		err   error
+	}
+	tickMsg struct{}
+)
+
+func newModel(ctx context.Context, opts Options, configPath string, cfg cli.Config) *model {
+	m := &model{
+		ctx:        ctx,
+		httpClient: opts.HTTPClient,
+		client:     newClient(cfg.Server, cfg.Token, opts.HTTPClient),
+		configPath: configPath,
+		config:     cfg,
+		refresh:    opts.Refresh,
+		mode:       modeList,
+	}
+
+	m.email = textinput.New()
+	m.email.Prompt = "Email:    "
+	m.email.SetValue(cfg.Email)
+
+	m.password = textinput.New()
+	m.password.Prompt = "Password: "
+	m.password.EchoMode = textinput.EchoPassword
+
+	m.input = textinput.New()
+	m.input.CharLimit = 255
+
+	m.editor = textarea.New()
+	m.editor.ShowLineNumbers = false
+	m.editor.CharLimit = 0
+	m.editor.SetHeight(editorHeight)
+
+	if cfg.Token == "" {
+		m.toLogin("")
+	}
+
+	return m
+}
+
+func (m *model) Init() tea.Cmd {
+	if m.mode == modeLogin {
+		return textinput.Blink
+	}
+	return tea.Batch(m.load(), m.startTicker())
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.editor.SetWidth(msg.Width)
+		m.input.Width = msg.Width - 20
+		m.scroll()
+		return m, nil
+
+	case tasksMsg:
+		if msg.seq != m.seq {
+			return m, nil
+		}
+		m.loading = false
+		if msg.err != nil {
+			return m, m.fail(msg.err)
+		}
+		m.setTasks(msg.tasks)
+		m.err = nil
+		return m, nil
+
+	case doneMsg:
+		if msg.err != nil {
+			return m, m.fail(msg.err)
+		}
+		m.message, m.err = msg.message, nil
+		return m, m.load()
+
+	case loginMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			m.password.SetValue("")
+			return m, nil
+		}
+		return m, m.loggedIn(msg.token)
+
+	case tickMsg:
+		// editing keeps the rows in place, the next tick reloads
+		if m.mode == modeList && !m.loading {
+			return m, tea.Batch(m.load(), m.tick())
+		}
+		return m, m.tick()
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+		switch m.mode {
+		case modeLogin:
+			return m, m.updateLogin(msg)
+		case modeList:
+			return m, m.updateList(msg)
+		case modeTitle, modeSearch, modeNew:
+			return m, m.updateInput(msg)
+		case modeDescription:
+			return m, m.updateEditor(msg)
+		case modeDelete:
+			return m, m.updateDelete(msg)
+		}
+	}
+
+	return m, m.updateFocused(msg)
+}
+
+// updateFocused passes messages like cursor blinks to the focused input
+func (m *model) updateFocused(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	switch m.mode {
+	case modeLogin:
+		if m.email.Focused() {
+			m.email, cmd = m.email.Update(msg)
+		} else {
+			m.password, cmd = m.password.Update(msg)
+		}
+	case modeTitle, modeSearch, modeNew:
+		m.input, cmd = m.input.Update(msg)
+	case modeDescription:
+		m.editor, cmd = m.editor.Update(msg)
+	}
+	return cmd
+}
+
+func (m *model) updateLogin(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		return tea.Quit
+	case "tab", "shift+tab", "up", "down":
+		return m.focusLogin(!m.email.Focused())
+	case "enter":
+		if m.email.Focused() {
+			return m.focusLogin(false)
+		}
+		if m.email.Value() == "" {
+			return m.focusLogin(true)
+		}
+		m.err = nil
+		m.message = "logging in..."
+		return m.login(m.email.Value(), m.password.Value())
+	}
+	return m.updateFocused(msg)
+}
+
+func (m *model) updateList(msg tea.KeyMsg) tea.Cmd {
+	task, selected := m.selected()
+
+	switch msg.String() {
+	case "q":
+		return tea.Quit
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "pgup", "ctrl+u":
+		m.move(-m.listHeight())
+	case "pgdown", "ctrl+d":
+		m.move(m.listHeight())
+	case "home", "g":
+		m.move(-len(m.tasks))
+	case "end", "G":
+		m.move(len(m.tasks))
+	case "r":
+		m.message = ""
+		return m.load()
+	case "tab":
+		m.category = (m.category + 1) % len(categories)
+		return m.load()
+	case "shift+tab":
+		m.category = (m.category + len(categories) - 1) % len(categories)
+		return m.load()
+	case "s":
+		m.scope = (m.scope + 1) % len(scopes)
+		return m.load()
+	case "/":
+		return m.edit(modeSearch, "Search: ", m.search)
+	case "esc":
+		if m.search != "" {
+			m.search = ""
+			return m.load()
+		}
+	case "n", "a":
+		return m.edit(modeNew, "New task: ", "")
+	case " ", "x":
+		if selected {
+			return m.toggle(task)
+		}
+	case "e", "enter":
+		if selected {
+			return m.edit(modeTitle, "Title: ", task.Title)
+		}
+	case "d":
+		if selected {
+			m.mode = modeDescription
+			m.editor.SetValue(task.Description)
+			return m.editor.Focus()
+		}
+	case "X", "delete":
+		if selected {
+			m.mode = modeDelete
+		}
+	}
+
+	return nil
+}
+
+func (m *model) updateInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.closeEditor()
+		return nil
+	case "enter":
+		value := m.input.Value()
+		mode := m.mode
+		m.closeEditor()
+
+		switch mode {
+		case modeSearch:
+			m.search = value
+			return m.load()
+		case modeNew:
+			if value == "" {
+				return nil
+			}
+			return m.quickAdd(value)
+		case modeTitle:
+			task, ok := m.selected()
+			if !ok || value == "" || value == task.Title {
+				return nil
+			}
+			return m.update(task, client.UpdateTaskRequest{Title: &value}, "title saved")
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return cmd
+}
+
+func (m *model) updateEditor(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.closeEditor()
+		return nil
+	case "ctrl+s":
+		value := m.editor.Value()
+		m.closeEditor()
+		task, ok := m.selected()
+		if !ok || value == task.Description {
+			return nil
+		}
+		return m.update(task, client.UpdateTaskRequest{Description: &value}, "description saved")
+	}
+
+	var cmd tea.Cmd
+	m.editor, cmd = m.editor.Update(msg)
+	return cmd
+}
+
+func (m *model) updateDelete(msg tea.KeyMsg) tea.Cmd {
+	m.mode = modeList
+	task, ok := m.selected()
+	if msg.String() != "y" || !ok {
+		m.message = ""
+		return nil
+	}
+	return m.delete(task)
+}
+
+func (m *model) edit(mode mode, prompt string, value string) tea.Cmd {
+	m.mode = mode
+	m.input.Prompt = prompt
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	return m.input.Focus()
+}
+
+func (m *model) closeEditor() {
+	m.mode = modeList
+	m.input.Blur()
+	m.editor.Blur()
+}
+
+func (m *model) focusLogin(email bool) tea.Cmd {
+	if email {
+		m.password.Blur()
+		return m.email.Focus()
+	}
+	m.email.Blur()
+	return m.password.Focus()
+}
+
+// fail shows the error, auth errors send the user to the login form
+func (m *model) fail(err error) tea.Cmd {
+	if errors.Is(err, client.ErrNoToken) || errors.Is(err, client.ErrTokenExpired) || errors.Is(err, client.ErrUnauthorized) {
+		message := ""
+		if !errors.Is(err, client.ErrNoToken) {
+			message = "session expired, log in again"
+		}
+		return m.toLogin(message)
+	}
+	m.err = err
+	return nil
+}
+
+func (m *model) toLogin(message string) tea.Cmd {
+	m.closeEditor()
+	m.mode = modeLogin
+	m.message = message
+	m.err = nil
+	m.password.SetValue("")
+	return m.focusLogin(m.email.Value() == "")
+}
+
+func (m *model) loggedIn(token string) tea.Cmd {
+	m.config.Email = m.email.Value()
+	m.config.Token = token
+	m.client = newClient(m.config.Server, token, m.httpClient)
+
+	m.password.SetValue("")
+	m.email.Blur()
+	m.password.Blur()
+	m.mode = modeList
+	m.message = "logged in as " + m.config.Email
+
+	if err := cli.SaveConfig(m.configPath, m.config); err != nil {
+		m.err = err
+	}
+
+	return tea.Batch(m.load(), m.startTicker())
+}
+
+// setTasks replaces the list keeping the cursor on the same task
+func (m *model) setTasks(tasks []client.Task) {
+	current, ok := m.selected()
+	m.tasks = tasks
+	if ok {
+		for i, t := range tasks {
+			if t.ID == current.ID {
+				m.cursor = i
+				break
+			}
+		}
+	}
+	m.move(0)
+}
+
+func (m *model) selected() (client.Task, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.tasks) {
+		return client.Task{}, false
+	}
+	return m.tasks[m.cursor], true
+}
+
+func (m *model) move(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.tasks)-1))
+	m.scroll()
+}
+
+// scroll keeps the cursor inside the visible rows
+func (m *model) scroll() {
+	height := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+	m.offset = max(0, min(m.offset, len(m.tasks)-height))
+}
@@ -0,0 +1,220 @@
+package tui
+
+import (
+	"fmt"
+	"github.com/charmbracelet/lipgloss"
+	"strings"
+	"time"
+)
+
+const (
+	detailHeight = 4
+	editorHeight = 6
+	// header, column names, separator, message and help lines
+	chromeHeight = 5
+)
+
+var (
+	boldStyle     = lipgloss.NewStyle().Bold(true)
+	faintStyle    = lipgloss.NewStyle().Faint(true)
+	selectedStyle = lipgloss.NewStyle().Reverse(true)
+	doneStyle     = lipgloss.NewStyle().Faint(true).Strikethrough(true)
+	overdueStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true)
+)
+
+var help = map[mode]string{
+	modeList:        "↑/↓ move  space done  e title  d description  n new  X delete  / search  tab category  s scope  r refresh  q quit",
+	modeTitle:       "enter save  esc cancel",
+	modeSearch:      "enter search  esc cancel",
+	modeNew:         "enter create, the line is parsed like \"Pay rent tomorrow 9am #home !high\"  esc cancel",
+	modeDescription: "ctrl+s save  esc cancel",
+	modeDelete:      "y delete  any other key cancels",
+	modeLogin:       "tab switch field  enter log in  esc quit",
+}
+
+func (m *model) View() string {
+	if m.mode == modeLogin {
+		return m.loginView()
+	}
+
+	var b strings.Builder
+	b.WriteString(m.headerView())
+	b.WriteByte('\n')
+	b.WriteString(faintStyle.Render(m.row("", "", "TITLE", "STATUS", "PRIORITY", "DUE", "TAGS")))
+	b.WriteByte('\n')
+
+	height := m.listHeight()
+	for i := m.offset; i < m.offset+height; i++ {
+		if i < len(m.tasks) {
+			b.WriteString(m.taskView(i))
+		} else if i == 0 && !m.loading {
+			b.WriteString(faintStyle.Render("  no tasks, press n to add one"))
+		}
+		b.WriteByte('\n')
+	}
+
+	b.WriteString(faintStyle.Render(strings.Repeat("─", max(m.width, 1))))
+	b.WriteByte('\n')
+	b.WriteString(m.detailView())
+	b.WriteByte('\n')
+	b.WriteString(m.messageView())
+	b.WriteByte('\n')
+	b.WriteString(faintStyle.Render(help[m.mode]))
+
+	return b.String()
+}
+
+func (m *model) headerView() string {
+	category := categories[m.category]
+	if category == "" {
+		category = "all"
+	}
+	filters := fmt.Sprintf("scope: %s  category: %s", scopes[m.scope], category)
+	if m.search != "" {
+		filters += fmt.Sprintf("  search: %q", m.search)
+	}
+
+	count := fmt.Sprintf("%d tasks", len(m.tasks))
+	if len(m.tasks) == 1 {
+		count = "1 task"
+	}
+	if m.loading {
+		count = "loading..."
+	}
+
+	return boldStyle.Render("TaskList") + "  " + filters + "  " + faintStyle.Render(count)
+}
+
+func (m *model) taskView(i int) string {
+	t := m.tasks[i]
+	selected := i == m.cursor
+
+	mark := "[ ]"
+	switch t.StatusCategory {
+	case "in_progress":
+		mark = "[~]"
+	case "done":
+		mark = "[x]"
+	}
+
+	if selected && m.mode == modeTitle {
+		return "› " + mark + " " + m.input.View()
+	}
+
+	cursor := ""
+	if selected {
+		cursor = "›"
+	}
+	row := m.row(cursor, mark, t.Title, t.Status, t.Priority, formatDue(t.Due), strings.Join(t.Tags, " "))
+
+	switch {
+	case selected:
+		return selectedStyle.Render(row)
+	case t.StatusCategory == "done":
+		return doneStyle.Render(row)
+	case t.Due != nil && t.Due.Before(time.Now()):
+		return overdueStyle.Render(row)
+	}
+	return row
+}
+
+// row lays out the columns, the title takes the width left by the other columns
+func (m *model) row(cursor, mark, title, status, priority, due, tags string) string {
+	titleWidth := max(m.width-2-4-15-9-17-16, 12)
+	return fmt.Sprintf("%-2s%-4s%-*s %-14s %-8s %-16s %s",
+		cursor, mark, titleWidth-1, truncate(title, titleWidth-1), truncate(status, 14), priority, due, truncate(tags, 16))
+}
+
+func (m *model) detailView() string {
+	if m.mode == modeDescription {
+		return m.editor.View()
+	}
+
+	t, ok := m.selected()
+	if !ok {
+		return strings.Repeat("\n", detailHeight-1)
+	}
+
+	lines := []string{boldStyle.Render(t.Title)}
+	if t.Description == "" {
+		lines = append(lines, faintStyle.Render("no description, press d to add one"))
+	} else {
+		lines = append(lines, strings.Split(t.Description, "\n")...)
+	}
+	for len(lines) < detailHeight {
+		lines = append(lines, "")
+	}
+	if len(lines) > detailHeight {
+		lines = append(lines[:detailHeight-1], faintStyle.Render("…"))
+	}
+	for i := range lines {
+		lines[i] = truncate(lines[i], max(m.width, 1))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m *model) messageView() string {
+	switch m.mode {
+	case modeSearch, modeNew:
+		return m.input.View()
+	case modeDelete:
+		t, _ := m.selected()
+		return errorStyle.Render(fmt.Sprintf("Delete %q? y/n", t.Title))
+	}
+
+	if m.err != nil {
+		return errorStyle.Render(m.err.Error())
+	}
+	return m.message
+}
+
+func (m *model) loginView() string {
+	lines := []string{
+		boldStyle.Render("TaskList") + "  " + faintStyle.Render(m.config.Server),
+		"",
+		m.email.View(),
+		m.password.View(),
+		"",
+	}
+	switch {
+	case m.err != nil:
+		lines = append(lines, errorStyle.Render(m.err.Error()))
+	default:
+		lines = append(lines, m.message)
+	}
+	lines = append(lines, "", faintStyle.Render(help[modeLogin]))
+
+	return lipgloss.NewStyle().Padding(1, 2).Render(strings.Join(lines, "\n"))
+}
+
+func (m *model) listHeight() int {
+	detail := detailHeight
+	if m.mode == modeDescription {
+		detail = editorHeight
+	}
+	return max(m.height-chromeHeight-detail, 1)
+}
+
+func formatDue(due *time.Time) string {
+	if due == nil {
+		return ""
+	}
+	return due.Local().Format("Mon Jan 02 15:04")
+}
+
+// truncate cuts the string to the width in runes, styled strings are not cut
+func truncate(s string, width int) string {
+	if strings.Contains(s, "\x1b") {
+		return s
+	}
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(r[:width])
+	}
+	return string(r[:width-1]) + "…"
+}
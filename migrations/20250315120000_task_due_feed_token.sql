@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN due_at datetime;
+ALTER TABLE users ADD COLUMN feed_token text;
+
+CREATE UNIQUE INDEX idx_users_feed_token ON users (feed_token);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX if exists idx_users_feed_token;
+
+ALTER TABLE users DROP COLUMN feed_token;
+ALTER TABLE tasks DROP COLUMN due_at;
+-- +goose StatementEnd
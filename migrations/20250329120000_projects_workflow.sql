@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE projects
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER  NOT NULL,
+    name       TEXT     NOT NULL,
+    created_at datetime NOT NULL,
+    updated_at datetime NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_projects_userid ON projects (user_id);
+
+ALTER TABLE tasks ADD COLUMN project_id INTEGER REFERENCES projects (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_projectid ON tasks (project_id);
+
+CREATE TABLE workflow_statuses
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER  NOT NULL,
+    project_id INTEGER,
+    name       TEXT     NOT NULL,
+    category   TEXT     NOT NULL,
+    position   INTEGER  NOT NULL,
+    wip_limit  INTEGER  NOT NULL DEFAULT 0,
+    created_at datetime NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_workflow_statuses_scope_name ON workflow_statuses (user_id, ifnull(project_id, 0), name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE if exists workflow_statuses;
+
+DROP INDEX if exists idx_tasks_projectid;
+ALTER TABLE tasks DROP COLUMN project_id;
+
+DROP TABLE if exists projects;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE custom_fields
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER  NOT NULL,
+    project_id INTEGER  NOT NULL,
+    name       TEXT     NOT NULL,
+    type       TEXT     NOT NULL,
+    options    TEXT     NOT NULL DEFAULT '[]',
+    position   INTEGER  NOT NULL DEFAULT 0,
+    created_at datetime NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_custom_fields_project_name ON custom_fields (project_id, name);
+
+CREATE TABLE task_field_values
+(
+    task_id  INTEGER NOT NULL,
+    field_id INTEGER NOT NULL,
+    value    TEXT    NOT NULL,
+    PRIMARY KEY (task_id, field_id),
+    FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    FOREIGN KEY (field_id) REFERENCES custom_fields (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_field_values_field ON task_field_values (field_id, value);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE if exists task_field_values;
+DROP TABLE if exists custom_fields;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE smart_lists
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER  NOT NULL,
+    name       TEXT     NOT NULL,
+    query      TEXT     NOT NULL,
+    created_at datetime NOT NULL,
+    updated_at datetime NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_smart_lists_userid ON smart_lists (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE if exists smart_lists;
+-- +goose StatementEnd
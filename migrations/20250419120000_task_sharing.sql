@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN assignee_id INTEGER REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_assignee ON tasks (assignee_id);
+
+CREATE TABLE task_shares
+(
+    task_id    INTEGER  NOT NULL,
+    user_id    INTEGER  NOT NULL,
+    permission TEXT     NOT NULL,
+    created_at datetime NOT NULL,
+    PRIMARY KEY (task_id, user_id),
+    FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_shares_user ON task_shares (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE if exists task_shares;
+DROP INDEX if exists idx_tasks_assignee;
+ALTER TABLE tasks DROP COLUMN assignee_id;
+-- +goose StatementEnd
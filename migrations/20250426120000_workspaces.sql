@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE workspaces
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id   INTEGER  NOT NULL,
+    name       TEXT     NOT NULL,
+    created_at datetime NOT NULL,
+    updated_at datetime NOT NULL,
+    FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE workspace_members
+(
+    workspace_id INTEGER  NOT NULL,
+    user_id      INTEGER  NOT NULL,
+    role         TEXT     NOT NULL,
+    created_at   datetime NOT NULL,
+    PRIMARY KEY (workspace_id, user_id),
+    FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_workspace_members_user ON workspace_members (user_id);
+
+CREATE TABLE workspace_invitations
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    workspace_id INTEGER  NOT NULL,
+    email        TEXT     NOT NULL,
+    role         TEXT     NOT NULL,
+    token        TEXT     NOT NULL,
+    invited_by   INTEGER  NOT NULL,
+    created_at   datetime NOT NULL,
+    expires_at   datetime NOT NULL,
+    accepted_at  datetime,
+    FOREIGN KEY (workspace_id) REFERENCES workspaces (id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_workspace_invitations_token ON workspace_invitations (token);
+
+ALTER TABLE projects ADD COLUMN workspace_id INTEGER REFERENCES workspaces (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_projects_workspace ON projects (workspace_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX if exists idx_projects_workspace;
+ALTER TABLE projects DROP COLUMN workspace_id;
+DROP TABLE if exists workspace_invitations;
+DROP TABLE if exists workspace_members;
+DROP TABLE if exists workspaces;
+-- +goose StatementEnd
@@ -0,0 +1,60 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE tasks ADD COLUMN created_by INTEGER;
+
+CREATE TABLE task_comments
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id    INTEGER  NOT NULL,
+    user_id    INTEGER  NOT NULL,
+    body       TEXT     NOT NULL,
+    created_at datetime NOT NULL,
+    FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_comments_task ON task_comments (task_id, created_at);
+
+CREATE TABLE task_watchers
+(
+    task_id    INTEGER  NOT NULL,
+    user_id    INTEGER  NOT NULL,
+    created_at datetime NOT NULL,
+    PRIMARY KEY (task_id, user_id),
+    FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE notifications
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER  NOT NULL,
+    task_id    INTEGER  NOT NULL,
+    actor_id   INTEGER  NOT NULL,
+    event      TEXT     NOT NULL,
+    message    TEXT     NOT NULL,
+    read_at    datetime,
+    created_at datetime NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_notifications_user ON notifications (user_id, created_at);
+
+CREATE TABLE notification_preferences
+(
+    user_id INTEGER NOT NULL,
+    event   TEXT    NOT NULL,
+    enabled INTEGER NOT NULL,
+    PRIMARY KEY (user_id, event),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE if exists notification_preferences;
+DROP TABLE if exists notifications;
+DROP TABLE if exists task_watchers;
+DROP TABLE if exists task_comments;
+ALTER TABLE tasks DROP COLUMN created_by;
+-- +goose StatementEnd
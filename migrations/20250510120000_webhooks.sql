@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE webhooks
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    INTEGER  NOT NULL,
+    url        TEXT     NOT NULL,
+    secret     TEXT     NOT NULL,
+    events     TEXT     NOT NULL,
+    active     INTEGER  NOT NULL DEFAULT 1,
+    created_at datetime NOT NULL,
+    updated_at datetime NOT NULL,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhooks_user ON webhooks (user_id);
+
+CREATE TABLE webhook_deliveries
+(
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id      INTEGER  NOT NULL,
+    event           TEXT     NOT NULL,
+    payload         BLOB     NOT NULL,
+    status          TEXT     NOT NULL,
+    attempts        INTEGER  NOT NULL DEFAULT 0,
+    response_code   INTEGER,
+    error           TEXT     NOT NULL DEFAULT '',
+    redelivery_of   INTEGER,
+    next_attempt_at datetime,
+    last_attempt_at datetime,
+    created_at      datetime NOT NULL,
+    FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, created_at);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE if exists webhook_deliveries;
+DROP TABLE if exists webhooks;
+-- +goose StatementEnd
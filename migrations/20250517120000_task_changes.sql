@@ -0,0 +1,160 @@
+-- +goose Up
+-- +goose StatementBegin
+-- task_changes is the change log for sync, seq is the version of the change.
+-- Rows are written by triggers so every writer of tasks is covered,
+-- user_id is set for access rows and is the user who got or lost the share
+CREATE TABLE task_changes
+(
+    seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id    INTEGER  NOT NULL,
+    field      TEXT     NOT NULL,
+    user_id    INTEGER,
+    changed_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_task_changes_task ON task_changes (task_id, field);
+
+-- task_tombstones keep the owner, project and assignee of deleted tasks to decide who is told about deletion
+CREATE TABLE task_tombstones
+(
+    task_id     INTEGER PRIMARY KEY,
+    user_id     INTEGER  NOT NULL,
+    project_id  INTEGER,
+    assignee_id INTEGER,
+    deleted_at  datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- sync_client_ids make creation of tasks from offline clients idempotent
+CREATE TABLE sync_client_ids
+(
+    user_id    INTEGER  NOT NULL,
+    client_id  TEXT     NOT NULL,
+    task_id    INTEGER  NOT NULL,
+    created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, client_id)
+);
+
+INSERT INTO task_changes (task_id, field) SELECT id, 'created' FROM tasks ORDER BY id;
+
+CREATE TRIGGER task_changes_insert AFTER INSERT ON tasks
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'created');
+END;
+
+CREATE TRIGGER task_changes_delete AFTER DELETE ON tasks
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (OLD.id, 'deleted');
+    INSERT OR REPLACE INTO task_tombstones (task_id, user_id, project_id, assignee_id)
+    VALUES (OLD.id, OLD.user_id, OLD.project_id, OLD.assignee_id);
+END;
+
+CREATE TRIGGER task_changes_title AFTER UPDATE OF task_name ON tasks
+    WHEN OLD.task_name IS NOT NEW.task_name
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'title');
+END;
+
+CREATE TRIGGER task_changes_description AFTER UPDATE OF description ON tasks
+    WHEN OLD.description IS NOT NEW.description
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'description');
+END;
+
+CREATE TRIGGER task_changes_status AFTER UPDATE OF status ON tasks
+    WHEN OLD.status IS NOT NEW.status
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'status');
+END;
+
+CREATE TRIGGER task_changes_priority AFTER UPDATE OF priority ON tasks
+    WHEN OLD.priority IS NOT NEW.priority
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'priority');
+END;
+
+CREATE TRIGGER task_changes_recurrence AFTER UPDATE OF recurrence ON tasks
+    WHEN OLD.recurrence IS NOT NEW.recurrence
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'recurrence');
+END;
+
+CREATE TRIGGER task_changes_due AFTER UPDATE OF due_at ON tasks
+    WHEN OLD.due_at IS NOT NEW.due_at
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'due');
+END;
+
+CREATE TRIGGER task_changes_assignee AFTER UPDATE OF assignee_id ON tasks
+    WHEN OLD.assignee_id IS NOT NEW.assignee_id
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'assignee');
+END;
+
+CREATE TRIGGER task_changes_project AFTER UPDATE OF project_id ON tasks
+    WHEN OLD.project_id IS NOT NEW.project_id
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'project');
+END;
+
+CREATE TRIGGER task_changes_tags_insert AFTER INSERT ON task_tags
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (NEW.task_id, 'tags');
+END;
+
+CREATE TRIGGER task_changes_tags_delete AFTER DELETE ON task_tags
+    WHEN EXISTS (SELECT 1 FROM tasks WHERE id = OLD.task_id)
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (OLD.task_id, 'tags');
+END;
+
+CREATE TRIGGER task_changes_fields_insert AFTER INSERT ON task_field_values
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (NEW.task_id, 'custom_fields');
+END;
+
+CREATE TRIGGER task_changes_fields_update AFTER UPDATE ON task_field_values
+    WHEN OLD.value IS NOT NEW.value
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (NEW.task_id, 'custom_fields');
+END;
+
+CREATE TRIGGER task_changes_fields_delete AFTER DELETE ON task_field_values
+    WHEN EXISTS (SELECT 1 FROM tasks WHERE id = OLD.task_id)
+BEGIN
+    INSERT INTO task_changes (task_id, field) VALUES (OLD.task_id, 'custom_fields');
+END;
+
+CREATE TRIGGER task_changes_share_insert AFTER INSERT ON task_shares
+BEGIN
+    INSERT INTO task_changes (task_id, field, user_id) VALUES (NEW.task_id, 'access', NEW.user_id);
+END;
+
+CREATE TRIGGER task_changes_share_delete AFTER DELETE ON task_shares
+BEGIN
+    INSERT INTO task_changes (task_id, field, user_id) VALUES (OLD.task_id, 'access', OLD.user_id);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER if exists task_changes_share_delete;
+DROP TRIGGER if exists task_changes_share_insert;
+DROP TRIGGER if exists task_changes_fields_delete;
+DROP TRIGGER if exists task_changes_fields_update;
+DROP TRIGGER if exists task_changes_fields_insert;
+DROP TRIGGER if exists task_changes_tags_delete;
+DROP TRIGGER if exists task_changes_tags_insert;
+DROP TRIGGER if exists task_changes_project;
+DROP TRIGGER if exists task_changes_assignee;
+DROP TRIGGER if exists task_changes_due;
+DROP TRIGGER if exists task_changes_recurrence;
+DROP TRIGGER if exists task_changes_priority;
+DROP TRIGGER if exists task_changes_status;
+DROP TRIGGER if exists task_changes_description;
+DROP TRIGGER if exists task_changes_title;
+DROP TRIGGER if exists task_changes_delete;
+DROP TRIGGER if exists task_changes_insert;
+DROP TABLE if exists sync_client_ids;
+DROP TABLE if exists task_tombstones;
+DROP TABLE if exists task_changes;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE app_passwords
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id      INTEGER  NOT NULL,
+    name         TEXT     NOT NULL,
+    token_hash   TEXT     NOT NULL UNIQUE,
+    created_at   datetime NOT NULL,
+    last_used_at datetime,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_app_passwords_user ON app_passwords (user_id);
+
+CREATE TABLE caldav_objects
+(
+    task_id INTEGER PRIMARY KEY,
+    name    TEXT NOT NULL,
+    uid     TEXT NOT NULL,
+    FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE if exists caldav_objects;
+DROP TABLE if exists app_passwords;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- watchers of a deleted task are read by notifications after the deletion and removed by them,
+-- so task_watchers must not cascade from tasks once foreign keys are enforced
+CREATE TABLE task_watchers_new
+(
+    task_id    INTEGER  NOT NULL,
+    user_id    INTEGER  NOT NULL,
+    created_at datetime NOT NULL,
+    PRIMARY KEY (task_id, user_id),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+INSERT INTO task_watchers_new (task_id, user_id, created_at) SELECT task_id, user_id, created_at FROM task_watchers;
+DROP TABLE task_watchers;
+ALTER TABLE task_watchers_new RENAME TO task_watchers;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE TABLE task_watchers_old
+(
+    task_id    INTEGER  NOT NULL,
+    user_id    INTEGER  NOT NULL,
+    created_at datetime NOT NULL,
+    PRIMARY KEY (task_id, user_id),
+    FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+INSERT INTO task_watchers_old (task_id, user_id, created_at)
+SELECT w.task_id, w.user_id, w.created_at FROM task_watchers w WHERE EXISTS (SELECT 1 FROM tasks t WHERE t.id = w.task_id);
+DROP TABLE task_watchers;
+ALTER TABLE task_watchers_old RENAME TO task_watchers;
+-- +goose StatementEnd
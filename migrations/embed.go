@@ -0,0 +1,24 @@
+// Package migrations embeds the goose migrations so the server binary applies them without goose installed
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS holds the sqlite migrations
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed postgres/*.sql
+var postgres embed.FS
+
+// Postgres returns the postgres migrations, they describe the same schema in the postgres dialect
+func Postgres() fs.FS {
+	sub, err := fs.Sub(postgres, "postgres")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
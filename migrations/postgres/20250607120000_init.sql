@@ -0,0 +1,416 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE users
+(
+    id            BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    email         TEXT        NOT NULL UNIQUE,
+    password_hash TEXT        NOT NULL,
+    feed_token    TEXT UNIQUE,
+    timezone      TEXT        NOT NULL DEFAULT 'UTC',
+    created_at    TIMESTAMPTZ NOT NULL,
+    disabled_at   TIMESTAMPTZ
+);
+
+CREATE TABLE workspaces
+(
+    id         BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    owner_id   BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE workspace_members
+(
+    workspace_id BIGINT      NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    user_id      BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role         TEXT        NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX idx_workspace_members_user ON workspace_members (user_id);
+
+CREATE TABLE workspace_invitations
+(
+    id           BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    workspace_id BIGINT      NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+    email        TEXT        NOT NULL,
+    role         TEXT        NOT NULL,
+    token        TEXT        NOT NULL UNIQUE,
+    invited_by   BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at   TIMESTAMPTZ NOT NULL,
+    expires_at   TIMESTAMPTZ NOT NULL,
+    accepted_at  TIMESTAMPTZ
+);
+
+CREATE TABLE projects
+(
+    id           BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id      BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    workspace_id BIGINT REFERENCES workspaces (id) ON DELETE SET NULL,
+    name         TEXT        NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_projects_userid ON projects (user_id);
+CREATE INDEX idx_projects_workspace ON projects (workspace_id);
+
+CREATE TABLE tasks
+(
+    id          BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id     BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_by  BIGINT,
+    assignee_id BIGINT REFERENCES users (id) ON DELETE SET NULL,
+    project_id  BIGINT REFERENCES projects (id) ON DELETE SET NULL,
+    task_name   TEXT        NOT NULL,
+    description TEXT        NOT NULL DEFAULT '',
+    status      TEXT        NOT NULL DEFAULT 'Pending',
+    priority    TEXT        NOT NULL DEFAULT '',
+    recurrence  TEXT        NOT NULL DEFAULT '',
+    due_at      TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_tasks_userid ON tasks (user_id);
+CREATE INDEX idx_tasks_projectid ON tasks (project_id);
+CREATE INDEX idx_tasks_assignee ON tasks (assignee_id);
+
+CREATE TABLE task_tags
+(
+    task_id BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    tag     TEXT   NOT NULL,
+    PRIMARY KEY (task_id, tag)
+);
+
+CREATE INDEX idx_task_tags_tag ON task_tags (tag);
+
+CREATE TABLE workflow_statuses
+(
+    id         BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    project_id BIGINT REFERENCES projects (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    category   TEXT        NOT NULL,
+    position   INTEGER     NOT NULL,
+    wip_limit  INTEGER     NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_workflow_statuses_scope_name ON workflow_statuses (user_id, COALESCE(project_id, 0), name);
+
+CREATE TABLE custom_fields
+(
+    id         BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    project_id BIGINT      NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    type       TEXT        NOT NULL,
+    options    TEXT        NOT NULL DEFAULT '[]',
+    position   INTEGER     NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_custom_fields_project_name ON custom_fields (project_id, name);
+
+CREATE TABLE task_field_values
+(
+    task_id  BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    field_id BIGINT NOT NULL REFERENCES custom_fields (id) ON DELETE CASCADE,
+    value    TEXT   NOT NULL,
+    PRIMARY KEY (task_id, field_id)
+);
+
+CREATE INDEX idx_task_field_values_field ON task_field_values (field_id, value);
+
+CREATE TABLE smart_lists
+(
+    id         BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name       TEXT        NOT NULL,
+    query      TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_smart_lists_userid ON smart_lists (user_id);
+
+CREATE TABLE task_shares
+(
+    task_id    BIGINT      NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    permission TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX idx_task_shares_user ON task_shares (user_id);
+
+CREATE TABLE task_comments
+(
+    id         BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    task_id    BIGINT      NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    body       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_task_comments_task ON task_comments (task_id, created_at);
+
+CREATE TABLE task_watchers
+(
+    task_id    BIGINT      NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (task_id, user_id)
+);
+
+CREATE TABLE notifications
+(
+    id         BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    task_id    BIGINT      NOT NULL,
+    actor_id   BIGINT      NOT NULL,
+    event      TEXT        NOT NULL,
+    message    TEXT        NOT NULL,
+    read_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_notifications_user ON notifications (user_id, created_at);
+
+CREATE TABLE notification_preferences
+(
+    user_id BIGINT  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    event   TEXT    NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, event)
+);
+
+CREATE TABLE webhooks
+(
+    id         BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url        TEXT        NOT NULL,
+    secret     TEXT        NOT NULL,
+    events     TEXT        NOT NULL,
+    active     BOOLEAN     NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_webhooks_user ON webhooks (user_id);
+
+CREATE TABLE webhook_deliveries
+(
+    id              BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    webhook_id      BIGINT      NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event           TEXT        NOT NULL,
+    payload         BYTEA       NOT NULL,
+    status          TEXT        NOT NULL,
+    attempts        INTEGER     NOT NULL DEFAULT 0,
+    response_code   INTEGER,
+    error           TEXT        NOT NULL DEFAULT '',
+    redelivery_of   BIGINT,
+    next_attempt_at TIMESTAMPTZ,
+    last_attempt_at TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, created_at);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+
+CREATE TABLE app_passwords
+(
+    id           BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id      BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name         TEXT        NOT NULL,
+    token_hash   TEXT        NOT NULL UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_app_passwords_user ON app_passwords (user_id);
+
+CREATE TABLE caldav_objects
+(
+    task_id BIGINT PRIMARY KEY REFERENCES tasks (id) ON DELETE CASCADE,
+    name    TEXT NOT NULL,
+    uid     TEXT NOT NULL
+);
+
+-- task_changes is the change log for sync, seq is the version of the change.
+-- Rows are written by triggers so every writer of tasks is covered,
+-- user_id is set for access rows and is the user who got or lost the share
+CREATE TABLE task_changes
+(
+    seq        BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    task_id    BIGINT      NOT NULL,
+    field      TEXT        NOT NULL,
+    user_id    BIGINT,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_task_changes_task ON task_changes (task_id, field);
+
+-- task_tombstones keep the owner, project and assignee of deleted tasks to decide who is told about deletion
+CREATE TABLE task_tombstones
+(
+    task_id     BIGINT PRIMARY KEY,
+    user_id     BIGINT      NOT NULL,
+    project_id  BIGINT,
+    assignee_id BIGINT,
+    deleted_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- sync_client_ids make creation of tasks from offline clients idempotent
+CREATE TABLE sync_client_ids
+(
+    user_id    BIGINT      NOT NULL,
+    client_id  TEXT        NOT NULL,
+    task_id    BIGINT      NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, client_id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION task_changes_tasks() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'created');
+        RETURN NULL;
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO task_changes (task_id, field) VALUES (OLD.id, 'deleted');
+        INSERT INTO task_tombstones (task_id, user_id, project_id, assignee_id)
+        VALUES (OLD.id, OLD.user_id, OLD.project_id, OLD.assignee_id)
+        ON CONFLICT (task_id) DO UPDATE SET user_id     = excluded.user_id,
+                                            project_id  = excluded.project_id,
+                                            assignee_id = excluded.assignee_id,
+                                            deleted_at  = excluded.deleted_at;
+        RETURN NULL;
+    END IF;
+
+    IF OLD.task_name IS DISTINCT FROM NEW.task_name THEN
+        INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'title');
+    END IF;
+    IF OLD.description IS DISTINCT FROM NEW.description THEN
+        INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'description');
+    END IF;
+    IF OLD.status IS DISTINCT FROM NEW.status THEN
+        INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'status');
+    END IF;
+    IF OLD.priority IS DISTINCT FROM NEW.priority THEN
+        INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'priority');
+    END IF;
+    IF OLD.recurrence IS DISTINCT FROM NEW.recurrence THEN
+        INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'recurrence');
+    END IF;
+    IF OLD.due_at IS DISTINCT FROM NEW.due_at THEN
+        INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'due');
+    END IF;
+    IF OLD.assignee_id IS DISTINCT FROM NEW.assignee_id THEN
+        INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'assignee');
+    END IF;
+    IF OLD.project_id IS DISTINCT FROM NEW.project_id THEN
+        INSERT INTO task_changes (task_id, field) VALUES (NEW.id, 'project');
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+-- task_changes_child logs changes of rows which belong to a task, the field is the trigger argument.
+-- Rows deleted together with their task are not logged, the task deletion is
+CREATE FUNCTION task_changes_child() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_changes (task_id, field) VALUES (NEW.task_id, TG_ARGV[0]);
+    ELSIF TG_OP = 'UPDATE' THEN
+        IF OLD IS DISTINCT FROM NEW THEN
+            INSERT INTO task_changes (task_id, field) VALUES (NEW.task_id, TG_ARGV[0]);
+        END IF;
+    ELSIF EXISTS (SELECT 1 FROM tasks WHERE id = OLD.task_id) THEN
+        INSERT INTO task_changes (task_id, field) VALUES (OLD.task_id, TG_ARGV[0]);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION task_changes_share() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO task_changes (task_id, field, user_id) VALUES (NEW.task_id, 'access', NEW.user_id);
+    ELSE
+        INSERT INTO task_changes (task_id, field, user_id) VALUES (OLD.task_id, 'access', OLD.user_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER task_changes_tasks
+    AFTER INSERT OR DELETE OR UPDATE
+    ON tasks
+    FOR EACH ROW
+EXECUTE FUNCTION task_changes_tasks();
+
+CREATE TRIGGER task_changes_tags
+    AFTER INSERT OR DELETE
+    ON task_tags
+    FOR EACH ROW
+EXECUTE FUNCTION task_changes_child('tags');
+
+CREATE TRIGGER task_changes_fields
+    AFTER INSERT OR UPDATE OR DELETE
+    ON task_field_values
+    FOR EACH ROW
+EXECUTE FUNCTION task_changes_child('custom_fields');
+
+CREATE TRIGGER task_changes_share
+    AFTER INSERT OR DELETE
+    ON task_shares
+    FOR EACH ROW
+EXECUTE FUNCTION task_changes_share();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE if exists sync_client_ids;
+DROP TABLE if exists task_tombstones;
+DROP TABLE if exists task_changes;
+DROP TABLE if exists caldav_objects;
+DROP TABLE if exists app_passwords;
+DROP TABLE if exists webhook_deliveries;
+DROP TABLE if exists webhooks;
+DROP TABLE if exists notification_preferences;
+DROP TABLE if exists notifications;
+DROP TABLE if exists task_watchers;
+DROP TABLE if exists task_comments;
+DROP TABLE if exists task_shares;
+DROP TABLE if exists smart_lists;
+DROP TABLE if exists task_field_values;
+DROP TABLE if exists custom_fields;
+DROP TABLE if exists workflow_statuses;
+DROP TABLE if exists task_tags;
+DROP TABLE if exists tasks;
+DROP TABLE if exists projects;
+DROP TABLE if exists workspace_invitations;
+DROP TABLE if exists workspace_members;
+DROP TABLE if exists workspaces;
+DROP TABLE if exists users;
+DROP FUNCTION if exists task_changes_share();
+DROP FUNCTION if exists task_changes_child();
+DROP FUNCTION if exists task_changes_tasks();
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- watchers of a deleted task are read by notifications after the deletion and removed by them
+ALTER TABLE task_watchers DROP CONSTRAINT task_watchers_task_id_fkey;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM task_watchers w WHERE NOT EXISTS (SELECT 1 FROM tasks t WHERE t.id = w.task_id);
+ALTER TABLE task_watchers ADD CONSTRAINT task_watchers_task_id_fkey
+    FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE;
+-- +goose StatementEnd
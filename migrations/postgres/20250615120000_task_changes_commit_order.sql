@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- seq of a change is taken under a lock held until the writing transaction ends, so changes
+-- become visible in seq order and a sync token never passes a change committed after it.
+-- The identity default is evaluated before the trigger, seq is taken again after the lock
+CREATE FUNCTION task_changes_commit_order() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('task_changes'));
+    NEW.seq := nextval(pg_get_serial_sequence('task_changes', 'seq'));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER task_changes_commit_order
+    BEFORE INSERT
+    ON task_changes
+    FOR EACH ROW
+EXECUTE FUNCTION task_changes_commit_order();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER if exists task_changes_commit_order ON task_changes;
+DROP FUNCTION if exists task_changes_commit_order();
+-- +goose StatementEnd
@@ -0,0 +1,385 @@
+// Package client is the Go client of the TaskList REST API.
+//
+//	c := client.New("http://localhost:8080", client.WithTokenRefresh(client.LoginRefresh(email, password)))
+//	id, err := c.CreateTask(ctx, client.TaskRequest{Title: "Pay rent"})
+//
+// Requests are retried with backoff on 429 and 5xx answers, errors of the server are *Error
+// and match the sentinel errors like ErrNotFound with errors.Is
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxResponseSize limits the body read from the server
+const maxResponseSize = 32 << 20
+
+// tokenLeeway refreshes tokens which expire in a moment instead of sending them
+const tokenLeeway = 10 * time.Second
+
+// RefreshFunc returns a new token when the current one is missing, expired or rejected.
+// The server has no refresh tokens, LoginRefresh logs in again with the credentials
+type RefreshFunc func(ctx context.Context, c *Client) (string, error)
+
+// RetryPolicy retries requests answered with 429 or 5xx. 429 and 503 are retried for every method,
+// other 5xx only for GET, PUT, PATCH and DELETE so a POST is not applied twice
+type RetryPolicy struct {
+	// MaxAttempts includes the first request, 1 disables retries
+	MaxAttempts int
+	// MinBackoff is doubled for every attempt up to MaxBackoff, a random jitter spreads retries
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  200 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+type Client struct {
+	baseURL string
+	http    *http.Client
+	retry   RetryPolicy
+	refresh RefreshFunc
+	onToken func(token string)
+	now     func() time.Time
+
+	mu    sync.Mutex
+	token string
+	// refreshMu makes concurrent requests wait for one refresh
+	refreshMu sync.Mutex
+}
+
+type Option func(c *Client)
+
+// WithHTTPClient sets the http client, http.DefaultClient is used by default
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) {
+		c.http = h
+	}
+}
+
+// WithToken sets the token of a previous Login
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// WithTokenRefresh sets the function called for a new token before sending an expired one
+// and once per request after 401
+func WithTokenRefresh(fn RefreshFunc) Option {
+	return func(c *Client) {
+		c.refresh = fn
+	}
+}
+
+// WithTokenHook sets the function called with every new token of Login and refresh, e.g. to store it
+func WithTokenHook(fn func(token string)) Option {
+	return func(c *Client) {
+		c.onToken = fn
+	}
+}
+
+// WithClock sets the time used to check expiry of the token
+func WithClock(now func() time.Time) Option {
+	return func(c *Client) {
+		c.now = now
+	}
+}
+
+// New returns the client of the server at baseURL like http://localhost:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    http.DefaultClient,
+		retry:   DefaultRetryPolicy,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.http == nil {
+		c.http = http.DefaultClient
+	}
+	if c.retry.MaxAttempts < 1 {
+		c.retry.MaxAttempts = 1
+	}
+	return c
+}
+
+// LoginRefresh logs in with the credentials when the token has to be refreshed
+func LoginRefresh(email string, password string) RefreshFunc {
+	return func(ctx context.Context, c *Client) (string, error) {
+		return c.login(ctx, email, password)
+	}
+}
+
+// Token returns the current token, empty before Login
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// SetToken replaces the token, the token hook is not called
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+func (c *Client) setToken(token string) {
+	c.SetToken(token)
+	if c.onToken != nil {
+		c.onToken(token)
+	}
+}
+
+// request is one API call, body is kept encoded so every attempt sends it again
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	body   []byte
+	auth   bool
+}
+
+// do sends the request with retries and decodes the answer into out
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, in any, out any, auth bool) error {
+	req := request{method: method, path: path, query: query, auth: auth}
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed encode request: %w", err)
+		}
+		req.body = data
+	}
+
+	refreshed := false
+	if auth {
+		var err error
+		if refreshed, err = c.validToken(ctx); err != nil {
+			return err
+		}
+	}
+
+	for {
+		sent := c.Token()
+		data, err := c.send(ctx, req)
+
+		var apiErr *Error
+		if auth && !refreshed && c.refresh != nil && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+			refreshed = true
+			if err = c.refreshToken(ctx, sent); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if out == nil {
+			return nil
+		}
+		if err = json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed decode response of %s %s: %w", method, path, err)
+		}
+		return nil
+	}
+}
+
+// send makes the attempts of the request, the error of the last one is returned
+func (c *Client) send(ctx context.Context, req request) ([]byte, error) {
+	for attempt := 1; ; attempt++ {
+		data, err := c.attempt(ctx, req)
+		if err == nil {
+			return data, nil
+		}
+
+		var apiErr *Error
+		if attempt >= c.retry.MaxAttempts || !errors.As(err, &apiErr) || !retryable(req.method, apiErr.StatusCode) {
+			return nil, err
+		}
+
+		wait := c.retry.backoff(attempt)
+		if apiErr.RetryAfter > 0 {
+			wait = apiErr.RetryAfter
+			if c.retry.MaxBackoff > 0 {
+				wait = min(wait, c.retry.MaxBackoff)
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, req request) ([]byte, error) {
+	u := c.baseURL + req.path
+	if len(req.query) > 0 {
+		u += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, req.method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Accept", "application/json")
+	if req.body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if req.auth {
+		r.Header.Set("Authorization", "Bearer "+c.Token())
+	}
+
+	resp, err := c.http.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed read response of %s %s: %w", req.method, req.path, err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, newError(resp, data)
+	}
+	return data, nil
+}
+
+// validToken refreshes the token when it is missing or expires and reports whether it did
+func (c *Client) validToken(ctx context.Context) (bool, error) {
+	token := c.Token()
+	expired := token != "" && tokenExpired(token, c.now().Add(tokenLeeway))
+	if token != "" && !expired {
+		return false, nil
+	}
+
+	if c.refresh == nil {
+		if expired {
+			return false, ErrTokenExpired
+		}
+		return false, ErrNoToken
+	}
+
+	if err := c.refreshToken(ctx, token); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// refreshToken replaces the stale token, a token already replaced by another request is kept
+func (c *Client) refreshToken(ctx context.Context, stale string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	if current := c.Token(); current != "" && current != stale {
+		return nil
+	}
+
+	token, err := c.refresh(ctx, c)
+	if err != nil {
+		return fmt.Errorf("failed refresh token: %w", err)
+	}
+	if token == "" {
+		return fmt.Errorf("failed refresh token: %w", ErrNoToken)
+	}
+	if token != c.Token() {
+		c.setToken(token)
+	}
+	return nil
+}
+
+// tokenExpired reads exp of the JWT without verifying it, the server verifies the signature.
+// Tokens which can not be read are sent and left to the server
+func tokenExpired(token string, at time.Time) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err = json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return false
+	}
+	return !time.Unix(claims.Exp, 0).After(at)
+}
+
+func retryable(method string, status int) bool {
+	switch {
+	case status == http.StatusTooManyRequests, status == http.StatusServiceUnavailable:
+		return true
+	case status >= http.StatusInternalServerError:
+		return method != http.MethodPost
+	default:
+		return false
+	}
+}
+
+// backoff is the wait before the next attempt, random in the upper half of the exponential delay
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.MinBackoff <= 0 {
+		return 0
+	}
+	d := p.MinBackoff << (attempt - 1)
+	if d <= 0 || (p.MaxBackoff > 0 && d > p.MaxBackoff) {
+		d = p.MaxBackoff
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter parses Retry-After in seconds or as http date
+func retryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil && s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"TaskList/internal/lib/http/response"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrNoToken is returned by authenticated calls before Login when there is no token refresh
+	ErrNoToken = errors.New("client: not logged in")
+	// ErrTokenExpired is returned instead of sending the expired token when there is no token refresh
+	ErrTokenExpired = errors.New("client: token expired")
+
+	ErrBadRequest      = errors.New("bad request")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrForbidden       = errors.New("forbidden")
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrGone            = errors.New("gone")
+	ErrTooLarge        = errors.New("request too large")
+	ErrTooManyRequests = errors.New("too many requests")
+	ErrServer          = errors.New("server error")
+)
+
+// Error is the error answer of the server
+type Error struct {
+	StatusCode int
+	// Message is the error of response.Response or the plain message of the auth middleware
+	Message string
+	// RetryAfter is the Retry-After header of 429 and 503 answers
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("server answered %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("server answered %d: %s", e.StatusCode, e.Message)
+}
+
+// Unwrap returns the sentinel error of the status so errors.Is(err, ErrNotFound) works
+func (e *Error) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return ErrBadRequest
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusConflict:
+		return ErrConflict
+	case e.StatusCode == http.StatusGone:
+		return ErrGone
+	case e.StatusCode == http.StatusRequestEntityTooLarge:
+		return ErrTooLarge
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrTooManyRequests
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrServer
+	default:
+		return nil
+	}
+}
+
+func newError(resp *http.Response, data []byte) *Error {
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		RetryAfter: retryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+
+	var res response.Response
+	var msg string
+	switch {
+	case json.Unmarshal(data, &res) == nil && res.Error != "":
+		e.Message = res.Error
+	case json.Unmarshal(data, &msg) == nil:
+		e.Message = msg
+	}
+
+	return e
+}
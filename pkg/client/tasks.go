@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Registration creates the user and returns its id
+func (c *Client) Registration(ctx context.Context, email string, password string) (int64, error) {
+	var res idResponse
+	body := map[string]string{"email": email, "password": password}
+	if err := c.do(ctx, http.MethodPost, "/registration", nil, body, &res, false); err != nil {
+		return 0, err
+	}
+	return res.ID, nil
+}
+
+// Login returns the token, the client uses it for the next calls and passes it to the token hook
+func (c *Client) Login(ctx context.Context, email string, password string) (string, error) {
+	token, err := c.login(ctx, email, password)
+	if err != nil {
+		return "", err
+	}
+	c.setToken(token)
+	return token, nil
+}
+
+func (c *Client) login(ctx context.Context, email string, password string) (string, error) {
+	var res loginResponse
+	body := map[string]string{"email": email, "password": password}
+	if err := c.do(ctx, http.MethodPost, "/login", nil, body, &res, false); err != nil {
+		return "", err
+	}
+	return res.Token, nil
+}
+
+func (c *Client) Tasks(ctx context.Context, filter TaskFilter) ([]Task, error) {
+	var res tasksResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/tasks", filter.values(), nil, &res, true); err != nil {
+		return nil, err
+	}
+	return res.Tasks, nil
+}
+
+func (c *Client) Task(ctx context.Context, id int64) (Task, error) {
+	var res tasksResponse
+	if err := c.do(ctx, http.MethodGet, taskPath(id, ""), nil, nil, &res, true); err != nil {
+		return Task{}, err
+	}
+	if len(res.Tasks) == 0 {
+		return Task{}, &Error{StatusCode: http.StatusNotFound, Message: "task not found"}
+	}
+	return res.Tasks[0], nil
+}
+
+// CreateTask returns the id of the created task
+func (c *Client) CreateTask(ctx context.Context, req TaskRequest) (int64, error) {
+	var res idResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/tasks", nil, req, &res, true); err != nil {
+		return 0, err
+	}
+	return res.ID, nil
+}
+
+// QuickAdd creates the task from one line like "Pay rent tomorrow 9am #home !high"
+func (c *Client) QuickAdd(ctx context.Context, text string) (int64, error) {
+	var res quickAddResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/tasks/quick", nil, map[string]any{"text": text}, &res, true); err != nil {
+		return 0, err
+	}
+	return res.ID, nil
+}
+
+// ParseQuickAdd returns how the server reads the line without creating the task
+func (c *Client) ParseQuickAdd(ctx context.Context, text string) (QuickAddParsed, error) {
+	var res quickAddResponse
+	body := map[string]any{"text": text, "dry_run": true}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/tasks/quick", nil, body, &res, true); err != nil {
+		return QuickAddParsed{}, err
+	}
+	if res.Parsed == nil {
+		return QuickAddParsed{}, nil
+	}
+	return *res.Parsed, nil
+}
+
+// UpdateTask changes the set fields and returns the updated task
+func (c *Client) UpdateTask(ctx context.Context, id int64, req UpdateTaskRequest) (Task, error) {
+	var res tasksResponse
+	if err := c.do(ctx, http.MethodPatch, taskPath(id, ""), nil, req, &res, true); err != nil {
+		return Task{}, err
+	}
+	if len(res.Tasks) == 0 {
+		return Task{}, nil
+	}
+	return res.Tasks[0], nil
+}
+
+func (c *Client) DeleteTask(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, taskPath(id, ""), nil, nil, nil, true)
+}
+
+// SetTaskFields sets custom field values by name, nil value clears the field
+func (c *Client) SetTaskFields(ctx context.Context, id int64, values map[string]any) error {
+	return c.do(ctx, http.MethodPut, taskPath(id, "/fields"), nil, values, nil, true)
+}
+
+// AssignTask assigns the task to the user with the email, empty email removes the assignee
+func (c *Client) AssignTask(ctx context.Context, id int64, email string) error {
+	return c.do(ctx, http.MethodPut, taskPath(id, "/assignee"), nil, map[string]string{"email": email}, nil, true)
+}
+
+// Statuses returns the workflow of the project, the workflow of tasks without project for nil
+func (c *Client) Statuses(ctx context.Context, projectID *int64) ([]WorkflowStatus, error) {
+	var query url.Values
+	if projectID != nil {
+		query = url.Values{"project_id": {strconv.FormatInt(*projectID, 10)}}
+	}
+
+	var res statusesResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/statuses", query, nil, &res, true); err != nil {
+		return nil, err
+	}
+	return res.Statuses, nil
+}
+
+func taskPath(id int64, suffix string) string {
+	return "/api/v1/tasks/" + strconv.FormatInt(id, 10) + suffix
+}
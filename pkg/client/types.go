@@ -0,0 +1,177 @@
+package client
+
+import (
+	"TaskList/internal/lib/http/response"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Task mirrors controller.Task
+type Task struct {
+	ID             int64          `json:"id"`
+	UserID         int64          `json:"user_id"`
+	AssigneeID     *int64         `json:"assignee_id,omitempty"`
+	ProjectID      *int64         `json:"project_id,omitempty"`
+	Title          string         `json:"title"`
+	Description    string         `json:"description,omitempty"`
+	Status         string         `json:"status"`
+	StatusCategory string         `json:"status_category,omitempty"`
+	Priority       string         `json:"priority,omitempty"`
+	Tags           []string       `json:"tags,omitempty"`
+	Recurrence     string         `json:"recurrence,omitempty"`
+	CustomFields   map[string]any `json:"custom_fields,omitempty"`
+	Due            *time.Time     `json:"due,omitempty"`
+	CreatedAt      time.Time      `json:"created"`
+	UpdatedAt      time.Time      `json:"updated"`
+}
+
+// TaskRequest mirrors controller.TaskRequest
+type TaskRequest struct {
+	ProjectID   *int64     `json:"project_id,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"`
+	Due         *time.Time `json:"due,omitempty"`
+	// CustomFields are values by field name, fields must be defined in the task project
+	CustomFields map[string]any `json:"custom_fields,omitempty"`
+}
+
+// UpdateTaskRequest mirrors controller.UpdateTaskRequest, nil fields are not changed
+type UpdateTaskRequest struct {
+	Title       *string    `json:"title,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Status      *string    `json:"status,omitempty"`
+	Priority    *string    `json:"priority,omitempty"`
+	Tags        *[]string  `json:"tags,omitempty"`
+	Recurrence  *string    `json:"recurrence,omitempty"`
+	Due         *time.Time `json:"due,omitempty"`
+	ClearDue    bool       `json:"clear_due,omitempty"`
+}
+
+// QuickAddParsed mirrors controller.QuickAddParsed
+type QuickAddParsed struct {
+	Title      string     `json:"title"`
+	Due        *time.Time `json:"due,omitempty"`
+	AllDay     bool       `json:"all_day,omitempty"`
+	Tags       []string   `json:"tags,omitempty"`
+	Priority   string     `json:"priority,omitempty"`
+	Recurrence string     `json:"recurrence,omitempty"`
+}
+
+// WorkflowStatus mirrors controller.WorkflowStatus
+type WorkflowStatus struct {
+	ID        int64  `json:"id,omitempty"`
+	ProjectID *int64 `json:"project_id,omitempty"`
+	Name      string `json:"name"`
+	Category  string `json:"category"`
+	Position  int    `json:"position"`
+	WIPLimit  int    `json:"wip_limit,omitempty"`
+}
+
+// TaskFilter is the query of GET /api/v1/tasks, zero fields are not sent
+type TaskFilter struct {
+	// Scope is owned (default), assigned, shared or all
+	Scope       string
+	WorkspaceID *int64
+	ProjectID   *int64
+	Statuses    []string
+	// Categories are todo, in_progress or done
+	Categories []string
+	Tags       []string
+	Text       string
+	// Due is overdue, today, upcoming, none, or range with DueFrom and DueTo
+	Due     string
+	DueDays int
+	DueFrom *time.Time
+	DueTo   *time.Time
+	Fields  []FieldCondition
+	// Sort is due, priority, created, updated, title or cf.<field>
+	Sort string
+	Desc bool
+}
+
+// FieldCondition filters by custom field, Op is eq, ne, gt, gte, lt, lte or contains
+type FieldCondition struct {
+	Name  string
+	Op    string
+	Value string
+}
+
+func (f TaskFilter) values() url.Values {
+	q := url.Values{}
+	set := func(name string, v string) {
+		if v != "" {
+			q.Set(name, v)
+		}
+	}
+
+	set("scope", f.Scope)
+	if f.WorkspaceID != nil {
+		q.Set("workspace_id", strconv.FormatInt(*f.WorkspaceID, 10))
+	}
+	if f.ProjectID != nil {
+		q.Set("project_id", strconv.FormatInt(*f.ProjectID, 10))
+	}
+	q["status"] = f.Statuses
+	q["category"] = f.Categories
+	q["tag"] = f.Tags
+	set("q", f.Text)
+	set("due", f.Due)
+	if f.DueDays != 0 {
+		q.Set("due_days", strconv.Itoa(f.DueDays))
+	}
+	if f.DueFrom != nil {
+		q.Set("due_from", f.DueFrom.Format(time.RFC3339))
+	}
+	if f.DueTo != nil {
+		q.Set("due_to", f.DueTo.Format(time.RFC3339))
+	}
+	for _, c := range f.Fields {
+		key := "cf." + c.Name
+		if c.Op != "" && c.Op != "eq" {
+			key += "[" + c.Op + "]"
+		}
+		q.Add(key, c.Value)
+	}
+	set("sort", f.Sort)
+	if f.Desc {
+		q.Set("order", "desc")
+	}
+
+	for k, v := range q {
+		if len(v) == 0 {
+			delete(q, k)
+		}
+	}
+	return q
+}
+
+type loginResponse struct {
+	response.Response
+	Token string `json:"token,omitempty"`
+}
+
+type idResponse struct {
+	response.Response
+	ID int64 `json:"id,omitempty"`
+}
+
+type quickAddResponse struct {
+	response.Response
+	ID     int64           `json:"id,omitempty"`
+	Parsed *QuickAddParsed `json:"parsed,omitempty"`
+}
+
+type tasksResponse struct {
+	response.Response
+	Tasks []Task `json:"tasks,omitempty"`
+}
+
+type statusesResponse struct {
+	response.Response
+	Statuses []WorkflowStatus `json:"statuses,omitempty"`
+}